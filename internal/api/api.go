@@ -0,0 +1,184 @@
+// Package api serves the signing and the verification of personal messages (EIP-191) and typed
+// data (EIP-712) over http on a loopback address, it's run with `protonet serve`
+//
+//	POST /sign   {"kind": "message|typed-data", "data": "..."}
+//	POST /verify {"kind": "message|typed-data", "address": "0x...", "signature": "0x...", "data": "..."}
+//
+// A message is text or 0x prefixed hex and typed data is EIP-712 json, as in the Sign tab.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/wallet"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultAddress is the address the api listens on when none is given
+const DefaultAddress = "127.0.0.1:8680"
+
+// maxRequestSize limits the size of the body of a request, the data signed or verified is in it
+const maxRequestSize = 1 << 20
+
+var (
+	ErrNotLoopback    = errors.New("the api listens on a loopback address only")
+	ErrSignRejected   = errors.New("signing rejected")
+	ErrInvalidKind    = errors.New(`kind must be "message" or "typed-data"`)
+	ErrBrowserRequest = errors.New("requests from browsers aren't served")
+)
+
+// Signer signs with the current account, *wallet.Wallet is the Signer of the app
+type Signer interface {
+	Account() (model.Account, error)
+	Sign(request wallet.SignRequest) ([]byte, error)
+}
+
+// ApproveFunc asks the user whether the decoded payload of kind is signed by address, nothing is
+// signed unless it returns true
+type ApproveFunc func(kind wallet.SignKind, address, payload string) bool
+
+type SignRequest struct {
+	Kind string `json:"kind"`
+	Data string `json:"data"`
+}
+
+type SignResponse struct {
+	Address   string `json:"address"`
+	Signature string `json:"signature"`
+}
+
+type VerifyRequest struct {
+	Kind      string `json:"kind"`
+	Address   string `json:"address"`
+	Signature string `json:"signature"`
+	Data      string `json:"data"`
+}
+
+// VerifyResponse tells whether the signature is signed by the address, Error is the reason it
+// isn't
+type VerifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type handler struct {
+	signer  Signer
+	approve ApproveFunc
+}
+
+// NewHandler returns the handler of the api, signing is approved with approve
+func NewHandler(signer Signer, approve ApproveFunc) http.Handler {
+	h := &handler{signer: signer, approve: approve}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sign", h.handleSign)
+	mux.HandleFunc("/verify", h.handleVerify)
+	return mux
+}
+
+// ListenAndServe serves handler on address, which must be a loopback address such as
+// DefaultAddress
+func ListenAndServe(address string, handler http.Handler) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return ErrNotLoopback
+	}
+	server := &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+func (h *handler) handleSign(w http.ResponseWriter, r *http.Request) {
+	var req SignRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	request, err := newSignRequest(req.Kind, req.Data)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	payload, err := request.Payload()
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	account, err := h.signer.Account()
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: err.Error()})
+		return
+	}
+	if !h.approve(request.Kind, account.EthAddress, payload) {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: ErrSignRejected.Error()})
+		return
+	}
+	signature, err := h.signer.Sign(request)
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, SignResponse{Address: account.EthAddress, Signature: hexutil.Encode(signature)})
+}
+
+func (h *handler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req VerifyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	request, err := newSignRequest(req.Kind, req.Data)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	if err = request.Verify(req.Address, req.Signature); err != nil {
+		writeJSON(w, http.StatusOK, VerifyResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, VerifyResponse{Valid: true})
+}
+
+// decodeRequest decodes the json body of a POST request into v, the error response is written if
+// it returns false. A request of a browser has an Origin header, any web page could send it.
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return false
+	}
+	if r.Header.Get("Origin") != "" {
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: ErrBrowserRequest.Error()})
+		return false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return false
+	}
+	return true
+}
+
+func newSignRequest(kind, data string) (wallet.SignRequest, error) {
+	signKind, ok := wallet.ParseSignKind(kind)
+	if !ok {
+		return wallet.SignRequest{}, ErrInvalidKind
+	}
+	return wallet.NewSignRequest(signKind, data)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
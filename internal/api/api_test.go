@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/wallet"
+)
+
+const typedData = `{
+	"types": {
+		"EIP712Domain": [{"name": "name", "type": "string"}, {"name": "chainId", "type": "uint256"}],
+		"Mail": [{"name": "contents", "type": "string"}]
+	},
+	"primaryType": "Mail",
+	"domain": {"name": "Protonet", "chainId": 1},
+	"message": {"contents": "hello"}
+}`
+
+// testSigner signs with a key generated for the test
+type testSigner struct {
+	pvtKeyHex string
+	address   string
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testSigner{
+		pvtKeyHex: hex.EncodeToString(crypto.FromECDSA(key)),
+		address:   crypto.PubkeyToAddress(key.PublicKey).Hex(),
+	}
+}
+
+func (s *testSigner) Account() (model.Account, error) {
+	return model.Account{EthAddress: s.address}, nil
+}
+
+func (s *testSigner) Sign(request wallet.SignRequest) ([]byte, error) {
+	if request.Kind == wallet.SignKindTypedData {
+		return evm.SignTypedData(s.pvtKeyHex, request.TypedData)
+	}
+	return evm.SignText(s.pvtKeyHex, request.Message)
+}
+
+func post(t *testing.T, handler http.Handler, path string, body interface{}, header http.Header) (int, []byte) {
+	t.Helper()
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.Bytes()
+}
+
+func TestSignAndVerify(t *testing.T) {
+	signer := newTestSigner(t)
+	var approved string
+	handler := NewHandler(signer, func(kind wallet.SignKind, address, payload string) bool {
+		approved = address
+		return true
+	})
+	for _, test := range []SignRequest{{Kind: "message", Data: "hello"}, {Kind: "typed-data", Data: typedData}} {
+		code, body := post(t, handler, "/sign", test, nil)
+		if code != http.StatusOK {
+			t.Fatalf("sign %s: status %d, %s", test.Kind, code, body)
+		}
+		var signed SignResponse
+		if err := json.Unmarshal(body, &signed); err != nil {
+			t.Fatal(err)
+		}
+		if signed.Address != signer.address || approved != signer.address {
+			t.Fatalf("sign %s: signed by %s, approved for %s, want %s", test.Kind, signed.Address, approved, signer.address)
+		}
+		verify := VerifyRequest{Kind: test.Kind, Address: signer.address, Signature: signed.Signature, Data: test.Data}
+		code, body = post(t, handler, "/verify", verify, nil)
+		var verified VerifyResponse
+		if err := json.Unmarshal(body, &verified); err != nil {
+			t.Fatal(err)
+		}
+		if code != http.StatusOK || !verified.Valid {
+			t.Fatalf("verify %s: status %d, %s", test.Kind, code, body)
+		}
+		// the signature of another address isn't valid
+		verify.Address = newTestSigner(t).address
+		_, body = post(t, handler, "/verify", verify, nil)
+		verified = VerifyResponse{}
+		if err := json.Unmarshal(body, &verified); err != nil {
+			t.Fatal(err)
+		}
+		if verified.Valid || verified.Error != wallet.ErrSignerMismatch.Error() {
+			t.Fatalf("verify %s of another address: %s", test.Kind, body)
+		}
+	}
+}
+
+func TestSignRejected(t *testing.T) {
+	handler := NewHandler(newTestSigner(t), func(wallet.SignKind, string, string) bool { return false })
+	code, body := post(t, handler, "/sign", SignRequest{Kind: "message", Data: "hello"}, nil)
+	if code != http.StatusForbidden {
+		t.Fatalf("status %d, %s", code, body)
+	}
+}
+
+func TestRefusedRequests(t *testing.T) {
+	handler := NewHandler(newTestSigner(t), func(wallet.SignKind, string, string) bool {
+		t.Fatal("approval asked for a refused request")
+		return false
+	})
+	code, _ := post(t, handler, "/sign", SignRequest{Kind: "message", Data: "hello"}, http.Header{"Origin": {"https://example.com"}})
+	if code != http.StatusForbidden {
+		t.Errorf("request with origin: status %d", code)
+	}
+	code, _ = post(t, handler, "/sign", SignRequest{Kind: "transaction", Data: "hello"}, nil)
+	if code != http.StatusBadRequest {
+		t.Errorf("unknown kind: status %d", code)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sign", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("get: status %d", rec.Code)
+	}
+}
+
+func TestListenAndServeLoopbackOnly(t *testing.T) {
+	if err := ListenAndServe("0.0.0.0:0", http.NotFoundHandler()); err != ErrNotLoopback {
+		t.Fatalf("got %v, want %v", err, ErrNotLoopback)
+	}
+}
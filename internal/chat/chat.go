@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"context"
 	"encoding/binary"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/dgraph-io/badger/v4"
@@ -21,6 +20,8 @@ import (
 	routedhost "github.com/libp2p/go-libp2p/p2p/host/routed"
 	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/db"
+	"github.com/mearaj/protonet/internal/model"
 	"github.com/mearaj/protonet/internal/pubsub"
 	"github.com/mearaj/protonet/internal/wallet"
 	"github.com/mearaj/protonet/utils"
@@ -31,21 +32,49 @@ import (
 
 var ErrStreamReset = network.ErrReset
 
+// ErrLegacyChat is returned when the peer supports only ECDSA identities and either side isn't ECDSA
+var ErrLegacyChat = errors.New("peer's version supports only ecdsa identities")
+
+var (
+	ErrContactBlocked     = errors.New("contact is blocked")
+	ErrRateLimited        = errors.New("peer sends messages too fast")
+	ErrMessageTooLarge    = errors.New("message is too large")
+	ErrRequestQuarantined = errors.New("contact request has too many messages")
+)
+
 type Chat interface {
 	SendNewMessage(account *Account, message *Message)
 }
 type chat struct {
 	host      host.Host
+	routing   *dht.IpfsDHT
 	hostError error
 	hostMutex sync.RWMutex
-	// chatStreams key is publicKey of peer
+	// identityRecord is the signed identity of the current account served over ProtocolIdentity
+	identityRecord IdentityRecord
+	identityMutex  sync.RWMutex
+	// chatStreams key is publicKey of peer, the account key of a primary device or the device
+	// key of a linked device
 	chatStreams      utils.Map[string, network.Stream]
 	chatStreamsOutCh utils.Map[string, chan Message]
+	// lockedQueue holds the messages received while the wallet is locked
+	lockedQueue lockedQueue
+	// rateLimiter limits the messages received from each peer
+	rateLimiter rateLimiter
+	// signalLimiter limits the signals received from each peer
+	signalLimiter rateLimiter
+	// chatStreamsSignalCh key is publicKey of peer, its signals are written between the messages
+	chatStreamsSignalCh utils.Map[string, chan signal]
+	// presence holds the ephemeral online and typing state of the contacts
+	presence presenceTracker
 }
 
 var GlobalChat = chat{
-	chatStreams:      utils.NewMap[string, network.Stream](),
-	chatStreamsOutCh: utils.NewMap[string, chan Message](),
+	chatStreams:         utils.NewMap[string, network.Stream](),
+	chatStreamsOutCh:    utils.NewMap[string, chan Message](),
+	chatStreamsSignalCh: utils.NewMap[string, chan signal](),
+	rateLimiter:         rateLimiter{burst: peerMessageBurst, interval: peerMessageInterval},
+	signalLimiter:       rateLimiter{burst: peerSignalBurst, interval: peerSignalInterval},
 }
 
 func init() {
@@ -60,36 +89,98 @@ func (c *chat) Host() (host.Host, error) {
 	return c.host, c.hostError
 }
 
-func (c *chat) setHost(host host.Host, err error) {
+func (c *chat) setHost(host host.Host, routing *dht.IpfsDHT, err error) {
 	c.hostMutex.Lock()
 	defer c.hostMutex.Unlock()
 	c.host = host
+	c.routing = routing
 	c.hostError = err
 }
 
 func (c *chat) handleHostChatStream(stream network.Stream) {
 	pubKey := stream.Conn().RemotePublicKey()
-	pubKeyBytes, err := pubKey.Raw()
+	if stream.Protocol() == ProtocolChatLegacy && !legacyChatSupported(pubKey) {
+		alog.Logger().Errorln(ErrLegacyChat)
+		_ = stream.Reset()
+		return
+	}
+	peerKeyHex, err := peerKey(pubKey)
 	if err != nil {
 		alog.Logger().Errorln(err)
 		return
 	}
-	pubKeyStr := hex.EncodeToString(pubKeyBytes)
-	c.chatStreams.Set(pubKeyStr, stream)
-	go c.writeChatStream(stream, pubKeyStr)
-	go c.readChatStream(stream, pubKeyStr)
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		_ = stream.Reset()
+		return
+	}
+	// the database is closed while the wallet is locked, the stream is only read and its
+	// messages are queued until the account of the peer is known after unlock
+	if !wallet.GlobalWallet.IsUnlocked() {
+		go c.readChatStream(stream, peerKeyHex, "")
+		return
+	}
+	// the messages of a device are encrypted to and signed by the key of its account
+	contactPubKeyHex := accountOfPeer(account.PublicKey, peerKeyHex)
+	if wallet.GlobalWallet.IsContactBlocked(account.PublicKey, contactPubKeyHex) {
+		_ = stream.Reset()
+		return
+	}
+	c.chatStreams.Set(peerKeyHex, stream)
+	// the legacy peers don't read the signals
+	if stream.Protocol() == ProtocolChat {
+		c.chatStreamsSignalCh.Set(peerKeyHex, make(chan signal, 4))
+	} else {
+		c.chatStreamsSignalCh.Delete(peerKeyHex)
+	}
+	if _, ok := c.chatStreamsOutCh.Get(peerKeyHex); !ok {
+		c.chatStreamsOutCh.Set(peerKeyHex, make(chan Message, 10))
+	}
+	go c.writeChatStream(stream, peerKeyHex, contactPubKeyHex)
+	go c.readChatStream(stream, peerKeyHex, contactPubKeyHex)
+	go c.sendProfile(stream.Conn().RemotePeer(), contactPubKeyHex)
+	// a linked device has already pushed its devices before opening the stream
+	if stream.Stat().Direction == network.DirInbound || !account.IsLinkedDevice() {
+		go c.pushDevices(stream.Conn().RemotePeer())
+	}
+	if contactPubKeyHex == account.PublicKey {
+		go c.catchUpDevice(account, peerKeyHex)
+		return
+	}
+	c.sendSignal(peerKeyHex, signalOnline)
 }
 
-func (c *chat) readChatStream(stream network.Stream, contactPubKeyHex string) {
+// readChatStream reads the stream of the peer of peerKeyHex, contactPubKeyHex is the account of
+// the peer, it's empty for a stream accepted while the wallet is locked and is resolved after unlock
+func (c *chat) readChatStream(stream network.Stream, peerKeyHex, contactPubKeyHex string) {
 	var err error
 	defer func() {
 		if err != nil {
 			alog.Logger().Errorln(err)
 		}
 		if err != nil && errors.Is(err, ErrStreamReset) {
-			c.chatStreams.Delete(contactPubKeyHex)
+			c.deleteChatStream(peerKeyHex, stream)
 		}
 	}()
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return
+	}
+	// the presence of the other devices of the account isn't tracked
+	fromOwnDevice := contactPubKeyHex == account.PublicKey
+	// resolved returns false if the account of the peer isn't known yet, a blocked peer is reset
+	resolved := func() bool {
+		if contactPubKeyHex != "" || !wallet.GlobalWallet.IsUnlocked() {
+			return contactPubKeyHex != ""
+		}
+		contactPubKeyHex = accountOfPeer(account.PublicKey, peerKeyHex)
+		fromOwnDevice = contactPubKeyHex == account.PublicKey
+		if wallet.GlobalWallet.IsContactBlocked(account.PublicKey, contactPubKeyHex) {
+			err = ErrContactBlocked
+			_ = stream.Reset()
+		}
+		return err == nil
+	}
 	for err == nil || !errors.Is(err, ErrStreamReset) {
 		b := make([]byte, 8)
 		_, err = io.ReadFull(stream, b)
@@ -97,115 +188,234 @@ func (c *chat) readChatStream(stream network.Stream, contactPubKeyHex string) {
 			continue
 		}
 		sizeOfMsg := binary.LittleEndian.Uint32(b)
-		if sizeOfMsg > 0 {
+		frameKind := b[4]
+		if sizeOfMsg > maxChatMessageSize || (frameKind == frameSignal && sizeOfMsg > maxSignalSize) {
+			err = ErrMessageTooLarge
+			_ = stream.Reset()
+			c.deleteChatStream(peerKeyHex, stream)
+			return
+		}
+		if frameKind == frameSignal {
 			pb := make([]byte, sizeOfMsg)
 			_, err = io.ReadFull(stream, pb)
-			if err != nil {
-				continue
+			if err == nil && resolved() && !fromOwnDevice && c.signalLimiter.Allow(peerKeyHex) {
+				c.handleSignal(contactPubKeyHex, pb)
 			}
-			networkMsg := Message{}
-			var acc Account
-			acc, err = wallet.GlobalWallet.Account()
-			if err != nil {
-				continue
-			}
-			//var pvtKeyStr string
-			//pvtKeyStr, err = wallet.GlobalWallet.GetPrivateKey(acc)
-			//if err != nil {
-			//	continue
-			//}
-			err = common.GetDecryptedStruct(acc.PrivateKey, pb, &networkMsg, libcrypto.ECDSA)
-			if err != nil {
-				continue
-			}
-			verPublicKey := acc.PublicKey
-			isMsgCreatedByMe := verPublicKey == networkMsg.Sender
-			if isMsgCreatedByMe {
-				verPublicKey = networkMsg.Recipient
-			}
-			err = common.VerifyMessage(&networkMsg, verPublicKey, libcrypto.ECDSA)
-			if err != nil {
-				continue
-			}
-			remotePublicKey := stream.Conn().RemotePublicKey()
-			var remotePublicKeyBytes []byte
-			remotePublicKeyBytes, err = remotePublicKey.Raw()
+			continue
+		}
+		if sizeOfMsg > 0 {
+			pb := make([]byte, sizeOfMsg)
+			_, err = io.ReadFull(stream, pb)
 			if err != nil {
 				continue
 			}
-			remotePublicKeyHex := hex.EncodeToString(remotePublicKeyBytes)
-			// Message is either created by user or his peer
-			if acc2, err := wallet.GlobalWallet.Account(); acc2.PublicKey != acc.PublicKey || err != nil {
+			// a flooding peer loses its messages until its bucket is refilled
+			if !c.rateLimiter.Allow(peerKeyHex) {
+				alog.Logger().Errorln(ErrRateLimited)
 				continue
 			}
-			msgIsValid := (acc.PublicKey == networkMsg.Recipient && networkMsg.Sender == remotePublicKeyHex) ||
-				(acc.PublicKey == networkMsg.Sender && networkMsg.Recipient == remotePublicKeyHex)
-			if !msgIsValid {
-				err = errors.New("invalid message")
+			// Keys are wiped while the wallet is locked, hence the message is queued as it is
+			// (encrypted) and is received after the wallet is unlocked
+			if !wallet.GlobalWallet.IsUnlocked() {
+				c.lockedQueue.Push(lockedMessage{peerKeyHex: peerKeyHex, data: pb})
 				continue
 			}
-			dbMessage := Message{
-				ID:        networkMsg.ID,
-				Sender:    networkMsg.Sender,
-				Recipient: networkMsg.Recipient,
-				CreatedAt: networkMsg.CreatedAt}
-			var key string
-			key, err = dbMessage.GetDBFullKey(acc.PublicKey)
-			if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
-				continue
-			}
-			err = wallet.GlobalWallet.ViewRecord([]byte(key), &dbMessage)
-			// this shouldn't happen,
-			msgExist := err == nil
-			// if networkMsg is not created by me
-			if !isMsgCreatedByMe {
-				// if networkMsg already exist, then it implies user's peer is requesting for the updated state
-				if msgExist {
-					if networkMsg.State < MessageStateReceived {
-						networkMsg.State = MessageStateReceived
-					}
-					if networkMsg.State < dbMessage.State {
-						networkMsg.State = dbMessage.State
-					}
-				} else {
-					// if networkMsg is new, then update the state to MessageStateReceived
-					networkMsg.State = MessageStateReceived
-				}
-			} else {
-				// if the message is created by me, then it implies user's peer is providing updated State
-				if networkMsg.State < dbMessage.State {
-					networkMsg.State = dbMessage.State
-				}
+			if !resolved() {
+				return
 			}
-			var msgCh chan Message
-			var ok bool
-			if msgCh, ok = c.chatStreamsOutCh.Get(networkMsg.Sender); !ok {
-				msgCh = make(chan Message, 10)
-				c.chatStreamsOutCh.Set(networkMsg.Sender, msgCh)
+			err = c.receiveMessage(pb, contactPubKeyHex)
+		}
+	}
+}
+
+// receiveMessage decrypts and verifies the encrypted message received from the contact and saves it,
+// the message of another device of the account is a message of any conversation of the account
+func (c *chat) receiveMessage(pb []byte, contactPubKeyHex string) (err error) {
+	networkMsg := Message{}
+	var acc Account
+	acc, err = wallet.GlobalWallet.Account()
+	if err != nil {
+		return err
+	}
+	var pvtKeyStr string
+	pvtKeyStr, err = wallet.GlobalWallet.GetPrivateKey(acc)
+	if err != nil {
+		return err
+	}
+	keyType, err := common.IdentityKeyType(acc.Algorithm())
+	if err != nil {
+		return err
+	}
+	err = common.GetDecryptedStruct(pvtKeyStr, pb, &networkMsg, keyType)
+	if err != nil {
+		return err
+	}
+	isMsgCreatedByMe := acc.PublicKey == networkMsg.Sender
+	fromOwnDevice := contactPubKeyHex == acc.PublicKey
+	// the message is signed with the key of the account of the peer, the account key for the
+	// other devices of the account
+	verPublicKey := contactPubKeyHex
+	// the message is signed by the peer with the algorithm of its own identity
+	verKeyType, err := common.PublicKeyType(verPublicKey)
+	if err != nil {
+		return err
+	}
+	err = common.VerifyMessage(&networkMsg, verPublicKey, verKeyType)
+	if err != nil {
+		return err
+	}
+	// remotePublicKeyHex is the other account of the conversation of the message
+	remotePublicKeyHex := networkMsg.Sender
+	if isMsgCreatedByMe {
+		remotePublicKeyHex = networkMsg.Recipient
+	}
+	if wallet.GlobalWallet.IsContactBlocked(acc.PublicKey, remotePublicKeyHex) {
+		return ErrContactBlocked
+	}
+	// Message is either created by user or his peer
+	if acc2, err := wallet.GlobalWallet.Account(); acc2.PublicKey != acc.PublicKey || err != nil {
+		return err
+	}
+	msgIsValid := (acc.PublicKey == networkMsg.Recipient || acc.PublicKey == networkMsg.Sender) &&
+		remotePublicKeyHex != acc.PublicKey && (fromOwnDevice || remotePublicKeyHex == contactPubKeyHex)
+	if !msgIsValid {
+		return errors.New("invalid message")
+	}
+	dbMessage := Message{
+		ID:        networkMsg.ID,
+		Sender:    networkMsg.Sender,
+		Recipient: networkMsg.Recipient,
+		CreatedAt: networkMsg.CreatedAt}
+	var key string
+	key, err = dbMessage.GetDBFullKey(acc.PublicKey)
+	if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+		return err
+	}
+	err = wallet.GlobalWallet.ViewRecord([]byte(key), &dbMessage)
+	// this shouldn't happen,
+	msgExist := err == nil
+	// another device of the account provides the state it knows
+	if fromOwnDevice {
+		if networkMsg.State < dbMessage.State {
+			networkMsg.State = dbMessage.State
+		}
+	} else if !isMsgCreatedByMe {
+		// if networkMsg is not created by me
+		// if networkMsg already exist, then it implies user's peer is requesting for the updated state
+		if msgExist {
+			if networkMsg.State < MessageStateReceived {
+				networkMsg.State = MessageStateReceived
 			}
-			select {
-			case msgCh <- networkMsg:
-			default:
+			if networkMsg.State < dbMessage.State {
+				networkMsg.State = dbMessage.State
 			}
-			err = wallet.GlobalWallet.SaveOrUpdateMessage(acc.PublicKey, &networkMsg)
+		} else {
+			// if networkMsg is new, then update the state to MessageStateReceived
+			networkMsg.State = MessageStateReceived
+		}
+	} else {
+		// if the message is created by me, then it implies user's peer is providing updated State
+		if networkMsg.State < dbMessage.State {
+			networkMsg.State = dbMessage.State
+		}
+	}
+	// the status of a payment is verified on chain by each side, the status claimed by the peer
+	// isn't trusted
+	if networkMsg.Payment != nil {
+		networkMsg.Payment.Status = model.PaymentStatusPending
+	}
+	// the state is acknowledged to the connected devices of the sender
+	if !isMsgCreatedByMe && !fromOwnDevice {
+		if hst, err := c.Host(); err == nil {
+			c.queueMessage(hst, peersOfAccount(acc, networkMsg.Sender), networkMsg, false)
+		}
+	}
+	// signatures of a safe transaction are collected across the conversations with the owners
+	if !isMsgCreatedByMe && (networkMsg.SafeTx != nil || networkMsg.SafeApproval != nil) {
+		_ = wallet.GlobalWallet.ReceiveSafeTx(&networkMsg)
+	}
+	_, contactErr := wallet.GlobalWallet.Contact(acc.PublicKey, remotePublicKeyHex)
+	_, requestErr := wallet.GlobalWallet.ContactRequest(acc.PublicKey, remotePublicKeyHex)
+	isContact := contactErr == nil
+	isNewSender := !isContact && errors.Is(requestErr, db.ErrContactNotFound)
+	// the messages of an unknown sender are quarantined up to maxRequestMessages
+	if !isContact && !isMsgCreatedByMe && !msgExist {
+		count, _ := wallet.GlobalWallet.MessagesCount(acc.PublicKey, remotePublicKeyHex)
+		if count >= maxRequestMessages {
+			return ErrRequestQuarantined
+		}
+	}
+	err = wallet.GlobalWallet.SaveOrUpdateMessage(acc.PublicKey, &networkMsg)
+	// the sender isn't typing the message it sent anymore
+	if err == nil && !isMsgCreatedByMe && !msgExist && !fromOwnDevice {
+		c.stoppedTyping(remotePublicKeyHex)
+	}
+	// the first message of a new key is identified to detect a known address chatting with a new key
+	if err == nil && !isMsgCreatedByMe && isNewSender && !fromOwnDevice {
+		go c.identifyNewContact(acc.PublicKey, remotePublicKeyHex)
+	}
+	return err
+}
+
+// BlockContact adds publicKey to the block list of the current account and closes the chat
+// streams of its devices, a request of publicKey is deleted with its messages
+func (c *chat) BlockContact(publicKey string) error {
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return err
+	}
+	if err = wallet.GlobalWallet.BlockContact(account.PublicKey, publicKey); err != nil {
+		return err
+	}
+	for _, peerKeyHex := range peersOfAccount(account, publicKey) {
+		if stream, ok := c.chatStreams.Get(peerKeyHex); ok {
+			_ = stream.Reset()
+			c.chatStreams.Delete(peerKeyHex)
 		}
 	}
+	if _, err = wallet.GlobalWallet.ContactRequest(account.PublicKey, publicKey); err == nil {
+		return wallet.GlobalWallet.DeleteContactRequest(account.PublicKey, publicKey)
+	}
+	return nil
 }
 
-func (c *chat) writeChatStream(stream network.Stream, contactPubKeyHex string) {
+// identifyNewContact asks the peer of a request created by its first message for its identity,
+// IdentifyContact flags the request if its address was known with another key
+func (c *chat) identifyNewContact(accountPublicKey, contactPublicKey string) {
+	contact, err := wallet.GlobalWallet.ContactRequest(accountPublicKey, contactPublicKey)
+	if err != nil {
+		return
+	}
+	if _, err = c.IdentifyContact(context.Background(), contact); err != nil {
+		// the peer may not serve its identity, which is fine for a contact added by public key
+		alog.Logger().Infoln(err)
+	}
+}
+
+// writeChatStream writes the queued messages and signals of the peer of peerKeyHex, the messages
+// are encrypted to contactPubKeyHex, the account of the peer
+func (c *chat) writeChatStream(stream network.Stream, peerKeyHex, contactPubKeyHex string) {
 	var err error
 	defer func() {
 		if err != nil {
 			alog.Logger().Errorln(err)
 		}
 		if err != nil && errors.Is(err, ErrStreamReset) {
-			c.chatStreams.Delete(contactPubKeyHex)
+			c.deleteChatStream(peerKeyHex, stream)
 		}
 	}()
 	account, err := wallet.GlobalWallet.Account()
 	if err != nil {
 		return
 	}
+	keyType, err := common.IdentityKeyType(account.Algorithm())
+	if err != nil {
+		return
+	}
+	contactKeyType, err := common.PublicKeyType(contactPubKeyHex)
+	if err != nil {
+		return
+	}
 	rw := bufio.NewWriter(stream)
 	// if current account is changed, then return
 	if acc, err := wallet.GlobalWallet.Account(); acc.PublicKey != account.PublicKey || err != nil {
@@ -213,46 +423,48 @@ func (c *chat) writeChatStream(stream network.Stream, contactPubKeyHex string) {
 	}
 	var ch chan Message
 	var ok bool
-	if ch, ok = c.chatStreamsOutCh.Get(contactPubKeyHex); !ok {
+	if ch, ok = c.chatStreamsOutCh.Get(peerKeyHex); !ok {
 		ch = make(chan Message, 10)
-		c.chatStreamsOutCh.Set(contactPubKeyHex, ch)
+		c.chatStreamsOutCh.Set(peerKeyHex, ch)
 	}
+	// a legacy stream has no signal channel and the nil channel is never selected
+	signalCh, _ := c.chatStreamsSignalCh.Get(peerKeyHex)
 	for err == nil || !errors.Is(err, ErrStreamReset) {
 		// if current account is changed, then return
 		if acc, err := wallet.GlobalWallet.Account(); acc.PublicKey != account.PublicKey || err != nil {
 			return
 		}
-		if dbMsg, ok := <-ch; ok {
+		var dbMsg Message
+		var ok bool
+		select {
+		case dbMsg, ok = <-ch:
+		case sig := <-signalCh:
+			err = writeChatFrame(rw, frameSignal, []byte{byte(sig)})
+			if errors.Is(err, ErrStreamReset) {
+				return
+			}
+			continue
+		}
+		if ok {
 			// reports the error encountered in prev iteration
 			if err != nil {
 				alog.Logger().Errorln(err)
 			}
-			//var pvtKeyStr string
-			//pvtKeyStr, err = wallet.GlobalWallet.GetPrivateKey(account)
-			//if err != nil {
-			//	continue
-			//}
-			err = common.SignMessage(account.PrivateKey, &dbMsg, libcrypto.ECDSA)
+			var pvtKeyStr string
+			pvtKeyStr, err = wallet.GlobalWallet.GetPrivateKey(account)
 			if err != nil {
 				continue
 			}
-			var bytes []byte
-			bytes, err = common.GetEncryptedStruct(contactPubKeyHex, dbMsg, libcrypto.ECDSA)
+			err = common.SignMessage(pvtKeyStr, &dbMsg, keyType)
 			if err != nil {
 				continue
 			}
-			messageSize := uint32(len(bytes))
-			b := make([]byte, 8)
-			binary.LittleEndian.PutUint32(b, messageSize)
-			b = append(b, bytes...)
-			_, err = rw.Write(b)
+			var bytes []byte
+			bytes, err = common.GetEncryptedStruct(contactPubKeyHex, dbMsg, contactKeyType)
 			if err != nil {
-				if errors.Is(err, ErrStreamReset) {
-					return
-				}
 				continue
 			}
-			err = rw.Flush()
+			err = writeChatFrame(rw, frameMessage, bytes)
 			if err != nil {
 				if errors.Is(err, ErrStreamReset) {
 					return
@@ -263,6 +475,27 @@ func (c *chat) writeChatStream(stream network.Stream, contactPubKeyHex string) {
 	}
 }
 
+// deleteChatStream deletes the chat stream of the peer of peerKeyHex if it's still stream, a
+// newer stream of the peer is kept
+func (c *chat) deleteChatStream(peerKeyHex string, stream network.Stream) {
+	if current, ok := c.chatStreams.Get(peerKeyHex); ok && current == stream {
+		c.chatStreams.Delete(peerKeyHex)
+	}
+}
+
+// writeChatFrame writes payload after the 8 bytes header of a chat stream frame, the header holds
+// the size of payload followed by the kind of the frame
+func writeChatFrame(rw *bufio.Writer, kind byte, payload []byte) error {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint32(b, uint32(len(payload)))
+	b[4] = kind
+	b = append(b, payload...)
+	if _, err := rw.Write(b); err != nil {
+		return err
+	}
+	return rw.Flush()
+}
+
 func (c *chat) SendNewMessage(identity *Account, message *Message) {
 	go func() {
 		var err error
@@ -279,56 +512,61 @@ func (c *chat) SendNewMessage(identity *Account, message *Message) {
 		}
 		hst, err := c.Host()
 		if err == nil {
-			publicKey, err := common.GetPublicKeyFromStr(message.Recipient, libcrypto.ECDSA)
-			if err != nil {
-				alog.Logger().Errorln(err)
-				return
-			}
-			peerID, err := peer.IDFromPublicKey(publicKey)
-			if err != nil {
-				alog.Logger().Errorln(err)
-				return
-			}
-			if _, ok := c.chatStreams.Get(message.Recipient); !ok {
-				stream, err := hst.NewStream(context.Background(), peerID, ProtocolChat)
-				if err != nil {
-					alog.Logger().Errorln(err)
-					return
-				}
-				c.handleHostChatStream(stream)
-			}
-			var msgCh chan Message
-			var ok bool
-			if msgCh, ok = c.chatStreamsOutCh.Get(message.Recipient); !ok {
-				msgCh = make(chan Message, 10)
-				c.chatStreamsOutCh.Set(message.Recipient, msgCh)
-			}
-			select {
-			case msgCh <- *message:
-			default:
-			}
+			// the message is delivered to every device of the recipient
+			c.queueMessage(hst, peersOfAccount(*identity, message.Recipient), *message, true)
 		}
 	}()
 }
 
+// openChatStream opens the chat with the peer of publicKeyHex, ProtocolChatLegacy is negotiated
+// with a peer of a version which doesn't support ProtocolChat.
+// A linked device pushes its devices first so that the peer knows the account of its key.
+func (c *chat) openChatStream(hst host.Host, publicKeyHex string) error {
+	peerID, err := peerIDOf(publicKeyHex)
+	if err != nil {
+		return err
+	}
+	if account, err := wallet.GlobalWallet.Account(); err == nil && account.IsLinkedDevice() {
+		c.pushDevices(peerID)
+	}
+	stream, err := hst.NewStream(context.Background(), peerID, ProtocolChat, ProtocolChatLegacy)
+	if err != nil {
+		return err
+	}
+	c.handleHostChatStream(stream)
+	return nil
+}
+
+// legacyChatSupported reports whether ProtocolChatLegacy can be spoken with the peer of
+// remoteKey, the legacy versions encrypt and verify with ECDSA only
+func legacyChatSupported(remoteKey libcrypto.PubKey) bool {
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return false
+	}
+	return account.Algorithm() == model.IdentityECDSA && int(remoteKey.Type()) == libcrypto.ECDSA
+}
+
 var ErrHostNotInitialized = errors.New("host not initialized")
 
-func (c *chat) makeHost() (host.Host, error) {
+func (c *chat) makeHost() (host.Host, *dht.IpfsDHT, error) {
 	if !wallet.GlobalWallet.IsOpen() {
-		return nil, errors.New("password is not set")
+		return nil, nil, errors.New("password is not set")
 	}
 	account, err := wallet.GlobalWallet.Account()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	// a linked device is a peer of its own, only the primary device chats with the account key
+	var pvtKey libcrypto.PrivKey
+	if account.IsLinkedDevice() {
+		pvtKey, err = wallet.GlobalWallet.DeviceKey(account)
+	} else {
+		pvtKey, err = accountKey(account)
 	}
-	//pvtKeyStr, err := wallet.GlobalWallet.GetPrivateKey(account)
-	//if err != nil {
-	//	return nil, err
-	//}
-	pvtKey, err := common.GetPrivateKeyFromStr(account.PrivateKey, libcrypto.ECDSA)
 	if err != nil {
 		alog.Logger().Errorln(err)
-		return nil, err
+		return nil, nil, err
 	}
 	hst, err := libp2p.New(
 		libp2p.Identity(pvtKey),
@@ -341,7 +579,7 @@ func (c *chat) makeHost() (host.Host, error) {
 		}),
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	ctx := context.Background()
 	dstore := ipfssync.MutexWrap(ipfsdatastore.NewMapDatastore())
@@ -351,12 +589,14 @@ func (c *chat) makeHost() (host.Host, error) {
 	if err != nil {
 		alog.Logger().Errorln(err)
 	}
-	return routedHost, nil
+	return routedHost, dHT, nil
 }
 
 // runChat keeps the chat running as long as app is running,
 // should be called only once for the entire lifecycle of app
 func (c *chat) runChat() {
+	// cancelIdentity stops publishing the identity of the previous account on reload
+	var cancelIdentity context.CancelFunc
 reloadClientService:
 	var sub *pubsub.Subscriber
 	var tckr *time.Ticker
@@ -368,27 +608,39 @@ reloadClientService:
 	if tckr != nil {
 		tckr.Stop()
 	}
+	if cancelIdentity != nil {
+		cancelIdentity()
+		c.setIdentity(IdentityRecord{})
+	}
 	hst, err := c.Host()
 	if hst != nil {
-		c.setHost(nil, ErrHostNotInitialized)
+		c.setHost(nil, nil, ErrHostNotInitialized)
 		hst.RemoveStreamHandler(ProtocolChat)
+		hst.RemoveStreamHandler(ProtocolChatLegacy)
+		hst.RemoveStreamHandler(ProtocolIdentity)
+		hst.RemoveStreamHandler(ProtocolProfile)
+		hst.RemoveStreamHandler(ProtocolDevices)
 		err := hst.Close()
 		if err != nil {
 			alog.Logger().Errorln(err)
 		}
 	}
 	c.chatStreams.Clear()
+	c.chatStreamsSignalCh.Clear()
+	c.presence.reset()
 	chatChannels := c.chatStreamsOutCh.Values()
 	c.chatStreamsOutCh.Clear() // clear the map before closing channel
 	for _, ch := range chatChannels {
 		go close(ch)
 	}
-	hst, err = c.makeHost()
+	hst, routing, err := c.makeHost()
 	for err != nil {
 		time.Sleep(time.Millisecond * 100)
-		hst, err = c.makeHost()
+		hst, routing, err = c.makeHost()
 	}
-	c.setHost(hst, nil)
+	c.setHost(hst, routing, nil)
+	c.watchConnectedness(hst)
+	c.receiveLockedMessages()
 	for _, addr := range dht.DefaultBootstrapPeers {
 		pi, _ := peer.AddrInfoFromP2pAddr(addr)
 		_ = hst.Connect(context.Background(), *pi)
@@ -403,6 +655,16 @@ reloadClientService:
 		fmt.Printf("  %s/p2p/%s\n", addr, hst.ID().String())
 	}
 	hst.SetStreamHandler(ProtocolChat, c.handleHostChatStream)
+	hst.SetStreamHandler(ProtocolChatLegacy, c.handleHostChatStream)
+	hst.SetStreamHandler(ProtocolIdentity, c.handleIdentityStream)
+	hst.SetStreamHandler(ProtocolProfile, c.handleProfileStream)
+	hst.SetStreamHandler(ProtocolDevices, c.handleDevicesStream)
+	var identityCtx context.Context
+	identityCtx, cancelIdentity = context.WithCancel(context.Background())
+	// the identity is served by the primary device, its peer.ID is derived from the account key
+	if !account.IsLinkedDevice() {
+		go c.publishIdentity(identityCtx, account, routing)
+	}
 	limit := int64(50)
 	tckr = time.NewTicker(time.Second * 1)
 	if acc, err := wallet.GlobalWallet.Account(); acc.PublicKey != account.PublicKey || err != nil {
@@ -410,11 +672,24 @@ reloadClientService:
 	}
 	for {
 		select {
-		case <-sub.Events():
-			if acc, err := wallet.GlobalWallet.Account(); acc.PublicKey != account.PublicKey || err != nil {
+		case event := <-sub.Events():
+			// the host restarts with the device key once this device is linked
+			if acc, err := wallet.GlobalWallet.Account(); acc.PublicKey != account.PublicKey ||
+				acc.DevicePublicKey != account.DevicePublicKey || err != nil {
 				goto reloadClientService
 			}
+			// the messages received while locked are decrypted once the keys are back
+			if _, ok := event.Data.(pubsub.WalletUnlockedEventData); ok {
+				go c.receiveLockedMessages()
+			}
+			c.syncOwnDevices(event)
 		case <-tckr.C:
+			// a locked wallet only receives, its messages can't be signed until it's unlocked
+			if !wallet.GlobalWallet.IsUnlocked() {
+				continue
+			}
+			// the other devices of the account catch up when their chat streams open
+			c.openChatStreams(hst, peersOfAccount(account, account.PublicKey))
 			contactsCount, _ := wallet.GlobalWallet.ContactsCount(account.PublicKey)
 			for offset := int64(0); offset < contactsCount; offset += limit {
 				contacts, _ := wallet.GlobalWallet.Contacts(account.PublicKey, int(offset), int(limit))
@@ -422,29 +697,10 @@ reloadClientService:
 					if acc, err := wallet.GlobalWallet.Account(); acc.PublicKey != account.PublicKey || err != nil {
 						goto reloadClientService
 					}
-					var publicKey libcrypto.PubKey
-					publicKey, err = common.GetPublicKeyFromStr(eachContact.PublicKey, libcrypto.ECDSA)
-					if err != nil {
-						alog.Logger().Errorln(err)
-						continue
-					}
-					peerID, err := peer.IDFromPublicKey(publicKey)
-					if err != nil {
-						alog.Logger().Errorln(err)
+					if wallet.GlobalWallet.IsContactBlocked(account.PublicKey, eachContact.PublicKey) {
 						continue
 					}
-					if _, ok := c.chatStreams.Get(eachContact.PublicKey); !ok {
-						stream, err := hst.NewStream(context.Background(), peerID, ProtocolChat)
-						if err != nil {
-							alog.Logger().Errorln(err)
-							continue
-						}
-						c.handleHostChatStream(stream)
-					}
-					if _, ok := c.chatStreamsOutCh.Get(eachContact.PublicKey); !ok {
-						ch := make(chan Message, 10)
-						c.chatStreamsOutCh.Set(eachContact.PublicKey, ch)
-					}
+					peers := c.openChatStreams(hst, peersOfAccount(account, eachContact.PublicKey))
 					msgLimit := int64(100)
 					count, _ := wallet.GlobalWallet.MessagesCount(account.PublicKey, eachContact.PublicKey)
 					// Resend the message for which we haven't received the read ack
@@ -459,8 +715,11 @@ reloadClientService:
 							}
 							// should send if I haven't received read acknowledgement for my messages
 							shouldSend := msg.State < MessageStateRead && msg.Sender == account.PublicKey
-							if shouldSend {
-								msgCh, _ := c.chatStreamsOutCh.Get(eachContact.PublicKey)
+							if !shouldSend {
+								continue
+							}
+							for _, peerKeyHex := range peers {
+								msgCh, _ := c.chatStreamsOutCh.Get(peerKeyHex)
 								select {
 								case msgCh <- msg:
 								default:
@@ -477,3 +736,22 @@ reloadClientService:
 		}
 	}
 }
+
+// openChatStreams opens the chat streams of the peers which aren't open and returns the peers
+// with an open stream, each of them has its channel of messages
+func (c *chat) openChatStreams(hst host.Host, peers []string) (opened []string) {
+	for _, peerKeyHex := range peers {
+		if _, ok := c.chatStreams.Get(peerKeyHex); !ok {
+			if err := c.openChatStream(hst, peerKeyHex); err != nil {
+				alog.Logger().Errorln(err)
+				continue
+			}
+		}
+		if _, ok := c.chatStreamsOutCh.Get(peerKeyHex); !ok {
+			ch := make(chan Message, 10)
+			c.chatStreamsOutCh.Set(peerKeyHex, ch)
+		}
+		opened = append(opened, peerKeyHex)
+	}
+	return opened
+}
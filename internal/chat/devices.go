@@ -0,0 +1,298 @@
+package chat
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	libcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"github.com/mearaj/protonet/internal/wallet"
+	"io"
+	"time"
+)
+
+const (
+	devicesStreamTimeout = 15 * time.Second
+	maxDeviceListSize    = 1 << 14
+	// deviceSyncMessages is the number of the latest messages of each contact sent to an own
+	// device when its chat stream opens
+	deviceSyncMessages = 100
+	// deviceSyncTimeout is how long a synced message waits for the chat stream of an own device
+	deviceSyncTimeout = 10 * time.Second
+)
+
+type Device = model.Device
+
+// peerKey returns the hex public key of a peer as the chat streams are keyed
+func peerKey(pubKey libcrypto.PubKey) (string, error) {
+	pubKeyBytes, err := pubKey.Raw()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(pubKeyBytes), nil
+}
+
+// hostKey returns the public key this device chats with, the device key of a linked device or
+// the account key of the primary device
+func hostKey(account Account) string {
+	if account.IsLinkedDevice() {
+		return account.DevicePublicKey
+	}
+	return account.PublicKey
+}
+
+// accountOfPeer returns the account the peer of peerKeyHex belongs to, a certified device of an
+// account is mapped to its owner and any other key is an account of its own
+func accountOfPeer(accountPublicKey, peerKeyHex string) string {
+	device, err := wallet.GlobalWallet.Device(accountPublicKey, peerKeyHex)
+	if err == nil && !device.IsRevoked() {
+		return device.OwnerPublicKey
+	}
+	return peerKeyHex
+}
+
+// peersOfAccount returns the keys of the peers of the owner the messages are delivered to, its
+// account key for its primary device and the keys of its devices, this device is excluded
+func peersOfAccount(account Account, ownerPublicKey string) []string {
+	self := hostKey(account)
+	var peers []string
+	if ownerPublicKey != self {
+		peers = append(peers, ownerPublicKey)
+	}
+	devices, _ := wallet.GlobalWallet.ActiveDevices(account.PublicKey, ownerPublicKey)
+	for _, device := range devices {
+		if device.DevicePublicKey != self {
+			peers = append(peers, device.DevicePublicKey)
+		}
+	}
+	return peers
+}
+
+// handleDevicesStream saves the devices listed by the peer, the list is accepted for the owner of
+// the peer only, the owner is the peer itself or the owner certifying the device key of the peer.
+// A new device is accepted from the device itself, which proves that it holds the device key,
+// while its revocation is accepted from any peer of the owner.
+func (c *chat) handleDevicesStream(stream network.Stream) {
+	var err error
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+			_ = stream.Reset()
+			return
+		}
+		_ = stream.Close()
+	}()
+	_ = stream.SetDeadline(time.Now().Add(devicesStreamTimeout))
+	var devices []Device
+	err = json.NewDecoder(io.LimitReader(stream, maxDeviceListSize)).Decode(&devices)
+	if err != nil {
+		return
+	}
+	if len(devices) > wallet.MaxDevices {
+		devices = devices[:wallet.MaxDevices]
+	}
+	remoteKey, err := peerKey(stream.Conn().RemotePublicKey())
+	if err != nil {
+		return
+	}
+	owner := remoteKey
+	for _, device := range devices {
+		if device.DevicePublicKey == remoteKey && wallet.GlobalWallet.VerifyDevice(device) == nil {
+			owner = device.OwnerPublicKey
+			break
+		}
+	}
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return
+	}
+	if wallet.GlobalWallet.IsContactBlocked(account.PublicKey, owner) {
+		err = ErrContactBlocked
+		return
+	}
+	for _, device := range devices {
+		if device.OwnerPublicKey != owner {
+			continue
+		}
+		if _, err := wallet.GlobalWallet.Device(account.PublicKey, device.DevicePublicKey); err != nil &&
+			!device.IsRevoked() && device.DevicePublicKey != remoteKey {
+			continue
+		}
+		if saveErr := wallet.GlobalWallet.ReceiveDevice(account.PublicKey, device); saveErr != nil {
+			alog.Logger().Errorln(saveErr)
+		}
+	}
+}
+
+// pushDevices pushes the devices of the current account to the peer, revoked devices included
+// so that the peer stops delivering to them
+func (c *chat) pushDevices(peerID peer.ID) {
+	var err error
+	defer func() {
+		if err != nil {
+			// the peer may be of a version without devices
+			alog.Logger().Infoln(err)
+		}
+	}()
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return
+	}
+	devices, err := wallet.GlobalWallet.Devices(account.PublicKey, account.PublicKey)
+	if err != nil || len(devices) == 0 {
+		return
+	}
+	if len(devices) > wallet.MaxDevices {
+		devices = devices[:wallet.MaxDevices]
+	}
+	hst, err := c.Host()
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), devicesStreamTimeout)
+	defer cancel()
+	stream, err := hst.NewStream(ctx, peerID, ProtocolDevices)
+	if err != nil {
+		return
+	}
+	defer func() { _ = stream.Close() }()
+	_ = stream.SetDeadline(time.Now().Add(devicesStreamTimeout))
+	err = json.NewEncoder(stream).Encode(devices)
+}
+
+// queueMessage queues msg to the chat streams of the peers, a peer without a chat stream is
+// dialed first unless dial is false
+func (c *chat) queueMessage(hst host.Host, peers []string, msg Message, dial bool) {
+	for _, eachPeer := range peers {
+		eachPeer := eachPeer
+		go func() {
+			defer func() {
+				// the channel may be closed by the reload of the host
+				if r := recover(); r != nil {
+					alog.Logger().Errorln(r)
+				}
+			}()
+			if _, ok := c.chatStreams.Get(eachPeer); !ok {
+				if !dial {
+					return
+				}
+				if err := c.openChatStream(hst, eachPeer); err != nil {
+					alog.Logger().Errorln(err)
+					return
+				}
+			}
+			var msgCh chan Message
+			var ok bool
+			if msgCh, ok = c.chatStreamsOutCh.Get(eachPeer); !ok {
+				msgCh = make(chan Message, 10)
+				c.chatStreamsOutCh.Set(eachPeer, msgCh)
+			}
+			select {
+			case msgCh <- msg:
+			default:
+			}
+		}()
+	}
+}
+
+// syncMessage sends msg to the other devices of the account which are connected, the devices
+// offline catch up when their chat stream opens
+func (c *chat) syncMessage(msg Message) {
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil || (msg.Sender != account.PublicKey && msg.Recipient != account.PublicKey) {
+		return
+	}
+	hst, err := c.Host()
+	if err != nil {
+		return
+	}
+	c.queueMessage(hst, peersOfAccount(account, account.PublicKey), msg, false)
+}
+
+// syncOwnDevices forwards the messages sent, received or read on this device to the other
+// devices of the account
+func (c *chat) syncOwnDevices(event pubsub.Event) {
+	switch e := event.Data.(type) {
+	case pubsub.SendNewMessageEventData:
+		c.syncMessage(e.Message)
+	case pubsub.NewMessageReceivedEventData:
+		c.syncMessage(e.Message)
+	case pubsub.MessageStateChangedEventData:
+		c.syncMessage(e.Message)
+	case pubsub.MessagesStateChangedEventData:
+		// the messages marked as read are among the latest ones
+		msgs, _ := wallet.GlobalWallet.Messages(e.AccountPublicKey, e.ContactPublicKey, 0, deviceSyncMessages)
+		for _, msg := range msgs {
+			if msg.State == MessageStateRead && msg.Sender == e.ContactPublicKey {
+				c.syncMessage(msg)
+			}
+		}
+	}
+}
+
+// catchUpDevice sends the latest messages of every conversation to the own device of
+// peerKeyHex, the messages are waited for by the chat stream instead of being dropped
+func (c *chat) catchUpDevice(account Account, peerKeyHex string) {
+	defer func() {
+		// the channel may be closed by the reload of the host
+		if r := recover(); r != nil {
+			alog.Logger().Errorln(r)
+		}
+	}()
+	msgCh, ok := c.chatStreamsOutCh.Get(peerKeyHex)
+	if !ok {
+		msgCh = make(chan Message, 10)
+		c.chatStreamsOutCh.Set(peerKeyHex, msgCh)
+	}
+	var conversations []Contact
+	for _, contacts := range []func(string, int, int) ([]Contact, error){
+		wallet.GlobalWallet.Contacts, wallet.GlobalWallet.ContactRequests,
+	} {
+		for offset := 0; ; offset += deviceSyncMessages {
+			page, _ := contacts(account.PublicKey, offset, deviceSyncMessages)
+			conversations = append(conversations, page...)
+			if len(page) < deviceSyncMessages {
+				break
+			}
+		}
+	}
+	for _, contact := range conversations {
+		msgs, _ := wallet.GlobalWallet.Messages(account.PublicKey, contact.PublicKey, 0, deviceSyncMessages)
+		for _, msg := range msgs {
+			select {
+			case msgCh <- msg:
+			case <-time.After(deviceSyncTimeout):
+				return
+			}
+		}
+	}
+}
+
+// peerIDOf returns the peer.ID of the peer of publicKeyHex
+func peerIDOf(publicKeyHex string) (peer.ID, error) {
+	publicKey, err := common.ParsePublicKey(publicKeyHex)
+	if err != nil {
+		return "", err
+	}
+	return peer.IDFromPublicKey(publicKey)
+}
+
+// accountKey returns the identity key of account, the messages and the profile are signed with
+// it on every device of the account
+func accountKey(account Account) (libcrypto.PrivKey, error) {
+	pvtKeyStr, err := wallet.GlobalWallet.GetPrivateKey(account)
+	if err != nil {
+		return nil, err
+	}
+	keyType, err := common.IdentityKeyType(account.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+	return common.GetPrivateKeyFromStr(pvtKeyStr, keyType)
+}
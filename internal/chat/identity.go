@@ -0,0 +1,396 @@
+package chat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ipfs/go-cid"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/wallet"
+	"github.com/multiformats/go-multihash"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// identityRepublishInterval is shorter than the expiry of the provider records in the dht
+	identityRepublishInterval = 12 * time.Hour
+	identityLookupTimeout     = 60 * time.Second
+	identityStreamTimeout     = 15 * time.Second
+	// maxIdentityProviders limits the peers asked for the identity of an address, anyone can
+	// claim to provide it but only its owner can sign it
+	maxIdentityProviders  = 8
+	maxIdentityRecordSize = 1 << 12
+	identityNonceSize     = 32
+)
+
+var (
+	ErrIdentityNotFound     = errors.New("no protonet user found for the address")
+	ErrInvalidIdentity      = errors.New("invalid identity record")
+	ErrInvalidContactInput  = errors.New("enter a public key, an ethereum address or an ens name")
+	ErrRoutingNotReady      = errors.New("peer routing isn't ready yet")
+	ErrInvalidChallenge     = errors.New("invalid identity challenge")
+	identityCIDPrefix       = cid.Prefix{Version: 1, Codec: cid.Raw, MhType: multihash.SHA2_256, MhLength: -1}
+	identityCIDKeyNamespace = "protonet-identity:"
+)
+
+// IdentityRecord maps an ethereum address to the chat public key of its owner, it's signed by
+// the ethereum key of the address as an EIP-191 personal message. Peers publish that they
+// provide the record of their address in the dht and serve it over ProtocolIdentity.
+type IdentityRecord struct {
+	EthAddress string
+	PublicKey  string
+	CreatedAt  time.Time
+	Signature  []byte
+}
+
+// message returns the text signed by the ethereum key
+func (r *IdentityRecord) message() []byte {
+	return []byte(fmt.Sprintf("Protonet identity\nAddress: %s\nPublic Key: %s\nCreated At: %d",
+		ethcommon.HexToAddress(r.EthAddress).Hex(), r.PublicKey, r.CreatedAt.Unix()))
+}
+
+// Verify checks that the record is signed by the key of its ethereum address
+func (r *IdentityRecord) Verify() error {
+	if !ethcommon.IsHexAddress(r.EthAddress) {
+		return ErrInvalidIdentity
+	}
+	if _, err := common.ParsePublicKey(r.PublicKey); err != nil {
+		return ErrInvalidIdentity
+	}
+	signer, err := evm.RecoverTextSigner(r.message(), r.Signature)
+	if err != nil || signer != ethcommon.HexToAddress(r.EthAddress) {
+		return ErrInvalidIdentity
+	}
+	return nil
+}
+
+// identityChallenge is sent by the peer looking up an address, the provider proves that it owns
+// the address by signing the nonce with its ethereum key
+type identityChallenge struct {
+	Nonce []byte
+}
+
+// identityProof answers an identityChallenge
+type identityProof struct {
+	Record IdentityRecord
+	// Signature is the EIP-191 signature of challengeMessage by the key of Record.EthAddress
+	Signature []byte
+}
+
+// challengeMessage is the text signed to answer the challenge of requester, it's bound to the
+// requester so that the answer can't be relayed to another peer
+func challengeMessage(address string, requester peer.ID, nonce []byte) []byte {
+	return []byte(fmt.Sprintf("Protonet identity challenge\nAddress: %s\nPeer: %s\nNonce: %x",
+		ethcommon.HexToAddress(address).Hex(), requester, nonce))
+}
+
+// signText signs msg as an EIP-191 personal message with the ethereum key of account
+func signText(account Account, msg []byte) ([]byte, error) {
+	pvtKeyHex, err := wallet.GlobalWallet.GetPrivateKey(account)
+	if err != nil {
+		return nil, err
+	}
+	return evm.SignText(pvtKeyHex, msg)
+}
+
+// newIdentityRecord signs the identity record of account, the wallet must be unlocked
+func newIdentityRecord(account Account) (record IdentityRecord, err error) {
+	pvtKeyHex, err := wallet.GlobalWallet.GetPrivateKey(account)
+	if err != nil {
+		return record, err
+	}
+	pvtKey, err := crypto.HexToECDSA(pvtKeyHex)
+	if err != nil {
+		return record, err
+	}
+	record = IdentityRecord{
+		EthAddress: crypto.PubkeyToAddress(pvtKey.PublicKey).Hex(),
+		PublicKey:  account.PublicKey,
+		CreatedAt:  time.Now(),
+	}
+	record.Signature, err = signText(account, record.message())
+	return record, err
+}
+
+// identityCID is the dht key of the identity of address
+func identityCID(address ethcommon.Address) (cid.Cid, error) {
+	return identityCIDPrefix.Sum([]byte(identityCIDKeyNamespace + strings.ToLower(address.Hex())))
+}
+
+func (c *chat) Routing() (*dht.IpfsDHT, error) {
+	c.hostMutex.RLock()
+	defer c.hostMutex.RUnlock()
+	if c.routing == nil {
+		return nil, ErrRoutingNotReady
+	}
+	return c.routing, nil
+}
+
+func (c *chat) identity() (IdentityRecord, bool) {
+	c.identityMutex.RLock()
+	defer c.identityMutex.RUnlock()
+	return c.identityRecord, c.identityRecord.PublicKey != ""
+}
+
+func (c *chat) setIdentity(record IdentityRecord) {
+	c.identityMutex.Lock()
+	defer c.identityMutex.Unlock()
+	c.identityRecord = record
+}
+
+// publishIdentity signs the identity of account and provides it in the dht every
+// identityRepublishInterval until ctx is done
+func (c *chat) publishIdentity(ctx context.Context, account Account, routing *dht.IpfsDHT) {
+	record, err := newIdentityRecord(account)
+	if err != nil {
+		alog.Logger().Errorln(err)
+		return
+	}
+	c.setIdentity(record)
+	key, err := identityCID(ethcommon.HexToAddress(record.EthAddress))
+	if err != nil {
+		alog.Logger().Errorln(err)
+		return
+	}
+	ticker := time.NewTicker(identityRepublishInterval)
+	defer ticker.Stop()
+	for {
+		if err = routing.Provide(ctx, key, true); err != nil && ctx.Err() == nil {
+			alog.Logger().Errorln(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleIdentityStream answers the challenge of the peer with the signed identity of the
+// current account
+func (c *chat) handleIdentityStream(stream network.Stream) {
+	var err error
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+			_ = stream.Reset()
+			return
+		}
+		_ = stream.Close()
+	}()
+	record, ok := c.identity()
+	account, accErr := wallet.GlobalWallet.Account()
+	if !ok || accErr != nil || account.PublicKey != record.PublicKey {
+		_ = stream.Reset()
+		return
+	}
+	_ = stream.SetDeadline(time.Now().Add(identityStreamTimeout))
+	var challenge identityChallenge
+	err = json.NewDecoder(io.LimitReader(stream, maxIdentityRecordSize)).Decode(&challenge)
+	if err != nil {
+		return
+	}
+	if len(challenge.Nonce) != identityNonceSize {
+		err = ErrInvalidChallenge
+		return
+	}
+	proof := identityProof{Record: record}
+	msg := challengeMessage(record.EthAddress, stream.Conn().RemotePeer(), challenge.Nonce)
+	if proof.Signature, err = signText(account, msg); err != nil {
+		return
+	}
+	err = json.NewEncoder(stream).Encode(proof)
+}
+
+// FindIdentity finds the peers providing the identity of address in the dht and returns the
+// first identity signed by the key of address
+func (c *chat) FindIdentity(ctx context.Context, address ethcommon.Address) (IdentityRecord, error) {
+	if record, ok := c.identity(); ok && ethcommon.HexToAddress(record.EthAddress) == address {
+		return record, nil
+	}
+	hst, err := c.Host()
+	if err != nil {
+		return IdentityRecord{}, err
+	}
+	routing, err := c.Routing()
+	if err != nil {
+		return IdentityRecord{}, err
+	}
+	key, err := identityCID(address)
+	if err != nil {
+		return IdentityRecord{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, identityLookupTimeout)
+	defer cancel()
+	for provider := range routing.FindProvidersAsync(ctx, key, maxIdentityProviders) {
+		if provider.ID == hst.ID() {
+			continue
+		}
+		record, err := requestIdentity(ctx, hst, provider)
+		if err != nil {
+			alog.Logger().Errorln(err)
+			continue
+		}
+		if ethcommon.HexToAddress(record.EthAddress) == address {
+			return record, nil
+		}
+	}
+	return IdentityRecord{}, ErrIdentityNotFound
+}
+
+// requestIdentity challenges provider to prove the ownership of its ethereum address. The
+// identity must be signed by the address, the challenge must be signed by the same address and
+// the public key of the identity must be the key of provider.
+func requestIdentity(ctx context.Context, hst host.Host, provider peer.AddrInfo) (record IdentityRecord, err error) {
+	hst.Peerstore().AddAddrs(provider.ID, provider.Addrs, peerstore.TempAddrTTL)
+	stream, err := hst.NewStream(ctx, provider.ID, ProtocolIdentity)
+	if err != nil {
+		return record, err
+	}
+	defer func() { _ = stream.Close() }()
+	_ = stream.SetDeadline(time.Now().Add(identityStreamTimeout))
+	challenge := identityChallenge{Nonce: make([]byte, identityNonceSize)}
+	if _, err = rand.Read(challenge.Nonce); err != nil {
+		return record, err
+	}
+	if err = json.NewEncoder(stream).Encode(challenge); err != nil {
+		return record, err
+	}
+	var proof identityProof
+	err = json.NewDecoder(io.LimitReader(stream, maxIdentityRecordSize)).Decode(&proof)
+	if err != nil {
+		return record, err
+	}
+	record = proof.Record
+	if err = record.Verify(); err != nil {
+		return record, err
+	}
+	signer, err := evm.RecoverTextSigner(challengeMessage(record.EthAddress, hst.ID(), challenge.Nonce), proof.Signature)
+	if err != nil || signer != ethcommon.HexToAddress(record.EthAddress) {
+		return record, ErrInvalidChallenge
+	}
+	publicKey, err := common.ParsePublicKey(record.PublicKey)
+	if err != nil {
+		return record, err
+	}
+	peerID, err := peer.IDFromPublicKey(publicKey)
+	if err != nil {
+		return record, err
+	}
+	if peerID != provider.ID {
+		return record, ErrInvalidIdentity
+	}
+	return record, nil
+}
+
+// ResolveContact resolves input to a contact of the current account, input is a chat public key,
+// an ethereum address or an ens name. An address is looked up in the address book first and
+// then in the dht.
+func (c *chat) ResolveContact(ctx context.Context, input string) (contact Contact, err error) {
+	input = strings.TrimSpace(input)
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return contact, err
+	}
+	contact.AccountPublicKey = account.PublicKey
+	var address ethcommon.Address
+	switch {
+	case ethcommon.IsHexAddress(input):
+		address = ethcommon.HexToAddress(input)
+	case evm.IsENSName(input):
+		if contact.ENSName, err = evm.NormalizeENSName(input); err != nil {
+			return contact, err
+		}
+		if address, err = wallet.GlobalWallet.ResolveENSName(contact.ENSName); err != nil {
+			return contact, err
+		}
+	default:
+		if _, err = common.ParsePublicKey(input); err != nil {
+			return contact, ErrInvalidContactInput
+		}
+		contact.PublicKey = input
+		return contact, nil
+	}
+	if saved, err := wallet.GlobalWallet.ContactByEthAddress(address); err == nil {
+		if contact.ENSName != "" {
+			saved.ENSName = contact.ENSName
+		}
+		return saved, nil
+	}
+	record, err := c.FindIdentity(ctx, address)
+	if err != nil {
+		return contact, err
+	}
+	contact.PublicKey = record.PublicKey
+	contact.EthAddress = address.Hex()
+	if contact.ENSName == "" {
+		// the reverse record is optional
+		contact.ENSName, _ = wallet.GlobalWallet.LookupENSName(address)
+	}
+	return contact, nil
+}
+
+// AddContact resolves input with ResolveContact and saves the contact as identified in the
+// address book of the current account
+func (c *chat) AddContact(ctx context.Context, input string) (contact Contact, err error) {
+	contact, err = c.ResolveContact(ctx, input)
+	if err != nil {
+		return contact, err
+	}
+	contact.Identified = true
+	if err = wallet.GlobalWallet.CheckContactKey(&contact); err != nil {
+		return contact, err
+	}
+	if contact.CreatedAt.IsZero() {
+		contact.CreatedAt = time.Now()
+	}
+	contact.UpdatedAt = time.Now()
+	err = wallet.GlobalWallet.AddUpdateContact(&contact)
+	return contact, err
+}
+
+// IdentifyContact asks the peer of contact for its identity and saves the ethereum address of
+// the contact, it's needed to pay a contact added by public key
+func (c *chat) IdentifyContact(ctx context.Context, contact Contact) (Contact, error) {
+	if contact.EthAddress != "" {
+		return contact, nil
+	}
+	hst, err := c.Host()
+	if err != nil {
+		return contact, err
+	}
+	publicKey, err := common.ParsePublicKey(contact.PublicKey)
+	if err != nil {
+		return contact, err
+	}
+	peerID, err := peer.IDFromPublicKey(publicKey)
+	if err != nil {
+		return contact, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, identityLookupTimeout)
+	defer cancel()
+	record, err := requestIdentity(ctx, hst, peer.AddrInfo{ID: peerID})
+	if err != nil {
+		return contact, err
+	}
+	contact.EthAddress = ethcommon.HexToAddress(record.EthAddress).Hex()
+	if err = wallet.GlobalWallet.CheckContactKey(&contact); err != nil {
+		return contact, err
+	}
+	contact.UpdatedAt = time.Now()
+	err = wallet.GlobalWallet.AddUpdateContact(&contact)
+	return contact, err
+}
@@ -0,0 +1,57 @@
+package chat
+
+import (
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/wallet"
+	"sync"
+)
+
+// maxLockedQueueSize is the maximum number of messages held while the wallet is locked,
+// the oldest message is dropped when it's full
+const maxLockedQueueSize = 1000
+
+// lockedMessage is a message received while the wallet is locked, data is still
+// encrypted with the account's public key. The account of the peer of peerKeyHex is resolved
+// after unlock as the devices are read from the database.
+type lockedMessage struct {
+	peerKeyHex string
+	data       []byte
+}
+
+// lockedQueue holds the messages received while the wallet is locked
+type lockedQueue struct {
+	messages []lockedMessage
+	mutex    sync.Mutex
+}
+
+func (q *lockedQueue) Push(msg lockedMessage) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if len(q.messages) >= maxLockedQueueSize {
+		q.messages = q.messages[1:]
+	}
+	q.messages = append(q.messages, msg)
+}
+
+// PopAll empties the queue and returns the messages in the order they were received
+func (q *lockedQueue) PopAll() []lockedMessage {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	messages := q.messages
+	q.messages = nil
+	return messages
+}
+
+// receiveLockedMessages receives the messages queued while the wallet was locked
+func (c *chat) receiveLockedMessages() {
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil || !wallet.GlobalWallet.IsUnlocked() {
+		return
+	}
+	for _, msg := range c.lockedQueue.PopAll() {
+		contactPubKeyHex := accountOfPeer(account.PublicKey, msg.peerKeyHex)
+		if err := c.receiveMessage(msg.data, contactPubKeyHex); err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package chat
+
+import (
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"github.com/mearaj/protonet/internal/wallet"
+	"sync"
+	"time"
+)
+
+const (
+	// typingTimeout is how long a typing signal lasts unless it's repeated
+	typingTimeout = 6 * time.Second
+	// typingResendInterval repeats the typing signal while the user keeps typing
+	typingResendInterval = 3 * time.Second
+	// maxSignalSize is the size of the largest signal frame read from a chat stream
+	maxSignalSize = 16
+)
+
+// frame kinds are written in the fifth byte of the header of a chat stream frame, the peers of
+// ProtocolChatLegacy leave it zero
+const (
+	frameMessage byte = iota
+	frameSignal
+)
+
+// signal is an ephemeral state sent over the chat stream, it's never encrypted nor saved, the
+// stream is already authenticated and encrypted by libp2p
+type signal byte
+
+const (
+	// signalOnline is sent when the chat stream opens
+	signalOnline signal = iota + 1
+	signalTyping
+	signalStoppedTyping
+)
+
+// Presence is the ephemeral state of a contact, it's kept in memory only
+type Presence struct {
+	Online   bool
+	LastSeen time.Time
+	// TypingUntil is when the typing signal of the contact expires
+	TypingUntil time.Time
+}
+
+// IsTyping returns true if the contact is typing a message
+func (p Presence) IsTyping() bool {
+	return p.Online && time.Now().Before(p.TypingUntil)
+}
+
+// presenceTracker holds the presence of the contacts of the current account
+type presenceTracker struct {
+	peers map[string]Presence
+	// typingSentAt is when the typing signal was last sent to each peer, a zero time means the
+	// peer was told that typing stopped
+	typingSentAt map[string]time.Time
+	mutex        sync.Mutex
+}
+
+func (t *presenceTracker) get(publicKey string) Presence {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.peers[publicKey]
+}
+
+// update applies change to the presence of publicKey and returns true if it changed
+func (t *presenceTracker) update(publicKey string, change func(presence *Presence)) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.peers == nil {
+		t.peers = make(map[string]Presence)
+	}
+	presence := t.peers[publicKey]
+	prev := presence
+	change(&presence)
+	t.peers[publicKey] = presence
+	return presence != prev
+}
+
+// shouldSendTyping returns true if the typing state must be sent to publicKey, typing is
+// repeated every typingResendInterval and stopping is sent once
+func (t *presenceTracker) shouldSendTyping(publicKey string, typing bool) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.typingSentAt == nil {
+		t.typingSentAt = make(map[string]time.Time)
+	}
+	sentAt := t.typingSentAt[publicKey]
+	if !typing {
+		delete(t.typingSentAt, publicKey)
+		return !sentAt.IsZero()
+	}
+	if time.Since(sentAt) < typingResendInterval {
+		return false
+	}
+	t.typingSentAt[publicKey] = time.Now()
+	return true
+}
+
+func (t *presenceTracker) reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.peers = nil
+	t.typingSentAt = nil
+}
+
+// Presence returns whether the contact of publicKey is online, when it was last seen and
+// whether it's typing
+func (c *chat) Presence(publicKey string) Presence {
+	return c.presence.get(publicKey)
+}
+
+// SetTyping tells the devices of the contact of publicKey whether the user is typing a message
+// to it, the signal is sent only over the open chat streams
+func (c *chat) SetTyping(publicKey string, typing bool) {
+	if !c.presence.shouldSendTyping(publicKey, typing) {
+		return
+	}
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return
+	}
+	sig := signalStoppedTyping
+	if typing {
+		sig = signalTyping
+	}
+	for _, peerKeyHex := range peersOfAccount(account, publicKey) {
+		c.sendSignal(peerKeyHex, sig)
+	}
+}
+
+// sendSignal queues sig to the chat stream of the peer of peerKeyHex if it supports signals, a
+// signal is dropped rather than waited for
+func (c *chat) sendSignal(peerKeyHex string, sig signal) {
+	stream, ok := c.chatStreams.Get(peerKeyHex)
+	if !ok || stream.Protocol() != ProtocolChat {
+		return
+	}
+	ch, ok := c.chatStreamsSignalCh.Get(peerKeyHex)
+	if !ok {
+		return
+	}
+	select {
+	case ch <- sig:
+	default:
+	}
+}
+
+// handleSignal applies a signal frame received from the peer of publicKey
+func (c *chat) handleSignal(publicKey string, payload []byte) {
+	if len(payload) == 0 {
+		return
+	}
+	var changed bool
+	switch signal(payload[0]) {
+	case signalOnline:
+		changed = c.presence.update(publicKey, func(presence *Presence) {
+			presence.Online = true
+		})
+	case signalTyping:
+		changed = c.presence.update(publicKey, func(presence *Presence) {
+			presence.Online = true
+			presence.TypingUntil = time.Now().Add(typingTimeout)
+		})
+	case signalStoppedTyping:
+		changed = c.presence.update(publicKey, func(presence *Presence) {
+			presence.TypingUntil = time.Time{}
+		})
+	}
+	if changed {
+		firePresenceChanged(publicKey)
+	}
+}
+
+// stoppedTyping clears the typing state of the peer of publicKey after its message is received
+func (c *chat) stoppedTyping(publicKey string) {
+	changed := c.presence.update(publicKey, func(presence *Presence) {
+		presence.TypingUntil = time.Time{}
+	})
+	if changed {
+		firePresenceChanged(publicKey)
+	}
+}
+
+// watchConnectedness drives the online and last seen state of the contacts from the connections
+// of hst, a contact is online while any of its devices is connected, the connections of the other
+// peers such as the dht peers are ignored
+func (c *chat) watchConnectedness(hst host.Host) {
+	hst.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(_ network.Network, conn network.Conn) {
+			go c.setConnected(conn, true)
+		},
+		DisconnectedF: func(n network.Network, conn network.Conn) {
+			// the peer is still online over its other connections
+			if n.Connectedness(conn.RemotePeer()) == network.Connected {
+				return
+			}
+			go c.setConnected(conn, false)
+		},
+	})
+}
+
+func (c *chat) setConnected(conn network.Conn, online bool) {
+	pubKey := conn.RemotePublicKey()
+	if pubKey == nil {
+		return
+	}
+	peerKeyHex, err := peerKey(pubKey)
+	if err != nil {
+		return
+	}
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return
+	}
+	publicKey := accountOfPeer(account.PublicKey, peerKeyHex)
+	if publicKey == account.PublicKey {
+		return
+	}
+	// the contact stays online while another of its devices is connected
+	if !online {
+		online = c.isAccountConnected(account, publicKey)
+	}
+	if _, err = wallet.GlobalWallet.Contact(account.PublicKey, publicKey); err != nil {
+		if _, err = wallet.GlobalWallet.ContactRequest(account.PublicKey, publicKey); err != nil {
+			return
+		}
+	}
+	changed := c.presence.update(publicKey, func(presence *Presence) {
+		if !online && presence.Online {
+			presence.LastSeen = time.Now()
+		}
+		presence.Online = online
+		if !online {
+			presence.TypingUntil = time.Time{}
+		}
+	})
+	if changed {
+		firePresenceChanged(publicKey)
+	}
+}
+
+// isAccountConnected returns true if any device of the account of publicKey is connected to the
+// host
+func (c *chat) isAccountConnected(account Account, publicKey string) bool {
+	hst, err := c.Host()
+	if err != nil {
+		return false
+	}
+	for _, peerKeyHex := range peersOfAccount(account, publicKey) {
+		peerID, err := peerIDOf(peerKeyHex)
+		if err == nil && hst.Network().Connectedness(peerID) == network.Connected {
+			return true
+		}
+	}
+	return false
+}
+
+func firePresenceChanged(publicKey string) {
+	wallet.GlobalWallet.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.PresenceChangedEventData{PublicKey: publicKey},
+		Topic: pubsub.PresenceChangedEventTopic,
+	})
+}
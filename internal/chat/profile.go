@@ -0,0 +1,186 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/db"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/wallet"
+	"io"
+	"time"
+)
+
+const (
+	profileStreamTimeout = 30 * time.Second
+	// maxProfileRecordSize fits the largest avatar encoded in json with the other fields
+	maxProfileRecordSize = 4*model.MaxProfileAvatarSize/3 + 1<<12
+)
+
+var ErrInvalidProfile = errors.New("invalid profile record")
+
+// ProfileRecord is the profile of the account of PublicKey signed by its identity key, it's pushed
+// over ProtocolProfile to the contacts and the other devices of the account when they connect
+type ProfileRecord struct {
+	PublicKey string
+	model.Profile
+	Signature []byte
+}
+
+// message returns the bytes signed by the identity key
+func (r *ProfileRecord) message() ([]byte, error) {
+	avatar, err := json.Marshal(r.Avatar)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("Protonet profile\nPublic Key: %s\nName: %q\nStatus: %q\nAvatar: %s\nUpdated At: %d",
+		r.PublicKey, r.Name, r.Status, avatar, r.UpdatedAt.UnixNano())), nil
+}
+
+// Verify checks the limits of the profile and that it's signed by the key of PublicKey
+func (r *ProfileRecord) Verify() error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	publicKey, err := common.ParsePublicKey(r.PublicKey)
+	if err != nil {
+		return ErrInvalidProfile
+	}
+	msg, err := r.message()
+	if err != nil {
+		return err
+	}
+	ok, err := publicKey.Verify(msg, r.Signature)
+	if err != nil || !ok {
+		return ErrInvalidProfile
+	}
+	return nil
+}
+
+// newProfileRecord signs the profile of account with its identity key, a linked device signs
+// with the account key as well
+func newProfileRecord(account Account) (record ProfileRecord, err error) {
+	pvtKey, err := accountKey(account)
+	if err != nil {
+		return record, err
+	}
+	record = ProfileRecord{PublicKey: account.PublicKey, Profile: account.Profile()}
+	msg, err := record.message()
+	if err != nil {
+		return record, err
+	}
+	record.Signature, err = pvtKey.Sign(msg)
+	return record, err
+}
+
+// handleProfileStream reads the profile pushed by the peer and caches it on its contact or its
+// request, the profile of a peer unknown to the current account is dropped. The profile pushed
+// by another device of the account updates the account.
+func (c *chat) handleProfileStream(stream network.Stream) {
+	var err error
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+			_ = stream.Reset()
+			return
+		}
+		_ = stream.Close()
+	}()
+	_ = stream.SetDeadline(time.Now().Add(profileStreamTimeout))
+	var record ProfileRecord
+	err = json.NewDecoder(io.LimitReader(stream, maxProfileRecordSize)).Decode(&record)
+	if err != nil {
+		return
+	}
+	if err = record.Verify(); err != nil {
+		return
+	}
+	remoteKey, err := peerKey(stream.Conn().RemotePublicKey())
+	if err != nil {
+		return
+	}
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return
+	}
+	if accountOfPeer(account.PublicKey, remoteKey) != record.PublicKey {
+		err = ErrInvalidProfile
+		return
+	}
+	if record.PublicKey == account.PublicKey {
+		err = wallet.GlobalWallet.ReceiveOwnProfile(record.Profile)
+		return
+	}
+	if wallet.GlobalWallet.IsContactBlocked(account.PublicKey, record.PublicKey) {
+		err = ErrContactBlocked
+		return
+	}
+	err = wallet.GlobalWallet.SaveContactProfile(account.PublicKey, record.PublicKey, record.Profile)
+	if errors.Is(err, db.ErrContactNotFound) {
+		err = nil
+	}
+}
+
+// sendProfile pushes the profile of the current account to the peer of the account of
+// publicKeyHex, only accepted contacts and the other devices of the account receive it, a request
+// learns it once it's accepted
+func (c *chat) sendProfile(peerID peer.ID, publicKeyHex string) {
+	var err error
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return
+	}
+	if _, err = wallet.GlobalWallet.Contact(account.PublicKey, publicKeyHex); err != nil && publicKeyHex != account.PublicKey {
+		err = nil
+		return
+	}
+	hst, err := c.Host()
+	if err != nil {
+		return
+	}
+	record, err := newProfileRecord(account)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), profileStreamTimeout)
+	defer cancel()
+	stream, err := hst.NewStream(ctx, peerID, ProtocolProfile)
+	if err != nil {
+		// the peer may be of a version without profiles
+		alog.Logger().Infoln(err)
+		err = nil
+		return
+	}
+	defer func() { _ = stream.Close() }()
+	_ = stream.SetDeadline(time.Now().Add(profileStreamTimeout))
+	err = json.NewEncoder(stream).Encode(record)
+}
+
+// UpdateProfile saves the profile of the current account and pushes it to the connected
+// contacts and devices, the others receive it the next time they connect
+func (c *chat) UpdateProfile(name, status string, avatar []byte) (model.Profile, error) {
+	profile, err := wallet.GlobalWallet.SetProfile(name, status, avatar)
+	if err != nil {
+		return profile, err
+	}
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return profile, err
+	}
+	for _, peerKeyHex := range c.chatStreams.Keys() {
+		if stream, ok := c.chatStreams.Get(peerKeyHex); ok {
+			go c.sendProfile(stream.Conn().RemotePeer(), accountOfPeer(account.PublicKey, peerKeyHex))
+		}
+	}
+	return profile, nil
+}
@@ -0,0 +1,80 @@
+package chat
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// peerMessageBurst is the number of messages a peer can send at once
+	peerMessageBurst = 20
+	// peerMessageInterval is the interval in which a peer earns one more message up to the burst
+	peerMessageInterval = time.Second
+	// peerSignalBurst and peerSignalInterval limit the signals of a peer apart from its messages,
+	// a peer flooding signals doesn't lose its messages
+	peerSignalBurst    = 10
+	peerSignalInterval = 500 * time.Millisecond
+	// bucketSweepInterval is the interval in which the buckets of idle peers are evicted
+	bucketSweepInterval = time.Minute
+	// maxRequestMessages is the number of messages quarantined from a sender until the request is
+	// accepted, the following messages are dropped
+	maxRequestMessages = 50
+	// maxChatMessageSize is the size of the largest encrypted message read from a chat stream,
+	// the stream of a peer sending a larger one is reset
+	maxChatMessageSize = 16 << 20
+)
+
+// peerBucket holds the messages a peer can still send, it's refilled with time
+type peerBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// rateLimiter limits the messages received from each peer with a token bucket of burst
+// messages refilled every interval
+type rateLimiter struct {
+	burst    float64
+	interval time.Duration
+	buckets  map[string]*peerBucket
+	sweptAt  time.Time
+	mutex    sync.Mutex
+}
+
+// Allow returns true if the peer of publicKey can send a message now and takes it from its bucket
+func (l *rateLimiter) Allow(publicKey string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.buckets == nil {
+		l.buckets = make(map[string]*peerBucket)
+	}
+	now := time.Now()
+	if now.Sub(l.sweptAt) > bucketSweepInterval {
+		l.sweep(now)
+	}
+	bucket, ok := l.buckets[publicKey]
+	if !ok {
+		bucket = &peerBucket{tokens: l.burst, updatedAt: now}
+		l.buckets[publicKey] = bucket
+	}
+	bucket.tokens += float64(now.Sub(bucket.updatedAt)) / float64(l.interval)
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.updatedAt = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// sweep evicts the buckets which are full again by now, a full bucket is the same as no bucket
+func (l *rateLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst) * l.interval
+	for publicKey, bucket := range l.buckets {
+		if now.Sub(bucket.updatedAt) >= refill {
+			delete(l.buckets, publicKey)
+		}
+	}
+	l.sweptAt = now
+}
@@ -7,9 +7,22 @@ import (
 
 type Account = model.Account
 type Contact = model.Contact
+type BlockedContact = model.BlockedContact
 
 const (
-	ProtocolChat protocol.ID = "/protonet.wallet/msg-chat/0.0.1"
+	// ProtocolChat supports the identities of all the algorithms, a message is encrypted to the
+	// algorithm of its recipient and signed with the algorithm of its sender. The presence and
+	// typing signals are sent between its messages.
+	ProtocolChat protocol.ID = "/protonet.wallet/msg-chat/0.0.2"
+	// ProtocolChatLegacy is spoken by the versions supporting only ECDSA identities, it's used
+	// with such a peer when the identity of the current account is ECDSA too
+	ProtocolChatLegacy protocol.ID = "/protonet.wallet/msg-chat/0.0.1"
+	// ProtocolIdentity answers a challenge with the signed IdentityRecord of the peer
+	ProtocolIdentity protocol.ID = "/protonet.wallet/identity/0.0.2"
+	// ProtocolProfile pushes the signed ProfileRecord of the peer to its contact
+	ProtocolProfile protocol.ID = "/protonet.wallet/profile/0.0.1"
+	// ProtocolDevices pushes the certified devices of the account of the peer
+	ProtocolDevices protocol.ID = "/protonet.wallet/devices/0.0.1"
 )
 
 const (
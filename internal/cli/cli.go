@@ -0,0 +1,282 @@
+// Package cli implements the command line interface, app runs without window when a command is given
+//
+//	protonet keystore import <file>
+//	protonet keystore export <eth address> <file>
+//	protonet sign message|typed-data <file>
+//	protonet verify message|typed-data <eth address> <signature> <file>
+//	protonet serve [address]
+//
+// The data signed and verified is read from file, a message is text or 0x prefixed hex and typed
+// data is EIP-712 json. serve runs the api of package api, its signing is approved on the terminal.
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/mearaj/protonet/internal/api"
+	"github.com/mearaj/protonet/internal/keystore"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/wallet"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const usage = `usage:
+  protonet keystore import <file>
+  protonet keystore export <eth address> <file>
+  protonet sign message|typed-data <file>
+  protonet verify message|typed-data <eth address> <signature> <file>
+  protonet serve [address]`
+
+// maxKeystoreFileSize limits the size of keystore file, keystore files are around 500 bytes
+const maxKeystoreFileSize = 1 << 16
+
+// maxSignFileSize limits the size of the data signed or verified
+const maxSignFileSize = 1 << 20
+
+var ErrUsage = errors.New(usage)
+var ErrAccountNotFound = errors.New("account not found")
+var ErrSignRejected = errors.New("signing rejected")
+
+// IsCommand reports whether arg is a cli command
+func IsCommand(arg string) bool {
+	return arg == "keystore" || arg == "sign" || arg == "verify" || arg == "serve"
+}
+
+// Run runs the command given by args, args exclude the program name
+func Run(args []string) error {
+	stdin := bufio.NewReader(os.Stdin)
+	if len(args) > 0 && args[0] == "serve" && len(args) <= 2 {
+		address := api.DefaultAddress
+		if len(args) == 2 {
+			address = args[1]
+		}
+		return serve(stdin, address)
+	}
+	if len(args) < 2 {
+		return ErrUsage
+	}
+	switch {
+	case args[0] == "keystore" && args[1] == "import" && len(args) == 3:
+		return importKeystore(stdin, args[2])
+	case args[0] == "keystore" && args[1] == "export" && len(args) == 4:
+		return exportKeystore(stdin, args[2], args[3])
+	case args[0] == "sign" && len(args) == 3:
+		kind, ok := wallet.ParseSignKind(args[1])
+		if !ok {
+			return ErrUsage
+		}
+		return sign(stdin, kind, args[2])
+	case args[0] == "verify" && len(args) == 5:
+		kind, ok := wallet.ParseSignKind(args[1])
+		if !ok {
+			return ErrUsage
+		}
+		return verify(kind, args[2], args[3], args[4])
+	}
+	return ErrUsage
+}
+
+func importKeystore(stdin *bufio.Reader, path string) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	keyJSON, err := io.ReadAll(io.LimitReader(file, maxKeystoreFileSize))
+	_ = file.Close()
+	if err != nil {
+		return err
+	}
+	if !keystore.IsKeystore(keyJSON) {
+		return keystore.ErrInvalidKeystore
+	}
+	passphrase, err := promptPassword(stdin, "Keystore passphrase: ")
+	if err != nil {
+		return err
+	}
+	err = openWallet(stdin)
+	if err != nil {
+		return err
+	}
+	defer wallet.GlobalWallet.Lock()
+	err = wallet.GlobalWallet.ImportKeystore(keyJSON, passphrase, model.IdentityECDSA)
+	if err != nil {
+		return err
+	}
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported account %s\n", account.EthAddress)
+	return nil
+}
+
+// exportKeystore writes the keystore of the account of ethAddress to path, encrypted with the
+// wallet password as in the app
+func exportKeystore(stdin *bufio.Reader, ethAddress, path string) (err error) {
+	passwd, err := promptPassword(stdin, "Wallet password: ")
+	if err != nil {
+		return err
+	}
+	err = wallet.GlobalWallet.OpenFromPassword(passwd)
+	if err != nil {
+		return err
+	}
+	defer wallet.GlobalWallet.Lock()
+	accounts, err := wallet.GlobalWallet.Accounts()
+	if err != nil {
+		return err
+	}
+	var account model.Account
+	for _, acc := range accounts {
+		if strings.EqualFold(acc.EthAddress, ethAddress) {
+			account = acc
+			break
+		}
+	}
+	if account.PublicKey == "" {
+		return ErrAccountNotFound
+	}
+	keyJSON, err := wallet.GlobalWallet.ExportKeystore(account, passwd)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, keyJSON, 0600)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported account %s to %s\n", account.EthAddress, path)
+	return nil
+}
+
+// sign prints the decoded data of path and signs it with the current account once the user
+// approves it
+func sign(stdin *bufio.Reader, kind wallet.SignKind, path string) error {
+	request, err := readSignRequest(kind, path)
+	if err != nil {
+		return err
+	}
+	payload, err := request.Payload()
+	if err != nil {
+		return err
+	}
+	err = openWallet(stdin)
+	if err != nil {
+		return err
+	}
+	defer wallet.GlobalWallet.Lock()
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return err
+	}
+	if !approveSign(stdin, kind, account.EthAddress, payload) {
+		return ErrSignRejected
+	}
+	signature, err := wallet.GlobalWallet.Sign(request)
+	if err != nil {
+		return err
+	}
+	fmt.Println(hexutil.Encode(signature))
+	return nil
+}
+
+// verify checks that signature of the data of path is signed by ethAddress, the wallet isn't
+// needed
+func verify(kind wallet.SignKind, ethAddress, signature, path string) error {
+	request, err := readSignRequest(kind, path)
+	if err != nil {
+		return err
+	}
+	err = request.Verify(ethAddress, signature)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Valid signature of %s\n", ethAddress)
+	return nil
+}
+
+// serve runs the api on address until the process is stopped, the wallet stays unlocked and every
+// signing is approved on the terminal one at a time
+func serve(stdin *bufio.Reader, address string) error {
+	err := openWallet(stdin)
+	if err != nil {
+		return err
+	}
+	defer wallet.GlobalWallet.Lock()
+	wallet.GlobalWallet.SetAutoLockTimeout(0)
+	var mutex sync.Mutex
+	handler := api.NewHandler(wallet.GlobalWallet, func(kind wallet.SignKind, address, payload string) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return approveSign(stdin, kind, address, payload)
+	})
+	fmt.Printf("Serving the api at http://%s\n", address)
+	return api.ListenAndServe(address, handler)
+}
+
+// approveSign prints the decoded payload of kind and asks the user to sign it with address
+func approveSign(stdin *bufio.Reader, kind wallet.SignKind, address, payload string) bool {
+	fmt.Printf("%s signed by %s:\n%s\n", kind, address, payload)
+	answer, err := prompt(stdin, "Sign? [y/N]: ")
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(answer), "y")
+}
+
+// readSignRequest reads the data to sign or verify from path, the line ending a message file
+// isn't part of the message
+func readSignRequest(kind wallet.SignKind, path string) (wallet.SignRequest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return wallet.SignRequest{}, err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxSignFileSize))
+	_ = file.Close()
+	if err != nil {
+		return wallet.SignRequest{}, err
+	}
+	payload := string(data)
+	if kind == wallet.SignKindMessage {
+		payload = strings.TrimSuffix(strings.TrimSuffix(payload, "\n"), "\r")
+	}
+	return wallet.NewSignRequest(kind, payload)
+}
+
+func openWallet(stdin *bufio.Reader) error {
+	passwd, err := promptPassword(stdin, "Wallet password: ")
+	if err != nil {
+		return err
+	}
+	return wallet.GlobalWallet.OpenFromPassword(passwd)
+}
+
+func prompt(stdin *bufio.Reader, label string) (string, error) {
+	fmt.Print(label)
+	line, err := stdin.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// promptPassword reads a password without echoing it when stdin is a terminal, piped input is
+// read as a line
+func promptPassword(stdin *bufio.Reader, label string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt(stdin, label)
+	}
+	fmt.Print(label)
+	passwd, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return string(passwd), nil
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"github.com/mearaj/protonet/internal/model"
+	"golang.org/x/image/draw"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+const (
+	// avatarSize is the width and height of an encoded avatar, a larger image is scaled down
+	avatarSize = 256
+	// maxAvatarSourcePixels bounds the images decoded for an avatar
+	maxAvatarSourcePixels = 64 << 20
+)
+
+var ErrInvalidAvatar = errors.New("avatar must be a png, jpeg or gif image")
+
+// EncodeAvatar decodes the png, jpeg or gif image in data, crops it to a square, scales it down
+// to avatarSize and encodes it as jpeg within model.MaxProfileAvatarSize
+func EncodeAvatar(data []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidAvatar
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxAvatarSourcePixels {
+		return nil, ErrInvalidAvatar
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidAvatar
+	}
+	bounds := src.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	crop := image.Rect(0, 0, side, side).Add(bounds.Min).
+		Add(image.Pt((bounds.Dx()-side)/2, (bounds.Dy()-side)/2))
+	size := avatarSize
+	if side < size {
+		size = side
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	// the quality is lowered until the avatar fits
+	for quality := 90; quality > 0; quality -= 20 {
+		var buff bytes.Buffer
+		if err = jpeg.Encode(&buff, dst, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, err
+		}
+		if buff.Len() <= model.MaxProfileAvatarSize {
+			return buff.Bytes(), nil
+		}
+	}
+	return nil, model.ErrProfileAvatarTooLong
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
@@ -20,56 +21,11 @@ import (
 	libcrypto "github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/mearaj/protonet/alog"
-	"github.com/mearaj/protonet/assets"
 	model2 "github.com/mearaj/protonet/internal/model"
+	"golang.org/x/crypto/nacl/box"
 	"golang.org/x/crypto/scrypt"
-	"image"
-	"image/gif"
-	_ "image/gif"
-	"image/jpeg"
-	_ "image/jpeg"
-	"image/png"
-	_ "image/png"
-	"net/http"
 )
 
-// returns the image in encoded format
-// if there's an error then returns assets.AppIcon image as fallback
-func fetchAvatarWithFallBack(url string) []byte {
-	res, err := http.Get(url)
-	if err != nil {
-		alog.Logger().Errorln(err)
-		return assets.AppIcon
-	}
-	img, ext, err := image.Decode(res.Body)
-	if err != nil {
-		alog.Logger().Errorln(err)
-		return assets.AppIcon
-	}
-	var buff bytes.Buffer
-	switch ext {
-	case "jpeg", "jpg":
-		err = jpeg.Encode(&buff, img, nil)
-		if err != nil {
-			alog.Logger().Errorln(err)
-			return assets.AppIcon
-		}
-	case "png":
-		err = png.Encode(&buff, img)
-		if err != nil {
-			alog.Logger().Errorln(err)
-			return assets.AppIcon
-		}
-	case "gif":
-		err = gif.Encode(&buff, img, nil)
-		if err != nil {
-			alog.Logger().Errorln(err)
-			return assets.AppIcon
-		}
-	}
-	return buff.Bytes()
-}
-
 func VerifyMessage(message *model2.Message, pubKeyHex string, algo int) (err error) {
 	publicKey, err := GetPublicKeyFromStr(pubKeyHex, algo)
 	if err != nil {
@@ -166,6 +122,9 @@ func encryptStructAlgoSecp256k1(pubKeyHex string, message interface{}) (data []b
 		return nil, err
 	}
 	pubKeyBytes, err := libPubKey.Raw()
+	if err != nil {
+		return nil, err
+	}
 	pubKey, err := secp256k1.ParsePubKey(pubKeyBytes)
 	if err != nil {
 		return nil, err
@@ -216,6 +175,33 @@ func encryptStructAlgoECDSA(pubKeyHex string, message interface{}) (data []byte,
 	return encrypted, err
 }
 
+// encryptStructAlgoEd25519 seals message in an anonymous nacl box to the X25519 key converted
+// from the ed25519 public key
+func encryptStructAlgoEd25519(pubKeyHex string, message interface{}) (data []byte, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	libPubKey, err := GetPublicKeyFromStr(pubKeyHex, libcrypto.Ed25519)
+	if err != nil {
+		return nil, err
+	}
+	pubKeyBytes, err := libPubKey.Raw()
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := x25519PublicKey(pubKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := EncodeToBytes(message)
+	if err != nil {
+		return nil, err
+	}
+	return box.SealAnonymous(nil, bs, pubKey, rand.Reader)
+}
+
 func GetEncryptedStruct(pubKeyHex string, message interface{}, algo int) (data []byte, err error) {
 	switch algo {
 	// Ref https://stackoverflow.com/questions/39410808/how-to-convert-a-interface-into-type-rsa-publickey-golang
@@ -224,6 +210,8 @@ func GetEncryptedStruct(pubKeyHex string, message interface{}, algo int) (data [
 		return nil, errors.New("rsa algorithm not supported")
 	case libcrypto.Secp256k1:
 		return encryptStructAlgoSecp256k1(pubKeyHex, message)
+	case libcrypto.Ed25519:
+		return encryptStructAlgoEd25519(pubKeyHex, message)
 	case libcrypto.ECDSA:
 		return encryptStructAlgoECDSA(pubKeyHex, message)
 	default:
@@ -241,7 +229,13 @@ func decryptStructAlgoSecp256k1(pvtKeyHex string, msgEncrypted []byte, message i
 		return
 	}
 	privKey := secp256k1.PrivKeyFromBytes(pkBytes)
+	if len(msgEncrypted) < 4 {
+		return errors.New("invalid encrypted message")
+	}
 	pubKeyLen := binary.LittleEndian.Uint32(msgEncrypted[:4])
+	if uint64(len(msgEncrypted)) < 4+uint64(pubKeyLen) {
+		return errors.New("invalid encrypted message")
+	}
 	pubKeyBytes := msgEncrypted[4 : 4+pubKeyLen]
 	pubKey, err := secp256k1.ParsePubKey(pubKeyBytes)
 	if err != nil {
@@ -286,6 +280,26 @@ func decryptStructAlgoECDSA(pvtKeyHex string, msgEncrypted []byte, message inter
 	return DecodeToStruct(message, msgBs)
 }
 
+func decryptStructAlgoEd25519(pvtKeyHex string, msgEncrypted []byte, message interface{}) (err error) {
+	libPrivateKey, err := GetPrivateKeyFromStr(pvtKeyHex, libcrypto.Ed25519)
+	if err != nil {
+		return err
+	}
+	pkBytes, err := libPrivateKey.Raw()
+	if err != nil {
+		return err
+	}
+	pvtKey, pubKey, err := x25519PrivateKey(pkBytes)
+	if err != nil {
+		return err
+	}
+	msgBs, ok := box.OpenAnonymous(nil, msgEncrypted, pubKey, pvtKey)
+	if !ok {
+		return errors.New("invalid encrypted message")
+	}
+	return DecodeToStruct(message, msgBs)
+}
+
 func GetDecryptedStruct(pvtKeyHex string, msgEncrypted []byte, message interface{}, algo int) (err error) {
 	switch algo {
 	case libcrypto.RSA:
@@ -294,6 +308,8 @@ func GetDecryptedStruct(pvtKeyHex string, msgEncrypted []byte, message interface
 		return errors.New("rsa algorithm not supported")
 	case libcrypto.Secp256k1:
 		return decryptStructAlgoSecp256k1(pvtKeyHex, msgEncrypted, message)
+	case libcrypto.Ed25519:
+		return decryptStructAlgoEd25519(pvtKeyHex, msgEncrypted, message)
 	case libcrypto.ECDSA:
 		return decryptStructAlgoECDSA(pvtKeyHex, msgEncrypted, message)
 	default:
@@ -364,9 +380,14 @@ func GetPrivateKeyFromStr(privateKeyStr string, algo int) (privateKey libcrypto.
 		privateKey, err = libcrypto.UnmarshalSecp256k1PrivateKey(privateKeyBytes)
 		return privateKey, err
 	case libcrypto.Ed25519:
-		//priv := ed25519.NewKeyFromSeed(privateKeyBytes)
-		//pubKeyBytes := ([]byte)(priv.Public().(ed25519.PublicKey))
-		//ed25519PrivateKeyBytes := append(privateKeyBytes, pubKeyBytes...)
+		// the seed of the ed25519 identity of an account is derived from its ethereum private key
+		if len(privateKeyBytes) == ed25519.SeedSize {
+			seed, err := ed25519IdentitySeed(privateKeyBytes)
+			if err != nil {
+				return nil, err
+			}
+			privateKeyBytes = ed25519.NewKeyFromSeed(seed)
+		}
 		privateKey, err = libcrypto.UnmarshalEd25519PrivateKey(privateKeyBytes)
 		return privateKey, err
 	case libcrypto.ECDSA:
@@ -456,16 +477,10 @@ func GetPrivateKeyFromPasswd(a model2.Account, passwd string) (string, error) {
 		return "", err
 	}
 	pvtKeyHex := hex.EncodeToString(pvtKeyBytes)
-	pvtKey, err := GetPrivateKeyFromStr(pvtKeyHex, libcrypto.Secp256k1)
-	if err != nil {
-		return "", err
-	}
-	pubKey := pvtKey.GetPublic()
-	pubKeyBs, err := pubKey.Raw()
+	pubKeyStr, err := IdentityPublicKey(pvtKeyHex, a.Algorithm())
 	if err != nil {
 		return "", err
 	}
-	pubKeyStr := hex.EncodeToString(pubKeyBs)
 	if a.PublicKey != pubKeyStr {
 		return "", errors.New("invalid password")
 	}
@@ -0,0 +1,144 @@
+package common
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	libcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	model2 "github.com/mearaj/protonet/internal/model"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"math/big"
+)
+
+var (
+	ErrIdentityAlgorithm = errors.New("identity algorithm not supported")
+	ErrInvalidPublicKey  = errors.New("invalid public key")
+)
+
+// IdentityKeyType returns the libp2p key type of the identity algorithm of an account
+func IdentityKeyType(algorithm string) (int, error) {
+	switch algorithm {
+	case model2.IdentityECDSA, "":
+		return libcrypto.ECDSA, nil
+	case model2.IdentitySecp256k1:
+		return libcrypto.Secp256k1, nil
+	case model2.IdentityEd25519:
+		return libcrypto.Ed25519, nil
+	}
+	return 0, ErrIdentityAlgorithm
+}
+
+// PublicKeyType returns the libp2p key type of the hex public key of an identity from its
+// encoding, ed25519 keys are 32 bytes, secp256k1 keys are 33 bytes compressed or 65 bytes
+// uncompressed and ecdsa keys are PKIX encoded
+func PublicKeyType(publicKeyHex string) (int, error) {
+	publicKeyBytes, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case len(publicKeyBytes) == ed25519.PublicKeySize:
+		return libcrypto.Ed25519, nil
+	case len(publicKeyBytes) == 33 && (publicKeyBytes[0] == 2 || publicKeyBytes[0] == 3),
+		len(publicKeyBytes) == 65 && publicKeyBytes[0] == 4:
+		return libcrypto.Secp256k1, nil
+	case len(publicKeyBytes) > 0 && publicKeyBytes[0] == 0x30:
+		return libcrypto.ECDSA, nil
+	}
+	return 0, ErrInvalidPublicKey
+}
+
+// ParsePublicKey returns the public key of an identity of any supported algorithm
+func ParsePublicKey(publicKeyHex string) (libcrypto.PubKey, error) {
+	keyType, err := PublicKeyType(publicKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	return GetPublicKeyFromStr(publicKeyHex, keyType)
+}
+
+// IdentityPublicKey returns the hex public key of the identity of the ethereum private key with
+// the algorithm of an account
+func IdentityPublicKey(pvtKeyHex string, algorithm string) (string, error) {
+	keyType, err := IdentityKeyType(algorithm)
+	if err != nil {
+		return "", err
+	}
+	pvtKey, err := GetPrivateKeyFromStr(pvtKeyHex, keyType)
+	if err != nil {
+		return "", err
+	}
+	pubKeyBytes, err := pvtKey.GetPublic().Raw()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(pubKeyBytes), nil
+}
+
+// ed25519IdentityInfo separates the seed of the ed25519 identity from any other use of the
+// ethereum private key it's derived from
+const ed25519IdentityInfo = "protonet ed25519 identity"
+
+// ed25519IdentitySeed derives the seed of the ed25519 identity of an account from its ethereum
+// private key, the key itself isn't reused as the seed of a signature scheme on another curve
+func ed25519IdentitySeed(pvtKey []byte) ([]byte, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, pvtKey, nil, []byte(ed25519IdentityInfo)), seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// x25519PublicKey converts the ed25519 public key to the X25519 public key of the same secret,
+// the montgomery u of the edwards point is (1 + y) / (1 - y)
+func x25519PublicKey(publicKey []byte) (*[32]byte, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// the key is y in little endian with the sign of x in the highest bit
+	yBytes := make([]byte, len(publicKey))
+	for i, b := range publicKey {
+		yBytes[len(publicKey)-1-i] = b
+	}
+	yBytes[0] &= 0x7f
+	y := new(big.Int).SetBytes(yBytes)
+	if y.Cmp(p) >= 0 {
+		return nil, ErrInvalidPublicKey
+	}
+	one := big.NewInt(1)
+	denominator := new(big.Int).Mod(new(big.Int).Sub(one, y), p)
+	if denominator.Sign() == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+	u := new(big.Int).Add(one, y)
+	u.Mul(u, denominator.ModInverse(denominator, p))
+	u.Mod(u, p)
+	uBytes := u.FillBytes(make([]byte, 32))
+	var x25519Key [32]byte
+	for i, b := range uBytes {
+		x25519Key[len(uBytes)-1-i] = b
+	}
+	return &x25519Key, nil
+}
+
+// x25519PrivateKey converts the ed25519 private key to the X25519 key pair of the same secret,
+// the scalar of both is the first half of the sha512 of the seed
+func x25519PrivateKey(privateKey ed25519.PrivateKey) (pvtKey *[32]byte, pubKey *[32]byte, err error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, nil, errors.New("invalid private key")
+	}
+	digest := sha512.Sum512(privateKey.Seed())
+	pvtKey, pubKey = new([32]byte), new([32]byte)
+	copy(pvtKey[:], digest[:32])
+	publicKey, err := curve25519.X25519(pvtKey[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	copy(pubKey[:], publicKey)
+	return pvtKey, pubKey, nil
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SafetyNumberQRPrefix prefixes the safety number in the QR code shown for in-person verification
+const SafetyNumberQRPrefix = "protonet-safety-number:"
+
+// SafetyNumberLength is the number of digits of a safety number, half of them are derived from
+// each public key
+const SafetyNumberLength = 60
+
+const (
+	fingerprintVersion    = 0
+	fingerprintIterations = 5200
+	fingerprintChunks     = 6
+)
+
+var ErrInvalidSafetyNumber = errors.New("invalid safety number")
+
+// SafetyNumber returns the safety number of the conversation between the two public keys, both
+// sides compute the same number as the fingerprints of the keys are sorted. Each fingerprint is
+// 30 digits of an iterated sha512 of the key, so forging a key with the same number is
+// impractical.
+func SafetyNumber(publicKeyA, publicKeyB string) (string, error) {
+	fingerprintA, err := keyFingerprint(publicKeyA)
+	if err != nil {
+		return "", err
+	}
+	fingerprintB, err := keyFingerprint(publicKeyB)
+	if err != nil {
+		return "", err
+	}
+	fingerprints := []string{fingerprintA, fingerprintB}
+	sort.Strings(fingerprints)
+	return fingerprints[0] + fingerprints[1], nil
+}
+
+// keyFingerprint returns the 30 digits fingerprint of the hex public key of an identity
+func keyFingerprint(publicKeyHex string) (string, error) {
+	if _, err := PublicKeyType(publicKeyHex); err != nil {
+		return "", err
+	}
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		return "", err
+	}
+	version := make([]byte, 2)
+	binary.BigEndian.PutUint16(version, fingerprintVersion)
+	digest := append(version, publicKey...)
+	for i := 0; i < fingerprintIterations; i++ {
+		sum := sha512.Sum512(append(digest, publicKey...))
+		digest = sum[:]
+	}
+	var fingerprint strings.Builder
+	for i := 0; i < fingerprintChunks; i++ {
+		chunk := digest[i*5 : i*5+5]
+		value := uint64(chunk[0])<<32 | uint64(chunk[1])<<24 | uint64(chunk[2])<<16 |
+			uint64(chunk[3])<<8 | uint64(chunk[4])
+		fingerprint.WriteString(fmt.Sprintf("%05d", value%100000))
+	}
+	return fingerprint.String(), nil
+}
+
+// FormatSafetyNumber groups the digits of safetyNumber by five to be read aloud
+func FormatSafetyNumber(safetyNumber string) string {
+	groups := make([]string, 0, len(safetyNumber)/5+1)
+	for len(safetyNumber) > 5 {
+		groups = append(groups, safetyNumber[:5])
+		safetyNumber = safetyNumber[5:]
+	}
+	groups = append(groups, safetyNumber)
+	return strings.Join(groups, " ")
+}
+
+// SafetyNumberQRCode returns the text of the QR code of safetyNumber
+func SafetyNumberQRCode(safetyNumber string) string {
+	return SafetyNumberQRPrefix + safetyNumber
+}
+
+// ParseSafetyNumber returns the safety number of code, code is the text of a scanned QR code or
+// the digits typed with or without spaces
+func ParseSafetyNumber(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	code = strings.TrimPrefix(code, SafetyNumberQRPrefix)
+	code = strings.Join(strings.Fields(code), "")
+	if len(code) != SafetyNumberLength {
+		return "", ErrInvalidSafetyNumber
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidSafetyNumber
+		}
+	}
+	return code, nil
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"github.com/dgraph-io/badger/v4"
 	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/internal/model"
@@ -71,6 +72,34 @@ func (d *ProtoDB) AddUpdateAccount(acc *Account) (err error) {
 	return err
 }
 
+// ReplaceAccount overwrites the stored record of acc, keeping its timestamps
+// and hence its position among Accounts
+func (d *ProtoDB) ReplaceAccount(acc *Account) (err error) {
+	if acc == nil || len(acc.PublicKey) == 0 {
+		return ErrInvalidAccount
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	fullKey, err := acc.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	err = dB.Update(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte(fullKey))
+		if err != nil {
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				return ErrAccountDoesNotExist
+			}
+			return err
+		}
+		return txn.Set([]byte(fullKey), EncodeToBytes(acc))
+	})
+	return err
+}
+
 func (d *ProtoDB) Account() (acc Account, err error) {
 	err = d.getErrorState()
 	if err != nil {
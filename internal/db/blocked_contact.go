@@ -0,0 +1,88 @@
+package db
+
+import (
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"time"
+)
+
+type BlockedContact = model.BlockedContact
+
+// BlockContact adds publicKey to the block list of the account
+func (d *ProtoDB) BlockContact(accountPublicKey, publicKey string) (err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	blocked := BlockedContact{AccountPublicKey: accountPublicKey, PublicKey: publicKey, BlockedAt: time.Now()}
+	key, err := blocked.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key), EncodeToBytes(&blocked))
+	})
+	if err != nil {
+		return err
+	}
+	d.fireBlockedContactsChanged(accountPublicKey)
+	return nil
+}
+
+// UnblockContact removes publicKey from the block list of the account
+func (d *ProtoDB) UnblockContact(accountPublicKey, publicKey string) (err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	blocked := BlockedContact{AccountPublicKey: accountPublicKey, PublicKey: publicKey}
+	key, err := blocked.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(key))
+	})
+	if err != nil {
+		return err
+	}
+	d.fireBlockedContactsChanged(accountPublicKey)
+	return nil
+}
+
+// IsContactBlocked returns true if publicKey is in the block list of the account
+func (d *ProtoDB) IsContactBlocked(accountPublicKey, publicKey string) bool {
+	blocked := BlockedContact{AccountPublicKey: accountPublicKey, PublicKey: publicKey}
+	key, err := blocked.GetDBFullKey()
+	if err != nil {
+		return false
+	}
+	err = d.ViewRecord([]byte(key), &blocked)
+	return err == nil
+}
+
+// BlockedContacts returns the block list of the account
+func (d *ProtoDB) BlockedContacts(accountPublicKey string) (blockedContacts []BlockedContact, err error) {
+	blocked := BlockedContact{AccountPublicKey: accountPublicKey}
+	keys, err := d.prefixScan(blocked.GetDBPrefixKey(), KeySeparator, 1)
+	if err != nil {
+		return blockedContacts, err
+	}
+	for _, key := range keys {
+		var eachBlocked BlockedContact
+		if err = d.ViewRecord([]byte(key), &eachBlocked); err == nil {
+			blockedContacts = append(blockedContacts, eachBlocked)
+		}
+	}
+	return blockedContacts, err
+}
+
+func (d *ProtoDB) fireBlockedContactsChanged(accountPublicKey string) {
+	d.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.BlockedContactsChangedEventData{AccountPublicKey: accountPublicKey},
+		Topic: pubsub.BlockedContactsChangedEventTopic,
+	})
+}
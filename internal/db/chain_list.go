@@ -0,0 +1,39 @@
+package db
+
+import (
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+	"time"
+)
+
+type ChainList = model.ChainList
+
+// ChainList returns the saved chain list or ErrChainListNotFound if none is merged yet
+func (d *ProtoDB) ChainList() (chainList ChainList, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return chainList, err
+	}
+	err = d.ViewRecord([]byte(chainList.GetDBFullKey()), &chainList)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return chainList, ErrChainListNotFound
+	}
+	return chainList, err
+}
+
+// SaveChainList replaces the saved chain list
+func (d *ProtoDB) SaveChainList(chainList *ChainList) (err error) {
+	if chainList == nil || len(chainList.Data) == 0 {
+		return ErrInvalidChainList
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	chainList.UpdatedAt = time.Now()
+	dB := d.getState().dB
+	return dB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(chainList.GetDBFullKey()), EncodeToBytes(chainList))
+	})
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/dgraph-io/badger/v4"
@@ -14,7 +15,15 @@ import (
 type Contact = model.Contact
 
 func (d *ProtoDB) Contacts(accountPublicKey string, offset, limit int) (contacts []Contact, err error) {
-	contact := Contact{AccountPublicKey: accountPublicKey}
+	return d.contacts(Contact{AccountPublicKey: accountPublicKey}, offset, limit)
+}
+
+// ContactRequests returns the contact requests of the account, the latest first
+func (d *ProtoDB) ContactRequests(accountPublicKey string, offset, limit int) (contacts []Contact, err error) {
+	return d.contacts(Contact{AccountPublicKey: accountPublicKey, Request: true}, offset, limit)
+}
+
+func (d *ProtoDB) contacts(contact Contact, offset, limit int) (contacts []Contact, err error) {
 	keyPrefix := contact.GetDBPrefixKey()
 	allKeys, err := d.prefixScanSorted(keyPrefix, KeySeparator, 1, 3, true)
 	if err != nil {
@@ -38,12 +47,45 @@ func (d *ProtoDB) Contacts(accountPublicKey string, offset, limit int) (contacts
 	return contacts, err
 }
 
+// Contact returns the contact with publicKey of the account, it returns ErrContactNotFound if
+// the account has no such contact
+func (d *ProtoDB) Contact(accountPublicKey, publicKey string) (contact Contact, err error) {
+	return d.contact(Contact{AccountPublicKey: accountPublicKey, PublicKey: publicKey})
+}
+
+// ContactRequest returns the contact request of publicKey to the account, it returns
+// ErrContactNotFound if there's no such request
+func (d *ProtoDB) ContactRequest(accountPublicKey, publicKey string) (contact Contact, err error) {
+	return d.contact(Contact{AccountPublicKey: accountPublicKey, PublicKey: publicKey, Request: true})
+}
+
+func (d *ProtoDB) contact(c Contact) (contact Contact, err error) {
+	if c.AccountPublicKey == "" || c.PublicKey == "" {
+		return contact, ErrInvalidContact
+	}
+	keys, err := d.prefixScanSorted(c.GetDBPrefixKey(), KeySeparator, 2, 3, true)
+	if err != nil {
+		return contact, err
+	}
+	if len(keys) == 0 {
+		return contact, ErrContactNotFound
+	}
+	err = d.ViewRecord([]byte(keys[0]), &contact)
+	return contact, err
+}
+
 func (d *ProtoDB) AddUpdateContact(c *Contact) (err error) {
 	err = d.getErrorState()
 	if err != nil {
 		return err
 	}
 	dB := d.getState().dB
+	// a contact is never both accepted and a request
+	if c.Request {
+		if _, err = d.Contact(c.AccountPublicKey, c.PublicKey); err == nil {
+			c.Request = false
+		}
+	}
 	fullKey, err := c.GetDBFullKey()
 	if err != nil {
 		return err
@@ -52,6 +94,16 @@ func (d *ProtoDB) AddUpdateContact(c *Contact) (err error) {
 	if err != nil {
 		return err
 	}
+	// saving the accepted contact accepts its request
+	var requestKeys []string
+	if !c.Request {
+		request := Contact{AccountPublicKey: c.AccountPublicKey, PublicKey: c.PublicKey, Request: true}
+		requestKeys, err = d.prefixScan(request.GetDBPrefixKey(), KeySeparator, 2)
+		if err != nil {
+			return err
+		}
+		duplicateKeys = append(duplicateKeys, requestKeys...)
+	}
 	txn := dB.NewTransaction(true)
 	defer txn.Discard()
 	if len(duplicateKeys) > 0 {
@@ -82,10 +134,53 @@ func (d *ProtoDB) AddUpdateContact(c *Contact) (err error) {
 		Topic: pubsub.SaveContactTopic,
 	}
 	d.EventBroker.Fire(event)
+	if c.Request || len(requestKeys) > 0 {
+		d.EventBroker.Fire(pubsub.Event{
+			Data:  pubsub.ContactRequestsChangedEventData{AccountPublicKey: c.AccountPublicKey},
+			Topic: pubsub.ContactRequestsChangedEventTopic,
+		})
+	}
 	return err
 }
 
+// AcceptContactRequest moves the request of publicKey to the contacts of the account, its
+// messages are kept
+func (d *ProtoDB) AcceptContactRequest(accountPublicKey, publicKey string) (contact Contact, err error) {
+	contact, err = d.ContactRequest(accountPublicKey, publicKey)
+	if err != nil {
+		return contact, err
+	}
+	contact.Request = false
+	err = d.AddUpdateContact(&contact)
+	return contact, err
+}
+
+// DeleteContactRequest deletes the request of publicKey and its quarantined messages
+func (d *ProtoDB) DeleteContactRequest(accountPublicKey, publicKey string) (err error) {
+	if _, err = d.ContactRequest(accountPublicKey, publicKey); err != nil {
+		return err
+	}
+	_, err = d.DeleteContacts(accountPublicKey, []Contact{{PublicKey: publicKey}})
+	if err != nil {
+		return err
+	}
+	d.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.ContactRequestsChangedEventData{AccountPublicKey: accountPublicKey},
+		Topic: pubsub.ContactRequestsChangedEventTopic,
+	})
+	return nil
+}
+
 func (d *ProtoDB) ContactsCount(accountPublicKey string) (count int64, err error) {
+	return d.contactsCount(Contact{AccountPublicKey: accountPublicKey})
+}
+
+// ContactRequestsCount returns the number of contact requests of the account
+func (d *ProtoDB) ContactRequestsCount(accountPublicKey string) (count int64, err error) {
+	return d.contactsCount(Contact{AccountPublicKey: accountPublicKey, Request: true})
+}
+
+func (d *ProtoDB) contactsCount(c Contact) (count int64, err error) {
 	err = d.getErrorState()
 	if err != nil {
 		return count, err
@@ -96,7 +191,6 @@ func (d *ProtoDB) ContactsCount(accountPublicKey string) (count int64, err error
 			alog.Logger().Errorln(r)
 		}
 	}()
-	c := Contact{AccountPublicKey: accountPublicKey}
 	prefixKey := c.GetDBPrefixKey()
 	// This should never happen
 	if err != nil {
@@ -144,7 +238,9 @@ func (d *ProtoDB) DeleteContacts(accountPublicKey string, contacts []Contact) (c
 			})
 		}
 	}()
-	// This will delete all the contacts and all messages that belongs to deleted contact
+	// This will delete all the contacts and all messages that belongs to deleted contact, a blocked
+	// key stays blocked
+	blockedPrefix := []byte(KeyPrefixBlockedContacts + KeySeparator)
 	for _, eachContact := range contacts {
 		keyComponent := fmt.Sprintf("%s%s%s", accountPublicKey, KeySeparator, eachContact.PublicKey)
 		err = dB.Update(func(txn *badger.Txn) error {
@@ -154,7 +250,7 @@ func (d *ProtoDB) DeleteContacts(accountPublicKey string, contacts []Contact) (c
 			for it.Rewind(); it.Valid(); it.Next() {
 				item := it.Item()
 				k := item.Key()
-				if strings.Contains(string(k), keyComponent) {
+				if strings.Contains(string(k), keyComponent) && !bytes.HasPrefix(k, blockedPrefix) {
 					err = txn.Delete(k)
 					if err == nil {
 						count++
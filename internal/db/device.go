@@ -0,0 +1,75 @@
+package db
+
+import (
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+)
+
+type Device = model.Device
+
+// SaveDevice saves the certified device, a revoked device stays revoked. The certificate must be
+// verified by the caller.
+func (d *ProtoDB) SaveDevice(device *Device) (err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	key, err := device.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	var saved Device
+	if err = d.ViewRecord([]byte(key), &saved); err == nil {
+		if saved.OwnerPublicKey != device.OwnerPublicKey {
+			return ErrInvalidDevice
+		}
+		if saved.IsRevoked() || (!device.IsRevoked() && saved.Name == device.Name) {
+			*device = saved
+			return nil
+		}
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key), EncodeToBytes(device))
+	})
+	if err != nil {
+		return err
+	}
+	d.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.DevicesChangedEventData{AccountPublicKey: device.AccountPublicKey},
+		Topic: pubsub.DevicesChangedEventTopic,
+	})
+	return nil
+}
+
+// Device returns the device of devicePublicKey saved by the account, it returns
+// ErrDeviceNotFound if there's none
+func (d *ProtoDB) Device(accountPublicKey, devicePublicKey string) (device Device, err error) {
+	device = Device{AccountPublicKey: accountPublicKey, OwnerPublicKey: accountPublicKey, DevicePublicKey: devicePublicKey}
+	key, err := device.GetDBFullKey()
+	if err != nil {
+		return device, err
+	}
+	device = Device{}
+	if err = d.ViewRecord([]byte(key), &device); err != nil {
+		return device, ErrDeviceNotFound
+	}
+	return device, nil
+}
+
+// Devices returns the devices of the owner saved by the account, revoked ones included
+func (d *ProtoDB) Devices(accountPublicKey, ownerPublicKey string) (devices []Device, err error) {
+	device := Device{AccountPublicKey: accountPublicKey}
+	keys, err := d.prefixScan(device.GetDBPrefixKey(), KeySeparator, 1)
+	if err != nil {
+		return devices, err
+	}
+	for _, key := range keys {
+		var eachDevice Device
+		if err = d.ViewRecord([]byte(key), &eachDevice); err == nil && eachDevice.OwnerPublicKey == ownerPublicKey {
+			devices = append(devices, eachDevice)
+		}
+	}
+	return devices, err
+}
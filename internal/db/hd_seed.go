@@ -0,0 +1,58 @@
+package db
+
+import (
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+	"time"
+)
+
+type HDSeed = model.HDSeed
+
+// HDSeed returns the saved seed or ErrHDSeedNotFound if it isn't created yet
+func (d *ProtoDB) HDSeed() (seed HDSeed, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return seed, err
+	}
+	err = d.ViewRecord([]byte(seed.GetDBFullKey()), &seed)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return seed, ErrHDSeedNotFound
+	}
+	return seed, err
+}
+
+// HDSeedByID returns the seed of id or ErrHDSeedNotFound if it isn't saved
+func (d *ProtoDB) HDSeedByID(id string) (seed HDSeed, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return seed, err
+	}
+	seed.ID = id
+	err = d.ViewRecord([]byte(seed.GetDBIDKey()), &seed)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return seed, ErrHDSeedNotFound
+	}
+	return seed, err
+}
+
+// SaveHDSeed saves seed as the current seed and under its ID
+func (d *ProtoDB) SaveHDSeed(seed *HDSeed) (err error) {
+	if seed == nil || seed.ID == "" || seed.Mnemonic == "" || seed.Seed == "" {
+		return ErrInvalidHDSeed
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	if seed.CreatedAt.IsZero() {
+		seed.CreatedAt = time.Now()
+	}
+	dB := d.getState().dB
+	return dB.Update(func(txn *badger.Txn) error {
+		if err := txn.Set([]byte(seed.GetDBIDKey()), EncodeToBytes(seed)); err != nil {
+			return err
+		}
+		return txn.Set([]byte(seed.GetDBFullKey()), EncodeToBytes(seed))
+	})
+}
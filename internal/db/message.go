@@ -174,7 +174,10 @@ func (d *ProtoDB) SaveOrUpdateMessage(accountPublicKey string, msg *Message) (er
 	}
 	if !isDuplicate {
 		if !isMsgCreatedByMe {
-			msg.State = model.MessageStateReceived
+			// a message synced from another device of the account keeps its state
+			if msg.State < model.MessageStateReceived {
+				msg.State = model.MessageStateReceived
+			}
 			msgStateChanged = true
 		}
 		bs := EncodeToBytes(&msg)
@@ -189,12 +192,25 @@ func (d *ProtoDB) SaveOrUpdateMessage(accountPublicKey string, msg *Message) (er
 
 	// After saving/updating new message, we update/create contact to update contact's UpdatedAt
 	if !isDuplicate {
-		contact = &Contact{PublicKey: msg.Sender, AccountPublicKey: msg.Recipient}
+		contactPublicKey := msg.Sender
 		if isMsgCreatedByMe {
-			contact.PublicKey = msg.Recipient
-			contact.AccountPublicKey = msg.Sender
+			contactPublicKey = msg.Recipient
+		}
+		// the saved contact keeps its address, name and verification
+		var saved Contact
+		saved, err = d.Contact(accountPublicKey, contactPublicKey)
+		if err != nil {
+			// an unknown sender is quarantined as a request, replying to a request accepts it
+			saved, err = d.ContactRequest(accountPublicKey, contactPublicKey)
+			if err != nil {
+				saved = Contact{PublicKey: contactPublicKey, AccountPublicKey: accountPublicKey, CreatedAt: time.Now()}
+				saved.Request = !isMsgCreatedByMe
+			}
+			if isMsgCreatedByMe {
+				saved.Request = false
+			}
 		}
-		contact.CreatedAt = time.Now()
+		contact = &saved
 		contact.UpdatedAt = time.Now()
 		err = d.AddUpdateContact(contact)
 		if err != nil {
@@ -396,3 +412,65 @@ func (d *ProtoDB) MarkPrevMessagesAsRead(accountPublicKey, contactPublicKey stri
 	}
 	return count, err
 }
+
+// SavePaymentStatus updates the status and the matched log of the payment of msg, they're
+// verified locally hence they're the only change of a saved message which isn't decided by its
+// recipient
+func (d *ProtoDB) SavePaymentStatus(accountPublicKey string, msg *Message) (err error) {
+	if msg == nil || msg.Payment == nil {
+		return ErrInvalidMessage
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	var statusChanged bool
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+		if statusChanged {
+			d.EventBroker.Fire(pubsub.Event{
+				Data:  pubsub.MessageStateChangedEventData{Message: *msg},
+				Topic: pubsub.MessageStateChangedEventTopic,
+			})
+		}
+	}()
+	fullKey, err := msg.GetDBFullKey(accountPublicKey)
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(fullKey))
+		if err != nil {
+			return err
+		}
+		var dbMsg Message
+		err = item.Value(func(val []byte) error {
+			return DecodeToStruct(&dbMsg, val)
+		})
+		if err != nil {
+			return err
+		}
+		if dbMsg.Payment == nil {
+			return ErrInvalidMessage
+		}
+		if dbMsg.Payment.Status == msg.Payment.Status && dbMsg.Payment.LogIndex == msg.Payment.LogIndex {
+			return nil
+		}
+		dbMsg.Payment.Status = msg.Payment.Status
+		dbMsg.Payment.LogIndex = msg.Payment.LogIndex
+		statusChanged = true
+		*msg = dbMsg
+		return txn.Set([]byte(fullKey), EncodeToBytes(&dbMsg))
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		statusChanged = false
+		return ErrInvalidMessage
+	}
+	if err != nil {
+		statusChanged = false
+	}
+	return err
+}
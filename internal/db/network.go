@@ -0,0 +1,165 @@
+package db
+
+import (
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"time"
+)
+
+type Network = model.Network
+type APIKey = model.APIKey
+
+// Networks returns the custom chains and the endpoints added to the embedded chains
+func (d *ProtoDB) Networks() (networks []Network, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return networks, err
+	}
+	var network Network
+	keys, err := d.prefixScan(network.GetDBPrefixKey(), KeySeparator, 1)
+	if err != nil {
+		return networks, err
+	}
+	for _, k := range keys {
+		var network Network
+		err = d.ViewRecord([]byte(k), &network)
+		if err != nil {
+			return networks, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Network returns the network of chainID or ErrNetworkNotFound, chainID is in decimal
+func (d *ProtoDB) Network(chainID string) (network Network, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return network, err
+	}
+	network.ChainID = chainID
+	fullKey, err := network.GetDBFullKey()
+	if err != nil {
+		return network, err
+	}
+	err = d.ViewRecord([]byte(fullKey), &network)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return network, ErrNetworkNotFound
+	}
+	return network, err
+}
+
+// SaveNetwork adds or replaces the network
+func (d *ProtoDB) SaveNetwork(network *Network) (err error) {
+	if network == nil || (network.Custom && network.Name == "") {
+		return ErrInvalidNetwork
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	fullKey, err := network.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	if network.CreatedAt.IsZero() {
+		network.CreatedAt = time.Now()
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(fullKey), EncodeToBytes(network))
+	})
+	if err != nil {
+		return err
+	}
+	d.fireNetworksChanged(network.ChainID)
+	return nil
+}
+
+// DeleteNetwork deletes the network, it's a no-op if the network doesn't exist
+func (d *ProtoDB) DeleteNetwork(network *Network) (err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	fullKey, err := network.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(fullKey))
+	})
+	if err != nil {
+		return err
+	}
+	d.fireNetworksChanged(network.ChainID)
+	return nil
+}
+
+func (d *ProtoDB) fireNetworksChanged(chainID string) {
+	d.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.NetworksChangedEventData{ChainID: chainID},
+		Topic: pubsub.NetworksChangedEventTopic,
+	})
+}
+
+// APIKeys returns the saved api keys, they are encrypted by the wallet
+func (d *ProtoDB) APIKeys() (apiKeys []APIKey, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return apiKeys, err
+	}
+	var apiKey APIKey
+	keys, err := d.prefixScan(apiKey.GetDBPrefixKey(), KeySeparator, 1)
+	if err != nil {
+		return apiKeys, err
+	}
+	for _, k := range keys {
+		var apiKey APIKey
+		err = d.ViewRecord([]byte(k), &apiKey)
+		if err != nil {
+			return apiKeys, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	return apiKeys, nil
+}
+
+// SaveAPIKey adds or replaces the api key of its rpc
+func (d *ProtoDB) SaveAPIKey(apiKey *APIKey) (err error) {
+	if apiKey == nil || apiKey.KeyEnc == "" {
+		return ErrInvalidAPIKey
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	fullKey, err := apiKey.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	return dB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(fullKey), EncodeToBytes(apiKey))
+	})
+}
+
+// DeleteAPIKey deletes the api key of rpc, it's a no-op if the key doesn't exist
+func (d *ProtoDB) DeleteAPIKey(rpc string) (err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	apiKey := APIKey{RPC: rpc}
+	fullKey, err := apiKey.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	return dB.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(fullKey))
+	})
+}
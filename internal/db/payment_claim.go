@@ -0,0 +1,73 @@
+package db
+
+import (
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+)
+
+type PaymentClaim = model.PaymentClaim
+
+// PaymentClaims returns the claims of the transfers of the transaction txHash on chainID
+func (d *ProtoDB) PaymentClaims(accountPublicKey, chainID, txHash string) (claims []PaymentClaim, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return claims, err
+	}
+	claim := PaymentClaim{ChainID: chainID, TxHash: txHash}
+	prefix := []byte(claim.GetDBPrefixKey(accountPublicKey))
+	dB := d.getState().dB
+	err = dB.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			var claim PaymentClaim
+			err := it.Item().Value(func(val []byte) error {
+				return DecodeToStruct(&claim, val)
+			})
+			if err != nil {
+				return err
+			}
+			claims = append(claims, claim)
+		}
+		return nil
+	})
+	return claims, err
+}
+
+// ClaimPayment saves claim unless its transfer is claimed by the payment of another message,
+// ErrPaymentClaimed is returned then
+func (d *ProtoDB) ClaimPayment(accountPublicKey string, claim *PaymentClaim) (err error) {
+	if claim == nil {
+		return ErrInvalidPaymentClaim
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	key, err := claim.GetDBFullKey(accountPublicKey)
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	return dB.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+		if err == nil {
+			var saved PaymentClaim
+			err = item.Value(func(val []byte) error {
+				return DecodeToStruct(&saved, val)
+			})
+			if err != nil {
+				return err
+			}
+			if saved.MessageID != claim.MessageID {
+				return ErrPaymentClaimed
+			}
+			return nil
+		}
+		return txn.Set([]byte(key), EncodeToBytes(claim))
+	})
+}
@@ -20,19 +20,45 @@ type Service interface {
 	Contacts(accountPublicKey string, offset, limit int) ([]Contact, error)
 	Messages(accountPublicKey, contactPubKey string, offset, limit int) ([]Message, error)
 	AddUpdateAccount(account *Account) error
+	ReplaceAccount(account *Account) error
 	AddUpdateContact(contact *Contact) (err error)
 	AccountExists(publicKey string) (bool, error)
 	LastMessage(accountPublicKey, contactPublicKey string) (Message, error)
 	DeleteAccounts([]Account) error
 	DeleteContacts(accountPublicKey string, contacts []Contact) (int64, error)
 	SaveOrUpdateMessage(accountPublicKey string, msg *Message) (err error)
+	SavePaymentStatus(accountPublicKey string, msg *Message) (err error)
+	PaymentClaims(accountPublicKey, chainID, txHash string) ([]PaymentClaim, error)
+	ClaimPayment(accountPublicKey string, claim *PaymentClaim) error
 	UnreadMessagesCount(accountPublicKey, contactPublicKey string) (count int64, err error)
 	MessagesCount(accountPublicKey, contactPublicKey string) (count int64, err error)
 	ContactsCount(addrPublicKey string) (int64, error)
 	MarkPrevMessagesAsRead(accountPublicKey, contactAddr string) (count int64, err error)
 	ViewRecord(key []byte, ptrStruct interface{}) (err error)
 	IsOpen() bool
-	VerifyPassword(passwd string) error
+	Settings() (Settings, error)
+	SaveSettings(settings *Settings) error
+	HDSeed() (HDSeed, error)
+	HDSeedByID(id string) (HDSeed, error)
+	SaveHDSeed(seed *HDSeed) error
+	Tokens(chainID string) ([]Token, error)
+	SaveToken(token *Token) error
+	DeleteToken(token *Token) error
+	Transactions(chainID, accountAddress string) ([]Transaction, error)
+	Transaction(chainID, accountAddress, hash string) (Transaction, error)
+	TransactionExists(tx *Transaction) (bool, error)
+	SaveTransaction(tx *Transaction) error
+	TransactionScanState(chainID, accountAddress string) (TransactionScanState, error)
+	SaveTransactionScanState(state *TransactionScanState) error
+	Networks() ([]Network, error)
+	Network(chainID string) (Network, error)
+	SaveNetwork(network *Network) error
+	DeleteNetwork(network *Network) error
+	APIKeys() ([]APIKey, error)
+	SaveAPIKey(apiKey *APIKey) error
+	DeleteAPIKey(rpc string) error
+	ChainList() (ChainList, error)
+	SaveChainList(chainList *ChainList) error
 }
 
 type State int
@@ -43,7 +69,6 @@ var (
 	ErrEncryptionKeyMismatch = badger.ErrEncryptionKeyMismatch
 	ErrPasswordMismatch      = errors.New("password mismatch")
 	ErrPasswordInvalid       = errors.New("password invalid")
-	ErrPasswordNotSet        = errors.New("password not set")
 )
 
 type protoDBState struct {
@@ -52,7 +77,6 @@ type protoDBState struct {
 }
 
 type ProtoDB struct {
-	password    string
 	EventBroker *pubsub.EventBroker
 	state       protoDBState
 	stateMutex  sync.RWMutex
@@ -64,6 +88,14 @@ func init() {
 	gob.Register(Account{})
 	gob.Register(Contact{})
 	gob.Register(Message{})
+	gob.Register(Settings{})
+	gob.Register(HDSeed{})
+	gob.Register(Token{})
+	gob.Register(Transaction{})
+	gob.Register(TransactionScanState{})
+	gob.Register(Network{})
+	gob.Register(APIKey{})
+	gob.Register(ChainList{})
 }
 
 //var GlobalProtoDB = &ProtoDB{}
@@ -99,7 +131,7 @@ func (d *ProtoDB) Open(options badger.Options) error {
 func (d *ProtoDB) Close() error {
 	state := d.getState()
 	if state.dB != nil {
-		_ = d.Close()
+		_ = state.dB.Close()
 	}
 	state.err = nil
 	state.dB = nil
@@ -214,7 +246,6 @@ func (d *ProtoDB) IsOpen() bool {
 }
 
 func (d *ProtoDB) OpenFromPassword(passwd string) error {
-	origPasswd := passwd
 	if d.IsOpen() {
 		return ErrDBAlreadyOpened
 	}
@@ -246,7 +277,6 @@ func (d *ProtoDB) OpenFromPassword(passwd string) error {
 	if err != nil {
 		return err
 	}
-	d.password = origPasswd
 	d.EventBroker.Fire(pubsub.Event{
 		Data:   pubsub.DatabaseOpenedEventData{},
 		Topic:  pubsub.DatabaseOpened,
@@ -263,19 +293,3 @@ func (d *ProtoDB) DatabaseExists() bool {
 	_, err = os.Stat(dbPath)
 	return err == nil
 }
-
-// VerifyPassword
-// returns nil if password is correct else may
-// return ErrPasswordMismatch or ErrPasswordInvalid or ErrPasswordNotSet
-func (d *ProtoDB) VerifyPassword(passwd string) error {
-	if strings.TrimSpace(passwd) == "" {
-		return ErrPasswordInvalid
-	}
-	if d.password == "" {
-		return ErrPasswordNotSet
-	}
-	if d.password != passwd {
-		return ErrPasswordMismatch
-	}
-	return nil
-}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"time"
+)
+
+type SafeTx = model.SafeTx
+
+// SafeTx returns the safe transaction of hash, ErrSafeTxNotFound if it isn't saved
+func (d *ProtoDB) SafeTx(hash string) (safeTx SafeTx, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return safeTx, err
+	}
+	safeTx.Hash = hash
+	key, err := safeTx.GetDBFullKey()
+	if err != nil {
+		return safeTx, err
+	}
+	err = d.ViewRecord([]byte(key), &safeTx)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return safeTx, ErrSafeTxNotFound
+	}
+	return safeTx, err
+}
+
+// SaveSafeTx saves safeTx merged with the saved one, the signatures of both are kept and the
+// hash of the executing transaction is kept once set. safeTx is updated with the merged fields.
+func (d *ProtoDB) SaveSafeTx(safeTx *SafeTx) (err error) {
+	if safeTx == nil {
+		return ErrInvalidSafeTx
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	key, err := safeTx.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	var changed bool
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		merged := *safeTx
+		merged.Signatures = make(map[string][]byte)
+		item, err := txn.Get([]byte(key))
+		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+		if err == nil {
+			// gob skips zero values, hence the saved record is decoded into a zero struct
+			var saved SafeTx
+			err = item.Value(func(val []byte) error {
+				return DecodeToStruct(&saved, val)
+			})
+			if err != nil {
+				return err
+			}
+			merged = saved
+			if merged.Signatures == nil {
+				merged.Signatures = make(map[string][]byte)
+			}
+		} else {
+			changed = true
+		}
+		for owner, signature := range safeTx.Signatures {
+			if _, ok := merged.Signatures[owner]; !ok {
+				merged.Signatures[owner] = signature
+				changed = true
+			}
+		}
+		if merged.TxHash == "" && safeTx.TxHash != "" {
+			merged.TxHash = safeTx.TxHash
+			changed = true
+		}
+		if merged.CreatedAt.IsZero() {
+			merged.CreatedAt = time.Now()
+		}
+		*safeTx = merged
+		if !changed {
+			return nil
+		}
+		return txn.Set([]byte(key), EncodeToBytes(&merged))
+	})
+	if err != nil || !changed {
+		return err
+	}
+	d.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.SafeTxChangedEventData{SafeTx: *safeTx},
+		Topic: pubsub.SafeTxChangedEventTopic,
+	})
+	return nil
+}
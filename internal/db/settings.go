@@ -0,0 +1,46 @@
+package db
+
+import (
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+)
+
+type Settings = model.Settings
+
+// Settings returns the saved settings or the default settings if they aren't saved yet
+func (d *ProtoDB) Settings() (settings Settings, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return model.NewSettings(), err
+	}
+	// gob skips zero values, hence decode into zero settings instead of defaults
+	err = d.ViewRecord([]byte(settings.GetDBFullKey()), &settings)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return model.NewSettings(), nil
+	}
+	return settings, err
+}
+
+func (d *ProtoDB) SaveSettings(settings *Settings) (err error) {
+	if settings == nil {
+		return errors.New("settings is nil")
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(settings.GetDBFullKey()), EncodeToBytes(settings))
+	})
+	if err != nil {
+		return err
+	}
+	d.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.SettingsChangedEventData{Settings: *settings},
+		Topic: pubsub.SettingsChangedEventTopic,
+	})
+	return nil
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"time"
+)
+
+type Token = model.Token
+
+// Tokens returns the tokens added by the user on chainID, chainID is in decimal
+func (d *ProtoDB) Tokens(chainID string) (tokens []Token, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return tokens, err
+	}
+	token := Token{ChainID: chainID}
+	keys, err := d.prefixScan(token.GetDBPrefixKey(), KeySeparator, 2)
+	if err != nil {
+		return tokens, err
+	}
+	for _, k := range keys {
+		var token Token
+		err = d.ViewRecord([]byte(k), &token)
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// SaveToken adds or replaces the token
+func (d *ProtoDB) SaveToken(token *Token) (err error) {
+	if token == nil || token.Symbol == "" {
+		return ErrInvalidToken
+	}
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	fullKey, err := token.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(fullKey), EncodeToBytes(token))
+	})
+	if err != nil {
+		return err
+	}
+	d.fireTokensChanged(token.ChainID)
+	return nil
+}
+
+// DeleteToken deletes the token, it's a no-op if the token doesn't exist
+func (d *ProtoDB) DeleteToken(token *Token) (err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	fullKey, err := token.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(fullKey))
+	})
+	if err != nil {
+		return err
+	}
+	d.fireTokensChanged(token.ChainID)
+	return nil
+}
+
+func (d *ProtoDB) fireTokensChanged(chainID string) {
+	d.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.TokensChangedEventData{ChainID: chainID},
+		Topic: pubsub.TokensChangedEventTopic,
+	})
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"errors"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"sort"
+	"time"
+)
+
+type Transaction = model.Transaction
+type TransactionScanState = model.TransactionScanState
+
+// Transactions returns the transactions of accountAddress on chainID, latest first
+func (d *ProtoDB) Transactions(chainID, accountAddress string) (txs []Transaction, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return txs, err
+	}
+	tx := Transaction{ChainID: chainID, AccountAddress: accountAddress}
+	keys, err := d.prefixScan(tx.GetDBPrefixKey(), KeySeparator, 3)
+	if err != nil {
+		return txs, err
+	}
+	for _, k := range keys {
+		var tx Transaction
+		err = d.ViewRecord([]byte(k), &tx)
+		if err != nil {
+			return txs, err
+		}
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].CreatedAt.After(txs[j].CreatedAt)
+	})
+	return txs, nil
+}
+
+// Transaction returns the transaction or ErrTransactionNotFound
+func (d *ProtoDB) Transaction(chainID, accountAddress, hash string) (tx Transaction, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return tx, err
+	}
+	tx = Transaction{ChainID: chainID, AccountAddress: accountAddress, Hash: hash}
+	fullKey, err := tx.GetDBFullKey()
+	if err != nil {
+		return tx, err
+	}
+	err = d.ViewRecord([]byte(fullKey), &tx)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return tx, ErrTransactionNotFound
+	}
+	return tx, err
+}
+
+// TransactionExists reports whether the transaction of the key of tx is saved
+func (d *ProtoDB) TransactionExists(tx *Transaction) (exists bool, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return false, err
+	}
+	fullKey, err := tx.GetDBFullKey()
+	if err != nil {
+		return false, err
+	}
+	err = d.getState().dB.View(func(txn *badger.Txn) error {
+		_, err := txn.Get([]byte(fullKey))
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// SaveTransaction adds or replaces the transaction
+func (d *ProtoDB) SaveTransaction(tx *Transaction) (err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	fullKey, err := tx.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	if tx.CreatedAt.IsZero() {
+		tx.CreatedAt = time.Now()
+	}
+	dB := d.getState().dB
+	err = dB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(fullKey), EncodeToBytes(tx))
+	})
+	if err != nil {
+		return err
+	}
+	d.EventBroker.Fire(pubsub.Event{
+		Data: pubsub.TransactionsChangedEventData{
+			ChainID:        tx.ChainID,
+			AccountAddress: tx.AccountAddress,
+		},
+		Topic: pubsub.TransactionsChangedEventTopic,
+	})
+	return nil
+}
+
+// TransactionScanState returns the scan state of accountAddress or ErrTransactionNotFound if
+// it isn't scanned yet
+func (d *ProtoDB) TransactionScanState(chainID, accountAddress string) (state TransactionScanState, err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return state, err
+	}
+	state = TransactionScanState{ChainID: chainID, AccountAddress: accountAddress}
+	fullKey, err := state.GetDBFullKey()
+	if err != nil {
+		return state, err
+	}
+	err = d.ViewRecord([]byte(fullKey), &state)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return state, ErrTransactionNotFound
+	}
+	return state, err
+}
+
+func (d *ProtoDB) SaveTransactionScanState(state *TransactionScanState) (err error) {
+	err = d.getErrorState()
+	if err != nil {
+		return err
+	}
+	fullKey, err := state.GetDBFullKey()
+	if err != nil {
+		return err
+	}
+	dB := d.getState().dB
+	return dB.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(fullKey), EncodeToBytes(state))
+	})
+}
@@ -8,12 +8,41 @@ const KeySeparator = "[]"
 const KeyPrefixAccounts = "accounts"
 const KeyPrefixMessages = "messages"
 const KeyPrefixContacts = "contacts"
+const KeyPrefixContactRequests = "contactrequests"
+const KeyPrefixBlockedContacts = "blockedcontacts"
+const KeyPrefixSettings = "settings"
+const KeyPrefixHDSeed = "hdseed"
+const KeyPrefixTokens = "tokens"
+const KeyPrefixTransactions = "transactions"
+const KeyPrefixTransactionScans = "txscans"
+const KeyPrefixNetworks = "networks"
+const KeyPrefixAPIKeys = "apikeys"
+const KeyPrefixChainList = "chainlist"
+const KeyPrefixSafeTxs = "safetxs"
+const KeyPrefixDevices = "devices"
+const KeyPrefixPaymentClaims = "paymentclaims"
 
 var ErrInvalidKey = errors.New("invalid key")
 var ErrInvalidAccount = errors.New("invalid account")
 var ErrInvalidMessage = errors.New("invalid message")
 var ErrInvalidContact = errors.New("invalid contact")
+var ErrContactNotFound = errors.New("contact not found")
 var ErrAccountDoesNotExist = errors.New("account does not exists")
+var ErrHDSeedNotFound = errors.New("hd seed not found")
+var ErrInvalidHDSeed = errors.New("invalid hd seed")
+var ErrInvalidToken = errors.New("invalid token")
+var ErrTransactionNotFound = errors.New("transaction not found")
+var ErrInvalidNetwork = errors.New("invalid network")
+var ErrNetworkNotFound = errors.New("network not found")
+var ErrInvalidAPIKey = errors.New("invalid api key")
+var ErrChainListNotFound = errors.New("chain list not found")
+var ErrInvalidChainList = errors.New("invalid chain list")
+var ErrInvalidSafeTx = errors.New("invalid safe transaction")
+var ErrSafeTxNotFound = errors.New("safe transaction not found")
+var ErrInvalidDevice = errors.New("invalid device")
+var ErrDeviceNotFound = errors.New("device not found")
+var ErrInvalidPaymentClaim = errors.New("invalid payment claim")
+var ErrPaymentClaimed = errors.New("transfer is claimed by the payment of another message")
 var ErrPasswdNotSet = errors.New("password is not set")
 var ErrPasswdAlreadyExist = errors.New("password already exist")
 var ErrPasswdCannotBeEmpty = errors.New("password cannot be empty")
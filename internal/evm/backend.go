@@ -0,0 +1,95 @@
+package evm
+
+import (
+	"context"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"math/big"
+)
+
+// Backend is the chain access needed for sending transactions, calling contracts and scanning
+// the history. Both ethclient.Client and go-ethereum's backends.SimulatedBackend satisfy it,
+// hence transactions are testable against a simulated chain.
+type Backend interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+}
+
+var _ Backend = (*ethclient.Client)(nil)
+var _ Backend = (*failoverBackend)(nil)
+
+// failoverBackend is the Backend of a chain which fails over to the next ranked endpoint
+type failoverBackend struct {
+	clients *RPCClients
+}
+
+func (b *failoverBackend) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return failover(b.clients, func(backend Backend) (*big.Int, error) {
+		return backend.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (b *failoverBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return failover(b.clients, func(backend Backend) (uint64, error) {
+		return backend.PendingNonceAt(ctx, account)
+	})
+}
+
+func (b *failoverBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return failover(b.clients, func(backend Backend) (*types.Header, error) {
+		return backend.HeaderByNumber(ctx, number)
+	})
+}
+
+func (b *failoverBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return failover(b.clients, func(backend Backend) (*big.Int, error) {
+		return backend.SuggestGasTipCap(ctx)
+	})
+}
+
+func (b *failoverBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return failover(b.clients, func(backend Backend) (uint64, error) {
+		return backend.EstimateGas(ctx, call)
+	})
+}
+
+func (b *failoverBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, err := failover(b.clients, func(backend Backend) (struct{}, error) {
+		return struct{}{}, backend.SendTransaction(ctx, tx)
+	})
+	return err
+}
+
+func (b *failoverBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return failover(b.clients, func(backend Backend) (*types.Receipt, error) {
+		return backend.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (b *failoverBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return failover(b.clients, func(backend Backend) ([]byte, error) {
+		return backend.CallContract(ctx, call, blockNumber)
+	})
+}
+
+func (b *failoverBackend) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return failover(b.clients, func(backend Backend) (*types.Block, error) {
+		return backend.BlockByNumber(ctx, number)
+	})
+}
+
+func (b *failoverBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return failover(b.clients, func(backend Backend) ([]types.Log, error) {
+		return backend.FilterLogs(ctx, query)
+	})
+}
@@ -1,29 +1,23 @@
 package evm
 
 import (
+	"bytes"
 	"embed"
 	"encoding/json"
+	"errors"
 	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/utils"
 	"math/big"
+	"os"
 	"sort"
 	"strings"
 	"sync"
-	"time"
 )
 
-type InitState int
+var ErrInvalidChainList = errors.New("invalid chain list")
 
-var currentState InitState
-var initStateMutex sync.RWMutex
-
-const (
-	InitStateIdle = InitState(iota)
-	InitStateStarted
-	InitStateFinished
-)
-
-var ()
+// chainStatusDeprecated is the status of the chains no longer maintained in the chain list
+const chainStatusDeprecated = "deprecated"
 
 //go:embed chains
 var chainsDir embed.FS
@@ -37,9 +31,6 @@ var iconsDownloadDirName = "iconsDownload"
 var iconsDir embed.FS
 var iconsDirName = "icons"
 
-var chainsSlice []Chain
-var chainsIDMap = utils.NewMap[string, Chain]()
-
 type Chain struct {
 	Name           string         `json:"name"`
 	Chain          string         `json:"chain"`
@@ -118,105 +109,245 @@ var iconDataMap = utils.NewMap[string, []IconData]()
 // string is hashed image name and []byte is encoded image data from icons folder
 var iconNameImageMap = utils.NewMap[string, []byte]()
 
+// IsDeprecated reports whether the chain list marks the chain deprecated
+func (c *Chain) IsDeprecated() bool {
+	return strings.EqualFold(c.Status, chainStatusDeprecated)
+}
+
+// IsFlagged reports whether the chain is deprecated or has red flags such as a reused chain id,
+// the user should be warned before using a flagged chain
+func (c *Chain) IsFlagged() bool {
+	return c.IsDeprecated() || len(c.RedFlags) > 0
+}
+
+// Registry holds the known chains, the embedded chains are loaded in background on start and an
+// updated chain list can be merged at runtime. Reads block until the embedded chains are loaded.
+type Registry struct {
+	chains []Chain
+	byID   map[string]int
+	mutex  sync.RWMutex
+	loaded chan struct{}
+}
+
+var registry = newRegistry()
+
+func newRegistry() *Registry {
+	return &Registry{byID: map[string]int{}, loaded: make(chan struct{})}
+}
+
 func init() {
-	setInitState(InitStateStarted)
 	go func() {
-		defer func() {
-			setInitState(InitStateFinished)
-		}()
-		files, err := chainsDir.ReadDir(chainsDirName)
+		defer close(registry.loaded)
+		loadIcons()
+		chains, err := loadEmbeddedChains()
 		if err != nil {
 			alog.Logger().Errorln(err)
 		}
-		for _, file := range files {
-			var val []byte
-			val, err = chainsDir.ReadFile(chainsDirName + "/" + file.Name())
-			if err != nil {
-				continue
+		registry.merge(chains)
+	}()
+}
+
+// DefaultRegistry returns the registry of the embedded chains and the merged chain lists
+func DefaultRegistry() *Registry {
+	return registry
+}
+
+// Chains returns the chains sorted by name
+func (r *Registry) Chains() []Chain {
+	<-r.loaded
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	chains := make([]Chain, len(r.chains))
+	copy(chains, r.chains)
+	return chains
+}
+
+// Chain returns the chain of chainID, chainID is in decimal
+func (r *Registry) Chain(chainID string) (Chain, bool) {
+	<-r.loaded
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	i, ok := r.byID[chainID]
+	if !ok {
+		return Chain{}, false
+	}
+	return r.chains[i], true
+}
+
+// MergeChainList merges the chains of a chain list bundle, which is either a json array of
+// chains such as chainlist's chains.json or a single chain such as eip155-1.json. A chain of the
+// bundle replaces the known chain of the same chain id.
+func (r *Registry) MergeChainList(data []byte) (added, updated []Chain, err error) {
+	chains, err := ParseChainList(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	<-r.loaded
+	added, updated = r.merge(chains)
+	return added, updated, nil
+}
+
+// LoadChainListFile merges the chain list bundle at path
+func (r *Registry) LoadChainListFile(path string) (added, updated []Chain, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r.MergeChainList(data)
+}
+
+func (r *Registry) merge(chains []Chain) (added, updated []Chain) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	// addedByID indexes added by chain id, a chain listed twice in chains is added once
+	addedByID := make(map[string]int)
+	for _, chain := range chains {
+		if chain.ChainID.Sign() <= 0 || chain.Name == "" {
+			continue
+		}
+		chain.IconData = chainIconData(chain.Icon)
+		chainID := chain.ChainID.String()
+		if i, ok := r.byID[chainID]; ok {
+			if len(chain.IconData) == 0 {
+				chain.IconData = r.chains[i].IconData
 			}
-			var chain Chain
-			err = json.Unmarshal(val, &chain)
-			if err != nil {
-				continue
+			r.chains[i] = chain
+			if j, ok := addedByID[chainID]; ok {
+				added[j] = chain
+			} else {
+				updated = append(updated, chain)
 			}
-			chainsSlice = append(chainsSlice, chain)
+			continue
+		}
+		r.chains = append(r.chains, chain)
+		r.byID[chainID] = len(r.chains) - 1
+		addedByID[chainID] = len(added)
+		added = append(added, chain)
+	}
+	sort.SliceStable(r.chains, func(i, j int) bool {
+		return strings.ToLower(r.chains[i].Name) < strings.ToLower(r.chains[j].Name)
+	})
+	for i, chain := range r.chains {
+		r.byID[chain.ChainID.String()] = i
+	}
+	return added, updated
+}
+
+// ParseChainList decodes a json array of chains or a single chain
+func ParseChainList(data []byte) ([]Chain, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, ErrInvalidChainList
+	}
+	var chains []Chain
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &chains); err != nil {
+			return nil, err
 		}
-		files, err = iconsDownloadDir.ReadDir(iconsDownloadDirName)
+		return chains, nil
+	}
+	var chain Chain
+	if err := json.Unmarshal(data, &chain); err != nil {
+		return nil, err
+	}
+	if chain.ChainID.Sign() <= 0 {
+		return nil, ErrInvalidChainList
+	}
+	return []Chain{chain}, nil
+}
+
+// loadEmbeddedChains decodes the embedded chain files, the invalid files are skipped
+func loadEmbeddedChains() ([]Chain, error) {
+	files, err := chainsDir.ReadDir(chainsDirName)
+	if err != nil {
+		return nil, err
+	}
+	chains := make([]Chain, 0, len(files))
+	for _, file := range files {
+		val, err := chainsDir.ReadFile(chainsDirName + "/" + file.Name())
 		if err != nil {
-			alog.Logger().Errorln(err)
+			continue
 		}
-		for _, file := range files {
-			var val []byte
-			val, err = iconsDownloadDir.ReadFile(iconsDownloadDirName + "/" + file.Name())
-			if err != nil {
-				continue
-			}
-			iconNameSlice := strings.Split(file.Name(), ".json")
-			iconName := strings.Join(iconNameSlice, "")
-			var iconDatas []IconData
-			err = json.Unmarshal(val, &iconDatas)
-			if err != nil {
-				continue
-			}
-			for _, iconData := range iconDatas {
-				if iconData.URL != "" {
-					imageNameSlice := strings.Split(iconData.URL, "/")
-					imageName := strings.Join(imageNameSlice[len(imageNameSlice)-1:], "")
-					iconNameImageMap.Set(imageName, []byte{})
-				}
-			}
-			iconDataMap.Set(iconName, iconDatas)
+		var chain Chain
+		if err = json.Unmarshal(val, &chain); err != nil {
+			continue
+		}
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
+
+// loadIcons fills iconDataMap and iconNameImageMap from the embedded icons
+func loadIcons() {
+	files, err := iconsDownloadDir.ReadDir(iconsDownloadDirName)
+	if err != nil {
+		alog.Logger().Errorln(err)
+	}
+	for _, file := range files {
+		var val []byte
+		val, err = iconsDownloadDir.ReadFile(iconsDownloadDirName + "/" + file.Name())
+		if err != nil {
+			continue
 		}
-		files, err = iconsDir.ReadDir(iconsDirName)
+		iconNameSlice := strings.Split(file.Name(), ".json")
+		iconName := strings.Join(iconNameSlice, "")
+		var iconDatas []IconData
+		err = json.Unmarshal(val, &iconDatas)
 		if err != nil {
-			alog.Logger().Errorln(err)
+			continue
 		}
-		for _, file := range files {
-			var val []byte
-			val, err = iconsDir.ReadFile(iconsDirName + "/" + file.Name())
-			if err != nil {
-				continue
-			}
-			if _, ok := iconNameImageMap.Get(file.Name()); ok {
-				iconNameImageMap.Set(file.Name(), val)
+		for _, iconData := range iconDatas {
+			if iconData.URL != "" {
+				iconNameImageMap.Set(iconImageName(iconData.URL), []byte{})
 			}
 		}
-		for i, chain := range chainsSlice {
-			iconDatas, ok := iconDataMap.Get(chainsSlice[i].Icon)
-			if ok {
-				for j, iconData := range iconDatas {
-					if iconData.URL != "" {
-						imageNameSlice := strings.Split(iconData.URL, "/")
-						imageName := strings.Join(imageNameSlice[len(imageNameSlice)-1:], "")
-						if val, ok := iconNameImageMap.Get(imageName); ok {
-							iconDatas[j].Data = val
-						}
-					}
-				}
-				chainsSlice[i].IconData = iconDatas
+		iconDataMap.Set(iconName, iconDatas)
+	}
+	files, err = iconsDir.ReadDir(iconsDirName)
+	if err != nil {
+		alog.Logger().Errorln(err)
+	}
+	for _, file := range files {
+		var val []byte
+		val, err = iconsDir.ReadFile(iconsDirName + "/" + file.Name())
+		if err != nil {
+			continue
+		}
+		if _, ok := iconNameImageMap.Get(file.Name()); ok {
+			iconNameImageMap.Set(file.Name(), val)
+		}
+	}
+}
+
+// chainIconData returns the embedded icons of the chain icon name with their image data
+func chainIconData(icon string) []IconData {
+	iconDatas, ok := iconDataMap.Get(icon)
+	if !ok {
+		return nil
+	}
+	result := make([]IconData, len(iconDatas))
+	for i, iconData := range iconDatas {
+		result[i] = iconData
+		if iconData.URL != "" {
+			if val, ok := iconNameImageMap.Get(iconImageName(iconData.URL)); ok {
+				result[i].Data = val
 			}
-			chainsIDMap.Set(chainsSlice[i].ChainID.String(), chain)
 		}
-		sort.Slice(chainsSlice, func(i, j int) bool {
-			return strings.ToLower(chainsSlice[i].Name) < strings.ToLower(chainsSlice[j].Name)
-		})
-	}()
+	}
+	return result
 }
 
-func getCurrentState() InitState {
-	initStateMutex.RLock()
-	defer initStateMutex.RUnlock()
-	return currentState
+func iconImageName(url string) string {
+	imageNameSlice := strings.Split(url, "/")
+	return strings.Join(imageNameSlice[len(imageNameSlice)-1:], "")
 }
+
+// ChainsSlice returns the chains of the default registry sorted by name
 func ChainsSlice() []Chain {
-	for getCurrentState() < InitStateFinished {
-		time.Sleep(0)
-	}
-	return chainsSlice
+	return registry.Chains()
 }
 
-func setInitState(state InitState) {
-	initStateMutex.Lock()
-	currentState = state
-	initStateMutex.Unlock()
+// ChainByID returns the chain of chainID from the default registry, chainID is in decimal
+func ChainByID(chainID string) (Chain, bool) {
+	return registry.Chain(chainID)
 }
@@ -0,0 +1,95 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const paymentURIScheme = "ethereum:"
+
+var ErrInvalidPaymentURI = errors.New("invalid ethereum payment uri")
+
+// PaymentURI is an EIP-681 uri requesting a native or ERC-20 transfer to To, Token is nil for
+// the native currency and Amount is in the smallest unit of the currency
+type PaymentURI struct {
+	ChainID *big.Int
+	To      common.Address
+	Token   *common.Address
+	Amount  *big.Int
+}
+
+// String returns the uri such as ethereum:0x…@1?value=1e18 or, for a token,
+// ethereum:0xToken@1/transfer?address=0x…&uint256=1000000
+func (u PaymentURI) String() string {
+	if u.Token != nil {
+		return fmt.Sprintf("%s%s@%s/transfer?address=%s&uint256=%s",
+			paymentURIScheme, u.Token.Hex(), u.ChainID, u.To.Hex(), u.Amount)
+	}
+	return fmt.Sprintf("%s%s@%s?value=%s", paymentURIScheme, u.To.Hex(), u.ChainID, u.Amount)
+}
+
+// ParsePaymentURI parses an EIP-681 uri of a native transfer or of an ERC-20 transfer call, the
+// chain id defaults to 1 and amounts may use the scientific notation such as 2.014e18. ENS
+// names as target aren't supported.
+func ParsePaymentURI(uri string) (PaymentURI, error) {
+	payment := PaymentURI{ChainID: big.NewInt(1), Amount: new(big.Int)}
+	rest, ok := strings.CutPrefix(strings.TrimSpace(uri), paymentURIScheme)
+	if !ok {
+		return payment, ErrInvalidPaymentURI
+	}
+	rest = strings.TrimPrefix(rest, "pay-")
+	rest, rawQuery, _ := strings.Cut(rest, "?")
+	rest, function, _ := strings.Cut(rest, "/")
+	target, chainID, hasChainID := strings.Cut(rest, "@")
+	if !common.IsHexAddress(target) {
+		return payment, ErrInvalidPaymentURI
+	}
+	if hasChainID {
+		if _, ok = payment.ChainID.SetString(chainID, 10); !ok || payment.ChainID.Sign() <= 0 {
+			return payment, ErrInvalidPaymentURI
+		}
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return payment, ErrInvalidPaymentURI
+	}
+	switch function {
+	case "":
+		payment.To = common.HexToAddress(target)
+		if value := query.Get("value"); value != "" {
+			payment.Amount, err = parsePaymentURINumber(value)
+		}
+	case "transfer":
+		token := common.HexToAddress(target)
+		payment.Token = &token
+		if !common.IsHexAddress(query.Get("address")) {
+			return payment, ErrInvalidPaymentURI
+		}
+		payment.To = common.HexToAddress(query.Get("address"))
+		payment.Amount, err = parsePaymentURINumber(query.Get("uint256"))
+	default:
+		return payment, ErrInvalidPaymentURI
+	}
+	if err != nil {
+		return payment, ErrInvalidPaymentURI
+	}
+	return payment, nil
+}
+
+// parsePaymentURINumber parses an integer which may use the scientific notation
+func parsePaymentURINumber(number string) (*big.Int, error) {
+	mantissa, exponent, hasExponent := strings.Cut(strings.ToLower(number), "e")
+	if !hasExponent {
+		exponent = "0"
+	}
+	exp, err := strconv.Atoi(exponent)
+	if err != nil || exp < 0 || exp > 77 {
+		return nil, ErrInvalidAmount
+	}
+	return ParseUnits(mantissa, exp)
+}
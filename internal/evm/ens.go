@@ -0,0 +1,179 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"strings"
+	"unicode"
+)
+
+const ensABIJSON = `[
+{"constant":true,"inputs":[{"name":"node","type":"bytes32"}],"name":"resolver","outputs":[{"name":"","type":"address"}],"type":"function"},
+{"constant":true,"inputs":[{"name":"node","type":"bytes32"}],"name":"addr","outputs":[{"name":"","type":"address"}],"type":"function"},
+{"constant":true,"inputs":[{"name":"node","type":"bytes32"}],"name":"name","outputs":[{"name":"","type":"string"}],"type":"function"}
+]`
+
+// ensABI holds the methods of the ens registry and of the public resolver used here
+var ensABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(ensABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+var (
+	ErrENSNotSupported = errors.New("chain doesn't support ens")
+	ErrENSNameNotFound = errors.New("ens name not found")
+	ErrInvalidENSName  = errors.New("invalid ens name")
+	ErrENSNameNotASCII = errors.New("only ascii ens names are supported")
+)
+
+// ENSRegistry returns the address of the ens registry of the chain
+func (c *Chain) ENSRegistry() (common.Address, bool) {
+	if !common.IsHexAddress(c.Ens.Registry) {
+		return common.Address{}, false
+	}
+	return common.HexToAddress(c.Ens.Registry), true
+}
+
+// IsENSName reports whether name looks like an ens name such as alice.eth
+func IsENSName(name string) bool {
+	name = strings.TrimSpace(name)
+	return strings.Contains(name, ".") && !common.IsHexAddress(name) && !strings.ContainsAny(name, " /:")
+}
+
+// NormalizeENSName lowercases name and checks its labels against the ascii rules of ENSIP-15,
+// a label has letters, digits, hyphens and leading underscores only and isn't an extension such
+// as xn--. Unicode names are rejected as their ENSIP-15 normalization isn't done.
+func NormalizeENSName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", ErrInvalidENSName
+	}
+	for _, label := range strings.Split(name, ".") {
+		if err := checkENSLabel(label); err != nil {
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+// checkENSLabel checks the lowercased label against the ascii rules of ENSIP-15
+func checkENSLabel(label string) error {
+	if label == "" || (len(label) >= 4 && label[2:4] == "--") {
+		return ErrInvalidENSName
+	}
+	leading := true
+	for _, r := range label {
+		switch {
+		case r > unicode.MaxASCII:
+			return ErrENSNameNotASCII
+		case r == '_' && leading:
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			leading = false
+		default:
+			return ErrInvalidENSName
+		}
+	}
+	return nil
+}
+
+// NameHash returns the EIP-137 namehash of the normalized name
+func NameHash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node.Bytes(), labelHash)
+	}
+	return node
+}
+
+// ResolveENSName returns the address name resolves to using the ens registry at registry
+func ResolveENSName(ctx context.Context, backend Backend, registry common.Address, name string) (common.Address, error) {
+	name, err := NormalizeENSName(name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	node := NameHash(name)
+	resolver, err := ensResolver(ctx, backend, registry, node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	out, err := callENS(ctx, backend, resolver, "addr", node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	address, ok := out[0].(common.Address)
+	if !ok || address == (common.Address{}) {
+		return common.Address{}, ErrENSNameNotFound
+	}
+	return address, nil
+}
+
+// LookupENSAddress returns the primary name of address from its reverse record, the name is
+// returned only if it resolves back to address
+func LookupENSAddress(ctx context.Context, backend Backend, registry common.Address, address common.Address) (string, error) {
+	reverseName := strings.ToLower(address.Hex()[2:]) + ".addr.reverse"
+	node := NameHash(reverseName)
+	resolver, err := ensResolver(ctx, backend, registry, node)
+	if err != nil {
+		return "", err
+	}
+	out, err := callENS(ctx, backend, resolver, "name", node)
+	if err != nil {
+		return "", err
+	}
+	name, ok := out[0].(string)
+	if !ok || name == "" {
+		return "", ErrENSNameNotFound
+	}
+	// a primary name which isn't normalized isn't shown
+	if normalized, err := NormalizeENSName(name); err != nil || normalized != name {
+		return "", ErrENSNameNotFound
+	}
+	// anyone can claim any name in their reverse record, hence the forward record is checked
+	resolved, err := ResolveENSName(ctx, backend, registry, name)
+	if err != nil {
+		return "", err
+	}
+	if resolved != address {
+		return "", ErrENSNameNotFound
+	}
+	return name, nil
+}
+
+func ensResolver(ctx context.Context, backend Backend, registry common.Address, node common.Hash) (common.Address, error) {
+	out, err := callENS(ctx, backend, registry, "resolver", node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	resolver, ok := out[0].(common.Address)
+	if !ok || resolver == (common.Address{}) {
+		return common.Address{}, ErrENSNameNotFound
+	}
+	return resolver, nil
+}
+
+func callENS(ctx context.Context, backend Backend, contract common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := ensABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := backend.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrENSNameNotFound
+	}
+	return ensABI.Unpack(method, out)
+}
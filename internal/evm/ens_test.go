@@ -0,0 +1,50 @@
+package evm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeENSName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		err  error
+	}{
+		{name: "alice.eth", want: "alice.eth"},
+		{name: " Alice.ETH ", want: "alice.eth"},
+		{name: "sub-1.alice.eth", want: "sub-1.alice.eth"},
+		{name: "_dmarc.alice.eth", want: "_dmarc.alice.eth"},
+		{name: "a_b.eth", err: ErrInvalidENSName},
+		{name: "xn--80ak6aa92e.eth", err: ErrInvalidENSName},
+		{name: "alice..eth", err: ErrInvalidENSName},
+		{name: "alice!.eth", err: ErrInvalidENSName},
+		{name: "", err: ErrInvalidENSName},
+		{name: "аlice.eth", err: ErrENSNameNotASCII},
+		{name: "💩.eth", err: ErrENSNameNotASCII},
+	}
+	for _, test := range tests {
+		got, err := NormalizeENSName(test.name)
+		if !errors.Is(err, test.err) {
+			t.Errorf("NormalizeENSName(%q) error = %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("NormalizeENSName(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestNameHash(t *testing.T) {
+	// the namehashes of EIP-137
+	tests := map[string]string{
+		"":        "0x0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	}
+	for name, want := range tests {
+		if got := NameHash(name).Hex(); got != want {
+			t.Errorf("NameHash(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"strings"
+)
+
+const erc20ABIJSON = `[
+{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"type":"function"},
+{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},
+{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"},
+{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"}
+]`
+
+var erc20ABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+var (
+	ErrNotERC20                 = errors.New("contract doesn't implement erc20")
+	ErrInsufficientTokenBalance = errors.New("insufficient token balance")
+)
+
+// Token is an ERC-20 token of a chain, Custom is true if the user added it
+type Token struct {
+	ChainID  *big.Int
+	Address  common.Address
+	Name     string
+	Symbol   string
+	Decimals int
+	Custom   bool
+}
+
+// builtinTokens are the well known tokens of the popular chains by chain id
+var builtinTokens = map[uint64][]Token{
+	// Ethereum Mainnet
+	1: {
+		{Address: common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"), Name: "Tether USD", Symbol: "USDT", Decimals: 6},
+		{Address: common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"), Name: "USD Coin", Symbol: "USDC", Decimals: 6},
+		{Address: common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F"), Name: "Dai Stablecoin", Symbol: "DAI", Decimals: 18},
+		{Address: common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"), Name: "Wrapped Ether", Symbol: "WETH", Decimals: 18},
+	},
+	// BNB Smart Chain Mainnet
+	56: {
+		{Address: common.HexToAddress("0x55d398326f99059fF775485246999027B3197955"), Name: "Tether USD", Symbol: "USDT", Decimals: 18},
+		{Address: common.HexToAddress("0xe9e7CEA3DedcA5984780Bafc599bD69ADd087D56"), Name: "BUSD Token", Symbol: "BUSD", Decimals: 18},
+	},
+	// Polygon Mainnet
+	137: {
+		{Address: common.HexToAddress("0xc2132D05D31c914a87C6611C10748AEb04B58e8F"), Name: "Tether USD", Symbol: "USDT", Decimals: 6},
+		{Address: common.HexToAddress("0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174"), Name: "USD Coin (PoS)", Symbol: "USDC", Decimals: 6},
+		{Address: common.HexToAddress("0x8f3Cf7ad23Cd3CaDbD9735AFf958023239c6A063"), Name: "Dai Stablecoin (PoS)", Symbol: "DAI", Decimals: 18},
+	},
+}
+
+// BuiltinTokens returns the built-in tokens of chainID
+func BuiltinTokens(chainID *big.Int) []Token {
+	if !chainID.IsUint64() {
+		return nil
+	}
+	tokens := make([]Token, len(builtinTokens[chainID.Uint64()]))
+	for i, token := range builtinTokens[chainID.Uint64()] {
+		token.ChainID = chainID
+		tokens[i] = token
+	}
+	return tokens
+}
+
+// FetchToken reads symbol, decimals and name of the token contract at address
+func FetchToken(ctx context.Context, backend Backend, chainID *big.Int, address common.Address) (token Token, err error) {
+	token = Token{ChainID: chainID, Address: address, Custom: true}
+	out, err := callERC20(ctx, backend, address, "decimals")
+	if err != nil {
+		return token, err
+	}
+	decimals, ok := out[0].(uint8)
+	if !ok {
+		return token, ErrNotERC20
+	}
+	token.Decimals = int(decimals)
+	if token.Symbol, err = callERC20String(ctx, backend, address, "symbol"); err != nil {
+		return token, err
+	}
+	// name is optional in erc20
+	token.Name, _ = callERC20String(ctx, backend, address, "name")
+	return token, nil
+}
+
+// TokenBalance returns the token balance of owner in the token's smallest unit
+func TokenBalance(ctx context.Context, backend Backend, token common.Address, owner common.Address) (*big.Int, error) {
+	out, err := callERC20(ctx, backend, token, "balanceOf", owner)
+	if err != nil {
+		return nil, err
+	}
+	balance, ok := out[0].(*big.Int)
+	if !ok {
+		return nil, ErrNotERC20
+	}
+	return balance, nil
+}
+
+// PackTransfer returns the call data of transfer(to, amount)
+func PackTransfer(to common.Address, amount *big.Int) ([]byte, error) {
+	return erc20ABI.Pack("transfer", to, amount)
+}
+
+// PrepareTokenTransfer prepares the transfer of amount of token from to to, the transaction
+// itself carries no native value
+func (t *Transactor) PrepareTokenTransfer(ctx context.Context, from common.Address, token Token, to common.Address, amount *big.Int) (*PreparedTx, error) {
+	balance, err := TokenBalance(ctx, t.backend, token.Address, from)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, ErrInsufficientTokenBalance
+	}
+	data, err := PackTransfer(to, amount)
+	if err != nil {
+		return nil, err
+	}
+	return t.Prepare(ctx, from, token.Address, new(big.Int), data)
+}
+
+func callERC20(ctx context.Context, backend Backend, token common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := erc20ABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := backend.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	// calling an account without code succeeds with empty output
+	if len(out) == 0 {
+		return nil, ErrNotERC20
+	}
+	return erc20ABI.Unpack(method, out)
+}
+
+// callERC20String calls a string method, a few old tokens such as MKR return bytes32 instead
+func callERC20String(ctx context.Context, backend Backend, token common.Address, method string) (string, error) {
+	data, err := erc20ABI.Pack(method)
+	if err != nil {
+		return "", err
+	}
+	out, err := backend.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(out) == 32 {
+		return string(bytes.TrimRight(out, "\x00")), nil
+	}
+	values, err := erc20ABI.Unpack(method, out)
+	if err != nil {
+		return "", ErrNotERC20
+	}
+	value, ok := values[0].(string)
+	if !ok {
+		return "", ErrNotERC20
+	}
+	return value, nil
+}
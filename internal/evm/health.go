@@ -0,0 +1,236 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	probeInterval = 30 * time.Second
+	probeTimeout  = 10 * time.Second
+	// maxBlockLag is the number of blocks an endpoint may be behind the highest block seen on
+	// the chain before it's ranked below the endpoints in sync
+	maxBlockLag = 5
+	// rpcCodeLimitExceeded is returned by the endpoints which rate limit requests
+	rpcCodeLimitExceeded = -32005
+)
+
+var ErrChainIDMismatch = errors.New("rpc serves a different chain id")
+
+// Health is the result of the last probe of an endpoint
+type Health struct {
+	CheckedAt   time.Time
+	Latency     time.Duration
+	BlockNumber uint64
+	Err         error
+}
+
+// IsHealthy reports whether the endpoint was probed and served the chain without error
+func (h Health) IsHealthy() bool {
+	return !h.CheckedAt.IsZero() && h.Err == nil
+}
+
+func (c *RPCClient) Health() Health {
+	c.healthMutex.RLock()
+	defer c.healthMutex.RUnlock()
+	return c.health
+}
+
+func (c *RPCClient) setHealth(health Health) {
+	c.healthMutex.Lock()
+	defer c.healthMutex.Unlock()
+	c.health = health
+}
+
+// markUnhealthy records err of a failed call so that the endpoint is ranked last until the
+// next probe
+func (c *RPCClient) markUnhealthy(err error) {
+	c.healthMutex.Lock()
+	defer c.healthMutex.Unlock()
+	c.health.CheckedAt = time.Now()
+	c.health.Err = err
+}
+
+// Probe connects the endpoint if it isn't connected and measures its latency and block height.
+// The endpoint is disconnected if it doesn't serve the chain id of its chain.
+func (c *RPCClient) Probe(ctx context.Context) Health {
+	health := Health{CheckedAt: time.Now()}
+	defer func() { c.setHealth(health) }()
+	if !c.IsConnected() {
+		if health.Err = c.Connect(); health.Err != nil && !errors.Is(health.Err, ErrAlreadyConnected) {
+			return health
+		}
+		health.Err = nil
+	}
+	client := c.getClient()
+	if client == nil {
+		health.Err = ErrNotConnected
+		return health
+	}
+	start := time.Now()
+	chainID, err := client.ChainID(ctx)
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Err = err
+		return health
+	}
+	if chainID.Cmp(&c.RPCClients.Chain.ChainID) != 0 {
+		health.Err = ErrChainIDMismatch
+		_ = c.Disconnect()
+		return health
+	}
+	health.BlockNumber, health.Err = client.BlockNumber(ctx)
+	return health
+}
+
+// ProbeAll probes the endpoints of chain concurrently, endpoints requiring an api key are
+// skipped until it's set
+func (c *RPCClients) ProbeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, client := range c.Clients() {
+		if client.KeyRequired() && client.APIKey() == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(client *RPCClient) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+			defer cancel()
+			client.Probe(ctx)
+		}(client)
+	}
+	wg.Wait()
+}
+
+// StartProber probes the endpoints of chain now and every probeInterval until StopProber,
+// onProbe is called after each round of probes
+func (c *RPCClients) StartProber(onProbe func()) {
+	c.proberMutex.Lock()
+	defer c.proberMutex.Unlock()
+	if c.proberCancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.proberCancel = cancel
+	go func() {
+		ticker := time.NewTicker(probeInterval)
+		defer ticker.Stop()
+		for {
+			c.ProbeAll(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if onProbe != nil {
+				onProbe()
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (c *RPCClients) StopProber() {
+	c.proberMutex.Lock()
+	defer c.proberMutex.Unlock()
+	if c.proberCancel != nil {
+		c.proberCancel()
+		c.proberCancel = nil
+	}
+}
+
+func (c *RPCClients) IsProbing() bool {
+	c.proberMutex.Lock()
+	defer c.proberMutex.Unlock()
+	return c.proberCancel != nil
+}
+
+// Ranked returns the connected endpoints of chain, best first. Healthy endpoints in sync with the
+// highest block come first, then the lagging ones, then the ones not probed yet and the failed
+// ones last. Endpoints of the same rank are ordered by latency.
+func (c *RPCClients) Ranked() []*RPCClient {
+	all := c.Clients()
+	clients := make([]*RPCClient, 0, len(all))
+	healths := make(map[*RPCClient]Health, len(all))
+	var highestBlock uint64
+	for _, client := range all {
+		if !client.IsConnected() {
+			continue
+		}
+		health := client.Health()
+		if health.IsHealthy() && health.BlockNumber > highestBlock {
+			highestBlock = health.BlockNumber
+		}
+		healths[client] = health
+		clients = append(clients, client)
+	}
+	rank := func(health Health) int {
+		switch {
+		case health.IsHealthy() && health.BlockNumber+maxBlockLag >= highestBlock:
+			return 0
+		case health.IsHealthy():
+			return 1
+		case health.CheckedAt.IsZero():
+			return 2
+		}
+		return 3
+	}
+	sort.SliceStable(clients, func(i, j int) bool {
+		healthI, healthJ := healths[clients[i]], healths[clients[j]]
+		rankI, rankJ := rank(healthI), rank(healthJ)
+		if rankI != rankJ {
+			return rankI < rankJ
+		}
+		return healthI.Latency < healthJ.Latency
+	})
+	return clients
+}
+
+// Best returns a Backend which calls the best ranked endpoint of chain, a call which fails
+// because of the endpoint is retried on the next ranked endpoint
+func (c *RPCClients) Best() (Backend, error) {
+	if !c.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return &failoverBackend{clients: c}, nil
+}
+
+// failover calls call with the ranked endpoints of c until an endpoint succeeds or fails with an
+// error which isn't the endpoint's fault
+func failover[T any](c *RPCClients, call func(backend Backend) (T, error)) (result T, err error) {
+	err = ErrNotConnected
+	for _, client := range c.Ranked() {
+		backend, backendErr := client.Backend()
+		if backendErr != nil {
+			continue
+		}
+		result, err = call(backend)
+		if err == nil || !isEndpointError(err) {
+			return result, err
+		}
+		client.markUnhealthy(err)
+	}
+	return result, err
+}
+
+// isEndpointError reports whether err is caused by the endpoint, such as a network error or
+// rate limiting, rather than by the request such as a reverted call, an unknown transaction or
+// a block which can't be decoded
+func isEndpointError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ethereum.NotFound) || isBlockDecodeError(err) {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.ErrorCode() == rpcCodeLimitExceeded
+	}
+	return true
+}
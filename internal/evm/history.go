@@ -0,0 +1,170 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mearaj/protonet/alog"
+	"math/big"
+	"strings"
+)
+
+// MaxNativeScanBlocks is the maximum number of blocks scanned by a call of ScanNativeTransfers,
+// each block is fetched with all its transactions
+const MaxNativeScanBlocks = 64
+
+var ErrScanRangeTooLarge = fmt.Errorf("more than %d blocks to scan", MaxNativeScanBlocks)
+
+// transferEventTopic is the topic of the ERC-20 Transfer(address,address,uint256) event
+var transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// Transfer is a native or token transfer found on chain, Token is nil for the native currency.
+// LogIndex is the index in the block of the Transfer log of a token transfer.
+type Transfer struct {
+	Hash        common.Hash
+	BlockNumber uint64
+	Time        uint64
+	From        common.Address
+	To          common.Address
+	Value       *big.Int
+	Token       *common.Address
+	LogIndex    uint
+}
+
+// ExplorerTxURL returns the url of the transaction on the first explorer of the chain, it's
+// empty if the chain has no explorer
+func (c *Chain) ExplorerTxURL(hash string) string {
+	if len(c.Explorers) == 0 {
+		return ""
+	}
+	return strings.TrimRight(c.Explorers[0].URL, "/") + "/tx/" + hash
+}
+
+// UnpackTransfer decodes the recipient and amount of transfer(to, amount) call data
+func UnpackTransfer(data []byte) (to common.Address, amount *big.Int, ok bool) {
+	method := erc20ABI.Methods["transfer"]
+	if len(data) < 4 || !bytes.Equal(data[:4], method.ID) {
+		return to, nil, false
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil || len(args) != 2 {
+		return to, nil, false
+	}
+	to, ok = args[0].(common.Address)
+	if !ok {
+		return to, nil, false
+	}
+	amount, ok = args[1].(*big.Int)
+	return to, amount, ok
+}
+
+// ScanNativeTransfers returns the transactions of blocks fromBlock to toBlock, both inclusive,
+// which send native currency to address, at most MaxNativeScanBlocks are scanned. A block which
+// can't be decoded, such as one with a transaction type go-ethereum doesn't know, is logged and
+// skipped so that the scan advances, any other error fails the scan so that it's retried.
+func ScanNativeTransfers(ctx context.Context, backend Backend, chainID *big.Int, address common.Address, fromBlock, toBlock uint64) ([]Transfer, error) {
+	if toBlock >= fromBlock && toBlock-fromBlock >= MaxNativeScanBlocks {
+		return nil, ErrScanRangeTooLarge
+	}
+	signer := types.LatestSignerForChainID(chainID)
+	transfers := make([]Transfer, 0)
+	for number := fromBlock; number <= toBlock; number++ {
+		block, err := backend.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil && isBlockDecodeError(err) {
+			alog.Logger().Errorf("skipped block %d of chain %s, %v", number, chainID, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range block.Transactions() {
+			if tx.To() == nil || *tx.To() != address || tx.Value().Sign() == 0 {
+				continue
+			}
+			from, err := types.Sender(signer, tx)
+			if err != nil {
+				continue
+			}
+			transfers = append(transfers, Transfer{
+				Hash:        tx.Hash(),
+				BlockNumber: number,
+				Time:        block.Time(),
+				From:        from,
+				To:          address,
+				Value:       tx.Value(),
+			})
+		}
+	}
+	return transfers, nil
+}
+
+// isBlockDecodeError reports whether err is returned by decoding a block served by the endpoint
+// rather than by fetching it. go-ethereum returns plain errors for the missing fields of a
+// transaction, hence they're matched by their text.
+func isBlockDecodeError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.Is(err, types.ErrTxTypeNotSupported) || errors.As(err, &syntaxErr) ||
+		errors.As(err, &typeErr) || strings.Contains(err.Error(), "missing required field")
+}
+
+// ScanTokenTransfers returns the Transfer events of tokens to address in blocks fromBlock to
+// toBlock, both inclusive
+func ScanTokenTransfers(ctx context.Context, backend Backend, address common.Address, tokens []common.Address, fromBlock, toBlock uint64) ([]Transfer, error) {
+	transfers := make([]Transfer, 0)
+	if len(tokens) == 0 {
+		return transfers, nil
+	}
+	logs, err := backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: tokens,
+		Topics:    [][]common.Hash{{transferEventTopic}, nil, {common.BytesToHash(address.Bytes())}},
+	})
+	if err != nil {
+		return transfers, err
+	}
+	blockTimes := make(map[uint64]uint64)
+	for _, log := range logs {
+		transfer, ok := transferFromLog(log)
+		if !ok {
+			continue
+		}
+		blockTime, ok := blockTimes[log.BlockNumber]
+		if !ok {
+			header, err := backend.HeaderByNumber(ctx, new(big.Int).SetUint64(log.BlockNumber))
+			if err != nil {
+				return transfers, err
+			}
+			blockTime = header.Time
+			blockTimes[log.BlockNumber] = blockTime
+		}
+		transfer.Time = blockTime
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+// transferFromLog decodes an ERC-20 Transfer event, Time of the transfer isn't set
+func transferFromLog(log types.Log) (transfer Transfer, ok bool) {
+	// ERC-721 Transfer has the same signature but an indexed token id instead of value
+	if len(log.Topics) != 3 || log.Topics[0] != transferEventTopic || len(log.Data) != 32 || log.Removed {
+		return transfer, false
+	}
+	token := log.Address
+	return Transfer{
+		Hash:        log.TxHash,
+		BlockNumber: log.BlockNumber,
+		From:        common.BytesToAddress(log.Topics[1].Bytes()),
+		To:          common.BytesToAddress(log.Topics[2].Bytes()),
+		Value:       new(big.Int).SetBytes(log.Data),
+		Token:       &token,
+		LogIndex:    log.Index,
+	}, true
+}
@@ -0,0 +1,62 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"sync"
+)
+
+// NonceManager hands out consecutive nonces per chain and address, so that transactions sent
+// before the previous ones are mined don't reuse a nonce
+type NonceManager struct {
+	nonces map[string]uint64
+	mutex  sync.Mutex
+}
+
+func NewNonceManager() *NonceManager {
+	return &NonceManager{nonces: map[string]uint64{}}
+}
+
+func nonceKey(chainID *big.Int, address common.Address) string {
+	return fmt.Sprintf("%s%s", chainID.String(), address.Hex())
+}
+
+// Acquire returns the next nonce of address, the larger of the pending nonce of backend and the
+// nonce after the last one handed out
+func (n *NonceManager) Acquire(ctx context.Context, backend Backend, chainID *big.Int, address common.Address) (uint64, error) {
+	pendingNonce, err := backend.PendingNonceAt(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	key := nonceKey(chainID, address)
+	nonce := pendingNonce
+	if next, ok := n.nonces[key]; ok && next > nonce {
+		nonce = next
+	}
+	n.nonces[key] = nonce + 1
+	return nonce, nil
+}
+
+// Release gives back nonce of a transaction which failed to be sent. If it isn't the last acquired
+// nonce, the nonces are forgotten and the pending nonce of backend fills the gap next time
+func (n *NonceManager) Release(chainID *big.Int, address common.Address, nonce uint64) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	key := nonceKey(chainID, address)
+	if next, ok := n.nonces[key]; ok && next == nonce+1 {
+		n.nonces[key] = nonce
+		return
+	}
+	delete(n.nonces, key)
+}
+
+// Reset forgets the nonces of address, the pending nonce of backend is used next time
+func (n *NonceManager) Reset(chainID *big.Int, address common.Address) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	delete(n.nonces, nonceKey(chainID, address))
+}
@@ -0,0 +1,86 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"math/big"
+)
+
+var (
+	ErrPaymentFailed   = errors.New("payment transaction failed")
+	ErrPaymentMismatch = errors.New("transaction doesn't match the payment")
+	ErrPaymentClaimed  = errors.New("transfer of the payment is claimed by another payment")
+)
+
+// Payment is a transfer claimed by its sender, Token is nil for the native currency. Claimed
+// are the indexes of the Transfer logs of the transaction claimed by other payments.
+type Payment struct {
+	ChainID *big.Int
+	Hash    common.Hash
+	From    common.Address
+	To      common.Address
+	Token   *common.Address
+	Amount  *big.Int
+	Claimed []uint
+}
+
+// VerifyPayment checks that the transaction of payment is mined successfully and transfers the
+// amount from the sender to the recipient, logIndex is the index of the Transfer log matched by
+// a token payment. It returns ethereum.NotFound while the transaction is pending,
+// ErrPaymentFailed if it's reverted, ErrPaymentMismatch if it's another transfer and
+// ErrPaymentClaimed if the matching Transfer logs are all claimed.
+func VerifyPayment(ctx context.Context, backend Backend, payment Payment) (logIndex uint, err error) {
+	receipt, err := backend.TransactionReceipt(ctx, payment.Hash)
+	if err != nil {
+		return 0, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return 0, ErrPaymentFailed
+	}
+	if payment.Token != nil {
+		err = ErrPaymentMismatch
+		for _, log := range receipt.Logs {
+			transfer, ok := transferFromLog(*log)
+			if !ok || *transfer.Token != *payment.Token || transfer.From != payment.From ||
+				transfer.To != payment.To || transfer.Value.Cmp(payment.Amount) != 0 {
+				continue
+			}
+			if isClaimed(payment.Claimed, log.Index) {
+				err = ErrPaymentClaimed
+				continue
+			}
+			return log.Index, nil
+		}
+		return 0, err
+	}
+	// the backend has no transaction lookup by hash, the receipt locates it in its block
+	block, err := backend.BlockByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	txs := block.Transactions()
+	if int(receipt.TransactionIndex) >= len(txs) {
+		return 0, ErrPaymentMismatch
+	}
+	tx := txs[receipt.TransactionIndex]
+	if tx.Hash() != payment.Hash || tx.To() == nil || *tx.To() != payment.To ||
+		tx.Value().Cmp(payment.Amount) != 0 {
+		return 0, ErrPaymentMismatch
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(payment.ChainID), tx)
+	if err != nil || from != payment.From {
+		return 0, ErrPaymentMismatch
+	}
+	return 0, nil
+}
+
+func isClaimed(claimed []uint, logIndex uint) bool {
+	for _, index := range claimed {
+		if index == logIndex {
+			return true
+		}
+	}
+	return false
+}
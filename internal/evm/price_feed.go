@@ -0,0 +1,212 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const aggregatorV3ABIJSON = `[
+{"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"description","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+{"inputs":[],"name":"latestRoundData","outputs":[{"name":"roundId","type":"uint80"},{"name":"answer","type":"int256"},{"name":"startedAt","type":"uint256"},{"name":"updatedAt","type":"uint256"},{"name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"}
+]`
+
+var aggregatorV3ABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorV3ABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// MaxPriceAge is the age after which the answer of a price feed is stale, the feeds used here
+// are updated at least once a day
+const MaxPriceAge = 26 * time.Hour
+
+var (
+	ErrNotPriceFeed     = errors.New("contract isn't a chainlink price feed")
+	ErrPriceFeedPair    = errors.New("price feed doesn't serve the expected pair")
+	ErrInvalidPrice     = errors.New("price feed answered an invalid price")
+	ErrStalePrice       = errors.New("price feed answer is stale")
+	ErrPriceUnavailable = errors.New("no price feed of the pair on the connected chains")
+)
+
+// PriceFeed is a Chainlink AggregatorV3Interface contract of Base priced in Quote, such as
+// ETH / USD, on the chain of ChainID
+type PriceFeed struct {
+	ChainID uint64
+	Address common.Address
+	Base    string
+	Quote   string
+}
+
+// Pair returns the pair of the feed as described by the contract, such as ETH / USD
+func (f PriceFeed) Pair() string {
+	return f.Base + " / " + f.Quote
+}
+
+// Price is an answer of a price feed, the price is Answer / 10^Decimals
+type Price struct {
+	PriceFeed
+	Answer    *big.Int
+	Decimals  int
+	UpdatedAt time.Time
+}
+
+// Float returns the price as a float
+func (p Price) Float() *big.Float {
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(p.Decimals)), nil))
+	return new(big.Float).Quo(new(big.Float).SetInt(p.Answer), scale)
+}
+
+// builtinPriceFeeds are the Chainlink feeds priced in USD of the native currencies, the stable
+// coins and the fiat currencies on the chains of the built-in tokens
+var builtinPriceFeeds = []PriceFeed{
+	// Ethereum Mainnet
+	{ChainID: 1, Address: common.HexToAddress("0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419"), Base: "ETH", Quote: "USD"},
+	{ChainID: 1, Address: common.HexToAddress("0xF4030086522a5bEEa4988F8cA5B36dbC97BeE88c"), Base: "BTC", Quote: "USD"},
+	{ChainID: 1, Address: common.HexToAddress("0x8fFfFfd4AfB6115b954Bd326cbe7B4BA576818f6"), Base: "USDC", Quote: "USD"},
+	{ChainID: 1, Address: common.HexToAddress("0x3E7d1eAB13ad0104d2750B8863b489D65364e32D"), Base: "USDT", Quote: "USD"},
+	{ChainID: 1, Address: common.HexToAddress("0xAed0c38402a5d19df6E4c03F4E2DceD6e29c1ee9"), Base: "DAI", Quote: "USD"},
+	{ChainID: 1, Address: common.HexToAddress("0xb49f677943BC038e9857d61E7d053CaA2C1734C1"), Base: "EUR", Quote: "USD"},
+	{ChainID: 1, Address: common.HexToAddress("0x5c0Ab2d9b5a7ed9f470386e82BB36A3613cDd4b5"), Base: "GBP", Quote: "USD"},
+	{ChainID: 1, Address: common.HexToAddress("0xBcE206caE7f0ec07b545EddE332A47C2F75bbeb3"), Base: "JPY", Quote: "USD"},
+	// Polygon Mainnet
+	{ChainID: 137, Address: common.HexToAddress("0xAB594600376Ec9fD91F8e885dADF0CE036862dE0"), Base: "MATIC", Quote: "USD"},
+	{ChainID: 137, Address: common.HexToAddress("0xF9680D99D6C9589e2a93a78A04A279e509205945"), Base: "ETH", Quote: "USD"},
+	{ChainID: 137, Address: common.HexToAddress("0xfE4A8cc5b5B2366C1B58Bea3858e81843581b2F7"), Base: "USDC", Quote: "USD"},
+	{ChainID: 137, Address: common.HexToAddress("0x0A6513e40db6EB1b165753AD52E80663aeA50545"), Base: "USDT", Quote: "USD"},
+	{ChainID: 137, Address: common.HexToAddress("0x4746DeC9e833A82EC7C2C1356372CcF2cfcD2F3D"), Base: "DAI", Quote: "USD"},
+	{ChainID: 137, Address: common.HexToAddress("0x73366Fe0AA0Ded304479862808e02506FE556a98"), Base: "EUR", Quote: "USD"},
+	// BNB Smart Chain Mainnet
+	{ChainID: 56, Address: common.HexToAddress("0x0567F2323251f0Aab15c8dFb1967E4e8A7D42aeE"), Base: "BNB", Quote: "USD"},
+	{ChainID: 56, Address: common.HexToAddress("0x9ef1B8c0E4F7dc8bF5719Ea496883DC6401d5b2e"), Base: "ETH", Quote: "USD"},
+	{ChainID: 56, Address: common.HexToAddress("0xB97Ad0E74fa7d920791E90258A6E2085088b4320"), Base: "USDT", Quote: "USD"},
+}
+
+// priceAssets are the assets priced by the feeds of the native currencies of the popular chains
+// by chain id, the native currency of a testnet isn't priced
+var priceAssets = map[uint64]string{
+	// Ethereum Mainnet
+	1: "ETH",
+	// OP Mainnet
+	10: "ETH",
+	// BNB Smart Chain Mainnet
+	56: "BNB",
+	// Polygon Mainnet
+	137: "MATIC",
+	// Base
+	8453: "ETH",
+	// Arbitrum One
+	42161: "ETH",
+}
+
+// tokenPriceAssets are the assets priced by the feeds of the built-in tokens by their symbol
+var tokenPriceAssets = map[string]string{
+	"USDT": "USDT",
+	"USDC": "USDC",
+	"DAI":  "DAI",
+	"WETH": "ETH",
+}
+
+// PriceFeeds returns the built-in feeds of base priced in quote
+func PriceFeeds(base, quote string) []PriceFeed {
+	feeds := make([]PriceFeed, 0)
+	for _, feed := range builtinPriceFeeds {
+		if feed.Base == base && feed.Quote == quote {
+			feeds = append(feeds, feed)
+		}
+	}
+	return feeds
+}
+
+// NativePriceAsset returns the asset priced for the native currency of chainID, false if its
+// native currency isn't priced
+func NativePriceAsset(chainID *big.Int) (string, bool) {
+	if !chainID.IsUint64() {
+		return "", false
+	}
+	asset, ok := priceAssets[chainID.Uint64()]
+	return asset, ok
+}
+
+// TokenPriceAsset returns the asset priced for token, only the built-in tokens are priced as the
+// symbol of any other token can be anything
+func TokenPriceAsset(token Token) (string, bool) {
+	for _, builtin := range BuiltinTokens(token.ChainID) {
+		if builtin.Address == token.Address {
+			asset, ok := tokenPriceAssets[builtin.Symbol]
+			return asset, ok
+		}
+	}
+	return "", false
+}
+
+// FetchPrice reads the latest answer of feed, the description of the contract must match the
+// pair of the feed and the answer must be positive and not older than MaxPriceAge
+func FetchPrice(ctx context.Context, backend Backend, feed PriceFeed) (price Price, err error) {
+	price.PriceFeed = feed
+	out, err := callPriceFeed(ctx, backend, feed.Address, "description")
+	if err != nil {
+		return price, err
+	}
+	if description, ok := out[0].(string); !ok || description != feed.Pair() {
+		return price, ErrPriceFeedPair
+	}
+	out, err = callPriceFeed(ctx, backend, feed.Address, "decimals")
+	if err != nil {
+		return price, err
+	}
+	decimals, ok := out[0].(uint8)
+	if !ok {
+		return price, ErrNotPriceFeed
+	}
+	price.Decimals = int(decimals)
+	out, err = callPriceFeed(ctx, backend, feed.Address, "latestRoundData")
+	if err != nil {
+		return price, err
+	}
+	if len(out) != 5 {
+		return price, ErrNotPriceFeed
+	}
+	answer, answerOk := out[1].(*big.Int)
+	updatedAt, updatedOk := out[3].(*big.Int)
+	if !answerOk || !updatedOk {
+		return price, ErrNotPriceFeed
+	}
+	if answer.Sign() <= 0 || !updatedAt.IsInt64() {
+		return price, ErrInvalidPrice
+	}
+	price.Answer = answer
+	price.UpdatedAt = time.Unix(updatedAt.Int64(), 0)
+	if time.Since(price.UpdatedAt) > MaxPriceAge {
+		return price, ErrStalePrice
+	}
+	return price, nil
+}
+
+func callPriceFeed(ctx context.Context, backend Backend, feed common.Address, method string) ([]interface{}, error) {
+	data, err := aggregatorV3ABI.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	out, err := backend.CallContract(ctx, ethereum.CallMsg{To: &feed, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	// calling an account without code succeeds with empty output
+	if len(out) == 0 {
+		return nil, ErrNotPriceFeed
+	}
+	values, err := aggregatorV3ABI.Unpack(method, out)
+	if err != nil || len(values) == 0 {
+		return nil, ErrNotPriceFeed
+	}
+	return values, nil
+}
@@ -2,6 +2,8 @@ package evm
 
 import (
 	"errors"
+	"net/url"
+	"regexp"
 	"strings"
 )
 
@@ -12,20 +14,35 @@ var (
 	ErrInvalidRPC       = errors.New("invalid rpc")
 )
 
+// apiKeyPlaceholder matches the api key placeholder of the templated urls such as
+// https://mainnet.infura.io/v3/${INFURA_API_KEY}
+var apiKeyPlaceholder = regexp.MustCompile(`\$\{[A-Z0-9_]*API_KEY\}`)
+
+// ParseRPC validates rawURL as an http(s) or ws(s) endpoint, it may contain an api key placeholder
+func ParseRPC(rawURL string) (RPC, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", ErrInvalidRPC
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ws", "wss":
+		return RPC(rawURL), nil
+	}
+	return "", ErrInvalidRPC
+}
+
 func (r RPC) KeyRequired() bool {
-	return strings.Contains(strings.ToLower(string(r)), "api_key")
+	return apiKeyPlaceholder.MatchString(string(r))
 }
 
+// GetURL returns the url with key substituted for its api key placeholder
 func (r RPC) GetURL(key string) (string, error) {
 	if !r.KeyRequired() {
 		return string(r), nil
 	}
-	if r.KeyRequired() && len(key) == 0 {
+	if len(key) == 0 {
 		return string(r), ErrAPIKeyIsRequired
 	}
-	lastIndex := strings.LastIndex(string(r), "/")
-	if lastIndex <= 0 {
-		return string(r), ErrInvalidRPC
-	}
-	return string(r)[:lastIndex], nil
+	return apiKeyPlaceholder.ReplaceAllLiteralString(string(r), url.PathEscape(key)), nil
 }
@@ -9,10 +9,17 @@ import (
 	"math"
 	"math/big"
 	"sync"
+	"time"
 )
 
 var ErrAlreadyConnected = errors.New("already connected")
 var ErrNotConnected = errors.New("not connected")
+var ErrRPCExists = errors.New("rpc already exists")
+var ErrRPCNotFound = errors.New("rpc not found")
+var ErrChainConnected = errors.New("chain is connected, disconnect it first")
+
+// connectTimeout limits the chain id check on connect
+const connectTimeout = 10 * time.Second
 
 type RPCClient struct {
 	*RPCClients
@@ -21,6 +28,8 @@ type RPCClient struct {
 	clientMutex sync.RWMutex
 	apiKey      string
 	apiKeyMutex sync.RWMutex
+	health      Health
+	healthMutex sync.RWMutex
 }
 
 func (c *RPCClient) getClient() *ethclient.Client {
@@ -51,11 +60,12 @@ func (c *RPCClient) IsConnected() bool {
 	return c.getClient() != nil
 }
 
+// Connect dials the endpoint and verifies that it serves the chain id of its chain
 func (c *RPCClient) Connect() (err error) {
 	if c.IsConnected() {
 		return ErrAlreadyConnected
 	}
-	url, err := c.RPC.GetURL(c.apiKey)
+	url, err := c.RPC.GetURL(c.APIKey())
 	if err != nil {
 		return err
 	}
@@ -67,6 +77,16 @@ func (c *RPCClient) Connect() (err error) {
 		}
 		return err
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	chainID, err := conn.ChainID(ctx)
+	if err == nil && chainID.Cmp(&c.RPCClients.Chain.ChainID) != 0 {
+		err = ErrChainIDMismatch
+	}
+	if err != nil {
+		conn.Close()
+		return err
+	}
 	c.setClient(conn)
 	return nil
 }
@@ -82,6 +102,16 @@ func (c *RPCClient) Disconnect() error {
 	c.setClient(nil)
 	return nil
 }
+
+// Backend returns the connected client as Backend
+func (c *RPCClient) Backend() (Backend, error) {
+	client := c.getClient()
+	if client == nil {
+		return nil, ErrNotConnected
+	}
+	return client, nil
+}
+
 func (c *RPCClient) ShowBalance(acc db.Account) (string, error) {
 	if !c.IsConnected() {
 		return "", ErrNotConnected
@@ -100,14 +130,27 @@ func (c *RPCClient) ShowBalance(acc db.Account) (string, error) {
 	return val.String(), nil
 }
 
-// RpcClients wraps multiple RpcClients for a Chain
+// RpcClients wraps multiple RpcClients for a Chain. RPCClients and Chain are changed
+// under mutex, hence they're read with Clients while the chain may be in use.
 type RPCClients struct {
-	Chain      Chain
-	RPCClients []*RPCClient
+	Chain        Chain
+	RPCClients   []*RPCClient
+	mutex        sync.RWMutex
+	proberCancel context.CancelFunc
+	proberMutex  sync.Mutex
+}
+
+// Clients returns a copy of the endpoints of chain
+func (c *RPCClients) Clients() []*RPCClient {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	clients := make([]*RPCClient, len(c.RPCClients))
+	copy(clients, c.RPCClients)
+	return clients
 }
 
 func (c *RPCClients) IsConnected() bool {
-	for _, state := range c.RPCClients {
+	for _, state := range c.Clients() {
 		if state.IsConnected() {
 			return true
 		}
@@ -115,17 +158,95 @@ func (c *RPCClients) IsConnected() bool {
 	return false
 }
 
-func GetAllRPCClients() []*RPCClients {
-	rpcClients := make([]*RPCClients, len(ChainsSlice()))
-	for i, ch := range ChainsSlice() {
-		rpcClient := RPCClients{
-			Chain:      ch,
-			RPCClients: make([]*RPCClient, 0),
+// Backend returns the Backend of chain which fails over between its connected endpoints
+func (c *RPCClients) Backend() (Backend, error) {
+	return c.Best()
+}
+
+// Disconnect stops probing the chain and disconnects all its endpoints
+func (c *RPCClients) Disconnect() {
+	c.StopProber()
+	for _, client := range c.Clients() {
+		if client.IsConnected() {
+			_ = client.Disconnect()
 		}
-		for _, rpc := range ch.RPC {
-			rpcClient.RPCClients = append(rpcClient.RPCClients, &RPCClient{RPC: rpc, client: nil, RPCClients: &rpcClient})
+	}
+}
+
+// AddRPC adds the endpoint to chain, the endpoints of chain can't change while it's connected
+func (c *RPCClients) AddRPC(rpc RPC) (*RPCClient, error) {
+	if c.IsConnected() || c.IsProbing() {
+		return nil, ErrChainConnected
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, client := range c.RPCClients {
+		if client.RPC == rpc {
+			return nil, ErrRPCExists
 		}
-		rpcClients[i] = &rpcClient
+	}
+	client := &RPCClient{RPC: rpc, RPCClients: c}
+	// the rpc slice of chain may be shared with the embedded chains, hence it's copied
+	c.Chain.RPC = append(c.Chain.RPC[:len(c.Chain.RPC):len(c.Chain.RPC)], rpc)
+	c.RPCClients = append(c.RPCClients, client)
+	return client, nil
+}
+
+// RemoveRPC removes the endpoint from chain, the endpoints of chain can't change while it's connected
+func (c *RPCClients) RemoveRPC(rpc RPC) error {
+	if c.IsConnected() || c.IsProbing() {
+		return ErrChainConnected
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, client := range c.RPCClients {
+		if client.RPC != rpc {
+			continue
+		}
+		clients := make([]*RPCClient, 0, len(c.RPCClients)-1)
+		c.RPCClients = append(append(clients, c.RPCClients[:i]...), c.RPCClients[i+1:]...)
+		rpcs := make([]RPC, 0, len(c.Chain.RPC))
+		for _, chainRPC := range c.Chain.RPC {
+			if chainRPC != rpc {
+				rpcs = append(rpcs, chainRPC)
+			}
+		}
+		c.Chain.RPC = rpcs
+		return nil
+	}
+	return ErrRPCNotFound
+}
+
+// UpdateChain replaces chain with the same chain of the registry, the endpoints of chain are
+// kept. The chain can't change while it's connected.
+func (c *RPCClients) UpdateChain(chain Chain) error {
+	if c.IsConnected() || c.IsProbing() {
+		return ErrChainConnected
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	chain.RPC = c.Chain.RPC
+	c.Chain = chain
+	return nil
+}
+
+// NewRPCClients returns the disconnected endpoints of chain
+func NewRPCClients(chain Chain) *RPCClients {
+	rpcClients := &RPCClients{
+		Chain:      chain,
+		RPCClients: make([]*RPCClient, 0, len(chain.RPC)),
+	}
+	for _, rpc := range chain.RPC {
+		rpcClients.RPCClients = append(rpcClients.RPCClients, &RPCClient{RPC: rpc, client: nil, RPCClients: rpcClients})
+	}
+	return rpcClients
+}
+
+func GetAllRPCClients() []*RPCClients {
+	chains := ChainsSlice()
+	rpcClients := make([]*RPCClients, len(chains))
+	for i, ch := range chains {
+		rpcClients[i] = NewRPCClients(ch)
 	}
 	return rpcClients
 }
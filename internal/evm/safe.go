@@ -0,0 +1,250 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+const safeABIJSON = `[
+{"constant":true,"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+{"constant":true,"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+{"constant":true,"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"type":"function"},
+{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"","type":"bool"}],"type":"function"}
+]`
+
+var safeABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(safeABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+var (
+	ErrNotSafe           = errors.New("contract isn't a safe")
+	ErrSafeNotOwner      = errors.New("address isn't an owner of the safe")
+	ErrSafeThreshold     = errors.New("not enough owners signed the safe transaction")
+	ErrSafeNonceConsumed = errors.New("safe nonce is already used by another transaction")
+)
+
+// safeTxTypes are the EIP-712 types of a transaction of a Safe 1.3 or later, the domain has no
+// name and version
+var safeTxTypes = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"SafeTx": {
+		{Name: "to", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "data", Type: "bytes"},
+		{Name: "operation", Type: "uint8"},
+		{Name: "safeTxGas", Type: "uint256"},
+		{Name: "baseGas", Type: "uint256"},
+		{Name: "gasPrice", Type: "uint256"},
+		{Name: "gasToken", Type: "address"},
+		{Name: "refundReceiver", Type: "address"},
+		{Name: "nonce", Type: "uint256"},
+	},
+}
+
+// SafeTx is a transaction executed by a Safe once its owners sign it. Only calls are built
+// here, the gas of the execution is paid by the owner who executes it without refund, hence
+// Operation, SafeTxGas, BaseGas, GasPrice, GasToken and RefundReceiver are zero.
+type SafeTx struct {
+	ChainID *big.Int
+	Safe    common.Address
+	To      common.Address
+	Value   *big.Int
+	Data    []byte
+	Nonce   *big.Int
+}
+
+// TypedData returns the EIP-712 typed data signed by the owners for tx
+func (tx *SafeTx) TypedData() apitypes.TypedData {
+	zero := common.Address{}.Hex()
+	return apitypes.TypedData{
+		Types:       safeTxTypes,
+		PrimaryType: "SafeTx",
+		Domain: apitypes.TypedDataDomain{
+			ChainId:           (*math.HexOrDecimal256)(tx.ChainID),
+			VerifyingContract: tx.Safe.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"to":             tx.To.Hex(),
+			"value":          tx.Value.String(),
+			"data":           hexutil.Encode(tx.Data),
+			"operation":      "0",
+			"safeTxGas":      "0",
+			"baseGas":        "0",
+			"gasPrice":       "0",
+			"gasToken":       zero,
+			"refundReceiver": zero,
+			"nonce":          tx.Nonce.String(),
+		},
+	}
+}
+
+// Hash returns the safeTxHash of tx, the hash signed by the owners and checked by the Safe
+func (tx *SafeTx) Hash() (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(tx.TypedData())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// RecoverSafeTxSigner returns the owner which signed tx with signature
+func RecoverSafeTxSigner(tx *SafeTx, signature []byte) (common.Address, error) {
+	hash, err := tx.Hash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return recoverSigner(hash.Bytes(), signature)
+}
+
+// Safe is the state of a Safe read from the chain
+type Safe struct {
+	Address   common.Address
+	Owners    []common.Address
+	Threshold uint64
+	Nonce     *big.Int
+}
+
+// IsOwner reports whether address is an owner of the safe
+func (s *Safe) IsOwner(address common.Address) bool {
+	for _, owner := range s.Owners {
+		if owner == address {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchSafe reads the owners, threshold and nonce of the Safe at address
+func FetchSafe(ctx context.Context, backend Backend, address common.Address) (safe Safe, err error) {
+	safe.Address = address
+	out, err := callSafe(ctx, backend, address, "getOwners")
+	if err != nil {
+		return safe, err
+	}
+	if safe.Owners, err = unpackSafeValue[[]common.Address](out); err != nil {
+		return safe, err
+	}
+	out, err = callSafe(ctx, backend, address, "getThreshold")
+	if err != nil {
+		return safe, err
+	}
+	threshold, err := unpackSafeValue[*big.Int](out)
+	if err != nil {
+		return safe, err
+	}
+	if !threshold.IsUint64() || threshold.Sign() == 0 {
+		return safe, ErrNotSafe
+	}
+	safe.Threshold = threshold.Uint64()
+	out, err = callSafe(ctx, backend, address, "nonce")
+	if err != nil {
+		return safe, err
+	}
+	safe.Nonce, err = unpackSafeValue[*big.Int](out)
+	return safe, err
+}
+
+// PackSafeSignatures returns the signatures of the owners in the order checked by the Safe,
+// which is by ascending owner address. The signatures are over the safeTxHash itself, hence
+// their v is 27 or 28.
+func PackSafeSignatures(signatures map[common.Address][]byte) []byte {
+	owners := make([]common.Address, 0, len(signatures))
+	for owner := range signatures {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		return bytes.Compare(owners[i].Bytes(), owners[j].Bytes()) < 0
+	})
+	packed := make([]byte, 0, len(owners)*65)
+	for _, owner := range owners {
+		sig := make([]byte, len(signatures[owner]))
+		copy(sig, signatures[owner])
+		if len(sig) == 65 && sig[64] < 27 {
+			sig[64] += 27
+		}
+		packed = append(packed, sig...)
+	}
+	return packed
+}
+
+// PackExecTransaction returns the call data of execTransaction of tx with the packed signatures
+func PackExecTransaction(tx *SafeTx, signatures []byte) ([]byte, error) {
+	zero := common.Address{}
+	return safeABI.Pack("execTransaction", tx.To, tx.Value, tx.Data, uint8(0),
+		new(big.Int), new(big.Int), new(big.Int), zero, zero, signatures)
+}
+
+// PrepareSafeExec prepares the execution of tx by from with the signatures of the owners, the
+// signatures of addresses which aren't owners are left out and at least the threshold of the
+// safe must remain
+func (t *Transactor) PrepareSafeExec(ctx context.Context, from common.Address, tx *SafeTx, signatures map[common.Address][]byte) (*PreparedTx, error) {
+	safe, err := FetchSafe(ctx, t.backend, tx.Safe)
+	if err != nil {
+		return nil, err
+	}
+	if safe.Nonce.Cmp(tx.Nonce) != 0 {
+		return nil, ErrSafeNonceConsumed
+	}
+	valid := make(map[common.Address][]byte)
+	for owner, signature := range signatures {
+		if safe.IsOwner(owner) {
+			valid[owner] = signature
+		}
+	}
+	if uint64(len(valid)) < safe.Threshold {
+		return nil, ErrSafeThreshold
+	}
+	data, err := PackExecTransaction(tx, PackSafeSignatures(valid))
+	if err != nil {
+		return nil, err
+	}
+	return t.Prepare(ctx, from, tx.Safe, new(big.Int), data)
+}
+
+func callSafe(ctx context.Context, backend Backend, safe common.Address, method string) ([]interface{}, error) {
+	data, err := safeABI.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	out, err := backend.CallContract(ctx, ethereum.CallMsg{To: &safe, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	// calling an account without code succeeds with empty output
+	if len(out) == 0 {
+		return nil, ErrNotSafe
+	}
+	values, err := safeABI.Unpack(method, out)
+	if err != nil {
+		return nil, ErrNotSafe
+	}
+	return values, nil
+}
+
+func unpackSafeValue[T any](values []interface{}) (value T, err error) {
+	if len(values) != 1 {
+		return value, ErrNotSafe
+	}
+	value, ok := values[0].(T)
+	if !ok {
+		return value, ErrNotSafe
+	}
+	return value, nil
+}
@@ -0,0 +1,242 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// the bytecode in testdata is the Safe 1.3.0 singleton and the SafeProxyFactory of Safe 1.4.1,
+// whose proxies only delegate to the singleton and hence work with any version of it
+const safeTestABIJSON = `[
+{"inputs":[{"name":"_owners","type":"address[]"},{"name":"_threshold","type":"uint256"},{"name":"to","type":"address"},{"name":"data","type":"bytes"},{"name":"fallbackHandler","type":"address"},{"name":"paymentToken","type":"address"},{"name":"payment","type":"uint256"},{"name":"paymentReceiver","type":"address"}],"name":"setup","outputs":[],"type":"function"},
+{"inputs":[{"name":"_singleton","type":"address"},{"name":"initializer","type":"bytes"},{"name":"saltNonce","type":"uint256"}],"name":"createProxyWithNonce","outputs":[{"name":"proxy","type":"address"}],"type":"function"}
+]`
+
+// deployContract deploys code from key and returns the address of the contract
+func deployContract(t *testing.T, sim *backends.SimulatedBackend, key *ecdsa.PrivateKey, code []byte) common.Address {
+	t.Helper()
+	receipt := sendSimulatedTx(t, sim, key, nil, nil, code)
+	return receipt.ContractAddress
+}
+
+// sendSimulatedTx sends a transaction from key, mines it and fails the test unless it succeeds
+func sendSimulatedTx(t *testing.T, sim *backends.SimulatedBackend, key *ecdsa.PrivateKey, to *common.Address, value *big.Int, data []byte) *types.Receipt {
+	t.Helper()
+	ctx := context.Background()
+	nonce, err := sim.PendingNonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gasPrice, err := sim.SuggestGasPrice(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+	chainID := sim.Blockchain().Config().ChainID
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), &types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      10_000_000,
+		To:       to,
+		Value:    value,
+		Data:     data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sim.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	receipt, err := sim.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction %s reverted", tx.Hash())
+	}
+	return receipt
+}
+
+func readBytecode(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// deploySafe deploys the singleton and a proxy of it owned by owners and returns the proxy
+func deploySafe(t *testing.T, sim *backends.SimulatedBackend, key *ecdsa.PrivateKey, owners []common.Address, threshold int64) common.Address {
+	t.Helper()
+	testABI, err := abi.JSON(strings.NewReader(safeTestABIJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	singleton := deployContract(t, sim, key, readBytecode(t, "safe_v1.3.0.bin"))
+	factory := deployContract(t, sim, key, readBytecode(t, "safe_proxy_factory_v1.4.1.bin"))
+	zero := common.Address{}
+	initializer, err := testABI.Pack("setup", owners, big.NewInt(threshold), zero, []byte{}, zero, zero, new(big.Int), zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := testABI.Pack("createProxyWithNonce", singleton, initializer, new(big.Int))
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := sendSimulatedTx(t, sim, key, &factory, nil, data)
+	// ProxyCreation(address indexed proxy, address singleton) is the last log of the factory
+	for i := len(receipt.Logs) - 1; i >= 0; i-- {
+		if log := receipt.Logs[i]; log.Address == factory && len(log.Topics) == 2 {
+			return common.BytesToAddress(log.Topics[1].Bytes())
+		}
+	}
+	t.Fatal("factory didn't log the proxy")
+	return common.Address{}
+}
+
+func TestSafeExec(t *testing.T) {
+	ctx := context.Background()
+	sim, key := newSimulatedChain(t)
+	chainID := sim.Blockchain().Config().ChainID
+	transactor := NewTransactor(sim, chainID, NewNonceManager())
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	ownerKeys := make(map[common.Address]*ecdsa.PrivateKey)
+	owners := make([]common.Address, 0, 3)
+	for i := 0; i < 3; i++ {
+		ownerKey, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		owner := crypto.PubkeyToAddress(ownerKey.PublicKey)
+		ownerKeys[owner] = ownerKey
+		owners = append(owners, owner)
+	}
+	safeAddress := deploySafe(t, sim, key, owners, 2)
+	sendSimulatedTx(t, sim, key, &safeAddress, big.NewInt(params.Ether), nil)
+
+	safe, err := FetchSafe(ctx, sim, safeAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if safe.Threshold != 2 || len(safe.Owners) != 3 || safe.Nonce.Sign() != 0 {
+		t.Fatalf("got safe %+v", safe)
+	}
+	for _, owner := range owners {
+		if !safe.IsOwner(owner) {
+			t.Fatalf("%s isn't an owner of the safe", owner)
+		}
+	}
+	if _, err = FetchSafe(ctx, sim, from); !errors.Is(err, ErrNotSafe) {
+		t.Fatalf("got error %v for an account without code, want %v", err, ErrNotSafe)
+	}
+
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	value := big.NewInt(params.Ether / 2)
+	safeTx := &SafeTx{ChainID: chainID, Safe: safeAddress, To: to, Value: value, Data: []byte{}, Nonce: safe.Nonce}
+	signatures := make(map[common.Address][]byte)
+	for _, owner := range owners[:2] {
+		signature, err := SignTypedData(pvtKeyHex(ownerKeys[owner]), safeTx.TypedData())
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := RecoverSafeTxSigner(safeTx, signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer != owner {
+			t.Fatalf("signature recovers %s, want %s", signer, owner)
+		}
+		signatures[owner] = signature
+	}
+
+	// the packed signatures are ordered by ascending owner
+	packed := PackSafeSignatures(signatures)
+	if len(packed) != 2*crypto.SignatureLength {
+		t.Fatalf("got %d bytes of packed signatures", len(packed))
+	}
+	signers := make([]common.Address, 0, 2)
+	for i := 0; i < len(packed); i += crypto.SignatureLength {
+		signer, err := RecoverSafeTxSigner(safeTx, packed[i:i+crypto.SignatureLength])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers = append(signers, signer)
+	}
+	if !sort.SliceIsSorted(signers, func(i, j int) bool { return bytes.Compare(signers[i].Bytes(), signers[j].Bytes()) < 0 }) {
+		t.Fatalf("packed signatures of %v aren't ordered by owner", signers)
+	}
+
+	// a signature of an address which isn't an owner doesn't count for the threshold
+	outsiderKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outsiderSignature, err := SignTypedData(pvtKeyHex(outsiderKey), safeTx.TypedData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	belowThreshold := map[common.Address][]byte{
+		owners[0]: signatures[owners[0]],
+		crypto.PubkeyToAddress(outsiderKey.PublicKey): outsiderSignature,
+	}
+	if _, err = transactor.PrepareSafeExec(ctx, from, safeTx, belowThreshold); !errors.Is(err, ErrSafeThreshold) {
+		t.Fatalf("got error %v below the threshold, want %v", err, ErrSafeThreshold)
+	}
+
+	prepared, err := transactor.PrepareSafeExec(ctx, from, safeTx, signatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := transactor.Send(ctx, prepared, pvtKeyHex(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	receipt, err := transactor.WaitMined(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatal("execTransaction reverted")
+	}
+	balance, err := sim.BalanceAt(ctx, to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(value) != 0 {
+		t.Errorf("got balance %s of the recipient, want %s", balance, value)
+	}
+	if safe, err = FetchSafe(ctx, sim, safeAddress); err != nil {
+		t.Fatal(err)
+	}
+	if safe.Nonce.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("got safe nonce %s after the execution, want 1", safe.Nonce)
+	}
+	if _, err = transactor.PrepareSafeExec(ctx, from, safeTx, signatures); !errors.Is(err, ErrSafeNonceConsumed) {
+		t.Fatalf("got error %v executing twice, want %v", err, ErrSafeNonceConsumed)
+	}
+}
@@ -0,0 +1,156 @@
+package evm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidTypedData = errors.New("invalid typed data")
+)
+
+// SignText signs msg as an EIP-191 personal message, v of the signature is 27 or 28 as returned
+// by the wallets
+func SignText(pvtKeyHex string, msg []byte) ([]byte, error) {
+	return signHash(pvtKeyHex, accounts.TextHash(msg))
+}
+
+// RecoverTextSigner returns the address which signed msg as an EIP-191 personal message, v of
+// the signature may be 0, 1, 27 or 28
+func RecoverTextSigner(msg []byte, signature []byte) (common.Address, error) {
+	return recoverSigner(accounts.TextHash(msg), signature)
+}
+
+// SignTypedData signs the EIP-712 hash of typedData as eth_signTypedData_v4
+func SignTypedData(pvtKeyHex string, typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTypedData, err)
+	}
+	return signHash(pvtKeyHex, hash)
+}
+
+// RecoverTypedDataSigner returns the address which signed the EIP-712 hash of typedData
+func RecoverTypedDataSigner(typedData apitypes.TypedData, signature []byte) (common.Address, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrInvalidTypedData, err)
+	}
+	return recoverSigner(hash, signature)
+}
+
+// ParseTypedData parses EIP-712 typed data in json as sent by dApps, the data is hashed once to
+// check that the message matches its types
+func ParseTypedData(data []byte) (typedData apitypes.TypedData, err error) {
+	data, err = quoteChainID(data)
+	if err != nil {
+		return typedData, fmt.Errorf("%w: %s", ErrInvalidTypedData, err)
+	}
+	if err = json.Unmarshal(data, &typedData); err != nil {
+		return typedData, fmt.Errorf("%w: %s", ErrInvalidTypedData, err)
+	}
+	if typedData.PrimaryType == "" || len(typedData.Types) == 0 {
+		return typedData, ErrInvalidTypedData
+	}
+	if _, _, err = apitypes.TypedDataAndHash(typedData); err != nil {
+		return typedData, fmt.Errorf("%w: %s", ErrInvalidTypedData, err)
+	}
+	return typedData, nil
+}
+
+// quoteChainID quotes the chain id of the domain of typed data, dApps send it as a number which
+// apitypes only accepts as a string
+func quoteChainID(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	var domain map[string]json.RawMessage
+	if err := json.Unmarshal(fields["domain"], &domain); err != nil || domain == nil {
+		return data, nil
+	}
+	chainID, ok := domain["chainId"]
+	if !ok || len(chainID) == 0 || chainID[0] == '"' || string(chainID) == "null" {
+		return data, nil
+	}
+	quoted, err := json.Marshal(string(chainID))
+	if err != nil {
+		return nil, err
+	}
+	domain["chainId"] = quoted
+	if fields["domain"], err = json.Marshal(domain); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// FormatTypedData returns the domain and the message of typedData as indented text, so that the
+// user can review them before signing
+func FormatTypedData(typedData apitypes.TypedData) (string, error) {
+	nameValues, err := typedData.Format()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidTypedData, err)
+	}
+	var b strings.Builder
+	for _, nameValue := range nameValues {
+		b.WriteString(nameValue.Pprint(0))
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// DecodeTextMessage returns the bytes of a personal message, dApps send the message as 0x
+// prefixed hex and a message which isn't valid hex is taken as text
+func DecodeTextMessage(msg string) []byte {
+	if strings.HasPrefix(msg, "0x") {
+		if data, err := hexutil.Decode(msg); err == nil {
+			return data
+		}
+	}
+	return []byte(msg)
+}
+
+// FormatTextMessage returns msg as text if it's valid utf8 and as 0x prefixed hex otherwise
+func FormatTextMessage(msg []byte) string {
+	if utf8.Valid(msg) {
+		return string(msg)
+	}
+	return hexutil.Encode(msg)
+}
+
+func signHash(pvtKeyHex string, hash []byte) ([]byte, error) {
+	pvtKey, err := crypto.HexToECDSA(pvtKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(hash, pvtKey)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+func recoverSigner(hash []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, ErrInvalidSignature
+	}
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, ErrInvalidSignature
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
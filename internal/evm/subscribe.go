@@ -0,0 +1,162 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/mearaj/protonet/alog"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// maxHeadGap is the number of blocks missed between two heads which are scanned for native
+// transfers, the history scan finds the ones of a larger gap
+const maxHeadGap = 64
+
+var ErrNoWebSocket = errors.New("no websocket endpoint is connected")
+
+// SubscriptionBackend is a Backend which supports subscriptions, such as ethclient.Client
+// connected over WebSocket or go-ethereum's backends.SimulatedBackend
+type SubscriptionBackend interface {
+	Backend
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+	SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+}
+
+var _ SubscriptionBackend = (*ethclient.Client)(nil)
+
+// IsWebSocket reports whether the endpoint is a ws:// or wss:// url, only those support subscriptions
+func (r RPC) IsWebSocket() bool {
+	url := strings.ToLower(string(r))
+	return strings.HasPrefix(url, "wss://") || strings.HasPrefix(url, "ws://")
+}
+
+// SubscriptionBackend returns the best ranked healthy websocket endpoint of chain
+func (c *RPCClients) SubscriptionBackend() (SubscriptionBackend, error) {
+	for _, client := range c.Ranked() {
+		if !client.IsWebSocket() || client.Health().Err != nil {
+			continue
+		}
+		if cl := client.getClient(); cl != nil {
+			return cl, nil
+		}
+	}
+	return nil, ErrNoWebSocket
+}
+
+// AccountWatcher notifies the balance changes of an account and the token transfers to or from
+// it as they are mined. Native transfers to the account are found by scanning each new block,
+// along with the blocks missed since the last head up to maxHeadGap.
+type AccountWatcher struct {
+	Backend SubscriptionBackend
+	ChainID *big.Int
+	Address common.Address
+	Tokens  []common.Address
+	// OnBalance is called with the native balance when it's read first and whenever it changes
+	OnBalance func(balance *big.Int)
+	// OnTransfer is called for each native transfer to and token transfer to or from the account
+	OnTransfer func(transfer Transfer)
+}
+
+// Watch runs until ctx is done, which returns nil, or until a subscription fails
+func (a *AccountWatcher) Watch(ctx context.Context) error {
+	heads := make(chan *types.Header, 16)
+	headSub, err := a.Backend.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return err
+	}
+	defer headSub.Unsubscribe()
+	// a nil channel never receives, hence the log subscriptions are optional
+	var incomingErr, outgoingErr <-chan error
+	logs := make(chan types.Log, 64)
+	if len(a.Tokens) > 0 {
+		addressTopic := common.BytesToHash(a.Address.Bytes())
+		incomingSub, err := a.Backend.SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+			Addresses: a.Tokens,
+			Topics:    [][]common.Hash{{transferEventTopic}, nil, {addressTopic}},
+		}, logs)
+		if err != nil {
+			return err
+		}
+		defer incomingSub.Unsubscribe()
+		outgoingSub, err := a.Backend.SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+			Addresses: a.Tokens,
+			Topics:    [][]common.Hash{{transferEventTopic}, {addressTopic}},
+		}, logs)
+		if err != nil {
+			return err
+		}
+		defer outgoingSub.Unsubscribe()
+		incomingErr, outgoingErr = incomingSub.Err(), outgoingSub.Err()
+	}
+	balance, err := a.Backend.BalanceAt(ctx, a.Address, nil)
+	if err != nil {
+		return err
+	}
+	a.onBalance(balance)
+	var lastBlock uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err = <-headSub.Err():
+			return err
+		case err = <-incomingErr:
+			return err
+		case err = <-outgoingErr:
+			return err
+		case header := <-heads:
+			block := header.Number.Uint64()
+			fromBlock := lastBlock + 1
+			// a head at or below the last one replaces blocks dropped by a reorg
+			if lastBlock == 0 || fromBlock > block || block-fromBlock >= maxHeadGap {
+				fromBlock = block
+			}
+			// blocks which can't be decoded are skipped by the scan, the blocks of a scan which
+			// fails are scanned again with the next head
+			transfers, err := ScanNativeTransfers(ctx, a.Backend, a.ChainID, a.Address, fromBlock, block)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				alog.Logger().Errorln(err)
+				continue
+			}
+			lastBlock = block
+			for _, transfer := range transfers {
+				a.onTransfer(transfer)
+			}
+			newBalance, err := a.Backend.BalanceAt(ctx, a.Address, header.Number)
+			if err != nil {
+				alog.Logger().Errorln(err)
+				continue
+			}
+			if newBalance.Cmp(balance) != 0 {
+				balance = newBalance
+				a.onBalance(balance)
+			}
+		case log := <-logs:
+			// removed logs are of the blocks dropped by a reorg
+			if transfer, ok := transferFromLog(log); ok && !log.Removed {
+				transfer.Time = uint64(time.Now().Unix())
+				a.onTransfer(transfer)
+			}
+		}
+	}
+}
+
+func (a *AccountWatcher) onBalance(balance *big.Int) {
+	if a.OnBalance != nil {
+		a.OnBalance(balance)
+	}
+}
+
+func (a *AccountWatcher) onTransfer(transfer Transfer) {
+	if a.OnTransfer != nil {
+		a.OnTransfer(transfer)
+	}
+}
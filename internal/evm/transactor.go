@@ -0,0 +1,182 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mearaj/protonet/alog"
+	"math/big"
+	"time"
+)
+
+// receiptPollInterval is the interval at which the receipt of a pending transaction is checked
+const receiptPollInterval = time.Second * 3
+
+var (
+	ErrEIP1559NotSupported = errors.New("chain doesn't support EIP-1559 transactions")
+	ErrInsufficientFunds   = errors.New("insufficient funds for amount and fee")
+	ErrInvalidSender       = errors.New("private key doesn't belong to the sender")
+	ErrTransactionFailed   = errors.New("transaction failed")
+)
+
+// PreparedTx is an unsigned EIP-1559 transaction with its estimated gas, meant to be confirmed
+// by the user before it's signed and sent
+type PreparedTx struct {
+	ChainID   *big.Int
+	From      common.Address
+	To        common.Address
+	Value     *big.Int
+	Data      []byte
+	Gas       uint64
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// MaxFee is the maximum fee paid for the transaction, the actual fee is lower when the base fee
+// of the block is lower than GasFeeCap - GasTipCap or when gas isn't entirely used
+func (p *PreparedTx) MaxFee() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(p.Gas), p.GasFeeCap)
+}
+
+// MaxTotal is the value plus MaxFee
+func (p *PreparedTx) MaxTotal() *big.Int {
+	return new(big.Int).Add(p.Value, p.MaxFee())
+}
+
+// PendingTx is a sent transaction which isn't mined yet
+type PendingTx struct {
+	Hash    common.Hash
+	ChainID *big.Int
+	From    common.Address
+	To      common.Address
+	Value   *big.Int
+	Nonce   uint64
+	SentAt  time.Time
+}
+
+// Transactor builds, signs and sends EIP-1559 transactions to a chain
+type Transactor struct {
+	backend Backend
+	chainID *big.Int
+	nonces  *NonceManager
+}
+
+func NewTransactor(backend Backend, chainID *big.Int, nonces *NonceManager) *Transactor {
+	return &Transactor{backend: backend, chainID: chainID, nonces: nonces}
+}
+
+// PrepareTransfer prepares the transfer of value in native currency from to to
+func (t *Transactor) PrepareTransfer(ctx context.Context, from, to common.Address, value *big.Int) (*PreparedTx, error) {
+	return t.Prepare(ctx, from, to, value, nil)
+}
+
+// Prepare estimates the gas and fee of the transaction and checks the sender can afford it.
+// Fee cap is twice the current base fee plus the tip, which keeps the transaction valid for
+// several consecutive blocks with full base fee increase.
+func (t *Transactor) Prepare(ctx context.Context, from, to common.Address, value *big.Int, data []byte) (*PreparedTx, error) {
+	header, err := t.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, ErrEIP1559NotSupported
+	}
+	gasTipCap, err := t.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), gasTipCap)
+	gas, err := t.backend.EstimateGas(ctx, ethereum.CallMsg{
+		From:      from,
+		To:        &to,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		Value:     value,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	prepared := &PreparedTx{
+		ChainID:   t.chainID,
+		From:      from,
+		To:        to,
+		Value:     value,
+		Data:      data,
+		Gas:       gas,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+	}
+	balance, err := t.backend.BalanceAt(ctx, from, nil)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(prepared.MaxTotal()) < 0 {
+		return nil, ErrInsufficientFunds
+	}
+	return prepared, nil
+}
+
+// Send signs prepared with pvtKeyHex under the next nonce of the sender and sends it
+func (t *Transactor) Send(ctx context.Context, prepared *PreparedTx, pvtKeyHex string) (*types.Transaction, error) {
+	pvtKey, err := crypto.HexToECDSA(pvtKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	if crypto.PubkeyToAddress(pvtKey.PublicKey) != prepared.From {
+		return nil, ErrInvalidSender
+	}
+	nonce, err := t.nonces.Acquire(ctx, t.backend, t.chainID, prepared.From)
+	if err != nil {
+		return nil, err
+	}
+	to := prepared.To
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   t.chainID,
+		Nonce:     nonce,
+		GasTipCap: prepared.GasTipCap,
+		GasFeeCap: prepared.GasFeeCap,
+		Gas:       prepared.Gas,
+		To:        &to,
+		Value:     prepared.Value,
+		Data:      prepared.Data,
+	})
+	signedTx, err := types.SignTx(tx, types.NewLondonSigner(t.chainID), pvtKey)
+	if err != nil {
+		t.nonces.Release(t.chainID, prepared.From, nonce)
+		return nil, err
+	}
+	err = t.backend.SendTransaction(ctx, signedTx)
+	if err != nil {
+		t.nonces.Release(t.chainID, prepared.From, nonce)
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// WaitMined polls for the receipt of the transaction until it's mined or ctx is done,
+// ErrTransactionFailed is returned along with the receipt of a reverted transaction
+func (t *Transactor) WaitMined(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	tckr := time.NewTicker(receiptPollInterval)
+	defer tckr.Stop()
+	for {
+		receipt, err := t.backend.TransactionReceipt(ctx, txHash)
+		if err == nil && receipt != nil {
+			if receipt.Status == types.ReceiptStatusFailed {
+				return receipt, ErrTransactionFailed
+			}
+			return receipt, nil
+		}
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			alog.Logger().Errorln(err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-tckr.C:
+		}
+	}
+}
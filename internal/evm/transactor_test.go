@@ -0,0 +1,199 @@
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const simulatedGasLimit = 30_000_000
+
+// newSimulatedChain returns a simulated chain in which the returned key holds 100 ether
+func newSimulatedChain(t *testing.T) (*backends.SimulatedBackend, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alloc := core.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))},
+	}
+	sim := backends.NewSimulatedBackend(alloc, simulatedGasLimit)
+	t.Cleanup(func() { _ = sim.Close() })
+	return sim, key
+}
+
+func pvtKeyHex(key *ecdsa.PrivateKey) string {
+	return hex.EncodeToString(crypto.FromECDSA(key))
+}
+
+func TestTransactorPrepareTransfer(t *testing.T) {
+	ctx := context.Background()
+	sim, key := newSimulatedChain(t)
+	chainID := sim.Blockchain().Config().ChainID
+	transactor := NewTransactor(sim, chainID, NewNonceManager())
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	prepared, err := transactor.PrepareTransfer(ctx, from, to, big.NewInt(params.GWei))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prepared.Gas != params.TxGas {
+		t.Errorf("got gas %d, want %d", prepared.Gas, params.TxGas)
+	}
+	header, err := sim.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gasTipCap, err := sim.SuggestGasTipCap(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prepared.GasTipCap.Cmp(gasTipCap) != 0 {
+		t.Errorf("got gas tip cap %s, want %s", prepared.GasTipCap, gasTipCap)
+	}
+	wantFeeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), gasTipCap)
+	if prepared.GasFeeCap.Cmp(wantFeeCap) != 0 {
+		t.Errorf("got gas fee cap %s, want %s", prepared.GasFeeCap, wantFeeCap)
+	}
+	wantMaxFee := new(big.Int).Mul(wantFeeCap, new(big.Int).SetUint64(params.TxGas))
+	if prepared.MaxFee().Cmp(wantMaxFee) != 0 {
+		t.Errorf("got max fee %s, want %s", prepared.MaxFee(), wantMaxFee)
+	}
+
+	// the gas estimation of the chain already fails for a sender who can't afford the fee cap
+	if _, err = transactor.PrepareTransfer(ctx, to, from, big.NewInt(params.GWei)); err == nil {
+		t.Error("prepared a transfer from a sender without funds")
+	}
+}
+
+func TestTransactorSend(t *testing.T) {
+	ctx := context.Background()
+	sim, key := newSimulatedChain(t)
+	chainID := sim.Blockchain().Config().ChainID
+	nonces := NewNonceManager()
+	transactor := NewTransactor(sim, chainID, nonces)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	value := big.NewInt(params.GWei)
+
+	prepared, err := transactor.PrepareTransfer(ctx, from, to, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = transactor.Send(ctx, prepared, pvtKeyHex(otherKey)); !errors.Is(err, ErrInvalidSender) {
+		t.Fatalf("got error %v for the key of another address, want %v", err, ErrInvalidSender)
+	}
+
+	for i := uint64(0); i < 2; i++ {
+		tx, err := transactor.Send(ctx, prepared, pvtKeyHex(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.Nonce() != i {
+			t.Fatalf("got nonce %d for transaction %d, want %d", tx.Nonce(), i, i)
+		}
+		sim.Commit()
+		receipt, err := transactor.WaitMined(ctx, tx.Hash())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if receipt.GasUsed != params.TxGas {
+			t.Errorf("got gas used %d, want %d", receipt.GasUsed, params.TxGas)
+		}
+	}
+	balance, err := sim.BalanceAt(ctx, to, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := new(big.Int).Mul(value, big.NewInt(2)); balance.Cmp(want) != 0 {
+		t.Errorf("got balance %s of the recipient, want %s", balance, want)
+	}
+}
+
+func TestTransactorSendReleasesNonce(t *testing.T) {
+	ctx := context.Background()
+	sim, key := newSimulatedChain(t)
+	chainID := sim.Blockchain().Config().ChainID
+	nonces := NewNonceManager()
+	transactor := NewTransactor(sim, chainID, nonces)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	prepared, err := transactor.PrepareTransfer(ctx, from, to, big.NewInt(params.GWei))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nonce 0 is reserved by a transaction not sent yet, hence the chain rejects nonce 1
+	reserved, err := nonces.Acquire(ctx, sim, chainID, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = transactor.Send(ctx, prepared, pvtKeyHex(key)); err == nil {
+		t.Fatal("chain accepted a transaction with a nonce gap")
+	}
+	nonce, err := nonces.Acquire(ctx, sim, chainID, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce != reserved+1 {
+		t.Fatalf("got nonce %d after a failed send, want the released nonce %d", nonce, reserved+1)
+	}
+}
+
+func TestNonceManager(t *testing.T) {
+	ctx := context.Background()
+	sim, key := newSimulatedChain(t)
+	chainID := sim.Blockchain().Config().ChainID
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	nonces := NewNonceManager()
+
+	acquire := func(want uint64) {
+		t.Helper()
+		nonce, err := nonces.Acquire(ctx, sim, chainID, address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nonce != want {
+			t.Fatalf("got nonce %d, want %d", nonce, want)
+		}
+	}
+	acquire(0)
+	acquire(1)
+	acquire(2)
+	// the last nonce is given back and handed out again
+	nonces.Release(chainID, address, 2)
+	acquire(2)
+	// a nonce in the middle forgets the nonces, the pending nonce of the chain is used
+	nonces.Release(chainID, address, 1)
+	acquire(0)
+	acquire(1)
+	// nonces are per chain
+	acquire2 := func(chainID *big.Int, want uint64) {
+		t.Helper()
+		nonce, err := nonces.Acquire(ctx, sim, chainID, address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nonce != want {
+			t.Fatalf("got nonce %d on chain %s, want %d", nonce, chainID, want)
+		}
+	}
+	acquire2(big.NewInt(5), 0)
+	nonces.Reset(chainID, address)
+	acquire(0)
+}
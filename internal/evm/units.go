@@ -0,0 +1,50 @@
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ParseUnits converts a decimal amount such as 1.5 into the smallest unit of currency with given decimals
+func ParseUnits(amount string, decimals int) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" || strings.HasPrefix(amount, "-") {
+		return nil, ErrInvalidAmount
+	}
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if len(fraction) > decimals {
+		return nil, ErrInvalidAmount
+	}
+	fraction += strings.Repeat("0", decimals-len(fraction))
+	value, ok := new(big.Int).SetString(whole+fraction, 10)
+	if !ok {
+		return nil, ErrInvalidAmount
+	}
+	return value, nil
+}
+
+// FormatUnits converts value in the smallest unit of currency with given decimals into a decimal amount
+func FormatUnits(value *big.Int, decimals int) string {
+	if value == nil {
+		return "0"
+	}
+	sign := ""
+	if value.Sign() < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(value).String()
+	if decimals <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
@@ -0,0 +1,144 @@
+package hdwallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// HardenedOffset is added to the index of hardened child keys
+const HardenedOffset uint32 = 0x80000000
+
+// EthBasePath is the BIP-44 path of ethereum accounts, i'th account is derived at EthBasePath/i
+const EthBasePath = "m/44'/60'/0'/0"
+
+var (
+	ErrInvalidSeed  = errors.New("invalid seed")
+	ErrInvalidPath  = errors.New("invalid derivation path")
+	ErrInvalidChild = errors.New("invalid child key, try the next index")
+)
+
+var masterKeyHMACKey = []byte("Bitcoin seed")
+
+// Key is a BIP-32 extended private key on secp256k1
+type Key struct {
+	privateKey []byte
+	chainCode  []byte
+}
+
+// EthAccountPath returns the derivation path of the i'th ethereum account
+func EthAccountPath(index uint32) string {
+	return fmt.Sprintf("%s/%d", EthBasePath, index)
+}
+
+// NewMasterKey returns the master key of seed
+func NewMasterKey(seed []byte) (*Key, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, ErrInvalidSeed
+	}
+	mac := hmac.New(sha512.New, masterKeyHMACKey)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	if !isValidPrivateKey(sum[:32]) {
+		return nil, ErrInvalidSeed
+	}
+	return &Key{privateKey: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// Child derives the child key at index, index >= HardenedOffset derives a hardened child
+func (k *Key) Child(index uint32) (*Key, error) {
+	data := make([]byte, 0, 37)
+	if index >= HardenedOffset {
+		data = append(data, 0)
+		data = append(data, k.privateKey...)
+	} else {
+		pvtKey, err := crypto.ToECDSA(k.privateKey)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, crypto.CompressPubkey(&pvtKey.PublicKey)...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	curveN := crypto.S256().Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(curveN) >= 0 {
+		return nil, ErrInvalidChild
+	}
+	il.Add(il, new(big.Int).SetBytes(k.privateKey))
+	il.Mod(il, curveN)
+	if il.Sign() == 0 {
+		return nil, ErrInvalidChild
+	}
+	return &Key{privateKey: il.FillBytes(make([]byte, 32)), chainCode: sum[32:]}, nil
+}
+
+// Derive derives the key at path relative to k, k must be the master key
+func (k *Key) Derive(path string) (*Key, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indexes {
+		key, err = key.Child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PrivateKeyHex returns the hex encoded private key
+func (k *Key) PrivateKeyHex() string {
+	return hex.EncodeToString(k.privateKey)
+}
+
+// ParsePath parses a path such as m/44'/60'/0'/0/0, hardened indexes are suffixed with ' or h
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, ErrInvalidPath
+	}
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		var offset uint32
+		if strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") {
+			offset = HardenedOffset
+			part = part[:len(part)-1]
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, ErrInvalidPath
+		}
+		indexes = append(indexes, uint32(index)+offset)
+	}
+	return indexes, nil
+}
+
+// DeriveEthPrivateKey derives the hex private key of the i'th ethereum account from seed
+func DeriveEthPrivateKey(seed []byte, index uint32) (string, error) {
+	masterKey, err := NewMasterKey(seed)
+	if err != nil {
+		return "", err
+	}
+	key, err := masterKey.Derive(EthAccountPath(index))
+	if err != nil {
+		return "", err
+	}
+	return key.PrivateKeyHex(), nil
+}
+
+func isValidPrivateKey(key []byte) bool {
+	k := new(big.Int).SetBytes(key)
+	return k.Sign() > 0 && k.Cmp(crypto.S256().Params().N) < 0
+}
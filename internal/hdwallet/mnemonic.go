@@ -0,0 +1,140 @@
+// Package hdwallet implements BIP-39 mnemonics and BIP-32 hierarchical deterministic key derivation
+package hdwallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+)
+
+// DefaultEntropyBits generates a mnemonic of 12 words
+const DefaultEntropyBits = 128
+
+var (
+	ErrInvalidEntropyBits = errors.New("entropy bits must be a multiple of 32 between 128 and 256")
+	ErrInvalidMnemonic    = errors.New("invalid mnemonic")
+	ErrInvalidChecksum    = errors.New("invalid mnemonic checksum")
+)
+
+//go:embed english.txt
+var englishWordList string
+
+// wordList is the BIP-39 english word list
+var wordList = strings.Fields(englishWordList)
+
+// wordIndex maps each word of wordList to its index
+var wordIndex = func() map[string]int {
+	index := make(map[string]int, len(wordList))
+	for i, word := range wordList {
+		index[word] = i
+	}
+	return index
+}()
+
+// NewEntropy returns random entropy of given bits
+func NewEntropy(bits int) ([]byte, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return nil, ErrInvalidEntropyBits
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// NewMnemonic returns a random mnemonic of DefaultEntropyBits
+func NewMnemonic() (string, error) {
+	entropy, err := NewEntropy(DefaultEntropyBits)
+	if err != nil {
+		return "", err
+	}
+	return MnemonicFromEntropy(entropy)
+}
+
+// MnemonicFromEntropy encodes entropy and its checksum as words, each word holds 11 bits
+func MnemonicFromEntropy(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", ErrInvalidEntropyBits
+	}
+	checksum := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), checksum[0])
+	wordsCount := (bits + bits/32) / 11
+	words := make([]string, wordsCount)
+	for i := range words {
+		words[i] = wordList[readBits(data, i*11, 11)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// EntropyFromMnemonic decodes the mnemonic and verifies its checksum
+func EntropyFromMnemonic(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, ErrInvalidMnemonic
+	}
+	totalBits := len(words) * 11
+	checksumBits := totalBits / 33
+	entropyBits := totalBits - checksumBits
+	data := make([]byte, (totalBits+7)/8)
+	for i, word := range words {
+		index, ok := wordIndex[strings.ToLower(word)]
+		if !ok {
+			return nil, ErrInvalidMnemonic
+		}
+		writeBits(data, i*11, 11, index)
+	}
+	entropy := data[:entropyBits/8]
+	checksum := sha256.Sum256(entropy)
+	if readBits(data, entropyBits, checksumBits) != readBits(checksum[:], 0, checksumBits) {
+		return nil, ErrInvalidChecksum
+	}
+	return append([]byte{}, entropy...), nil
+}
+
+// ValidateMnemonic returns an error if mnemonic has unknown words or an invalid checksum
+func ValidateMnemonic(mnemonic string) error {
+	_, err := EntropyFromMnemonic(mnemonic)
+	return err
+}
+
+// NormalizeMnemonic lower cases the words and separates them by a single space
+func NormalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+}
+
+// NewSeed returns the 64 bytes BIP-39 seed of mnemonic, passphrase is optional
+func NewSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	password := norm.NFKD.String(NormalizeMnemonic(mnemonic))
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+	return pbkdf2.Key([]byte(password), []byte(salt), 2048, 64, sha512.New), nil
+}
+
+// readBits reads count bits of data starting at bit offset, most significant bit first
+func readBits(data []byte, offset, count int) int {
+	value := 0
+	for i := offset; i < offset+count; i++ {
+		bit := (data[i/8] >> (7 - uint(i%8))) & 1
+		value = value<<1 | int(bit)
+	}
+	return value
+}
+
+// writeBits writes the lowest count bits of value into data starting at bit offset
+func writeBits(data []byte, offset, count, value int) {
+	for i := 0; i < count; i++ {
+		if value>>(count-1-i)&1 == 1 {
+			pos := offset + i
+			data[pos/8] |= 1 << (7 - uint(pos%8))
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package keystore reads and writes the Web3 Secret Storage (keystore v3) format used by ethereum
+// wallets, on top of go-ethereum's keystore
+// https://ethereum.org/en/developers/docs/data-structures-and-encoding/web3-secret-storage/
+package keystore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	gethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+const (
+	version = 3
+
+	// StandardScryptN and StandardScryptP are the scrypt parameters used by geth for new keys
+	StandardScryptN = gethkeystore.StandardScryptN
+	StandardScryptP = gethkeystore.StandardScryptP
+
+	kdfScrypt = "scrypt"
+	kdfPBKDF2 = "pbkdf2"
+	prfSHA256 = "hmac-sha256"
+	cipherAES = "aes-128-ctr"
+	dkLen     = 32
+
+	// maxScryptMemory bounds the memory scrypt takes, 128·N·r bytes, for the params of a keystore
+	maxScryptMemory = 256 << 20
+	maxScryptP      = 16
+	maxPBKDF2C      = 1 << 22
+)
+
+var (
+	ErrInvalidKeystore    = errors.New("invalid keystore file")
+	ErrUnsupportedVersion = errors.New("unsupported keystore version")
+	ErrUnsupportedKDF     = errors.New("unsupported keystore kdf")
+	ErrUnsupportedCipher  = errors.New("unsupported keystore cipher")
+	ErrKDFParams          = errors.New("keystore kdf params are out of the supported range")
+	ErrDecrypt            = errors.New("could not decrypt keystore with given passphrase")
+	ErrAddressMismatch    = errors.New("keystore address doesn't match its private key")
+)
+
+// keyJSON holds the fields of a keystore json checked before it's handed to go-ethereum
+type keyJSON struct {
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+	Version int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher     string        `json:"cipher"`
+	CipherText string        `json:"ciphertext"`
+	KDF        string        `json:"kdf"`
+	KDFParams  kdfParamsJSON `json:"kdfparams"`
+}
+
+// kdfParamsJSON holds the params of both scrypt (n, r, p) and pbkdf2 (c, prf)
+type kdfParamsJSON struct {
+	DKLen int    `json:"dklen"`
+	N     int    `json:"n,omitempty"`
+	R     int    `json:"r,omitempty"`
+	P     int    `json:"p,omitempty"`
+	C     int    `json:"c,omitempty"`
+	PRF   string `json:"prf,omitempty"`
+}
+
+// IsKeystore reports whether data looks like a keystore json, it doesn't validate it
+func IsKeystore(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return false
+	}
+	var k keyJSON
+	return json.Unmarshal(data, &k) == nil && k.Crypto.CipherText != ""
+}
+
+// Decrypt decrypts the keystore json with passphrase and returns the hex private key. The kdf
+// params are checked first so that a crafted keystore can't make the key derivation exhaust
+// the memory or the cpu.
+func Decrypt(data []byte, passphrase string) (pvtKeyHex string, err error) {
+	var k keyJSON
+	if err = json.Unmarshal(data, &k); err != nil {
+		return "", ErrInvalidKeystore
+	}
+	if k.Version != version {
+		return "", ErrUnsupportedVersion
+	}
+	if k.Crypto.Cipher != cipherAES {
+		return "", ErrUnsupportedCipher
+	}
+	if err = validateKDF(k.Crypto); err != nil {
+		return "", err
+	}
+	key, err := gethkeystore.DecryptKey(data, passphrase)
+	if errors.Is(err, gethkeystore.ErrDecrypt) {
+		return "", ErrDecrypt
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidKeystore, err)
+	}
+	if k.Address != "" {
+		address := key.Address.Hex()
+		if !strings.EqualFold(strings.TrimPrefix(k.Address, "0x"), strings.TrimPrefix(address, "0x")) {
+			return "", ErrAddressMismatch
+		}
+	}
+	return hex.EncodeToString(crypto.FromECDSA(key.PrivateKey)), nil
+}
+
+// Encrypt encrypts the hex private key with passphrase using scrypt and returns the keystore json
+func Encrypt(pvtKeyHex, passphrase string, scryptN, scryptP int) ([]byte, error) {
+	ecdsaKey, err := crypto.HexToECDSA(pvtKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	key := &gethkeystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(ecdsaKey.PublicKey),
+		PrivateKey: ecdsaKey,
+	}
+	return gethkeystore.EncryptKey(key, passphrase, scryptN, scryptP)
+}
+
+// FileName returns the geth style file name of keystore, UTC--<created at>--<address>
+func FileName(address string, createdAt time.Time) string {
+	address = strings.ToLower(strings.TrimPrefix(address, "0x"))
+	return fmt.Sprintf("UTC--%s--%s", createdAt.UTC().Format("2006-01-02T15-04-05.000000000Z"), address)
+}
+
+// validateKDF checks the kdf params of c against the ones a wallet could have used
+func validateKDF(c cryptoJSON) error {
+	params := c.KDFParams
+	if params.DKLen != dkLen {
+		return ErrKDFParams
+	}
+	switch c.KDF {
+	case kdfScrypt:
+		n, r, p := params.N, params.R, params.P
+		if n <= 1 || n&(n-1) != 0 || r <= 0 || p <= 0 || p > maxScryptP {
+			return ErrKDFParams
+		}
+		if r > maxScryptMemory/128 || n > maxScryptMemory/(128*r) {
+			return ErrKDFParams
+		}
+		return nil
+	case kdfPBKDF2:
+		if params.PRF != prfSHA256 {
+			return fmt.Errorf("%w: prf %s", ErrUnsupportedKDF, params.PRF)
+		}
+		if params.C <= 0 || params.C > maxPBKDF2C {
+			return ErrKDFParams
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedKDF, c.KDF)
+}
@@ -5,14 +5,59 @@ import (
 	"time"
 )
 
+// Identity algorithms of the chat identity of an account, the identity key is derived from the
+// ethereum private key of the account on the curve of the algorithm
+const (
+	IdentityECDSA     = "ecdsa"
+	IdentitySecp256k1 = "secp256k1"
+	IdentityEd25519   = "ed25519"
+)
+
+// IdentityAlgorithms are the algorithms of the chat identity an account can be created with
+var IdentityAlgorithms = []string{IdentityECDSA, IdentitySecp256k1, IdentityEd25519}
+
 type Account struct {
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	PrivateKey   string
+	KeyEncrypted bool   // PrivateKey is encrypted with the user password
+	HDPath       string // HDPath is the derivation path of HD accounts, empty for imported keys
+	HDSeedID     string // HDSeedID is the ID of the HDSeed of HD accounts, empty for imported keys
 	PublicImage  []byte
 	PrivateImage []byte
 	PublicKey    string
 	EthAddress   string
+	// IdentityAlgorithm is the algorithm of the chat identity, empty for the accounts created
+	// before the algorithm was chosen which are ECDSA
+	IdentityAlgorithm string
+	// DisplayName, Status and PublicImage are the profile of the account shown to its contacts,
+	// ProfileUpdatedAt is when the user last changed them
+	DisplayName      string
+	Status           string
+	ProfileUpdatedAt time.Time
+	// DeviceKey is the encrypted private key of the device sub-key of the account on this
+	// device, it's the libp2p identity of a linked device instead of the account key so that
+	// the devices of the account don't share a peer.ID. It's empty on the primary device.
+	DeviceKey       string
+	DevicePublicKey string
+}
+
+// IsLinkedDevice returns true if this device chats with its device sub-key
+func (a *Account) IsLinkedDevice() bool {
+	return a.DevicePublicKey != ""
+}
+
+// Profile returns the profile of the account shown to its contacts
+func (a *Account) Profile() Profile {
+	return Profile{Name: a.DisplayName, Status: a.Status, Avatar: a.PublicImage, UpdatedAt: a.ProfileUpdatedAt}
+}
+
+// Algorithm returns the algorithm of the chat identity of the account
+func (a *Account) Algorithm() string {
+	if a.IdentityAlgorithm == "" {
+		return IdentityECDSA
+	}
+	return a.IdentityAlgorithm
 }
 
 func (a *Account) GetDBFullKey() (key string, err error) {
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// BlockedContact is a public key the account refuses to chat with, the chat streams of the key
+// are reset and its messages are dropped
+type BlockedContact struct {
+	AccountPublicKey string
+	PublicKey        string
+	BlockedAt        time.Time
+}
+
+func (b *BlockedContact) GetDBFullKey() (key string, err error) {
+	if len(b.AccountPublicKey) == 0 || len(b.PublicKey) == 0 {
+		return key, ErrInvalidBlockedContact
+	}
+	key = fmt.Sprintf("%s%s%s", b.GetDBPrefixKey(), KeySeparator, b.PublicKey)
+	return key, nil
+}
+
+func (b *BlockedContact) GetDBPrefixKey() (key string) {
+	return fmt.Sprintf("%s%s%s", KeyPrefixBlockedContacts, KeySeparator, b.AccountPublicKey)
+}
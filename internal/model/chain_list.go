@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// ChainList is the chain list bundle merged by the user, in the json format of chainlist's
+// chains.json. It's merged again on unlock, there's only one ChainList record in the database.
+type ChainList struct {
+	UpdatedAt time.Time
+	Data      []byte
+}
+
+func (c *ChainList) GetDBFullKey() string {
+	return KeyPrefixChainList
+}
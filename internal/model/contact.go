@@ -12,6 +12,56 @@ type Contact struct {
 	Identified       bool
 	PublicKey        string
 	AccountPublicKey string
+	// EthAddress is the ethereum address the contact was resolved from, empty if the contact
+	// was added by public key
+	EthAddress string
+	// ENSName is the ens name the contact was resolved from or the verified primary name of
+	// EthAddress
+	ENSName string
+	// VerifiedAt is when the user verified PublicKey in person by comparing the safety number,
+	// zero if the contact isn't verified. A contact is keyed by its public key, hence a new key
+	// of the same person is another contact which isn't verified.
+	VerifiedAt time.Time
+	// Request is true while the contact is a request of an unknown sender, its messages are
+	// quarantined in the requests inbox until the user accepts it
+	Request bool
+	// PreviousKey is the public key of another contact with the same EthAddress, it's set when
+	// the person behind EthAddress chats with a new key until the user verifies the new key
+	PreviousKey string
+	// ProfileName and ProfileStatus are from the signed profile of the contact, its avatar is
+	// cached in Avatar. LocalName is the name the user gave to the contact, it overrides the
+	// name of the profile.
+	ProfileName      string
+	ProfileStatus    string
+	ProfileUpdatedAt time.Time
+	LocalName        string
+}
+
+// DisplayName returns the name of the contact shown to the user, the local name first then the
+// name of its profile, its ens name, its ethereum address and finally its public key
+func (c *Contact) DisplayName() string {
+	switch {
+	case c.LocalName != "":
+		return c.LocalName
+	case c.ProfileName != "":
+		return c.ProfileName
+	case c.ENSName != "":
+		return c.ENSName
+	case c.EthAddress != "":
+		return c.EthAddress
+	}
+	return c.PublicKey
+}
+
+// IsVerified returns true if the user verified the current public key of the contact
+func (c *Contact) IsVerified() bool {
+	return !c.VerifiedAt.IsZero()
+}
+
+// KeyChanged returns true if the ethereum address of the contact was known with another key
+// and the new key isn't verified yet
+func (c *Contact) KeyChanged() bool {
+	return c.PreviousKey != "" && c.PreviousKey != c.PublicKey && !c.IsVerified()
 }
 
 func (c *Contact) GetDBFullKey() (key string, err error) {
@@ -21,7 +71,7 @@ func (c *Contact) GetDBFullKey() (key string, err error) {
 	updatedTime := c.UpdatedAt.UnixNano()
 	createdTime := c.CreatedAt.UnixNano()
 	key = fmt.Sprintf("%s%s%s%s%s%s%d%s%d",
-		KeyPrefixContacts,
+		c.keyPrefix(),
 		KeySeparator, c.AccountPublicKey,
 		KeySeparator, c.PublicKey,
 		KeySeparator, updatedTime,
@@ -31,7 +81,7 @@ func (c *Contact) GetDBFullKey() (key string, err error) {
 }
 
 func (c *Contact) GetDBPrefixKey() (key string) {
-	key = KeyPrefixContacts
+	key = c.keyPrefix()
 	if len(c.AccountPublicKey) == 0 {
 		return key
 	}
@@ -50,3 +100,11 @@ func (c *Contact) GetDBPrefixKey() (key string) {
 	key = fmt.Sprintf("%s%s%d", key, KeySeparator, c.CreatedAt.UnixNano())
 	return key
 }
+
+// keyPrefix returns the key prefix of the contacts or of the contact requests
+func (c *Contact) keyPrefix() string {
+	if c.Request {
+		return KeyPrefixContactRequests
+	}
+	return KeyPrefixContacts
+}
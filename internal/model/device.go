@@ -0,0 +1,49 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Device is a device of the account of OwnerPublicKey, its libp2p key DevicePublicKey is
+// certified by the identity key of the owner. The devices of an account share the account key
+// for the messages and chat as separate peers with their device keys. AccountPublicKey is the
+// local account which saved the device, the owner is itself for its own devices.
+type Device struct {
+	AccountPublicKey string
+	OwnerPublicKey   string
+	DevicePublicKey  string
+	Name             string
+	CreatedAt        time.Time
+	// RevokedAt is set when the owner removes the device, a revoked device is never delivered to
+	RevokedAt time.Time
+	// Signature is the signature of CertificateMessage by the identity key of the owner
+	Signature []byte
+}
+
+// IsRevoked returns true if the owner removed the device
+func (d *Device) IsRevoked() bool {
+	return !d.RevokedAt.IsZero()
+}
+
+// CertificateMessage returns the bytes signed by the identity key of the owner
+func (d *Device) CertificateMessage() []byte {
+	var revokedAt int64
+	if d.IsRevoked() {
+		revokedAt = d.RevokedAt.UnixNano()
+	}
+	return []byte(fmt.Sprintf("Protonet device\nOwner: %s\nDevice: %s\nName: %q\nCreated At: %d\nRevoked At: %d",
+		d.OwnerPublicKey, d.DevicePublicKey, d.Name, d.CreatedAt.UnixNano(), revokedAt))
+}
+
+func (d *Device) GetDBFullKey() (key string, err error) {
+	if len(d.AccountPublicKey) == 0 || len(d.OwnerPublicKey) == 0 || len(d.DevicePublicKey) == 0 {
+		return key, ErrInvalidDevice
+	}
+	key = fmt.Sprintf("%s%s%s", d.GetDBPrefixKey(), KeySeparator, d.DevicePublicKey)
+	return key, nil
+}
+
+func (d *Device) GetDBPrefixKey() (key string) {
+	return fmt.Sprintf("%s%s%s", KeyPrefixDevices, KeySeparator, d.AccountPublicKey)
+}
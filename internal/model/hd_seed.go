@@ -0,0 +1,30 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// HDSeed is the BIP-39 seed from which the HD accounts are derived. The seed new accounts are
+// derived from is the current seed, every seed is also saved under its ID so that the accounts of
+// a previous seed keep their mnemonic. Mnemonic and Seed are hex encoded and encrypted with the
+// user password
+type HDSeed struct {
+	// ID is the fingerprint of the seed
+	ID        string
+	CreatedAt time.Time
+	Mnemonic  string
+	Seed      string
+	// NextIndex is the index of the next account to be derived along m/44'/60'/0'/0
+	NextIndex uint32
+}
+
+// GetDBFullKey returns the key of the current seed
+func (s *HDSeed) GetDBFullKey() string {
+	return KeyPrefixHDSeed
+}
+
+// GetDBIDKey returns the key of the seed of ID
+func (s *HDSeed) GetDBIDKey() string {
+	return fmt.Sprintf("%s%s%s", KeyPrefixHDSeed, KeySeparator, s.ID)
+}
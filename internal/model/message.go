@@ -19,6 +19,14 @@ type Message struct {
 	Text      string
 	Sign      []byte
 	Audio     []byte
+	// Payment is set if the message is a payment to the Recipient
+	Payment *Payment
+	// PaymentRequest is set if the message requests a payment from the Recipient
+	PaymentRequest *PaymentRequest
+	// SafeTx is set if the message proposes a transaction of a Safe to the Recipient
+	SafeTx *SafeTx
+	// SafeApproval is set if the message approves a proposed transaction of a Safe
+	SafeApproval *SafeApproval
 	// Holds the read state of the Recipient, this is the only field that can be different
 	// between sender-and-receiver, and it's the only field that can be changed and is always
 	// decided by the recipient
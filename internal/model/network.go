@@ -0,0 +1,50 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Network holds the user's additions to a chain, ChainID is in decimal. A custom chain added by
+// the user has Custom set and its details, otherwise RPCs are the endpoints added to an
+// embedded chain.
+type Network struct {
+	CreatedAt   time.Time
+	ChainID     string
+	Custom      bool
+	Name        string
+	ShortName   string
+	Symbol      string
+	Decimals    int
+	ExplorerURL string
+	RPCs        []string
+}
+
+func (n *Network) GetDBFullKey() (key string, err error) {
+	if len(n.ChainID) == 0 {
+		return key, ErrInvalidNetwork
+	}
+	return fmt.Sprintf("%s%s", n.GetDBPrefixKey(), n.ChainID), nil
+}
+
+func (n *Network) GetDBPrefixKey() (key string) {
+	return fmt.Sprintf("%s%s", KeyPrefixNetworks, KeySeparator)
+}
+
+// APIKey is the api key of a templated rpc url, KeyEnc is hex encoded and encrypted with the
+// wallet password
+type APIKey struct {
+	RPC    string
+	KeyEnc string
+}
+
+func (a *APIKey) GetDBFullKey() (key string, err error) {
+	if len(a.RPC) == 0 {
+		return key, ErrInvalidAPIKey
+	}
+	return fmt.Sprintf("%s%s", a.GetDBPrefixKey(), a.RPC), nil
+}
+
+func (a *APIKey) GetDBPrefixKey() (key string) {
+	return fmt.Sprintf("%s%s", KeyPrefixAPIKeys, KeySeparator)
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	PaymentStatusPending = iota
+	PaymentStatusConfirmed
+	PaymentStatusFailed
+	// PaymentStatusInvalid is the status of a payment which doesn't match its transaction
+	PaymentStatusInvalid
+	// PaymentStatusDuplicate is the status of a payment whose transfer is claimed by the payment
+	// of another message
+	PaymentStatusDuplicate
+)
+
+// Payment is a native or ERC-20 transfer sent within a conversation. Addresses are hex, ChainID,
+// Amount and LogIndex are in decimal, Amount is in the smallest unit of the currency and
+// TokenAddress is empty for the native currency. Status is verified on chain by each side of the
+// conversation, the status claimed by the peer isn't trusted.
+type Payment struct {
+	ChainID      string
+	TokenAddress string
+	Symbol       string
+	Decimals     int
+	Amount       string
+	TxHash       string
+	From         string
+	To           string
+	Status       int
+	// RequestID is the id of the message with the PaymentRequest paid by the payment
+	RequestID string
+	// LogIndex is the index of the Transfer log matched by a confirmed token payment, it's set
+	// locally by the verification
+	LogIndex string
+}
+
+// PaymentClaim records the message whose payment claimed a transfer, so that one transfer can't
+// confirm the payments of several messages. A transfer is identified by its chain, transaction
+// hash and the index of its Transfer log, LogIndex is empty for a native transfer.
+type PaymentClaim struct {
+	ChainID   string
+	TxHash    string
+	LogIndex  string
+	MessageID string
+}
+
+func (c *PaymentClaim) GetDBFullKey(accountPublicKey string) (key string, err error) {
+	if len(accountPublicKey) == 0 || len(c.ChainID) == 0 || len(c.TxHash) == 0 || len(c.MessageID) == 0 {
+		return key, ErrInvalidPaymentClaim
+	}
+	logIndex := c.LogIndex
+	if logIndex == "" {
+		logIndex = "native"
+	}
+	return c.GetDBPrefixKey(accountPublicKey) + logIndex, nil
+}
+
+// GetDBPrefixKey returns the prefix of the keys of the claims of the transaction
+func (c *PaymentClaim) GetDBPrefixKey(accountPublicKey string) (key string) {
+	return fmt.Sprintf("%s%s%s%s%s%s%s%s",
+		KeyPrefixPaymentClaims,
+		KeySeparator, accountPublicKey,
+		KeySeparator, c.ChainID,
+		KeySeparator, strings.ToLower(c.TxHash),
+		KeySeparator,
+	)
+}
+
+// PaymentRequest asks the recipient of the message to pay Amount to To, it's rendered as an
+// EIP-681 uri. Signature is the EIP-191 signature of the request by the key of To, it binds To
+// to the chat public key of the requester. Symbol and Decimals aren't signed, the request is shown
+// and paid in the currency read from the chain.
+type PaymentRequest struct {
+	ChainID      string
+	TokenAddress string
+	Symbol       string
+	Decimals     int
+	Amount       string
+	To           string
+	Memo         string
+	ExpiresAt    time.Time
+	Signature    []byte
+}
+
+// IsExpired reports whether the request can't be paid anymore, a request without expiry
+// doesn't expire
+func (r *PaymentRequest) IsExpired() bool {
+	return !r.ExpiresAt.IsZero() && time.Now().After(r.ExpiresAt)
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxProfileNameLength   = 64
+	MaxProfileStatusLength = 140
+	// MaxProfileAvatarSize is the size of the largest encoded avatar of a profile
+	MaxProfileAvatarSize = 96 << 10
+)
+
+var (
+	ErrProfileNameTooLong   = errors.New("name is too long")
+	ErrProfileStatusTooLong = errors.New("status is too long")
+	ErrProfileAvatarTooLong = errors.New("avatar is too large")
+)
+
+// Profile is what an account shows of itself to its contacts, it's exchanged signed by the
+// identity key of the account and cached on the contacts
+type Profile struct {
+	Name      string
+	Status    string
+	Avatar    []byte
+	UpdatedAt time.Time
+}
+
+// Validate checks the limits of the fields of the profile
+func (p *Profile) Validate() error {
+	if utf8.RuneCountInString(p.Name) > MaxProfileNameLength {
+		return ErrProfileNameTooLong
+	}
+	if utf8.RuneCountInString(p.Status) > MaxProfileStatusLength {
+		return ErrProfileStatusTooLong
+	}
+	if len(p.Avatar) > MaxProfileAvatarSize {
+		return ErrProfileAvatarTooLong
+	}
+	return nil
+}
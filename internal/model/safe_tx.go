@@ -0,0 +1,44 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SafeTx is a call of a Safe multisig proposed to its owners in a conversation. ChainID, Value
+// and Nonce are in decimal, addresses and Hash are in hex and Value is in the smallest unit of
+// the native currency. Hash is the safeTxHash computed locally from the other fields, it's
+// never taken from the peer. Signatures are the EIP-712 signatures of Hash by the hex address
+// of their signer, the signer is recovered from the signature itself.
+type SafeTx struct {
+	CreatedAt  time.Time
+	Hash       string
+	ChainID    string
+	Safe       string
+	To         string
+	Value      string
+	Data       []byte
+	Nonce      string
+	Memo       string
+	Signatures map[string][]byte
+	// TxHash is the hash of the transaction executing the safe transaction, if it's executed
+	// from this wallet
+	TxHash string
+}
+
+// SafeApproval is the signature of the safe transaction of Hash by Owner, sent back to the
+// other owners in a conversation
+type SafeApproval struct {
+	Hash      string
+	Owner     string
+	Signature []byte
+}
+
+func (t *SafeTx) GetDBFullKey() (key string, err error) {
+	if len(t.Hash) == 0 {
+		return key, ErrInvalidSafeTx
+	}
+	key = fmt.Sprintf("%s%s%s", KeyPrefixSafeTxs, KeySeparator, strings.ToLower(t.Hash))
+	return key, nil
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+const DefaultAutoLockTimeout = time.Minute * 15
+
+// Settings holds the user preferences, there's only one Settings record in the database
+type Settings struct {
+	// AutoLockTimeout is the inactivity duration after which the wallet is locked, zero means never
+	AutoLockTimeout time.Duration
+	// FavoriteChains are the chain ids in decimal of the chains marked favorite
+	FavoriteChains []string
+	// FavoriteRPCs are the rpc urls marked favorite
+	FavoriteRPCs []string
+	// WalletConnectRelayURL is the relay connecting dApps, empty for the public relay
+	WalletConnectRelayURL string
+	// WalletConnectProjectID is the project id required by the public relay
+	WalletConnectProjectID string
+	// DisplayCurrency is the fiat currency of the portfolio value, empty for USD
+	DisplayCurrency string
+}
+
+func NewSettings() Settings {
+	return Settings{AutoLockTimeout: DefaultAutoLockTimeout}
+}
+
+func (s *Settings) GetDBFullKey() string {
+	return KeyPrefixSettings
+}
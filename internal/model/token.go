@@ -0,0 +1,37 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Token is an ERC-20 token added by the user, ChainID is in decimal and Address in hex
+type Token struct {
+	CreatedAt time.Time
+	ChainID   string
+	Address   string
+	Name      string
+	Symbol    string
+	Decimals  int
+}
+
+func (t *Token) GetDBFullKey() (key string, err error) {
+	if len(t.ChainID) == 0 || len(t.Address) == 0 {
+		return key, ErrInvalidToken
+	}
+	key = fmt.Sprintf("%s%s%s%s%s",
+		KeyPrefixTokens,
+		KeySeparator, t.ChainID,
+		KeySeparator, strings.ToLower(t.Address),
+	)
+	return key, nil
+}
+
+func (t *Token) GetDBPrefixKey() (key string) {
+	key = KeyPrefixTokens
+	if len(t.ChainID) == 0 {
+		return key
+	}
+	return fmt.Sprintf("%s%s%s%s", key, KeySeparator, t.ChainID, KeySeparator)
+}
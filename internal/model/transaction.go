@@ -0,0 +1,84 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TransactionStatusPending = iota
+	TransactionStatusConfirmed
+	TransactionStatusFailed
+)
+
+const (
+	TransactionOutgoing = iota
+	TransactionIncoming
+)
+
+// Transaction is a native or ERC-20 transfer sent or received by an account. Addresses are hex,
+// ChainID, Value and LogIndex are in decimal, Value is in the smallest unit of the currency.
+// TokenAddress is empty for the native currency. LogIndex is the index of the Transfer log of a
+// received token transfer, a transaction may emit several of them, it's empty otherwise.
+type Transaction struct {
+	CreatedAt      time.Time
+	ChainID        string
+	AccountAddress string
+	Hash           string
+	From           string
+	To             string
+	Value          string
+	TokenAddress   string
+	Symbol         string
+	Decimals       int
+	Direction      int
+	Status         int
+	BlockNumber    uint64
+	LogIndex       string
+}
+
+// GetDBFullKey returns the key of the transaction, received token transfers are keyed by their
+// log index and token besides the hash
+func (t *Transaction) GetDBFullKey() (key string, err error) {
+	if len(t.ChainID) == 0 || len(t.AccountAddress) == 0 || len(t.Hash) == 0 {
+		return key, ErrInvalidTransaction
+	}
+	key = fmt.Sprintf("%s%s", t.GetDBPrefixKey(), strings.ToLower(t.Hash))
+	if t.LogIndex != "" {
+		key = fmt.Sprintf("%s%s%s%s%s", key, KeySeparator, t.LogIndex, KeySeparator, strings.ToLower(t.TokenAddress))
+	}
+	return key, nil
+}
+
+func (t *Transaction) GetDBPrefixKey() (key string) {
+	return transactionsPrefixKey(t.ChainID, t.AccountAddress)
+}
+
+// TransactionScanState is the last block scanned for incoming transfers of an account
+type TransactionScanState struct {
+	ChainID        string
+	AccountAddress string
+	LastBlock      uint64
+}
+
+func (s *TransactionScanState) GetDBFullKey() (key string, err error) {
+	if len(s.ChainID) == 0 || len(s.AccountAddress) == 0 {
+		return key, ErrInvalidTransaction
+	}
+	key = fmt.Sprintf("%s%s%s%s%s",
+		KeyPrefixTransactionScans,
+		KeySeparator, s.ChainID,
+		KeySeparator, strings.ToLower(s.AccountAddress),
+	)
+	return key, nil
+}
+
+func transactionsPrefixKey(chainID, accountAddress string) string {
+	return fmt.Sprintf("%s%s%s%s%s%s",
+		KeyPrefixTransactions,
+		KeySeparator, chainID,
+		KeySeparator, strings.ToLower(accountAddress),
+		KeySeparator,
+	)
+}
@@ -5,8 +5,29 @@ import "errors"
 var ErrInvalidAccount = errors.New("invalid account")
 var ErrInvalidMessage = errors.New("invalid message")
 var ErrInvalidContact = errors.New("invalid contact")
+var ErrInvalidBlockedContact = errors.New("invalid blocked contact")
+var ErrInvalidToken = errors.New("invalid token")
+var ErrInvalidTransaction = errors.New("invalid transaction")
+var ErrInvalidNetwork = errors.New("invalid network")
+var ErrInvalidAPIKey = errors.New("invalid api key")
+var ErrInvalidSafeTx = errors.New("invalid safe transaction")
+var ErrInvalidDevice = errors.New("invalid device")
+var ErrInvalidPaymentClaim = errors.New("invalid payment claim")
 
 const KeySeparator = "[]"
 const KeyPrefixAccounts = "accounts"
 const KeyPrefixMessages = "messages"
 const KeyPrefixContacts = "contacts"
+const KeyPrefixContactRequests = "contactrequests"
+const KeyPrefixBlockedContacts = "blockedcontacts"
+const KeyPrefixSettings = "settings"
+const KeyPrefixHDSeed = "hdseed"
+const KeyPrefixTokens = "tokens"
+const KeyPrefixTransactions = "transactions"
+const KeyPrefixTransactionScans = "txscans"
+const KeyPrefixNetworks = "networks"
+const KeyPrefixAPIKeys = "apikeys"
+const KeyPrefixChainList = "chainlist"
+const KeyPrefixSafeTxs = "safetxs"
+const KeyPrefixDevices = "devices"
+const KeyPrefixPaymentClaims = "paymentclaims"
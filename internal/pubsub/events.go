@@ -2,8 +2,12 @@ package pubsub
 
 import (
 	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	model2 "github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/walletconnect"
 	"github.com/mearaj/protonet/utils"
+	"math/big"
 	"sync"
 )
 
@@ -23,6 +27,25 @@ const (
 	SaveContactTopic
 	NewMessageReceivedTopic
 	DatabaseOpened
+	WalletLockedEventTopic
+	WalletUnlockedEventTopic
+	SettingsChangedEventTopic
+	TransactionSentEventTopic
+	TransactionMinedEventTopic
+	TokensChangedEventTopic
+	TransactionsChangedEventTopic
+	RPCHealthChangedEventTopic
+	BalanceChangedEventTopic
+	IncomingTransferEventTopic
+	NetworksChangedEventTopic
+	DAppProposalEventTopic
+	DAppRequestEventTopic
+	DAppSessionsChangedEventTopic
+	SafeTxChangedEventTopic
+	ContactRequestsChangedEventTopic
+	BlockedContactsChangedEventTopic
+	PresenceChangedEventTopic
+	DevicesChangedEventTopic
 )
 
 var AllTopicsArr = [...]Topic{
@@ -39,9 +62,126 @@ var AllTopicsArr = [...]Topic{
 	SaveContactTopic,
 	NewMessageReceivedTopic,
 	DatabaseOpened,
+	WalletLockedEventTopic,
+	WalletUnlockedEventTopic,
+	SettingsChangedEventTopic,
+	TransactionSentEventTopic,
+	TransactionMinedEventTopic,
+	TokensChangedEventTopic,
+	TransactionsChangedEventTopic,
+	RPCHealthChangedEventTopic,
+	BalanceChangedEventTopic,
+	IncomingTransferEventTopic,
+	NetworksChangedEventTopic,
+	DAppProposalEventTopic,
+	DAppRequestEventTopic,
+	DAppSessionsChangedEventTopic,
+	SafeTxChangedEventTopic,
+	ContactRequestsChangedEventTopic,
+	BlockedContactsChangedEventTopic,
+	PresenceChangedEventTopic,
+	DevicesChangedEventTopic,
 }
 
 type DatabaseOpenedEventData struct{}
+type WalletLockedEventData struct{}
+type WalletUnlockedEventData struct{}
+type SettingsChangedEventData struct {
+	model2.Settings
+}
+type TransactionSentEventData struct {
+	ChainID *big.Int
+	Hash    common.Hash
+	From    common.Address
+	To      common.Address
+	Value   *big.Int
+}
+
+// TransactionMinedEventData is fired when a sent transaction is mined, Event.Err is set if it
+// failed or couldn't be tracked
+type TransactionMinedEventData struct {
+	ChainID *big.Int
+	Hash    common.Hash
+	From    common.Address
+	Receipt *types.Receipt
+}
+
+// TokensChangedEventData is fired when a token is added or removed, ChainID is in decimal
+type TokensChangedEventData struct {
+	ChainID string
+}
+
+// TransactionsChangedEventData is fired when a transaction of the history is added or updated
+type TransactionsChangedEventData struct {
+	ChainID        string
+	AccountAddress string
+}
+
+// RPCHealthChangedEventData is fired after the endpoints of a chain are probed, ChainID is in
+// decimal
+type RPCHealthChangedEventData struct {
+	ChainID string
+}
+
+// BalanceChangedEventData is fired when a live subscription sees the balance of an account
+// change, Token is nil for the native currency
+type BalanceChangedEventData struct {
+	ChainID *big.Int
+	Address common.Address
+	Token   *common.Address
+	Balance *big.Int
+}
+
+// IncomingTransferEventData is fired when a live subscription sees a transfer to an account,
+// Token is nil for the native currency, Value is in the smallest unit of the currency
+type IncomingTransferEventData struct {
+	ChainID  *big.Int
+	Hash     common.Hash
+	From     common.Address
+	To       common.Address
+	Token    *common.Address
+	Value    *big.Int
+	Symbol   string
+	Decimals int
+}
+
+// NetworksChangedEventData is fired when a custom chain or endpoint is added or removed, ChainID
+// is in decimal
+type NetworksChangedEventData struct {
+	ChainID string
+}
+
+// DAppProposalEventData is fired when a paired dApp asks to open a session with the wallet
+type DAppProposalEventData struct {
+	walletconnect.Proposal
+}
+
+// DAppRequestEventData is fired when a dApp calls a method needing the approval of the user
+type DAppRequestEventData struct {
+	walletconnect.Request
+}
+
+// DAppSessionsChangedEventData is fired when a dApp session is opened or closed
+type DAppSessionsChangedEventData struct{}
+
+// SafeTxChangedEventData is fired when a safe transaction is proposed, signed or executed
+type SafeTxChangedEventData struct {
+	model2.SafeTx
+}
+
+// ContactRequestsChangedEventData is fired when a contact request of the account is received,
+// accepted or deleted
+type ContactRequestsChangedEventData struct{ AccountPublicKey string }
+
+// BlockedContactsChangedEventData is fired when the account blocks or unblocks a public key
+type BlockedContactsChangedEventData struct{ AccountPublicKey string }
+
+// PresenceChangedEventData is fired when the contact of PublicKey goes online or offline, or
+// starts or stops typing, the presence isn't saved
+type PresenceChangedEventData struct{ PublicKey string }
+
+// DevicesChangedEventData is fired when the account saves a device of its own or of a contact
+type DevicesChangedEventData struct{ AccountPublicKey string }
 type AccountsChangedEventData struct{}
 type CurrentAccountChangedEventData struct {
 	PrevAccountPublicKey    string
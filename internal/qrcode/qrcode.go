@@ -0,0 +1,53 @@
+// Package qrcode encodes text as a QR code with error correction level M on top of
+// github.com/skip2/go-qrcode, payment uris fit in the small versions
+package qrcode
+
+import (
+	"errors"
+	"image"
+	"image/color"
+
+	goqrcode "github.com/skip2/go-qrcode"
+)
+
+var ErrTooLong = errors.New("text is too long for a qr code")
+
+// Code is an encoded QR code, modules are indexed by row then column and true is dark
+type Code struct {
+	Size    int
+	modules [][]bool
+}
+
+// Dark reports whether the module at column x and row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Image returns the code with each module scale pixels wide and a quiet zone of border
+// modules, the quiet zone must be at least 4 modules for scanners
+func (c *Code) Image(scale, border int) *image.Gray {
+	width := (c.Size + border*2) * scale
+	img := image.NewGray(image.Rect(0, 0, width, width))
+	for y := 0; y < width; y++ {
+		for x := 0; x < width; x++ {
+			mx, my := x/scale-border, y/scale-border
+			clr := color.Gray{Y: 0xff}
+			if mx >= 0 && my >= 0 && mx < c.Size && my < c.Size && c.modules[my][mx] {
+				clr = color.Gray{}
+			}
+			img.SetGray(x, y, clr)
+		}
+	}
+	return img
+}
+
+// Encode encodes text in the smallest version which holds it
+func Encode(text string) (*Code, error) {
+	q, err := goqrcode.New(text, goqrcode.Medium)
+	if err != nil {
+		return nil, ErrTooLong
+	}
+	q.DisableBorder = true
+	modules := q.Bitmap()
+	return &Code{Size: len(modules), modules: modules}, nil
+}
@@ -0,0 +1,119 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/db"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+)
+
+// MaxChainListSize limits the size of a chain list bundle, chainlist's chains.json is a few megabytes
+const MaxChainListSize = 32 << 20
+
+// LoadChainList merges the chain list bundle into the chain registry, the new chains are added
+// to the connections and the disconnected chains are updated. The bundle is saved and merged
+// again on unlock.
+func (w *Wallet) LoadChainList(data []byte) (added, updated int, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	if len(data) > MaxChainListSize {
+		return 0, 0, evm.ErrInvalidChainList
+	}
+	addedChains, updatedChains, err := evm.DefaultRegistry().MergeChainList(data)
+	if err != nil {
+		return 0, 0, err
+	}
+	w.applyChains(addedChains, updatedChains)
+	if err = w.saveChainList(data); err != nil {
+		return len(addedChains), len(updatedChains), err
+	}
+	w.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.NetworksChangedEventData{},
+		Topic: pubsub.NetworksChangedEventTopic,
+	})
+	return len(addedChains), len(updatedChains), nil
+}
+
+// loadChainList merges the saved chain list bundle, it's a no-op if none is saved
+func (w *Wallet) loadChainList() error {
+	chainList, err := w.ProtoDB.ChainList()
+	if errors.Is(err, db.ErrChainListNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	added, updated, err := evm.DefaultRegistry().MergeChainList(chainList.Data)
+	if err != nil {
+		return err
+	}
+	w.applyChains(added, updated)
+	return nil
+}
+
+// saveChainList merges the chains of data into the saved bundle, hence the chains of the
+// earlier bundles are kept
+func (w *Wallet) saveChainList(data []byte) error {
+	chains, err := evm.ParseChainList(data)
+	if err != nil {
+		return err
+	}
+	chainList, err := w.ProtoDB.ChainList()
+	if err != nil && !errors.Is(err, db.ErrChainListNotFound) {
+		return err
+	}
+	if err == nil {
+		savedChains, err := evm.ParseChainList(chainList.Data)
+		if err != nil {
+			return err
+		}
+		chainIDs := make(map[string]struct{}, len(chains))
+		for _, chain := range chains {
+			chainIDs[chain.ChainID.String()] = struct{}{}
+		}
+		for _, chain := range savedChains {
+			if _, ok := chainIDs[chain.ChainID.String()]; !ok {
+				chains = append(chains, chain)
+			}
+		}
+	}
+	data, err = json.Marshal(chains)
+	if err != nil {
+		return err
+	}
+	return w.ProtoDB.SaveChainList(&model.ChainList{Data: data})
+}
+
+// applyChains adds the connections of the added chains and updates the disconnected connections
+// of the updated chains, the custom rpc urls of a chain are kept
+func (w *Wallet) applyChains(added, updated []evm.Chain) {
+	for _, chain := range added {
+		// a custom network may use the chain id of a chain new to the registry
+		if _, ok := w.Connection(chain.ChainID.String()); ok {
+			continue
+		}
+		conn := evm.NewRPCClients(chain)
+		w.connectionsMutex.Lock()
+		w.connections = append(w.connections, conn)
+		w.connectionsMutex.Unlock()
+	}
+	for _, chain := range updated {
+		chainID := chain.ChainID.String()
+		conn, ok := w.Connection(chainID)
+		if !ok || w.IsCustomNetwork(chainID) || conn.IsConnected() || conn.IsProbing() {
+			continue
+		}
+		for _, rpc := range chain.RPC {
+			_, _ = conn.AddRPC(rpc)
+		}
+		if err := conn.UpdateChain(chain); err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}
+}
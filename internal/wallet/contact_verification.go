@@ -0,0 +1,92 @@
+package wallet
+
+import (
+	"errors"
+	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/model"
+	"strings"
+	"time"
+)
+
+var ErrSafetyNumberMismatch = errors.New("safety numbers don't match, the key of the contact may not be theirs")
+
+// ContactSafetyNumber returns the safety number of the conversation of the current account with
+// contact
+func (w *Wallet) ContactSafetyNumber(contact model.Contact) (string, error) {
+	account, err := w.Account()
+	if err != nil {
+		return "", err
+	}
+	return common.SafetyNumber(account.PublicKey, contact.PublicKey)
+}
+
+// VerifyContact marks the current key of contact as verified, code is the scanned QR code or the
+// safety number read by the contact, it must match the safety number of the conversation
+func (w *Wallet) VerifyContact(contact *model.Contact, code string) error {
+	scanned, err := common.ParseSafetyNumber(code)
+	if err != nil {
+		return err
+	}
+	safetyNumber, err := w.ContactSafetyNumber(*contact)
+	if err != nil {
+		return err
+	}
+	if scanned != safetyNumber {
+		return ErrSafetyNumberMismatch
+	}
+	return w.MarkContactVerified(contact)
+}
+
+// MarkContactVerified marks the current key of contact as verified after the user compared the
+// safety number with the contact
+func (w *Wallet) MarkContactVerified(contact *model.Contact) error {
+	saved, err := w.ProtoDB.Contact(contact.AccountPublicKey, contact.PublicKey)
+	if err != nil {
+		return err
+	}
+	saved.VerifiedAt = time.Now()
+	saved.PreviousKey = ""
+	if err = w.AddUpdateContact(&saved); err != nil {
+		return err
+	}
+	*contact = saved
+	return nil
+}
+
+// UnverifyContact clears the verification of contact
+func (w *Wallet) UnverifyContact(contact *model.Contact) error {
+	saved, err := w.ProtoDB.Contact(contact.AccountPublicKey, contact.PublicKey)
+	if err != nil {
+		return err
+	}
+	saved.VerifiedAt = time.Time{}
+	if err = w.AddUpdateContact(&saved); err != nil {
+		return err
+	}
+	*contact = saved
+	return nil
+}
+
+// CheckContactKey sets the PreviousKey of contact if another contact of its account has the same
+// ethereum address with a different key, which means the person behind the address now chats
+// with a new key. The verification of the previous key isn't carried over.
+func (w *Wallet) CheckContactKey(contact *model.Contact) error {
+	if contact.EthAddress == "" || contact.IsVerified() {
+		return nil
+	}
+	count, err := w.ProtoDB.ContactsCount(contact.AccountPublicKey)
+	if err != nil || count == 0 {
+		return err
+	}
+	contacts, err := w.ProtoDB.Contacts(contact.AccountPublicKey, 0, int(count))
+	if err != nil {
+		return err
+	}
+	for _, other := range contacts {
+		if other.PublicKey != contact.PublicKey && strings.EqualFold(other.EthAddress, contact.EthAddress) {
+			contact.PreviousKey = other.PublicKey
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"github.com/mearaj/protonet/internal/walletconnect"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+const eip155Namespace = "eip155"
+
+var (
+	// dAppMethods are the methods served to the dApps, each call is approved by the user
+	dAppMethods = []string{"eth_sendTransaction", "personal_sign", "eth_signTypedData", "eth_signTypedData_v4"}
+	dAppEvents  = []string{"chainChanged", "accountsChanged"}
+	dAppWallet  = walletconnect.Metadata{
+		Name:        "Protonet",
+		Description: "Protonet wallet and messenger",
+		URL:         "https://github.com/mearaj/protonet",
+		Icons:       []string{},
+	}
+)
+
+var (
+	ErrDAppChainsNotSupported  = errors.New("dApp requires chains which aren't supported")
+	ErrDAppMethodsNotSupported = errors.New("dApp requires methods which aren't supported")
+	ErrDAppAccountMismatch     = errors.New("dApp request isn't for the current account")
+	ErrDAppInvalidParams       = errors.New("invalid params of the dApp request")
+)
+
+// DAppCall is a decoded request of a dApp ready for approval, Sign is set for the signing
+// methods and Tx with its Conn for eth_sendTransaction. Sign is signed with Wallet.Sign and
+// returned with RespondDAppSignature, Tx is sent with SendDAppTransaction.
+type DAppCall struct {
+	walletconnect.Request
+	Sign *SignRequest
+	Conn *evm.RPCClients
+	Tx   *evm.PreparedTx
+}
+
+// dApps holds the WalletConnect client, it's created on first use with the relay of the settings
+// and closed when the wallet is locked
+type dApps struct {
+	client *walletconnect.Client
+	mutex  sync.Mutex
+}
+
+// walletConnect returns the WalletConnect client, connecting it to the relay if needed
+func (w *Wallet) walletConnect() (*walletconnect.Client, error) {
+	w.dApps.mutex.Lock()
+	defer w.dApps.mutex.Unlock()
+	if w.dApps.client != nil {
+		return w.dApps.client, nil
+	}
+	settings, err := w.Settings()
+	if err != nil {
+		return nil, err
+	}
+	w.dApps.client, err = walletconnect.NewClient(walletconnect.Options{
+		RelayURL:  settings.WalletConnectRelayURL,
+		ProjectID: settings.WalletConnectProjectID,
+		Metadata:  dAppWallet,
+		Handler:   dAppHandler{w},
+	})
+	return w.dApps.client, err
+}
+
+// closeWalletConnect disconnects from the relay, the sessions are dropped
+func (w *Wallet) closeWalletConnect() {
+	w.dApps.mutex.Lock()
+	defer w.dApps.mutex.Unlock()
+	if w.dApps.client == nil {
+		return
+	}
+	w.dApps.client.Close()
+	w.dApps.client = nil
+	w.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.DAppSessionsChangedEventData{},
+		Topic: pubsub.DAppSessionsChangedEventTopic,
+	})
+}
+
+// SetWalletConnectRelay saves the relay connecting the dApps, the current sessions are closed and
+// the relay is used from the next pairing
+func (w *Wallet) SetWalletConnectRelay(relayURL, projectID string) error {
+	settings, err := w.Settings()
+	if err != nil {
+		return err
+	}
+	settings.WalletConnectRelayURL = strings.TrimSpace(relayURL)
+	settings.WalletConnectProjectID = strings.TrimSpace(projectID)
+	if err = w.SaveSettings(&settings); err != nil {
+		return err
+	}
+	w.closeWalletConnect()
+	return nil
+}
+
+// PairDApp pairs with the dApp of the WalletConnect uri, its session proposal is fired as
+// pubsub.DAppProposalEventData
+func (w *Wallet) PairDApp(uri string) error {
+	client, err := w.walletConnect()
+	if err != nil {
+		return err
+	}
+	return client.Pair(context.Background(), uri)
+}
+
+// ApproveDApp opens the session of proposal with the current account on the chains required by
+// the dApp and on its optional chains which are known
+func (w *Wallet) ApproveDApp(proposal walletconnect.Proposal) error {
+	client, err := w.walletConnect()
+	if err != nil {
+		return err
+	}
+	account, err := w.Account()
+	if err != nil {
+		return err
+	}
+	namespace, err := dAppNamespace(proposal, common.HexToAddress(account.EthAddress))
+	ctx := context.Background()
+	if err != nil {
+		code := walletconnect.CodeUnsupportedChains
+		if errors.Is(err, ErrDAppMethodsNotSupported) {
+			code = walletconnect.CodeUnsupportedMethods
+		}
+		if rejectErr := client.Reject(ctx, proposal, code, err.Error()); rejectErr != nil {
+			return rejectErr
+		}
+		return err
+	}
+	_, err = client.Approve(ctx, proposal, map[string]walletconnect.Namespace{eip155Namespace: namespace})
+	return err
+}
+
+// RejectDApp rejects the session proposal
+func (w *Wallet) RejectDApp(proposal walletconnect.Proposal) error {
+	client, err := w.walletConnect()
+	if err != nil {
+		return err
+	}
+	return client.Reject(context.Background(), proposal, walletconnect.CodeUserRejected, "User rejected.")
+}
+
+// DAppSessions returns the open dApp sessions
+func (w *Wallet) DAppSessions() []walletconnect.Session {
+	w.dApps.mutex.Lock()
+	client := w.dApps.client
+	w.dApps.mutex.Unlock()
+	if client == nil {
+		return nil
+	}
+	return client.Sessions()
+}
+
+// DisconnectDApp closes the dApp session of topic
+func (w *Wallet) DisconnectDApp(topic string) error {
+	client, err := w.walletConnect()
+	if err != nil {
+		return err
+	}
+	return client.Disconnect(context.Background(), topic)
+}
+
+// PrepareDAppCall decodes request and checks it's for the current account, a transaction is
+// prepared with its fee on the connected chain of the request
+func (w *Wallet) PrepareDAppCall(request walletconnect.Request) (call DAppCall, err error) {
+	call.Request = request
+	account, err := w.Account()
+	if err != nil {
+		return call, err
+	}
+	from := common.HexToAddress(account.EthAddress)
+	var params []json.RawMessage
+	if err = json.Unmarshal(request.Params, &params); err != nil {
+		return call, ErrDAppInvalidParams
+	}
+	switch request.Method {
+	case "personal_sign":
+		var msg, address string
+		if len(params) < 2 || json.Unmarshal(params[0], &msg) != nil || json.Unmarshal(params[1], &address) != nil {
+			return call, ErrDAppInvalidParams
+		}
+		if !strings.EqualFold(address, from.Hex()) {
+			return call, ErrDAppAccountMismatch
+		}
+		call.Sign = &SignRequest{Kind: SignKindMessage, Message: evm.DecodeTextMessage(msg)}
+	case "eth_signTypedData", "eth_signTypedData_v4":
+		var address string
+		if len(params) < 2 || json.Unmarshal(params[0], &address) != nil {
+			return call, ErrDAppInvalidParams
+		}
+		if !strings.EqualFold(address, from.Hex()) {
+			return call, ErrDAppAccountMismatch
+		}
+		// typed data is sent either as a json string or as an object
+		data := []byte(params[1])
+		var text string
+		if json.Unmarshal(params[1], &text) == nil {
+			data = []byte(text)
+		}
+		request, err := NewSignRequest(SignKindTypedData, string(data))
+		if err != nil {
+			return call, err
+		}
+		call.Sign = &request
+	case "eth_sendTransaction":
+		call.Conn, call.Tx, err = w.prepareDAppTransaction(request.ChainID, from, params)
+	default:
+		err = fmt.Errorf("%w: %s", ErrDAppInvalidParams, request.Method)
+	}
+	return call, err
+}
+
+func (w *Wallet) prepareDAppTransaction(chainID string, from common.Address, params []json.RawMessage) (*evm.RPCClients, *evm.PreparedTx, error) {
+	var tx struct {
+		From  string         `json:"from"`
+		To    string         `json:"to"`
+		Value *hexutil.Big   `json:"value"`
+		Data  *hexutil.Bytes `json:"data"`
+		Input *hexutil.Bytes `json:"input"`
+	}
+	if len(params) < 1 || json.Unmarshal(params[0], &tx) != nil {
+		return nil, nil, ErrDAppInvalidParams
+	}
+	if !strings.EqualFold(tx.From, from.Hex()) {
+		return nil, nil, ErrDAppAccountMismatch
+	}
+	// contract creation isn't supported by the transactor
+	if !common.IsHexAddress(tx.To) {
+		return nil, nil, ErrInvalidAddress
+	}
+	conn, ok := w.Connection(strings.TrimPrefix(chainID, eip155Namespace+":"))
+	if !ok || !conn.IsConnected() {
+		return nil, nil, fmt.Errorf("connect to the chain %s to send the transaction", chainID)
+	}
+	value := new(big.Int)
+	if tx.Value != nil {
+		value = tx.Value.ToInt()
+	}
+	var data []byte
+	switch {
+	case tx.Data != nil:
+		data = *tx.Data
+	case tx.Input != nil:
+		data = *tx.Input
+	}
+	transactor, err := w.Transactor(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	prepared, err := transactor.Prepare(context.Background(), from, common.HexToAddress(tx.To), value, data)
+	return conn, prepared, err
+}
+
+// SendDAppTransaction sends the transaction of call and returns its hash to the dApp, a failure
+// is returned to the dApp too
+func (w *Wallet) SendDAppTransaction(call DAppCall) error {
+	client, err := w.walletConnect()
+	if err != nil {
+		return err
+	}
+	if call.Tx == nil {
+		return ErrDAppInvalidParams
+	}
+	ctx := context.Background()
+	txHash, err := w.SendTransaction(call.Conn, call.Tx)
+	if err != nil {
+		if respondErr := client.RespondError(ctx, call.Request, walletconnect.CodeRequestFailed, err.Error()); respondErr != nil {
+			return respondErr
+		}
+		return err
+	}
+	return client.Respond(ctx, call.Request, txHash.Hex())
+}
+
+// RespondDAppSignature returns the signature of the signing request to the dApp, the signature
+// is made by the user with Sign after approving the request
+func (w *Wallet) RespondDAppSignature(request walletconnect.Request, signature []byte) error {
+	client, err := w.walletConnect()
+	if err != nil {
+		return err
+	}
+	return client.Respond(context.Background(), request, hexutil.Encode(signature))
+}
+
+// RejectDAppCall answers request with the rejection of the user, or with err if the request
+// couldn't be prepared
+func (w *Wallet) RejectDAppCall(request walletconnect.Request, err error) error {
+	client, clientErr := w.walletConnect()
+	if clientErr != nil {
+		return clientErr
+	}
+	if err != nil {
+		return client.RespondError(context.Background(), request, walletconnect.CodeRequestFailed, err.Error())
+	}
+	return client.RespondError(context.Background(), request, walletconnect.CodeUserRejected, "User rejected.")
+}
+
+// dAppNamespace returns the eip155 namespace of a session of proposal with address, every
+// required chain and method must be supported
+func dAppNamespace(proposal walletconnect.Proposal, address common.Address) (namespace walletconnect.Namespace, err error) {
+	namespace.Methods = dAppMethods
+	namespace.Events = dAppEvents
+	for name, required := range proposal.RequiredNamespaces {
+		if name != eip155Namespace {
+			return namespace, ErrDAppChainsNotSupported
+		}
+		for _, chain := range required.Chains {
+			if !isKnownDAppChain(chain) {
+				return namespace, ErrDAppChainsNotSupported
+			}
+			namespace.Chains = appendUnique(namespace.Chains, chain)
+		}
+		for _, method := range required.Methods {
+			if !contains(dAppMethods, method) {
+				return namespace, ErrDAppMethodsNotSupported
+			}
+		}
+	}
+	if optional, ok := proposal.OptionalNamespaces[eip155Namespace]; ok {
+		for _, chain := range optional.Chains {
+			if isKnownDAppChain(chain) {
+				namespace.Chains = appendUnique(namespace.Chains, chain)
+			}
+		}
+	}
+	if len(namespace.Chains) == 0 {
+		return namespace, ErrDAppChainsNotSupported
+	}
+	for _, chain := range namespace.Chains {
+		namespace.Accounts = append(namespace.Accounts, chain+":"+address.Hex())
+	}
+	return namespace, nil
+}
+
+// isKnownDAppChain reports whether chain, a CAIP-2 id such as eip155:1, is in the chain list
+func isKnownDAppChain(chain string) bool {
+	chainID, ok := strings.CutPrefix(chain, eip155Namespace+":")
+	if !ok {
+		return false
+	}
+	_, ok = evm.ChainByID(chainID)
+	return ok
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+// dAppHandler fires the proposals and requests of the dApps for the ui
+type dAppHandler struct {
+	w *Wallet
+}
+
+func (h dAppHandler) OnProposal(proposal walletconnect.Proposal) {
+	h.w.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.DAppProposalEventData{Proposal: proposal},
+		Topic: pubsub.DAppProposalEventTopic,
+	})
+}
+
+func (h dAppHandler) OnRequest(request walletconnect.Request) {
+	h.w.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.DAppRequestEventData{Request: request},
+		Topic: pubsub.DAppRequestEventTopic,
+	})
+}
+
+func (h dAppHandler) OnSessionsChanged() {
+	h.w.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.DAppSessionsChangedEventData{},
+		Topic: pubsub.DAppSessionsChangedEventTopic,
+	})
+}
@@ -0,0 +1,182 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	libcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/model"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxDevices is the number of devices of an account saved from a single device list
+const MaxDevices = 16
+
+var (
+	ErrDeviceAlreadyLinked = errors.New("this device is already linked")
+	ErrInvalidDeviceName   = errors.New("enter a device name of at most 64 characters")
+	ErrRevokeThisDevice    = errors.New("this device can't remove itself, remove it from another device")
+	ErrInvalidCertificate  = errors.New("device isn't certified by its owner")
+)
+
+// identityKey returns the libp2p identity key of account, the wallet must be unlocked
+func (w *Wallet) identityKey(account model.Account) (libcrypto.PrivKey, error) {
+	pvtKeyHex, err := w.GetPrivateKey(account)
+	if err != nil {
+		return nil, err
+	}
+	keyType, err := common.IdentityKeyType(account.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+	return common.GetPrivateKeyFromStr(pvtKeyHex, keyType)
+}
+
+// DeviceKey returns the device sub-key of a linked device, the wallet must be unlocked
+func (w *Wallet) DeviceKey(account model.Account) (libcrypto.PrivKey, error) {
+	if !account.IsLinkedDevice() {
+		return nil, ErrKeyNotInCache
+	}
+	keyBytes, err := w.keys.decrypt(account.DeviceKey)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(keyBytes)
+	return libcrypto.UnmarshalPrivateKey(keyBytes)
+}
+
+// certifyDevice signs the certificate of device with the identity key of its owner account
+func (w *Wallet) certifyDevice(account model.Account, device *model.Device) (err error) {
+	pvtKey, err := w.identityKey(account)
+	if err != nil {
+		return err
+	}
+	device.Signature, err = pvtKey.Sign(device.CertificateMessage())
+	return err
+}
+
+// VerifyDevice checks that device is certified by the identity key of its owner
+func (w *Wallet) VerifyDevice(device model.Device) error {
+	if device.OwnerPublicKey == "" || device.DevicePublicKey == "" || device.CreatedAt.IsZero() ||
+		device.DevicePublicKey == device.OwnerPublicKey ||
+		utf8.RuneCountInString(device.Name) > model.MaxProfileNameLength {
+		return model.ErrInvalidDevice
+	}
+	if _, err := common.ParsePublicKey(device.DevicePublicKey); err != nil {
+		return model.ErrInvalidDevice
+	}
+	ownerKey, err := common.ParsePublicKey(device.OwnerPublicKey)
+	if err != nil {
+		return model.ErrInvalidDevice
+	}
+	ok, err := ownerKey.Verify(device.CertificateMessage(), device.Signature)
+	if err != nil || !ok {
+		return ErrInvalidCertificate
+	}
+	return nil
+}
+
+// LinkDevice makes this device a linked device of the current account, it generates the device
+// sub-key, certifies it with the account key and uses it as the identity of the chat host from
+// then on. The primary device keeps chatting with the account key.
+func (w *Wallet) LinkDevice(name string) (device model.Device, err error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > model.MaxProfileNameLength {
+		return device, ErrInvalidDeviceName
+	}
+	account, err := w.Account()
+	if err != nil {
+		return device, err
+	}
+	if account.IsLinkedDevice() {
+		return device, ErrDeviceAlreadyLinked
+	}
+	pvtKey, pubKey, err := libcrypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return device, err
+	}
+	pubKeyBytes, err := pubKey.Raw()
+	if err != nil {
+		return device, err
+	}
+	device = model.Device{
+		AccountPublicKey: account.PublicKey,
+		OwnerPublicKey:   account.PublicKey,
+		DevicePublicKey:  hex.EncodeToString(pubKeyBytes),
+		Name:             name,
+		CreatedAt:        time.Now(),
+	}
+	if err = w.certifyDevice(account, &device); err != nil {
+		return device, err
+	}
+	keyBytes, err := libcrypto.MarshalPrivateKey(pvtKey)
+	if err != nil {
+		return device, err
+	}
+	defer wipe(keyBytes)
+	if account.DeviceKey, err = w.keys.encrypt(keyBytes); err != nil {
+		return device, err
+	}
+	account.DevicePublicKey = device.DevicePublicKey
+	if err = w.ProtoDB.ReplaceAccount(&account); err != nil {
+		return device, err
+	}
+	// the chat host restarts with the device key on the event of the saved device
+	err = w.SaveDevice(&device)
+	return device, err
+}
+
+// ReceiveDevice saves a device of the owner after verifying its certificate
+func (w *Wallet) ReceiveDevice(accountPublicKey string, device model.Device) error {
+	if err := w.VerifyDevice(device); err != nil {
+		return err
+	}
+	device.AccountPublicKey = accountPublicKey
+	return w.SaveDevice(&device)
+}
+
+// RevokeDevice removes another device of the current account, the contacts stop delivering to
+// it once they receive the revoked certificate. The device still holds the account key, move
+// the funds and the contacts to a new account if it's lost.
+func (w *Wallet) RevokeDevice(devicePublicKey string) error {
+	account, err := w.Account()
+	if err != nil {
+		return err
+	}
+	if devicePublicKey == account.DevicePublicKey {
+		return ErrRevokeThisDevice
+	}
+	device, err := w.ProtoDB.Device(account.PublicKey, devicePublicKey)
+	if err != nil {
+		return err
+	}
+	if device.OwnerPublicKey != account.PublicKey {
+		return model.ErrInvalidDevice
+	}
+	if device.IsRevoked() {
+		return nil
+	}
+	device.RevokedAt = time.Now()
+	if err = w.certifyDevice(account, &device); err != nil {
+		return err
+	}
+	return w.SaveDevice(&device)
+}
+
+// ActiveDevices returns the devices of the owner saved by the account which aren't revoked
+func (w *Wallet) ActiveDevices(accountPublicKey, ownerPublicKey string) ([]model.Device, error) {
+	devices, err := w.Devices(accountPublicKey, ownerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	active := devices[:0]
+	for _, device := range devices {
+		if !device.IsRevoked() {
+			active = append(active, device)
+		}
+	}
+	return active, nil
+}
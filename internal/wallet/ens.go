@@ -0,0 +1,92 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"strings"
+	"time"
+)
+
+// ensTimeout limits an ens lookup including the calls to the registry and the resolver
+const ensTimeout = 20 * time.Second
+
+// ensChainID is Ethereum Mainnet, where .eth names are registered
+const ensChainID = "1"
+
+var (
+	ErrENSNotConnected = errors.New("connect to Ethereum Mainnet to resolve ens names")
+	ErrContactNotFound = errors.New("contact not found")
+)
+
+// ResolveENSName returns the address of the ens name such as alice.eth
+func (w *Wallet) ResolveENSName(name string) (common.Address, error) {
+	conn, registry, err := w.ensConnection()
+	if err != nil {
+		return common.Address{}, err
+	}
+	backend, err := conn.Backend()
+	if err != nil {
+		return common.Address{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ensTimeout)
+	defer cancel()
+	return evm.ResolveENSName(ctx, backend, registry, name)
+}
+
+// LookupENSName returns the verified primary ens name of address
+func (w *Wallet) LookupENSName(address common.Address) (string, error) {
+	conn, registry, err := w.ensConnection()
+	if err != nil {
+		return "", err
+	}
+	backend, err := conn.Backend()
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ensTimeout)
+	defer cancel()
+	return evm.LookupENSAddress(ctx, backend, registry, address)
+}
+
+// ensConnection returns the connection of Ethereum Mainnet with its ens registry, the names of
+// the testnets are registered apart hence they aren't used
+func (w *Wallet) ensConnection() (conn *evm.RPCClients, registry common.Address, err error) {
+	conn, ok := w.Connection(ensChainID)
+	if !ok || !conn.IsConnected() {
+		return nil, registry, ErrENSNotConnected
+	}
+	registry, ok = conn.Chain.ENSRegistry()
+	if !ok {
+		return nil, registry, evm.ErrENSNotSupported
+	}
+	return conn, registry, nil
+}
+
+// ContactByEthAddress returns the contact of the current account with the ethereum address from
+// the address book, it returns ErrContactNotFound if there's none
+func (w *Wallet) ContactByEthAddress(address common.Address) (model.Contact, error) {
+	account, err := w.Account()
+	if err != nil {
+		return model.Contact{}, err
+	}
+	count, err := w.ProtoDB.ContactsCount(account.PublicKey)
+	if err != nil {
+		return model.Contact{}, err
+	}
+	if count == 0 {
+		return model.Contact{}, ErrContactNotFound
+	}
+	contacts, err := w.ProtoDB.Contacts(account.PublicKey, 0, int(count))
+	if err != nil {
+		return model.Contact{}, err
+	}
+	for _, contact := range contacts {
+		if strings.EqualFold(contact.EthAddress, address.Hex()) {
+			return contact, nil
+		}
+	}
+	return model.Contact{}, ErrContactNotFound
+}
@@ -0,0 +1,96 @@
+package wallet
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/db"
+	"github.com/mearaj/protonet/internal/hdwallet"
+	"github.com/mearaj/protonet/internal/model"
+)
+
+var ErrMnemonicNotSaved = errors.New("the recovery phrase of this account isn't saved, back up its private key instead")
+
+// ImportMnemonic makes the seed of mnemonic the current HD seed and derives its next account
+// with its chat identity of algorithm. The previous seed stays saved for the accounts derived
+// from it, a mnemonic imported again continues from its next index.
+func (w *Wallet) ImportMnemonic(mnemonic, algorithm string) (err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	if !w.ProtoDB.IsOpen() {
+		return db.ErrDBNotOpened
+	}
+	mnemonic = hdwallet.NormalizeMnemonic(mnemonic)
+	seed, err := hdwallet.NewSeed(mnemonic, "")
+	if err != nil {
+		return err
+	}
+	defer wipe(seed)
+	id := hdSeedID(seed)
+	hdSeed, err := w.ProtoDB.HDSeedByID(id)
+	if errors.Is(err, db.ErrHDSeedNotFound) {
+		var mnemonicEnc, seedEnc string
+		mnemonicEnc, err = w.keys.encrypt([]byte(mnemonic))
+		if err != nil {
+			return err
+		}
+		seedEnc, err = w.keys.encrypt(seed)
+		if err != nil {
+			return err
+		}
+		hdSeed = model.HDSeed{ID: id, Mnemonic: mnemonicEnc, Seed: seedEnc}
+	} else if err != nil {
+		return err
+	}
+	return w.deriveNextAccount(&hdSeed, seed, algorithm)
+}
+
+// Mnemonic returns the decrypted mnemonic of the HD seed of account, meant for backup
+func (w *Wallet) Mnemonic(account model.Account) (string, error) {
+	if account.HDPath == "" || account.HDSeedID == "" {
+		return "", ErrMnemonicNotSaved
+	}
+	hdSeed, err := w.ProtoDB.HDSeedByID(account.HDSeedID)
+	if errors.Is(err, db.ErrHDSeedNotFound) {
+		return "", ErrMnemonicNotSaved
+	}
+	if err != nil {
+		return "", err
+	}
+	mnemonic, err := w.keys.decrypt(hdSeed.Mnemonic)
+	if err != nil {
+		return "", err
+	}
+	return string(mnemonic), nil
+}
+
+// hdSeedID returns the fingerprint of seed which identifies it in the database
+func hdSeedID(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	return hex.EncodeToString(sum[:8])
+}
+
+// deriveNextAccount derives the account at hdSeed.NextIndex, saves it as primary account and
+// saves hdSeed with the incremented index
+func (w *Wallet) deriveNextAccount(hdSeed *model.HDSeed, seed []byte, algorithm string) (err error) {
+	index := hdSeed.NextIndex
+	pvtKeyHex, err := hdwallet.DeriveEthPrivateKey(seed, index)
+	// BIP-32 recommends to skip to the next index for an invalid child, probability is lower than 1 in 2^127
+	for errors.Is(err, hdwallet.ErrInvalidChild) {
+		index++
+		pvtKeyHex, err = hdwallet.DeriveEthPrivateKey(seed, index)
+	}
+	if err != nil {
+		return err
+	}
+	err = w.createAccount(pvtKeyHex, hdwallet.EthAccountPath(index), hdSeed.ID, algorithm)
+	if err != nil {
+		return err
+	}
+	hdSeed.NextIndex = index + 1
+	return w.ProtoDB.SaveHDSeed(hdSeed)
+}
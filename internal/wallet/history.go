@@ -0,0 +1,217 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/db"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// initialScanBlocks is how far back from the head the first scan of an account goes
+	initialScanBlocks = 256
+	// scanBlocksStep is the number of blocks scanned before the scan state is saved
+	scanBlocksStep = evm.MaxNativeScanBlocks
+	// maxScanBlocks is the number of blocks scanned by a call of ScanTransactionHistory, the
+	// blocks after are scanned by the next call
+	maxScanBlocks = 32 * scanBlocksStep
+)
+
+// TransactionHistory returns the transactions of the current account on chainID, latest first
+func (w *Wallet) TransactionHistory(chainID *big.Int) ([]model.Transaction, error) {
+	account, err := w.Account()
+	if err != nil {
+		return nil, err
+	}
+	return w.ProtoDB.Transactions(chainID.String(), account.EthAddress)
+}
+
+// ScanTransactionHistory scans the blocks after the last scanned block up to the head of the
+// connected chain for incoming native and token transfers of the current account, at most
+// maxScanBlocks are scanned. The scan state isn't advanced past a step which fails.
+func (w *Wallet) ScanTransactionHistory(conn *evm.RPCClients) (err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	account, err := w.Account()
+	if err != nil {
+		return err
+	}
+	backend, err := conn.Backend()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	chainID := &conn.Chain.ChainID
+	address := common.HexToAddress(account.EthAddress)
+	head, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	headNumber := head.Number.Uint64()
+	state, err := w.ProtoDB.TransactionScanState(chainID.String(), account.EthAddress)
+	if errors.Is(err, db.ErrTransactionNotFound) {
+		state.LastBlock = 0
+		if headNumber > initialScanBlocks {
+			state.LastBlock = headNumber - initialScanBlocks
+		}
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	tokens, err := w.ChainTokens(chainID)
+	if err != nil {
+		return err
+	}
+	tokensByAddress := make(map[common.Address]evm.Token, len(tokens))
+	tokenAddresses := make([]common.Address, 0, len(tokens))
+	for _, token := range tokens {
+		tokensByAddress[token.Address] = token
+		tokenAddresses = append(tokenAddresses, token.Address)
+	}
+	lastBlock := headNumber
+	if lastBlock > state.LastBlock+maxScanBlocks {
+		lastBlock = state.LastBlock + maxScanBlocks
+	}
+	for state.LastBlock < lastBlock {
+		fromBlock := state.LastBlock + 1
+		toBlock := fromBlock + scanBlocksStep - 1
+		if toBlock > lastBlock {
+			toBlock = lastBlock
+		}
+		transfers, err := evm.ScanNativeTransfers(ctx, backend, chainID, address, fromBlock, toBlock)
+		if err != nil {
+			return err
+		}
+		tokenTransfers, err := evm.ScanTokenTransfers(ctx, backend, address, tokenAddresses, fromBlock, toBlock)
+		if err != nil {
+			return err
+		}
+		for _, transfer := range append(transfers, tokenTransfers...) {
+			if err = w.saveIncomingTransfer(conn, account.EthAddress, transfer, tokensByAddress); err != nil {
+				return err
+			}
+		}
+		state.LastBlock = toBlock
+		if err = w.ProtoDB.SaveTransactionScanState(&state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveIncomingTransfer adds the transfer to the history unless it's there already. Token
+// transfers are saved per Transfer log, a transaction may transfer several tokens or the same
+// token several times.
+func (w *Wallet) saveIncomingTransfer(conn *evm.RPCClients, accountAddress string, transfer evm.Transfer, tokens map[common.Address]evm.Token) error {
+	chainID := conn.Chain.ChainID.String()
+	// a transfer to self is in the history as the outgoing transaction
+	if strings.EqualFold(transfer.From.Hex(), accountAddress) {
+		_, err := w.ProtoDB.Transaction(chainID, accountAddress, transfer.Hash.Hex())
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, db.ErrTransactionNotFound) {
+			return err
+		}
+	}
+	tx := model.Transaction{
+		CreatedAt:      time.Unix(int64(transfer.Time), 0),
+		ChainID:        chainID,
+		AccountAddress: accountAddress,
+		Hash:           transfer.Hash.Hex(),
+		From:           transfer.From.Hex(),
+		To:             transfer.To.Hex(),
+		Value:          transfer.Value.String(),
+		Symbol:         conn.Chain.NativeCurrency.Symbol,
+		Decimals:       conn.Chain.NativeCurrency.Decimals,
+		Direction:      model.TransactionIncoming,
+		Status:         model.TransactionStatusConfirmed,
+		BlockNumber:    transfer.BlockNumber,
+	}
+	if transfer.Token != nil {
+		token := tokens[*transfer.Token]
+		tx.TokenAddress = token.Address.Hex()
+		tx.Symbol = token.Symbol
+		tx.Decimals = token.Decimals
+		tx.LogIndex = strconv.FormatUint(uint64(transfer.LogIndex), 10)
+	}
+	exists, err := w.ProtoDB.TransactionExists(&tx)
+	if err != nil || exists {
+		return err
+	}
+	return w.ProtoDB.SaveTransaction(&tx)
+}
+
+// saveOutgoingTransaction adds the sent transaction to the history as pending, token transfers
+// are saved with the token recipient and amount
+func (w *Wallet) saveOutgoingTransaction(conn *evm.RPCClients, pendingTx evm.PendingTx, data []byte) error {
+	tx := model.Transaction{
+		CreatedAt:      pendingTx.SentAt,
+		ChainID:        pendingTx.ChainID.String(),
+		AccountAddress: pendingTx.From.Hex(),
+		Hash:           pendingTx.Hash.Hex(),
+		From:           pendingTx.From.Hex(),
+		To:             pendingTx.To.Hex(),
+		Value:          pendingTx.Value.String(),
+		Symbol:         conn.Chain.NativeCurrency.Symbol,
+		Decimals:       conn.Chain.NativeCurrency.Decimals,
+		Direction:      model.TransactionOutgoing,
+		Status:         model.TransactionStatusPending,
+	}
+	if to, amount, ok := evm.UnpackTransfer(data); ok {
+		tokens, err := w.ChainTokens(pendingTx.ChainID)
+		if err != nil {
+			return err
+		}
+		for _, token := range tokens {
+			if token.Address == pendingTx.To {
+				tx.TokenAddress = token.Address.Hex()
+				tx.To = to.Hex()
+				tx.Value = amount.String()
+				tx.Symbol = token.Symbol
+				tx.Decimals = token.Decimals
+				break
+			}
+		}
+	}
+	return w.ProtoDB.SaveTransaction(&tx)
+}
+
+// updateTransactionStatus updates the status of the outgoing transaction from its receipt,
+// receipt is nil if the transaction couldn't be tracked
+func (w *Wallet) updateTransactionStatus(pendingTx evm.PendingTx, receipt *types.Receipt) error {
+	tx, err := w.ProtoDB.Transaction(pendingTx.ChainID.String(), pendingTx.From.Hex(), pendingTx.Hash.Hex())
+	if err != nil {
+		return err
+	}
+	if receipt == nil {
+		return nil
+	}
+	tx.Status = model.TransactionStatusConfirmed
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		tx.Status = model.TransactionStatusFailed
+	}
+	tx.BlockNumber = receipt.BlockNumber.Uint64()
+	return w.ProtoDB.SaveTransaction(&tx)
+}
+
+// FormatTransactionValue returns the value of tx with its symbol such as 1.5 ETH
+func FormatTransactionValue(tx model.Transaction) string {
+	value, ok := new(big.Int).SetString(tx.Value, 10)
+	if !ok {
+		return strings.TrimSpace(tx.Value + " " + tx.Symbol)
+	}
+	return evm.FormatUnits(value, tx.Decimals) + " " + tx.Symbol
+}
@@ -0,0 +1,167 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/model"
+	"sync"
+)
+
+var (
+	ErrWalletLocked      = errors.New("wallet is locked")
+	ErrKeyNotInCache     = errors.New("private key not found in cache")
+	ErrKeyNotEncrypted   = errors.New("private key is not encrypted")
+	ErrInvalidPrivateKey = errors.New("invalid private key")
+)
+
+// keyCache holds the user password and the decrypted private keys in memory
+// while the wallet is unlocked, keys of cache are account public keys.
+// Everything held here is wiped when the wallet is locked.
+type keyCache struct {
+	passwd []byte
+	keys   map[string][]byte
+	mutex  sync.RWMutex
+}
+
+func newKeyCache() *keyCache {
+	return &keyCache{keys: map[string][]byte{}}
+}
+
+func (k *keyCache) IsUnlocked() bool {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	return k.passwd != nil
+}
+
+func (k *keyCache) unlock(passwd string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	wipe(k.passwd)
+	k.passwd = []byte(passwd)
+}
+
+// lock wipes the password and all the cached keys
+func (k *keyCache) lock() {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	wipe(k.passwd)
+	k.passwd = nil
+	for publicKey, pvtKey := range k.keys {
+		wipe(pvtKey)
+		delete(k.keys, publicKey)
+	}
+}
+
+func (k *keyCache) password() ([]byte, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	if k.passwd == nil {
+		return nil, ErrWalletLocked
+	}
+	passwd := make([]byte, len(k.passwd))
+	copy(passwd, k.passwd)
+	return passwd, nil
+}
+
+func (k *keyCache) set(publicKey string, pvtKey []byte) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if prev, ok := k.keys[publicKey]; ok {
+		wipe(prev)
+	}
+	k.keys[publicKey] = pvtKey
+}
+
+func (k *keyCache) get(publicKey string) (pvtKeyHex string, err error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	if k.passwd == nil {
+		return "", ErrWalletLocked
+	}
+	pvtKey, ok := k.keys[publicKey]
+	if !ok {
+		return "", ErrKeyNotInCache
+	}
+	return hex.EncodeToString(pvtKey), nil
+}
+
+func (k *keyCache) delete(publicKey string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if pvtKey, ok := k.keys[publicKey]; ok {
+		wipe(pvtKey)
+		delete(k.keys, publicKey)
+	}
+}
+
+// encrypt encrypts data with the cached password and returns it hex encoded
+func (k *keyCache) encrypt(data []byte) (encHex string, err error) {
+	passwd, err := k.password()
+	if err != nil {
+		return "", err
+	}
+	defer wipe(passwd)
+	encBytes, err := common.Encrypt(passwd, data)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(encBytes), nil
+}
+
+// decrypt decrypts hex encoded encHex with the cached password
+func (k *keyCache) decrypt(encHex string) (data []byte, err error) {
+	passwd, err := k.password()
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(passwd)
+	encBytes, err := hex.DecodeString(encHex)
+	if err != nil {
+		return nil, err
+	}
+	return common.Decrypt(passwd, encBytes)
+}
+
+// encryptPrivateKey encrypts pvtKeyHex with the cached password and returns it hex encoded,
+// the plain key is cached against publicKey
+func (k *keyCache) encryptPrivateKey(publicKey, pvtKeyHex string) (encHex string, err error) {
+	pvtKeyBytes, err := hex.DecodeString(pvtKeyHex)
+	if err != nil {
+		return "", err
+	}
+	encHex, err = k.encrypt(pvtKeyBytes)
+	if err != nil {
+		return "", err
+	}
+	k.set(publicKey, pvtKeyBytes)
+	return encHex, nil
+}
+
+// decryptPrivateKey decrypts the private key of an encrypted account and caches it
+func (k *keyCache) decryptPrivateKey(a model.Account) (err error) {
+	if !a.KeyEncrypted {
+		return ErrKeyNotEncrypted
+	}
+	passwd, err := k.password()
+	if err != nil {
+		return err
+	}
+	defer wipe(passwd)
+	pvtKeyHex, err := common.GetPrivateKeyFromPasswd(a, string(passwd))
+	if err != nil {
+		return err
+	}
+	pvtKeyBytes, err := hex.DecodeString(pvtKeyHex)
+	if err != nil {
+		return err
+	}
+	k.set(a.PublicKey, pvtKeyBytes)
+	return nil
+}
+
+func wipe(bs []byte) {
+	for i := range bs {
+		bs[i] = 0
+	}
+}
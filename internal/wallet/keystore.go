@@ -0,0 +1,31 @@
+package wallet
+
+import (
+	"errors"
+	"github.com/mearaj/protonet/internal/keystore"
+	"github.com/mearaj/protonet/internal/model"
+)
+
+var ErrPassphraseEmpty = errors.New("keystore passphrase is empty")
+
+// ImportKeystore decrypts the keystore v3 json with passphrase and creates its account with its
+// chat identity of algorithm
+func (w *Wallet) ImportKeystore(keyJSON []byte, passphrase, algorithm string) (err error) {
+	pvtKeyHex, err := keystore.Decrypt(keyJSON, passphrase)
+	if err != nil {
+		return err
+	}
+	return w.CreateAccount(pvtKeyHex, algorithm)
+}
+
+// ExportKeystore returns the private key of account as keystore v3 json encrypted with passphrase
+func (w *Wallet) ExportKeystore(account model.Account, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrPassphraseEmpty
+	}
+	pvtKeyHex, err := w.GetPrivateKey(account)
+	if err != nil {
+		return nil, err
+	}
+	return keystore.Encrypt(pvtKeyHex, passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+}
@@ -0,0 +1,175 @@
+package wallet
+
+import (
+	"crypto/subtle"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/db"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"strings"
+	"sync"
+	"time"
+)
+
+const autoLockCheckInterval = time.Second * 5
+
+// lockedAccount is the public part of the account kept while the database is closed, the chat
+// keeps receiving for it while the wallet is locked
+type lockedAccount struct {
+	account *model.Account
+	mutex   sync.RWMutex
+}
+
+// autoLock tracks the user activity, timeout of zero disables auto lock
+type autoLock struct {
+	lastActivity time.Time
+	timeout      time.Duration
+	mutex        sync.RWMutex
+}
+
+// Touch records the user activity and hence postpones the auto lock
+func (w *Wallet) Touch() {
+	w.autoLock.mutex.Lock()
+	w.autoLock.lastActivity = time.Now()
+	w.autoLock.mutex.Unlock()
+}
+
+func (w *Wallet) SetAutoLockTimeout(timeout time.Duration) {
+	w.autoLock.mutex.Lock()
+	w.autoLock.timeout = timeout
+	w.autoLock.mutex.Unlock()
+}
+
+func (w *Wallet) AutoLockTimeout() time.Duration {
+	w.autoLock.mutex.RLock()
+	defer w.autoLock.mutex.RUnlock()
+	return w.autoLock.timeout
+}
+
+func (w *Wallet) isIdle() bool {
+	w.autoLock.mutex.RLock()
+	defer w.autoLock.mutex.RUnlock()
+	return w.autoLock.timeout > 0 && time.Since(w.autoLock.lastActivity) > w.autoLock.timeout
+}
+
+// runAutoLock keeps checking for inactivity as long as app is running,
+// should be called only once for the entire lifecycle of app
+func (w *Wallet) runAutoLock() {
+	w.SetAutoLockTimeout(model.DefaultAutoLockTimeout)
+	tckr := time.NewTicker(autoLockCheckInterval)
+	defer tckr.Stop()
+	for range tckr.C {
+		if w.IsUnlocked() && w.isIdle() {
+			alog.Logger().Infoln("locking wallet after inactivity")
+			w.Lock()
+		}
+	}
+}
+
+// Lock wipes the password, decrypted private keys and api keys from memory, disconnects the
+// chains, which stops their watchers and probers, and closes the database. The public key of the
+// account is kept so that the chat keeps receiving while locked, the messages wait encrypted
+// until the database is opened again from the password.
+func (w *Wallet) Lock() {
+	wasUnlocked := w.IsUnlocked()
+	w.keepLockedAccount()
+	w.keys.lock()
+	w.disconnectChainsOnLock()
+	w.clearAPIKeys()
+	w.closeWalletConnect()
+	if err := w.ProtoDB.Close(); err != nil {
+		alog.Logger().Errorln(err)
+	}
+	if wasUnlocked {
+		w.EventBroker.Fire(pubsub.Event{
+			Data:  pubsub.WalletLockedEventData{},
+			Topic: pubsub.WalletLockedEventTopic,
+		})
+	}
+}
+
+// keepLockedAccount keeps the public part of the account before the database is closed
+func (w *Wallet) keepLockedAccount() {
+	account, err := w.ProtoDB.Account()
+	if err != nil {
+		return
+	}
+	w.lockedAccount.mutex.Lock()
+	w.lockedAccount.account = &model.Account{
+		PublicKey:         account.PublicKey,
+		EthAddress:        account.EthAddress,
+		IdentityAlgorithm: account.IdentityAlgorithm,
+		DevicePublicKey:   account.DevicePublicKey,
+	}
+	w.lockedAccount.mutex.Unlock()
+}
+
+func (w *Wallet) clearLockedAccount() {
+	w.lockedAccount.mutex.Lock()
+	w.lockedAccount.account = nil
+	w.lockedAccount.mutex.Unlock()
+}
+
+// Account returns the primary account, only its public part is returned while the wallet is
+// locked and the database is closed
+func (w *Wallet) Account() (model.Account, error) {
+	if w.ProtoDB.IsOpen() {
+		return w.ProtoDB.Account()
+	}
+	w.lockedAccount.mutex.RLock()
+	defer w.lockedAccount.mutex.RUnlock()
+	if w.lockedAccount.account == nil {
+		return model.Account{}, db.ErrDBNotOpened
+	}
+	return *w.lockedAccount.account, nil
+}
+
+// VerifyPassword returns nil if passwd is the password of the unlocked wallet else may
+// return db.ErrPasswordMismatch or db.ErrPasswordInvalid or ErrWalletLocked
+func (w *Wallet) VerifyPassword(passwd string) error {
+	if strings.TrimSpace(passwd) == "" {
+		return db.ErrPasswordInvalid
+	}
+	cached, err := w.keys.password()
+	if err != nil {
+		return err
+	}
+	defer wipe(cached)
+	if subtle.ConstantTimeCompare(cached, []byte(passwd)) != 1 {
+		return db.ErrPasswordMismatch
+	}
+	return nil
+}
+
+// disconnectChainsOnLock disconnects the connected chains and remembers them for unlock
+func (w *Wallet) disconnectChainsOnLock() {
+	for _, conn := range w.Connections() {
+		chainID := conn.Chain.ChainID.String()
+		_, watched := w.watchers.Get(chainID)
+		if !watched && !conn.IsProbing() {
+			continue
+		}
+		w.lockedChains.Set(chainID, struct{}{})
+		w.DisconnectChain(conn)
+	}
+}
+
+// reconnectLockedChains connects again the chains disconnected by Lock
+func (w *Wallet) reconnectLockedChains() {
+	for _, chainID := range w.lockedChains.Keys() {
+		w.lockedChains.Delete(chainID)
+		if conn, ok := w.Connection(chainID); ok {
+			w.ConnectChain(conn)
+		}
+	}
+}
+
+func (w *Wallet) IsUnlocked() bool {
+	return w.keys.IsUnlocked()
+}
+
+// IsOpen returns true if the database is open and the wallet is unlocked, the password is asked
+// for otherwise
+func (w *Wallet) IsOpen() bool {
+	return w.ProtoDB.IsOpen() && w.IsUnlocked()
+}
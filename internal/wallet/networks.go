@@ -0,0 +1,319 @@
+package wallet
+
+import (
+	"errors"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/db"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrNetworkExists    = errors.New("network already exists")
+	ErrNetworkNotFound  = errors.New("network not found")
+	ErrNetworkNotCustom = errors.New("only custom networks can be removed")
+	ErrRPCNotCustom     = errors.New("only custom rpc urls can be removed")
+	ErrInvalidChainID   = errors.New("invalid chain id")
+)
+
+// Connection returns the connection of chainID, chainID is in decimal
+func (w *Wallet) Connection(chainID string) (*evm.RPCClients, bool) {
+	for _, conn := range w.Connections() {
+		if conn.Chain.ChainID.String() == chainID {
+			return conn, true
+		}
+	}
+	return nil, false
+}
+
+// AddNetwork adds a custom chain with its first rpc url, explorerURL is optional
+func (w *Wallet) AddNetwork(chainID, name, symbol string, decimals int, rpcURL, explorerURL string) (conn *evm.RPCClients, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	id, ok := new(big.Int).SetString(strings.TrimSpace(chainID), 10)
+	if !ok || id.Sign() <= 0 {
+		return nil, ErrInvalidChainID
+	}
+	name, symbol = strings.TrimSpace(name), strings.TrimSpace(symbol)
+	if name == "" || symbol == "" || decimals < 0 {
+		return nil, model.ErrInvalidNetwork
+	}
+	rpc, err := evm.ParseRPC(rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok = w.Connection(id.String()); ok {
+		return nil, ErrNetworkExists
+	}
+	network := model.Network{
+		ChainID:     id.String(),
+		Custom:      true,
+		Name:        name,
+		ShortName:   strings.ToLower(symbol),
+		Symbol:      symbol,
+		Decimals:    decimals,
+		ExplorerURL: strings.TrimSpace(explorerURL),
+		RPCs:        []string{string(rpc)},
+	}
+	if err = w.ProtoDB.SaveNetwork(&network); err != nil {
+		return nil, err
+	}
+	return w.addNetworkConnection(network), nil
+}
+
+// RemoveNetwork disconnects and removes a custom chain, the embedded chains can't be removed
+func (w *Wallet) RemoveNetwork(conn *evm.RPCClients) (err error) {
+	chainID := conn.Chain.ChainID.String()
+	network, err := w.ProtoDB.Network(chainID)
+	if errors.Is(err, db.ErrNetworkNotFound) || (err == nil && !network.Custom) {
+		return ErrNetworkNotCustom
+	}
+	if err != nil {
+		return err
+	}
+	w.DisconnectChain(conn)
+	w.customChains.Delete(chainID)
+	for _, rpc := range network.RPCs {
+		w.customRPCs.Delete(rpc)
+	}
+	w.connectionsMutex.Lock()
+	connections := make([]*evm.RPCClients, 0, len(w.connections))
+	for _, c := range w.connections {
+		if c != conn {
+			connections = append(connections, c)
+		}
+	}
+	w.connections = connections
+	w.connectionsMutex.Unlock()
+	return w.ProtoDB.DeleteNetwork(&network)
+}
+
+// AddRPC adds a custom rpc url to the disconnected chain
+func (w *Wallet) AddRPC(conn *evm.RPCClients, rpcURL string) (err error) {
+	rpc, err := evm.ParseRPC(rpcURL)
+	if err != nil {
+		return err
+	}
+	chainID := conn.Chain.ChainID.String()
+	network, err := w.ProtoDB.Network(chainID)
+	if errors.Is(err, db.ErrNetworkNotFound) {
+		network, err = model.Network{ChainID: chainID}, nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err = conn.AddRPC(rpc); err != nil {
+		return err
+	}
+	network.RPCs = append(network.RPCs, string(rpc))
+	if err = w.ProtoDB.SaveNetwork(&network); err != nil {
+		_ = conn.RemoveRPC(rpc)
+		return err
+	}
+	w.customRPCs.Set(string(rpc), struct{}{})
+	return nil
+}
+
+// RemoveRPC removes a custom rpc url from the disconnected chain, the embedded urls can't be removed
+func (w *Wallet) RemoveRPC(conn *evm.RPCClients, rpc evm.RPC) (err error) {
+	if !w.IsCustomRPC(rpc) {
+		return ErrRPCNotCustom
+	}
+	network, err := w.ProtoDB.Network(conn.Chain.ChainID.String())
+	if err != nil {
+		return err
+	}
+	if err = conn.RemoveRPC(rpc); err != nil {
+		return err
+	}
+	rpcs := make([]string, 0, len(network.RPCs))
+	for _, r := range network.RPCs {
+		if r != string(rpc) {
+			rpcs = append(rpcs, r)
+		}
+	}
+	network.RPCs = rpcs
+	w.customRPCs.Delete(string(rpc))
+	if err = w.SetAPIKey(conn, rpc, ""); err != nil {
+		return err
+	}
+	return w.ProtoDB.SaveNetwork(&network)
+}
+
+// IsCustomNetwork reports whether the chain of chainID was added by the user, chainID is in decimal
+func (w *Wallet) IsCustomNetwork(chainID string) bool {
+	_, ok := w.customChains.Get(chainID)
+	return ok
+}
+
+// IsCustomRPC reports whether rpc was added by the user
+func (w *Wallet) IsCustomRPC(rpc evm.RPC) bool {
+	_, ok := w.customRPCs.Get(string(rpc))
+	return ok
+}
+
+// SetAPIKey sets the api key of the templated rpc of conn and saves it encrypted with the
+// wallet password, empty apiKey deletes the key
+func (w *Wallet) SetAPIKey(conn *evm.RPCClients, rpc evm.RPC, apiKey string) (err error) {
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		err = w.ProtoDB.DeleteAPIKey(string(rpc))
+	} else {
+		var keyEnc string
+		keyEnc, err = w.keys.encrypt([]byte(apiKey))
+		if err != nil {
+			return err
+		}
+		err = w.ProtoDB.SaveAPIKey(&model.APIKey{RPC: string(rpc), KeyEnc: keyEnc})
+	}
+	if err != nil {
+		return err
+	}
+	for _, client := range conn.Clients() {
+		if client.RPC == rpc {
+			client.SetAPIKey(apiKey)
+		}
+	}
+	return nil
+}
+
+// IsFavoriteChain reports whether the chain of chainID is marked favorite, chainID is in decimal
+func (w *Wallet) IsFavoriteChain(chainID string) bool {
+	_, ok := w.FavoriteChains.Get(chainID)
+	return ok
+}
+
+// SetFavoriteChain marks or unmarks the chain of chainID favorite and saves it in settings
+func (w *Wallet) SetFavoriteChain(chainID string, favorite bool) error {
+	if favorite {
+		w.FavoriteChains.Set(chainID, struct{}{})
+	} else {
+		w.FavoriteChains.Delete(chainID)
+	}
+	return w.saveFavorites()
+}
+
+func (w *Wallet) IsFavoriteRPC(rpc evm.RPC) bool {
+	_, ok := w.FavoriteRPCs.Get(string(rpc))
+	return ok
+}
+
+// SetFavoriteRPC marks or unmarks rpc favorite and saves it in settings
+func (w *Wallet) SetFavoriteRPC(rpc evm.RPC, favorite bool) error {
+	if favorite {
+		w.FavoriteRPCs.Set(string(rpc), struct{}{})
+	} else {
+		w.FavoriteRPCs.Delete(string(rpc))
+	}
+	return w.saveFavorites()
+}
+
+func (w *Wallet) saveFavorites() error {
+	settings, err := w.ProtoDB.Settings()
+	if err != nil {
+		return err
+	}
+	settings.FavoriteChains = w.FavoriteChains.Keys()
+	settings.FavoriteRPCs = w.FavoriteRPCs.Keys()
+	return w.ProtoDB.SaveSettings(&settings)
+}
+
+// loadNetworks merges the saved chain list, adds the saved custom chains and rpc urls to the
+// connections, sets the saved api keys and favorites. It's called on unlock, hence it skips
+// what's loaded already.
+func (w *Wallet) loadNetworks(settings model.Settings) error {
+	if err := w.loadChainList(); err != nil {
+		alog.Logger().Errorln(err)
+	}
+	for _, chainID := range settings.FavoriteChains {
+		w.FavoriteChains.Set(chainID, struct{}{})
+	}
+	for _, rpc := range settings.FavoriteRPCs {
+		w.FavoriteRPCs.Set(rpc, struct{}{})
+	}
+	networks, err := w.ProtoDB.Networks()
+	if err != nil {
+		return err
+	}
+	for _, network := range networks {
+		conn, ok := w.Connection(network.ChainID)
+		if !ok {
+			if network.Custom {
+				w.addNetworkConnection(network)
+			}
+			continue
+		}
+		for _, rpcURL := range network.RPCs {
+			_, err = conn.AddRPC(evm.RPC(rpcURL))
+			if err != nil && !errors.Is(err, evm.ErrRPCExists) {
+				alog.Logger().Errorln(err)
+				continue
+			}
+			w.customRPCs.Set(rpcURL, struct{}{})
+		}
+	}
+	apiKeys, err := w.ProtoDB.APIKeys()
+	if err != nil {
+		return err
+	}
+	for _, apiKey := range apiKeys {
+		key, err := w.keys.decrypt(apiKey.KeyEnc)
+		if err != nil {
+			return err
+		}
+		for _, conn := range w.Connections() {
+			for _, client := range conn.Clients() {
+				if client.RPC == evm.RPC(apiKey.RPC) {
+					client.SetAPIKey(string(key))
+				}
+			}
+		}
+		wipe(key)
+	}
+	return nil
+}
+
+// clearAPIKeys wipes the api keys from memory when the wallet is locked, the connected
+// endpoints stay connected
+func (w *Wallet) clearAPIKeys() {
+	for _, conn := range w.Connections() {
+		for _, client := range conn.Clients() {
+			if client.APIKey() != "" {
+				client.SetAPIKey("")
+			}
+		}
+	}
+}
+
+func (w *Wallet) addNetworkConnection(network model.Network) *evm.RPCClients {
+	chain := evm.Chain{
+		Name:      network.Name,
+		Chain:     network.Symbol,
+		ShortName: network.ShortName,
+		NativeCurrency: evm.NativeCurrency{
+			Name:     network.Symbol,
+			Symbol:   network.Symbol,
+			Decimals: network.Decimals,
+		},
+	}
+	chain.ChainID.SetString(network.ChainID, 10)
+	chain.NetworkID.Set(&chain.ChainID)
+	for _, rpc := range network.RPCs {
+		chain.RPC = append(chain.RPC, evm.RPC(rpc))
+		w.customRPCs.Set(rpc, struct{}{})
+	}
+	if network.ExplorerURL != "" {
+		chain.Explorers = []evm.Explorer{{Name: network.Name, URL: network.ExplorerURL, Standard: "EIP3091"}}
+	}
+	conn := evm.NewRPCClients(chain)
+	w.customChains.Set(network.ChainID, struct{}{})
+	w.connectionsMutex.Lock()
+	w.connections = append(w.connections, conn)
+	w.connectionsMutex.Unlock()
+	return conn
+}
@@ -0,0 +1,146 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// MaxPaymentRequestMemo is the maximum length of the memo of a payment request in bytes
+const MaxPaymentRequestMemo = 140
+
+var (
+	ErrInvalidPaymentRequest = errors.New("invalid payment request")
+	ErrPaymentRequestExpired = errors.New("payment request is expired")
+	ErrPaymentRequestMemo    = fmt.Errorf("memo is longer than %d bytes", MaxPaymentRequestMemo)
+)
+
+// NewPaymentRequest returns the signed request of amount of token to the current account, token
+// is nil for the native currency, amount is in decimal such as 1.5 and zero expiry never expires
+func (w *Wallet) NewPaymentRequest(conn *evm.RPCClients, token *evm.Token, amount, memo string, expiry time.Duration) (request model.PaymentRequest, err error) {
+	memo = strings.TrimSpace(memo)
+	if len(memo) > MaxPaymentRequestMemo {
+		return request, ErrPaymentRequestMemo
+	}
+	account, err := w.Account()
+	if err != nil {
+		return request, err
+	}
+	request = model.PaymentRequest{
+		ChainID:  conn.Chain.ChainID.String(),
+		Symbol:   conn.Chain.NativeCurrency.Symbol,
+		Decimals: conn.Chain.NativeCurrency.Decimals,
+		To:       common.HexToAddress(account.EthAddress).Hex(),
+		Memo:     memo,
+	}
+	if token != nil {
+		request.TokenAddress = token.Address.Hex()
+		request.Symbol = token.Symbol
+		request.Decimals = token.Decimals
+	}
+	value, err := evm.ParseUnits(amount, request.Decimals)
+	if err != nil {
+		return request, err
+	}
+	if value.Sign() <= 0 {
+		return request, evm.ErrInvalidAmount
+	}
+	request.Amount = value.String()
+	if expiry > 0 {
+		request.ExpiresAt = time.Now().Add(expiry).UTC()
+	}
+	msg, err := paymentRequestMessage(request, account.PublicKey)
+	if err != nil {
+		return request, err
+	}
+	pvtKeyHex, err := w.GetPrivateKey(account)
+	if err != nil {
+		return request, err
+	}
+	request.Signature, err = evm.SignText(pvtKeyHex, msg)
+	return request, err
+}
+
+// VerifyPaymentRequest checks that request is signed by the key of its recipient address for
+// the chat public key of requester and that it isn't expired
+func (w *Wallet) VerifyPaymentRequest(request model.PaymentRequest, requester string) error {
+	msg, err := paymentRequestMessage(request, requester)
+	if err != nil {
+		return err
+	}
+	signer, err := evm.RecoverTextSigner(msg, request.Signature)
+	if err != nil || signer != common.HexToAddress(request.To) {
+		return ErrInvalidPaymentRequest
+	}
+	if request.IsExpired() {
+		return ErrPaymentRequestExpired
+	}
+	return nil
+}
+
+// PaymentRequestURI returns the EIP-681 uri of request
+func PaymentRequestURI(request model.PaymentRequest) (uri evm.PaymentURI, err error) {
+	chainID, ok := new(big.Int).SetString(request.ChainID, 10)
+	amount, amountOk := new(big.Int).SetString(request.Amount, 10)
+	if !ok || !amountOk || !common.IsHexAddress(request.To) {
+		return uri, ErrInvalidPaymentRequest
+	}
+	uri = evm.PaymentURI{ChainID: chainID, To: common.HexToAddress(request.To), Amount: amount}
+	if request.TokenAddress != "" {
+		if !common.IsHexAddress(request.TokenAddress) {
+			return uri, ErrInvalidPaymentRequest
+		}
+		token := common.HexToAddress(request.TokenAddress)
+		uri.Token = &token
+	}
+	return uri, nil
+}
+
+// FormatPaymentRequest returns the amount of request in currency such as 1.5 ETH, currency is read
+// with Currency as the symbol and decimals of request aren't signed
+func FormatPaymentRequest(request model.PaymentRequest, currency Currency) string {
+	return FormatPayment(model.Payment{Amount: request.Amount, Symbol: currency.Symbol, Decimals: currency.Decimals})
+}
+
+// paymentRequestMessage returns the text signed for request, the uri covers the chain, the
+// currency, the amount and the recipient address
+func paymentRequestMessage(request model.PaymentRequest, requester string) ([]byte, error) {
+	uri, err := PaymentRequestURI(request)
+	if err != nil {
+		return nil, err
+	}
+	var expiresAt int64
+	if !request.ExpiresAt.IsZero() {
+		expiresAt = request.ExpiresAt.Unix()
+	}
+	return []byte(fmt.Sprintf("Protonet payment request\nURI: %s\nMemo: %s\nExpires At: %d\nRequester: %s",
+		uri, request.Memo, expiresAt, requester)), nil
+}
+
+// Token returns the token at address of the connected chain, a token which isn't known is read
+// from the chain and isn't saved
+func (w *Wallet) Token(conn *evm.RPCClients, address common.Address) (evm.Token, error) {
+	tokens, err := w.ChainTokens(&conn.Chain.ChainID)
+	if err != nil {
+		return evm.Token{}, err
+	}
+	for _, token := range tokens {
+		if token.Address == address {
+			return token, nil
+		}
+	}
+	backend, err := conn.Backend()
+	if err != nil {
+		return evm.Token{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), verifyPaymentTimeout)
+	defer cancel()
+	token, err := evm.FetchToken(ctx, backend, &conn.Chain.ChainID, address)
+	return token, err
+}
@@ -0,0 +1,234 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/db"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// verifyPaymentTimeout limits the calls verifying a payment on chain
+const verifyPaymentTimeout = 20 * time.Second
+
+var (
+	ErrPaymentNotConnected  = errors.New("connect to the chain of the payment to verify it")
+	ErrCurrencyNotConnected = errors.New("connect to the chain to read its token")
+)
+
+// Currency is the symbol and decimals of the native currency or a token of a chain, Known is
+// false for a token which isn't in the tokens of the chain and is read from its contract
+type Currency struct {
+	Symbol   string
+	Decimals int
+	Known    bool
+}
+
+// NewPayment returns the payment of the prepared transfer sent as txHash, token is nil for the
+// native currency
+func (w *Wallet) NewPayment(conn *evm.RPCClients, token *evm.Token, prepared *evm.PreparedTx, txHash common.Hash) (model.Payment, error) {
+	payment := model.Payment{
+		ChainID:  conn.Chain.ChainID.String(),
+		Symbol:   conn.Chain.NativeCurrency.Symbol,
+		Decimals: conn.Chain.NativeCurrency.Decimals,
+		Amount:   prepared.Value.String(),
+		TxHash:   txHash.Hex(),
+		From:     prepared.From.Hex(),
+		To:       prepared.To.Hex(),
+		Status:   model.PaymentStatusPending,
+	}
+	if token == nil {
+		return payment, nil
+	}
+	to, amount, ok := evm.UnpackTransfer(prepared.Data)
+	if !ok {
+		return payment, evm.ErrNotERC20
+	}
+	payment.TokenAddress = token.Address.Hex()
+	payment.Symbol = token.Symbol
+	payment.Decimals = token.Decimals
+	payment.Amount = amount.String()
+	payment.To = to.Hex()
+	return payment, nil
+}
+
+// VerifyPayment verifies the payment of msg on chain and saves its status, a payment received by
+// the current account must be sent to its address. A confirmed payment claims its transfer, the
+// payment of another message with the same transfer is flagged as PaymentStatusDuplicate.
+func (w *Wallet) VerifyPayment(msg *model.Message) (err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	if msg.Payment == nil {
+		return model.ErrInvalidMessage
+	}
+	account, err := w.Account()
+	if err != nil {
+		return err
+	}
+	verified := *msg
+	payment := *msg.Payment
+	verified.Payment = &payment
+	payment.Status, payment.LogIndex, err = w.paymentStatus(account, msg, &payment)
+	if err != nil {
+		return err
+	}
+	if payment == *msg.Payment {
+		return nil
+	}
+	if err = w.ProtoDB.SavePaymentStatus(account.PublicKey, &verified); err != nil {
+		return err
+	}
+	*msg = verified
+	return nil
+}
+
+// paymentStatus returns the status of the payment of msg on chain and the index of the Transfer
+// log it claimed, the symbol and decimals of a confirmed payment are replaced with the ones of
+// its currency on chain as the ones claimed by the peer aren't trusted
+func (w *Wallet) paymentStatus(account model.Account, msg *model.Message, payment *model.Payment) (status int, logIndex string, err error) {
+	chainID, ok := new(big.Int).SetString(payment.ChainID, 10)
+	amount, amountOk := new(big.Int).SetString(payment.Amount, 10)
+	if !ok || !amountOk || !common.IsHexAddress(payment.From) || !common.IsHexAddress(payment.To) {
+		return model.PaymentStatusInvalid, "", nil
+	}
+	if payment.TokenAddress != "" && !common.IsHexAddress(payment.TokenAddress) {
+		return model.PaymentStatusInvalid, "", nil
+	}
+	address := payment.To
+	if msg.Sender == account.PublicKey {
+		address = payment.From
+	}
+	if !strings.EqualFold(address, account.EthAddress) {
+		return model.PaymentStatusInvalid, "", nil
+	}
+	conn, ok := w.Connection(payment.ChainID)
+	if !ok || !conn.IsConnected() {
+		return payment.Status, payment.LogIndex, ErrPaymentNotConnected
+	}
+	backend, err := conn.Backend()
+	if err != nil {
+		return payment.Status, payment.LogIndex, err
+	}
+	hash := common.HexToHash(payment.TxHash)
+	claims, err := w.ProtoDB.PaymentClaims(account.PublicKey, payment.ChainID, hash.Hex())
+	if err != nil {
+		return payment.Status, payment.LogIndex, err
+	}
+	claimed := evm.Payment{
+		ChainID: chainID,
+		Hash:    hash,
+		From:    common.HexToAddress(payment.From),
+		To:      common.HexToAddress(payment.To),
+		Amount:  amount,
+	}
+	if payment.TokenAddress != "" {
+		token := common.HexToAddress(payment.TokenAddress)
+		claimed.Token = &token
+	}
+	for _, claim := range claims {
+		if claim.MessageID == msg.ID {
+			continue
+		}
+		if claimed.Token == nil && claim.LogIndex == "" {
+			return model.PaymentStatusDuplicate, "", nil
+		}
+		if index, err := strconv.ParseUint(claim.LogIndex, 10, 32); err == nil {
+			claimed.Claimed = append(claimed.Claimed, uint(index))
+		}
+	}
+	currency, err := w.currencyOf(conn, claimed.Token)
+	if err != nil {
+		return payment.Status, payment.LogIndex, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), verifyPaymentTimeout)
+	defer cancel()
+	index, err := evm.VerifyPayment(ctx, backend, claimed)
+	switch {
+	case err == nil:
+	case errors.Is(err, ethereum.NotFound):
+		return model.PaymentStatusPending, "", nil
+	case errors.Is(err, evm.ErrPaymentFailed):
+		return model.PaymentStatusFailed, "", nil
+	case errors.Is(err, evm.ErrPaymentMismatch):
+		return model.PaymentStatusInvalid, "", nil
+	case errors.Is(err, evm.ErrPaymentClaimed):
+		return model.PaymentStatusDuplicate, "", nil
+	default:
+		return payment.Status, payment.LogIndex, err
+	}
+	claim := model.PaymentClaim{ChainID: payment.ChainID, TxHash: hash.Hex(), MessageID: msg.ID}
+	if claimed.Token != nil {
+		claim.LogIndex = strconv.FormatUint(uint64(index), 10)
+	}
+	err = w.ProtoDB.ClaimPayment(account.PublicKey, &claim)
+	if errors.Is(err, db.ErrPaymentClaimed) {
+		return model.PaymentStatusDuplicate, "", nil
+	}
+	if err != nil {
+		return payment.Status, payment.LogIndex, err
+	}
+	payment.Symbol, payment.Decimals = currency.Symbol, currency.Decimals
+	return model.PaymentStatusConfirmed, claim.LogIndex, nil
+}
+
+// Currency returns the currency of tokenAddress on the chain of chainID, an empty tokenAddress is
+// the native currency. A token which isn't known is read from the chain, which must be connected.
+func (w *Wallet) Currency(chainID, tokenAddress string) (Currency, error) {
+	conn, ok := w.Connection(chainID)
+	if !ok {
+		return Currency{}, ErrNetworkNotFound
+	}
+	if tokenAddress == "" {
+		return w.currencyOf(conn, nil)
+	}
+	if !common.IsHexAddress(tokenAddress) {
+		return Currency{}, ErrInvalidAddress
+	}
+	token := common.HexToAddress(tokenAddress)
+	return w.currencyOf(conn, &token)
+}
+
+// currencyOf returns the currency of token on the chain of conn, token is nil for the native
+// currency
+func (w *Wallet) currencyOf(conn *evm.RPCClients, token *common.Address) (Currency, error) {
+	if token == nil {
+		native := conn.Chain.NativeCurrency
+		return Currency{Symbol: native.Symbol, Decimals: native.Decimals, Known: true}, nil
+	}
+	tokens, err := w.ChainTokens(&conn.Chain.ChainID)
+	if err != nil {
+		return Currency{}, err
+	}
+	for _, t := range tokens {
+		if t.Address == *token {
+			return Currency{Symbol: t.Symbol, Decimals: t.Decimals, Known: true}, nil
+		}
+	}
+	if !conn.IsConnected() {
+		return Currency{}, ErrCurrencyNotConnected
+	}
+	t, err := w.Token(conn, *token)
+	if err != nil {
+		return Currency{}, err
+	}
+	return Currency{Symbol: t.Symbol, Decimals: t.Decimals}, nil
+}
+
+// FormatPayment returns the amount of payment with its symbol such as 1.5 ETH
+func FormatPayment(payment model.Payment) string {
+	amount, ok := new(big.Int).SetString(payment.Amount, 10)
+	if !ok {
+		return payment.Amount + " " + payment.Symbol
+	}
+	return evm.FormatUnits(amount, payment.Decimals) + " " + payment.Symbol
+}
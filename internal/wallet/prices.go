@@ -0,0 +1,240 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/evm"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// DefaultDisplayCurrency is the currency of the portfolio value unless the user chooses another
+const DefaultDisplayCurrency = "USD"
+
+// DisplayCurrencies are the currencies of the portfolio value, the feeds price in USD hence the
+// other currencies are converted with their own USD feed
+var DisplayCurrencies = []string{"USD", "EUR", "GBP", "JPY"}
+
+// priceCacheTTL is how long a price read from a feed is used before it's read again
+const priceCacheTTL = time.Minute * 5
+
+const priceCallTimeout = time.Second * 15
+
+var ErrDisplayCurrency = errors.New("display currency isn't supported")
+
+type cachedPrice struct {
+	price     *big.Float
+	fetchedAt time.Time
+}
+
+// ChainValue is the value of the balances of the current account on a chain
+type ChainValue struct {
+	ChainID *big.Int
+	Value   *big.Float
+}
+
+// PortfolioValue is the value of the balances of the current account on the connected chains in
+// Currency, Unpriced lists the assets held without a price feed which aren't counted
+type PortfolioValue struct {
+	Currency string
+	Total    *big.Float
+	Chains   []ChainValue
+	Unpriced []string
+}
+
+// ChainValue returns the value of chainID, false if the chain isn't valued
+func (p *PortfolioValue) ChainValue(chainID *big.Int) (*big.Float, bool) {
+	for _, chain := range p.Chains {
+		if chain.ChainID.Cmp(chainID) == 0 {
+			return chain.Value, true
+		}
+	}
+	return nil, false
+}
+
+// DisplayCurrency returns the currency of the portfolio value chosen by the user
+func (w *Wallet) DisplayCurrency() string {
+	settings, err := w.Settings()
+	if err != nil || settings.DisplayCurrency == "" {
+		return DefaultDisplayCurrency
+	}
+	return settings.DisplayCurrency
+}
+
+// SetDisplayCurrency saves currency as the currency of the portfolio value
+func (w *Wallet) SetDisplayCurrency(currency string) error {
+	supported := false
+	for _, c := range DisplayCurrencies {
+		supported = supported || c == currency
+	}
+	if !supported {
+		return ErrDisplayCurrency
+	}
+	settings, err := w.Settings()
+	if err != nil {
+		return err
+	}
+	settings.DisplayCurrency = currency
+	return w.SaveSettings(&settings)
+}
+
+// Price returns the price of asset such as ETH in currency, the prices are read from the
+// Chainlink feeds of the connected chains and cached for priceCacheTTL
+func (w *Wallet) Price(asset, currency string) (*big.Float, error) {
+	price, err := w.usdPrice(asset)
+	if err != nil {
+		return nil, err
+	}
+	if currency == "USD" {
+		return price, nil
+	}
+	currencyPrice, err := w.usdPrice(currency)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Float).Quo(price, currencyPrice), nil
+}
+
+// usdPrice returns the price of asset in USD from the first connected chain which has its feed
+func (w *Wallet) usdPrice(asset string) (*big.Float, error) {
+	if asset == "USD" {
+		return big.NewFloat(1), nil
+	}
+	if cached, ok := w.prices.Get(asset); ok && time.Since(cached.fetchedAt) < priceCacheTTL {
+		return cached.price, nil
+	}
+	err := evm.ErrPriceUnavailable
+	for _, feed := range evm.PriceFeeds(asset, "USD") {
+		conn := w.connectedChain(new(big.Int).SetUint64(feed.ChainID))
+		if conn == nil {
+			continue
+		}
+		var backend evm.Backend
+		if backend, err = conn.Backend(); err != nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), priceCallTimeout)
+		var price evm.Price
+		price, err = evm.FetchPrice(ctx, backend, feed)
+		cancel()
+		if err != nil {
+			alog.Logger().Errorln(fmt.Errorf("price feed %s on chain %d: %w", feed.Pair(), feed.ChainID, err))
+			continue
+		}
+		w.prices.Set(asset, cachedPrice{price: price.Float(), fetchedAt: time.Now()})
+		return price.Float(), nil
+	}
+	return nil, err
+}
+
+// connectedChain returns the connection of chainID if it's connected
+func (w *Wallet) connectedChain(chainID *big.Int) *evm.RPCClients {
+	for _, conn := range w.Connections() {
+		if conn.Chain.ChainID.Cmp(chainID) == 0 && conn.IsConnected() {
+			return conn
+		}
+	}
+	return nil
+}
+
+// PortfolioValue values the native and token balances of the current account on the connected
+// chains in the display currency. Only the native currencies of the mainnets and the built-in
+// tokens are priced, a chain without any priced asset isn't valued.
+func (w *Wallet) PortfolioValue() (portfolio PortfolioValue, err error) {
+	portfolio = PortfolioValue{Currency: w.DisplayCurrency(), Total: new(big.Float)}
+	account, err := w.Account()
+	if err != nil {
+		return portfolio, err
+	}
+	owner := common.HexToAddress(account.EthAddress)
+	for _, conn := range w.Connections() {
+		if !conn.IsConnected() {
+			continue
+		}
+		value, unpriced, valued := w.chainValue(conn, owner, portfolio.Currency)
+		portfolio.Unpriced = append(portfolio.Unpriced, unpriced...)
+		if !valued {
+			continue
+		}
+		portfolio.Chains = append(portfolio.Chains, ChainValue{ChainID: &conn.Chain.ChainID, Value: value})
+		portfolio.Total.Add(portfolio.Total, value)
+	}
+	return portfolio, nil
+}
+
+// chainValue values the balances of owner on conn in currency, valued is false if no asset of
+// the chain is priced
+func (w *Wallet) chainValue(conn *evm.RPCClients, owner common.Address, currency string) (value *big.Float, unpriced []string, valued bool) {
+	value = new(big.Float)
+	backend, err := conn.Backend()
+	if err != nil {
+		return value, nil, false
+	}
+	chainName := conn.Chain.Name
+	addValue := func(asset, symbol string, balance *big.Int, decimals int) {
+		price, err := w.Price(asset, currency)
+		if err != nil {
+			if balance.Sign() > 0 {
+				unpriced = append(unpriced, fmt.Sprintf("%s (%s)", symbol, chainName))
+			}
+			return
+		}
+		value.Add(value, new(big.Float).Mul(unitsFloat(balance, decimals), price))
+		valued = true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), priceCallTimeout)
+	defer cancel()
+	native := conn.Chain.NativeCurrency
+	balance, err := backend.BalanceAt(ctx, owner, nil)
+	if err == nil {
+		if asset, ok := evm.NativePriceAsset(&conn.Chain.ChainID); ok {
+			addValue(asset, native.Symbol, balance, native.Decimals)
+		} else if balance.Sign() > 0 {
+			unpriced = append(unpriced, fmt.Sprintf("%s (%s)", native.Symbol, chainName))
+		}
+	}
+	tokens, _ := w.ChainTokens(&conn.Chain.ChainID)
+	for _, token := range tokens {
+		asset, ok := evm.TokenPriceAsset(token)
+		if !ok {
+			continue
+		}
+		balance, err = evm.TokenBalance(ctx, backend, token.Address, owner)
+		if err != nil || balance.Sign() == 0 {
+			continue
+		}
+		addValue(asset, token.Symbol, balance, token.Decimals)
+	}
+	return value, unpriced, valued
+}
+
+// FormatFiat formats value in currency with two decimals, such as 1,234.50 USD
+func FormatFiat(value *big.Float, currency string) string {
+	if value == nil {
+		return ""
+	}
+	txt := value.Text('f', 2)
+	whole, fraction, _ := strings.Cut(txt, ".")
+	sign := ""
+	if strings.HasPrefix(whole, "-") {
+		sign, whole = "-", whole[1:]
+	}
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s.%s %s", sign, grouped.String(), fraction, currency)
+}
+
+// unitsFloat returns value in the smallest unit as a float of the whole unit
+func unitsFloat(value *big.Int, decimals int) *big.Float {
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return new(big.Float).Quo(new(big.Float).SetInt(value), scale)
+}
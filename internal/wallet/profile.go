@@ -0,0 +1,109 @@
+package wallet
+
+import (
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Profile returns the profile of the current account
+func (w *Wallet) Profile() (model.Profile, error) {
+	account, err := w.Account()
+	if err != nil {
+		return model.Profile{}, err
+	}
+	return account.Profile(), nil
+}
+
+// SetProfile saves name, status and avatar as the profile of the current account, avatar must be
+// encoded with common.EncodeAvatar, it's shared with the contacts the next time they connect
+func (w *Wallet) SetProfile(name, status string, avatar []byte) (profile model.Profile, err error) {
+	account, err := w.Account()
+	if err != nil {
+		return profile, err
+	}
+	profile = model.Profile{
+		Name:      strings.TrimSpace(name),
+		Status:    strings.TrimSpace(status),
+		Avatar:    avatar,
+		UpdatedAt: time.Now(),
+	}
+	if err = profile.Validate(); err != nil {
+		return profile, err
+	}
+	return profile, w.saveAccountProfile(account, profile)
+}
+
+// ReceiveOwnProfile saves the verified profile of the current account set on another of its
+// devices, a profile older than the saved one is ignored
+func (w *Wallet) ReceiveOwnProfile(profile model.Profile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	account, err := w.Account()
+	if err != nil {
+		return err
+	}
+	if !profile.UpdatedAt.After(account.ProfileUpdatedAt) {
+		return nil
+	}
+	return w.saveAccountProfile(account, profile)
+}
+
+func (w *Wallet) saveAccountProfile(account model.Account, profile model.Profile) error {
+	account.DisplayName = profile.Name
+	account.Status = profile.Status
+	account.PublicImage = profile.Avatar
+	account.ProfileUpdatedAt = profile.UpdatedAt
+	if err := w.ProtoDB.ReplaceAccount(&account); err != nil {
+		return err
+	}
+	w.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.AccountsChangedEventData{},
+		Topic: pubsub.AccountsChangedEventTopic,
+	})
+	return nil
+}
+
+// SaveContactProfile caches the verified profile of publicKey on its contact or its request, a
+// profile older than the cached one is ignored
+func (w *Wallet) SaveContactProfile(accountPublicKey, publicKey string, profile model.Profile) error {
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	contact, err := w.ProtoDB.Contact(accountPublicKey, publicKey)
+	if err != nil {
+		if contact, err = w.ProtoDB.ContactRequest(accountPublicKey, publicKey); err != nil {
+			return err
+		}
+	}
+	if !profile.UpdatedAt.After(contact.ProfileUpdatedAt) {
+		return nil
+	}
+	contact.ProfileName = profile.Name
+	contact.ProfileStatus = profile.Status
+	contact.Avatar = profile.Avatar
+	contact.ProfileUpdatedAt = profile.UpdatedAt
+	return w.AddUpdateContact(&contact)
+}
+
+// SetContactName sets the name the user gives to contact, it's shown instead of the name of its
+// profile, an empty name clears it
+func (w *Wallet) SetContactName(contact *model.Contact, name string) error {
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > model.MaxProfileNameLength {
+		return model.ErrProfileNameTooLong
+	}
+	saved, err := w.ProtoDB.Contact(contact.AccountPublicKey, contact.PublicKey)
+	if err != nil {
+		return err
+	}
+	saved.LocalName = name
+	if err = w.AddUpdateContact(&saved); err != nil {
+		return err
+	}
+	*contact = saved
+	return nil
+}
@@ -0,0 +1,348 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// safeCallTimeout limits the calls reading a safe from the chain
+const safeCallTimeout = 20 * time.Second
+
+// MaxSafeTxMemo is the maximum length of the memo of a safe transaction in bytes
+const MaxSafeTxMemo = 140
+
+var (
+	ErrInvalidSafeTx       = errors.New("invalid safe transaction")
+	ErrSafeNotConnected    = errors.New("connect to the chain of the safe")
+	ErrSafeTxMemo          = fmt.Errorf("memo is longer than %d bytes", MaxSafeTxMemo)
+	ErrSafeTxAlreadySigned = errors.New("safe transaction is already signed by the account")
+)
+
+// SafeTxStatus is the state of a safe transaction on chain, Approvals are the owners of the safe
+// which signed the transaction and NonceUsed is true once the safe executed a transaction with
+// its nonce, which is either the transaction or another one replacing it
+type SafeTxStatus struct {
+	Safe      evm.Safe
+	Approvals []common.Address
+	NonceUsed bool
+}
+
+// CanExecute reports whether enough owners signed the transaction to execute it
+func (s *SafeTxStatus) CanExecute() bool {
+	return !s.NonceUsed && uint64(len(s.Approvals)) >= s.Safe.Threshold
+}
+
+// ProposeSafeTransfer signs the transfer of amount of token from safe to to with the current
+// account, which must be an owner of the safe, and saves it for collecting the signatures of
+// the other owners. token is nil for the native currency and amount is in decimal such as 1.5.
+// The transaction takes the current nonce of the safe, proposals of the same nonce exclude each
+// other.
+func (w *Wallet) ProposeSafeTransfer(conn *evm.RPCClients, safe string, token *evm.Token, to, amount, memo string) (safeTx model.SafeTx, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	memo = strings.TrimSpace(memo)
+	if len(memo) > MaxSafeTxMemo {
+		return safeTx, ErrSafeTxMemo
+	}
+	if !common.IsHexAddress(safe) || !common.IsHexAddress(to) {
+		return safeTx, ErrInvalidAddress
+	}
+	decimals := conn.Chain.NativeCurrency.Decimals
+	if token != nil {
+		decimals = token.Decimals
+	}
+	value, err := evm.ParseUnits(amount, decimals)
+	if err != nil {
+		return safeTx, err
+	}
+	if value.Sign() <= 0 {
+		return safeTx, evm.ErrInvalidAmount
+	}
+	account, err := w.Account()
+	if err != nil {
+		return safeTx, err
+	}
+	backend, err := conn.Backend()
+	if err != nil {
+		return safeTx, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), safeCallTimeout)
+	defer cancel()
+	state, err := evm.FetchSafe(ctx, backend, common.HexToAddress(safe))
+	if err != nil {
+		return safeTx, err
+	}
+	if !state.IsOwner(common.HexToAddress(account.EthAddress)) {
+		return safeTx, evm.ErrSafeNotOwner
+	}
+	tx := &evm.SafeTx{
+		ChainID: &conn.Chain.ChainID,
+		Safe:    state.Address,
+		To:      common.HexToAddress(to),
+		Value:   value,
+		Nonce:   state.Nonce,
+	}
+	if token != nil {
+		tx.To, tx.Value = token.Address, new(big.Int)
+		if tx.Data, err = evm.PackTransfer(common.HexToAddress(to), value); err != nil {
+			return safeTx, err
+		}
+	}
+	hash, err := tx.Hash()
+	if err != nil {
+		return safeTx, err
+	}
+	safeTx = model.SafeTx{
+		Hash:    hash.Hex(),
+		ChainID: tx.ChainID.String(),
+		Safe:    tx.Safe.Hex(),
+		To:      tx.To.Hex(),
+		Value:   tx.Value.String(),
+		Data:    tx.Data,
+		Nonce:   tx.Nonce.String(),
+		Memo:    memo,
+	}
+	signature, err := w.signSafeTx(account, tx)
+	if err != nil {
+		return safeTx, err
+	}
+	safeTx.Signatures = map[string][]byte{common.HexToAddress(account.EthAddress).Hex(): signature}
+	err = w.ProtoDB.SaveSafeTx(&safeTx)
+	return safeTx, err
+}
+
+// ApproveSafeTx signs the saved safe transaction of hash with the current account and returns
+// the approval sent to the other owners
+func (w *Wallet) ApproveSafeTx(hash string) (approval model.SafeApproval, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	account, err := w.Account()
+	if err != nil {
+		return approval, err
+	}
+	safeTx, err := w.ProtoDB.SafeTx(hash)
+	if err != nil {
+		return approval, err
+	}
+	owner := common.HexToAddress(account.EthAddress).Hex()
+	if _, ok := safeTx.Signatures[owner]; ok {
+		return approval, ErrSafeTxAlreadySigned
+	}
+	tx, err := SafeTxOf(safeTx)
+	if err != nil {
+		return approval, err
+	}
+	signature, err := w.signSafeTx(account, tx)
+	if err != nil {
+		return approval, err
+	}
+	safeTx.Signatures = map[string][]byte{owner: signature}
+	if err = w.ProtoDB.SaveSafeTx(&safeTx); err != nil {
+		return approval, err
+	}
+	return model.SafeApproval{Hash: safeTx.Hash, Owner: owner, Signature: signature}, nil
+}
+
+// ReceiveSafeTx saves the safe transaction or the approval of a received message. The hash of
+// the transaction is computed from its fields and only the signatures recovering to their claimed
+// signer are kept, an approval is saved only for a transaction proposed earlier.
+func (w *Wallet) ReceiveSafeTx(msg *model.Message) (err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	switch {
+	case msg.SafeTx != nil:
+		safeTx := *msg.SafeTx
+		tx, err := SafeTxOf(safeTx)
+		if err != nil {
+			return err
+		}
+		hash, err := tx.Hash()
+		if err != nil {
+			return err
+		}
+		safeTx.Hash, safeTx.TxHash, safeTx.CreatedAt = hash.Hex(), "", time.Time{}
+		safeTx.Signatures = verifiedSafeSignatures(tx, msg.SafeTx.Signatures)
+		return w.ProtoDB.SaveSafeTx(&safeTx)
+	case msg.SafeApproval != nil:
+		approval := *msg.SafeApproval
+		safeTx, err := w.ProtoDB.SafeTx(approval.Hash)
+		if err != nil {
+			return err
+		}
+		tx, err := SafeTxOf(safeTx)
+		if err != nil {
+			return err
+		}
+		safeTx.Signatures = verifiedSafeSignatures(tx, map[string][]byte{approval.Owner: approval.Signature})
+		if len(safeTx.Signatures) == 0 {
+			return evm.ErrInvalidSignature
+		}
+		return w.ProtoDB.SaveSafeTx(&safeTx)
+	}
+	return model.ErrInvalidMessage
+}
+
+// SafeTxStatus reads the safe of safeTx from the chain and checks which of its owners signed it
+func (w *Wallet) SafeTxStatus(safeTx model.SafeTx) (status SafeTxStatus, err error) {
+	tx, err := SafeTxOf(safeTx)
+	if err != nil {
+		return status, err
+	}
+	conn, ok := w.Connection(safeTx.ChainID)
+	if !ok || !conn.IsConnected() {
+		return status, ErrSafeNotConnected
+	}
+	backend, err := conn.Backend()
+	if err != nil {
+		return status, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), safeCallTimeout)
+	defer cancel()
+	status.Safe, err = evm.FetchSafe(ctx, backend, tx.Safe)
+	if err != nil {
+		return status, err
+	}
+	for owner := range verifiedSafeSignatures(tx, safeTx.Signatures) {
+		if status.Safe.IsOwner(common.HexToAddress(owner)) {
+			status.Approvals = append(status.Approvals, common.HexToAddress(owner))
+		}
+	}
+	status.NonceUsed = status.Safe.Nonce.Cmp(tx.Nonce) > 0
+	return status, nil
+}
+
+// PrepareSafeExec prepares the execution of safeTx by the current account with the signatures
+// collected from the owners
+func (w *Wallet) PrepareSafeExec(safeTx model.SafeTx) (conn *evm.RPCClients, prepared *evm.PreparedTx, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	tx, err := SafeTxOf(safeTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, ok := w.Connection(safeTx.ChainID)
+	if !ok || !conn.IsConnected() {
+		return nil, nil, ErrSafeNotConnected
+	}
+	account, err := w.Account()
+	if err != nil {
+		return nil, nil, err
+	}
+	transactor, err := w.Transactor(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	signatures := make(map[common.Address][]byte)
+	for owner, signature := range verifiedSafeSignatures(tx, safeTx.Signatures) {
+		signatures[common.HexToAddress(owner)] = signature
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), safeCallTimeout)
+	defer cancel()
+	from := common.HexToAddress(account.EthAddress)
+	prepared, err = transactor.PrepareSafeExec(ctx, from, tx, signatures)
+	return conn, prepared, err
+}
+
+// ExecuteSafeTx sends the prepared execution of safeTx and saves the hash of its transaction
+func (w *Wallet) ExecuteSafeTx(conn *evm.RPCClients, safeTx model.SafeTx, prepared *evm.PreparedTx) (txHash common.Hash, err error) {
+	txHash, err = w.SendTransaction(conn, prepared)
+	if err != nil {
+		return txHash, err
+	}
+	safeTx.Signatures = nil
+	safeTx.TxHash = txHash.Hex()
+	if err = w.ProtoDB.SaveSafeTx(&safeTx); err != nil {
+		// transaction is sent already, hence it's only logged
+		alog.Logger().Errorln(err)
+	}
+	return txHash, nil
+}
+
+// FormatSafeTx returns what safeTx does such as 1.5 ETH to 0x.., a transfer of a token which
+// isn't known is in the smallest unit of the token
+func (w *Wallet) FormatSafeTx(safeTx model.SafeTx) string {
+	tx, err := SafeTxOf(safeTx)
+	if err != nil {
+		return err.Error()
+	}
+	if len(tx.Data) == 0 {
+		chain, ok := evm.ChainByID(safeTx.ChainID)
+		if !ok {
+			return fmt.Sprintf("%s wei to %s", tx.Value, tx.To.Hex())
+		}
+		currency := chain.NativeCurrency
+		return fmt.Sprintf("%s %s to %s", evm.FormatUnits(tx.Value, currency.Decimals), currency.Symbol, tx.To.Hex())
+	}
+	to, amount, ok := evm.UnpackTransfer(tx.Data)
+	if !ok || tx.Value.Sign() != 0 {
+		return fmt.Sprintf("Call of %s with %d bytes of data", tx.To.Hex(), len(tx.Data))
+	}
+	tokens, _ := w.ChainTokens(tx.ChainID)
+	for _, token := range tokens {
+		if token.Address == tx.To {
+			return fmt.Sprintf("%s %s to %s", evm.FormatUnits(amount, token.Decimals), token.Symbol, to.Hex())
+		}
+	}
+	return fmt.Sprintf("%s units of token %s to %s", amount, tx.To.Hex(), to.Hex())
+}
+
+// SafeTxOf returns the transaction of the Safe described by safeTx
+func SafeTxOf(safeTx model.SafeTx) (*evm.SafeTx, error) {
+	chainID, ok := new(big.Int).SetString(safeTx.ChainID, 10)
+	value, valueOk := new(big.Int).SetString(safeTx.Value, 10)
+	nonce, nonceOk := new(big.Int).SetString(safeTx.Nonce, 10)
+	if !ok || !valueOk || !nonceOk || value.Sign() < 0 || nonce.Sign() < 0 {
+		return nil, ErrInvalidSafeTx
+	}
+	if !common.IsHexAddress(safeTx.Safe) || !common.IsHexAddress(safeTx.To) {
+		return nil, ErrInvalidSafeTx
+	}
+	return &evm.SafeTx{
+		ChainID: chainID,
+		Safe:    common.HexToAddress(safeTx.Safe),
+		To:      common.HexToAddress(safeTx.To),
+		Value:   value,
+		Data:    safeTx.Data,
+		Nonce:   nonce,
+	}, nil
+}
+
+func (w *Wallet) signSafeTx(account model.Account, tx *evm.SafeTx) ([]byte, error) {
+	pvtKeyHex, err := w.GetPrivateKey(account)
+	if err != nil {
+		return nil, err
+	}
+	return evm.SignTypedData(pvtKeyHex, tx.TypedData())
+}
+
+// verifiedSafeSignatures returns the signatures of tx which recover to their claimed signer
+func verifiedSafeSignatures(tx *evm.SafeTx, signatures map[string][]byte) map[string][]byte {
+	verified := make(map[string][]byte)
+	for owner, signature := range signatures {
+		signer, err := evm.RecoverSafeTxSigner(tx, signature)
+		if err == nil && common.IsHexAddress(owner) && signer == common.HexToAddress(owner) {
+			verified[signer.Hex()] = signature
+		}
+	}
+	return verified
+}
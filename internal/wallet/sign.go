@@ -0,0 +1,117 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/mearaj/protonet/internal/evm"
+	"strings"
+)
+
+// SignKind is the kind of data of a SignRequest
+type SignKind int
+
+const (
+	// SignKindMessage is an EIP-191 personal message as signed by personal_sign
+	SignKindMessage = SignKind(iota)
+	// SignKindTypedData is EIP-712 typed data as signed by eth_signTypedData_v4
+	SignKindTypedData
+)
+
+var ErrSignerMismatch = errors.New("signature isn't signed by the address")
+
+// ParseSignKind returns the SignKind of "message" or "typed-data" as named on the command line
+// and in the api
+func ParseSignKind(name string) (SignKind, bool) {
+	switch name {
+	case "message":
+		return SignKindMessage, true
+	case "typed-data":
+		return SignKindTypedData, true
+	}
+	return 0, false
+}
+
+func (k SignKind) String() string {
+	if k == SignKindTypedData {
+		return "Typed Data (EIP-712)"
+	}
+	return "Personal Message (EIP-191)"
+}
+
+// SignRequest is data to sign with the current account, the data is decoded when the request is
+// created so that the user reviews what is actually signed
+type SignRequest struct {
+	Kind      SignKind
+	Message   []byte
+	TypedData apitypes.TypedData
+}
+
+// NewSignRequest decodes payload of kind, a personal message is text or 0x prefixed hex and typed
+// data is json
+func NewSignRequest(kind SignKind, payload string) (request SignRequest, err error) {
+	request.Kind = kind
+	switch kind {
+	case SignKindMessage:
+		request.Message = evm.DecodeTextMessage(payload)
+	case SignKindTypedData:
+		request.TypedData, err = evm.ParseTypedData([]byte(payload))
+	default:
+		err = fmt.Errorf("unknown sign kind %d", kind)
+	}
+	return request, err
+}
+
+// Payload returns the decoded data of the request as text for review
+func (r SignRequest) Payload() (string, error) {
+	if r.Kind == SignKindTypedData {
+		return evm.FormatTypedData(r.TypedData)
+	}
+	return evm.FormatTextMessage(r.Message), nil
+}
+
+// Signer returns the address which signed the request with signature
+func (r SignRequest) Signer(signature []byte) (common.Address, error) {
+	if r.Kind == SignKindTypedData {
+		return evm.RecoverTypedDataSigner(r.TypedData, signature)
+	}
+	return evm.RecoverTextSigner(r.Message, signature)
+}
+
+// Verify checks that signature, in 0x prefixed hex, of the request is signed by address
+func (r SignRequest) Verify(address, signature string) error {
+	address = strings.TrimSpace(address)
+	if !common.IsHexAddress(address) {
+		return ErrInvalidAddress
+	}
+	sig, err := hexutil.Decode(strings.TrimSpace(signature))
+	if err != nil {
+		return evm.ErrInvalidSignature
+	}
+	signer, err := r.Signer(sig)
+	if err != nil {
+		return err
+	}
+	if signer != common.HexToAddress(address) {
+		return ErrSignerMismatch
+	}
+	return nil
+}
+
+// Sign signs the request with the current account, the wallet must be unlocked
+func (w *Wallet) Sign(request SignRequest) ([]byte, error) {
+	account, err := w.Account()
+	if err != nil {
+		return nil, err
+	}
+	pvtKeyHex, err := w.GetPrivateKey(account)
+	if err != nil {
+		return nil, err
+	}
+	if request.Kind == SignKindTypedData {
+		return evm.SignTypedData(pvtKeyHex, request.TypedData)
+	}
+	return evm.SignText(pvtKeyHex, request.Message)
+}
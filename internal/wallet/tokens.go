@@ -0,0 +1,120 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"math/big"
+)
+
+var ErrTokenExists = errors.New("token is already added")
+
+// ChainTokens returns the built-in tokens of chainID followed by the tokens added by the user
+func (w *Wallet) ChainTokens(chainID *big.Int) ([]evm.Token, error) {
+	tokens := evm.BuiltinTokens(chainID)
+	customTokens, err := w.ProtoDB.Tokens(chainID.String())
+	if err != nil {
+		return tokens, err
+	}
+	for _, t := range customTokens {
+		tokens = append(tokens, evm.Token{
+			ChainID:  chainID,
+			Address:  common.HexToAddress(t.Address),
+			Name:     t.Name,
+			Symbol:   t.Symbol,
+			Decimals: t.Decimals,
+			Custom:   true,
+		})
+	}
+	return tokens, nil
+}
+
+// AddToken reads the token contract at address on the connected chain and saves it
+func (w *Wallet) AddToken(conn *evm.RPCClients, address string) (token evm.Token, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	if !common.IsHexAddress(address) {
+		return token, ErrInvalidAddress
+	}
+	chainID := &conn.Chain.ChainID
+	tokenAddress := common.HexToAddress(address)
+	tokens, err := w.ChainTokens(chainID)
+	if err != nil {
+		return token, err
+	}
+	for _, t := range tokens {
+		if t.Address == tokenAddress {
+			return token, ErrTokenExists
+		}
+	}
+	backend, err := conn.Backend()
+	if err != nil {
+		return token, err
+	}
+	token, err = evm.FetchToken(context.Background(), backend, chainID, tokenAddress)
+	if err != nil {
+		return token, err
+	}
+	err = w.ProtoDB.SaveToken(&model.Token{
+		ChainID:  chainID.String(),
+		Address:  token.Address.Hex(),
+		Name:     token.Name,
+		Symbol:   token.Symbol,
+		Decimals: token.Decimals,
+	})
+	return token, err
+}
+
+// RemoveToken removes a token added by the user, built-in tokens can't be removed
+func (w *Wallet) RemoveToken(token evm.Token) error {
+	if !token.Custom {
+		return model.ErrInvalidToken
+	}
+	return w.ProtoDB.DeleteToken(&model.Token{ChainID: token.ChainID.String(), Address: token.Address.Hex()})
+}
+
+// TokenBalance returns the token balance of the current account
+func (w *Wallet) TokenBalance(conn *evm.RPCClients, token evm.Token) (*big.Int, error) {
+	account, err := w.Account()
+	if err != nil {
+		return nil, err
+	}
+	backend, err := conn.Backend()
+	if err != nil {
+		return nil, err
+	}
+	return evm.TokenBalance(context.Background(), backend, token.Address, common.HexToAddress(account.EthAddress))
+}
+
+// PrepareTokenTransfer prepares the transfer of amount of token from the current account to
+// toAddress, amount is in decimal such as 1.5
+func (w *Wallet) PrepareTokenTransfer(conn *evm.RPCClients, token evm.Token, toAddress, amount string) (prepared *evm.PreparedTx, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	if !common.IsHexAddress(toAddress) {
+		return nil, ErrInvalidAddress
+	}
+	value, err := evm.ParseUnits(amount, token.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	account, err := w.Account()
+	if err != nil {
+		return nil, err
+	}
+	transactor, err := w.Transactor(conn)
+	if err != nil {
+		return nil, err
+	}
+	from := common.HexToAddress(account.EthAddress)
+	return transactor.PrepareTokenTransfer(context.Background(), from, token, common.HexToAddress(toAddress), value)
+}
@@ -0,0 +1,144 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"math/big"
+	"time"
+)
+
+// pendingTxTimeout is the duration after which a pending transaction is no longer tracked
+const pendingTxTimeout = time.Hour
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+// Transactor returns the Transactor of the connected chain
+func (w *Wallet) Transactor(conn *evm.RPCClients) (*evm.Transactor, error) {
+	backend, err := conn.Backend()
+	if err != nil {
+		return nil, err
+	}
+	return evm.NewTransactor(backend, &conn.Chain.ChainID, w.nonces), nil
+}
+
+// PrepareNativeTransfer prepares the transfer of amount in native currency of chain from the
+// current account to toAddress, amount is in decimal such as 1.5
+func (w *Wallet) PrepareNativeTransfer(conn *evm.RPCClients, toAddress, amount string) (prepared *evm.PreparedTx, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	if !common.IsHexAddress(toAddress) {
+		return nil, ErrInvalidAddress
+	}
+	value, err := evm.ParseUnits(amount, conn.Chain.NativeCurrency.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	account, err := w.Account()
+	if err != nil {
+		return nil, err
+	}
+	transactor, err := w.Transactor(conn)
+	if err != nil {
+		return nil, err
+	}
+	from := common.HexToAddress(account.EthAddress)
+	return transactor.PrepareTransfer(context.Background(), from, common.HexToAddress(toAddress), value)
+}
+
+// SendTransaction signs the prepared transaction with the key of the current account, sends it
+// and tracks it until it's mined
+func (w *Wallet) SendTransaction(conn *evm.RPCClients, prepared *evm.PreparedTx) (txHash common.Hash, err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	account, err := w.Account()
+	if err != nil {
+		return txHash, err
+	}
+	pvtKeyHex, err := w.GetPrivateKey(account)
+	if err != nil {
+		return txHash, err
+	}
+	transactor, err := w.Transactor(conn)
+	if err != nil {
+		return txHash, err
+	}
+	tx, err := transactor.Send(context.Background(), prepared, pvtKeyHex)
+	if err != nil {
+		return txHash, err
+	}
+	pendingTx := evm.PendingTx{
+		Hash:    tx.Hash(),
+		ChainID: prepared.ChainID,
+		From:    prepared.From,
+		To:      prepared.To,
+		Value:   prepared.Value,
+		Nonce:   tx.Nonce(),
+		SentAt:  time.Now(),
+	}
+	w.pendingTxs.Set(pendingTx.Hash, pendingTx)
+	if err = w.saveOutgoingTransaction(conn, pendingTx, prepared.Data); err != nil {
+		// transaction is sent already, hence it's only logged
+		alog.Logger().Errorln(err)
+		err = nil
+	}
+	w.EventBroker.Fire(pubsub.Event{
+		Data: pubsub.TransactionSentEventData{
+			ChainID: pendingTx.ChainID,
+			Hash:    pendingTx.Hash,
+			From:    pendingTx.From,
+			To:      pendingTx.To,
+			Value:   pendingTx.Value,
+		},
+		Topic: pubsub.TransactionSentEventTopic,
+	})
+	go w.trackTransaction(transactor, pendingTx)
+	return pendingTx.Hash, nil
+}
+
+// PendingTransactions returns the transactions of address on chainID which aren't mined yet
+func (w *Wallet) PendingTransactions(chainID *big.Int, address common.Address) []evm.PendingTx {
+	pendingTxs := make([]evm.PendingTx, 0)
+	for _, tx := range w.pendingTxs.Values() {
+		if tx.ChainID.Cmp(chainID) == 0 && tx.From == address {
+			pendingTxs = append(pendingTxs, tx)
+		}
+	}
+	return pendingTxs
+}
+
+func (w *Wallet) trackTransaction(transactor *evm.Transactor, pendingTx evm.PendingTx) {
+	ctx, cancel := context.WithTimeout(context.Background(), pendingTxTimeout)
+	defer cancel()
+	receipt, err := transactor.WaitMined(ctx, pendingTx.Hash)
+	w.pendingTxs.Delete(pendingTx.Hash)
+	if updateErr := w.updateTransactionStatus(pendingTx, receipt); updateErr != nil {
+		alog.Logger().Errorln(updateErr)
+	}
+	if err != nil {
+		alog.Logger().Errorln(err)
+		// nonce of a transaction which isn't mined can't be relied on anymore
+		if receipt == nil {
+			w.nonces.Reset(pendingTx.ChainID, pendingTx.From)
+		}
+	}
+	w.EventBroker.Fire(pubsub.Event{
+		Data: pubsub.TransactionMinedEventData{
+			ChainID: pendingTx.ChainID,
+			Hash:    pendingTx.Hash,
+			From:    pendingTx.From,
+			Receipt: receipt,
+		},
+		Topic: pubsub.TransactionMinedEventTopic,
+		Err:   err,
+	})
+}
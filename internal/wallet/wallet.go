@@ -1,17 +1,22 @@
 package wallet
 
 import (
-	"encoding/hex"
+	"context"
 	"errors"
-	libcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	common2 "github.com/ethereum/go-ethereum/common"
 	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/internal/common"
 	"github.com/mearaj/protonet/internal/db"
 	"github.com/mearaj/protonet/internal/evm"
 	_ "github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/hdwallet"
 	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
 	"github.com/mearaj/protonet/utils"
+	"math/big"
 	"strings"
+	"sync"
+	"time"
 )
 
 var GlobalWallet = New()
@@ -22,16 +27,62 @@ var _ Manager = &Wallet{}
 const defaultChainMediator = "https://rpc.ntity.io"
 
 type Manager interface {
-	CreateAccount(privateKeyHex string) error
-	AutoCreateAccount() error
+	CreateAccount(privateKeyHex, algorithm string) error
+	AutoCreateAccount(algorithm string) error
+	ImportMnemonic(mnemonic, algorithm string) error
+	ImportKeystore(keyJSON []byte, passphrase, algorithm string) error
+	ExportKeystore(account model.Account, passphrase string) ([]byte, error)
+	Mnemonic(account model.Account) (string, error)
 	Connections() []*evm.RPCClients
+	ConnectChain(conn *evm.RPCClients)
+	DisconnectChain(conn *evm.RPCClients)
+	Lock()
+	IsUnlocked() bool
+	Touch()
+	SetAutoLockTimeout(timeout time.Duration)
+	GetPrivateKey(account model.Account) (string, error)
+	PrepareNativeTransfer(conn *evm.RPCClients, toAddress, amount string) (*evm.PreparedTx, error)
+	SendTransaction(conn *evm.RPCClients, prepared *evm.PreparedTx) (common2.Hash, error)
+	PendingTransactions(chainID *big.Int, address common2.Address) []evm.PendingTx
+	ChainTokens(chainID *big.Int) ([]evm.Token, error)
+	AddToken(conn *evm.RPCClients, address string) (evm.Token, error)
+	RemoveToken(token evm.Token) error
+	TokenBalance(conn *evm.RPCClients, token evm.Token) (*big.Int, error)
+	PrepareTokenTransfer(conn *evm.RPCClients, token evm.Token, toAddress, amount string) (*evm.PreparedTx, error)
+	TransactionHistory(chainID *big.Int) ([]model.Transaction, error)
+	ScanTransactionHistory(conn *evm.RPCClients) error
+	AddNetwork(chainID, name, symbol string, decimals int, rpcURL, explorerURL string) (*evm.RPCClients, error)
+	RemoveNetwork(conn *evm.RPCClients) error
+	AddRPC(conn *evm.RPCClients, rpcURL string) error
+	RemoveRPC(conn *evm.RPCClients, rpc evm.RPC) error
+	SetAPIKey(conn *evm.RPCClients, rpc evm.RPC, apiKey string) error
+	SetFavoriteChain(chainID string, favorite bool) error
+	SetFavoriteRPC(rpc evm.RPC, favorite bool) error
+	LoadChainList(data []byte) (added, updated int, err error)
+	Sign(request SignRequest) ([]byte, error)
 }
 
 type Wallet struct {
-	connections []*evm.RPCClients
+	connections      []*evm.RPCClients
+	connectionsMutex sync.RWMutex
+	// customChains are the chain ids in decimal of the chains added by the user
+	customChains utils.Map[string, struct{}]
+	// customRPCs are the rpc urls added by the user
+	customRPCs utils.Map[string, struct{}]
 	*db.ProtoDB
+	keys           *keyCache
+	autoLock       autoLock
+	nonces         *evm.NonceManager
+	pendingTxs     utils.Map[common2.Hash, evm.PendingTx]
+	watchers       utils.Map[string, context.CancelFunc]
 	FavoriteChains utils.Map[string, struct{}]
 	FavoriteRPCs   utils.Map[string, struct{}]
+	dApps          dApps
+	// prices are the USD prices read from the feeds by asset
+	prices utils.Map[string, cachedPrice]
+	// lockedChains are the chains disconnected by Lock, they're connected again on unlock
+	lockedChains  utils.Map[string, struct{}]
+	lockedAccount lockedAccount
 }
 
 var _ Manager = &Wallet{}
@@ -40,12 +91,22 @@ func New() *Wallet {
 	wa := &Wallet{}
 	wa.connections = evm.GetAllRPCClients()
 	wa.ProtoDB = db.New()
+	wa.keys = newKeyCache()
+	wa.nonces = evm.NewNonceManager()
+	wa.pendingTxs = utils.NewMap[common2.Hash, evm.PendingTx]()
+	wa.watchers = utils.NewMap[string, context.CancelFunc]()
+	wa.lockedChains = utils.NewMap[string, struct{}]()
+	wa.customChains = utils.NewMap[string, struct{}]()
+	wa.customRPCs = utils.NewMap[string, struct{}]()
+	go wa.runAutoLock()
 	wa.FavoriteChains = utils.NewMap[string, struct{}]()
 	wa.FavoriteRPCs = utils.NewMap[string, struct{}]()
+	wa.prices = utils.NewMap[string, cachedPrice]()
 	return wa
 }
 
-func (w *Wallet) CreateAccount(pvtKeyHex string) (err error) {
+// CreateAccount saves the account of pvtKeyHex with its chat identity of algorithm
+func (w *Wallet) CreateAccount(pvtKeyHex, algorithm string) (err error) {
 	if strings.TrimSpace(pvtKeyHex) == "" {
 		err = errors.New("private key is empty")
 		return
@@ -58,68 +119,192 @@ func (w *Wallet) CreateAccount(pvtKeyHex string) (err error) {
 			alog.Logger().Errorln(err)
 		}
 	}()
-	algo := libcrypto.ECDSA
 	if len(pvtKeyHex) == 242 {
 		pvtKeyHex = pvtKeyHex[len(pvtKeyHex)-64:]
 	}
-	pvtKey, err := common.GetPrivateKeyFromStr(pvtKeyHex, algo)
+	return w.createAccount(pvtKeyHex, "", "", algorithm)
+}
+
+// AutoCreateAccount derives the next HD account with its chat identity of algorithm, a new
+// mnemonic is created if it doesn't exist yet
+func (w *Wallet) AutoCreateAccount(algorithm string) (err error) {
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}()
+	hdSeed, err := w.ProtoDB.HDSeed()
+	if errors.Is(err, db.ErrHDSeedNotFound) {
+		var mnemonic string
+		mnemonic, err = hdwallet.NewMnemonic()
+		if err != nil {
+			return err
+		}
+		return w.ImportMnemonic(mnemonic, algorithm)
+	}
 	if err != nil {
-		return
+		return err
 	}
-	pubKeyBytes, err := pvtKey.GetPublic().Raw()
+	seed, err := w.keys.decrypt(hdSeed.Seed)
 	if err != nil {
-		return
+		return err
 	}
-	publicKeyStr := hex.EncodeToString(pubKeyBytes)
-	pvtKeyBytes, err := hex.DecodeString(pvtKeyHex)
+	defer wipe(seed)
+	return w.deriveNextAccount(&hdSeed, seed, algorithm)
+}
+
+// createAccount saves the account of pvtKeyHex, hdPath and hdSeedID are empty for imported keys.
+// The chat identity of the account is derived from pvtKeyHex with algorithm.
+func (w *Wallet) createAccount(pvtKeyHex, hdPath, hdSeedID, algorithm string) (err error) {
+	publicKeyStr, err := common.IdentityPublicKey(pvtKeyHex, algorithm)
 	if err != nil {
-		return
+		return err
+	}
+	ethAddress, err := common.GetEthAddress(pvtKeyHex)
+	if err != nil {
+		return err
 	}
-	pvtKeyEnc := hex.EncodeToString(pvtKeyBytes)
-	ethAddress, err := common.GetEthAddress(pvtKeyEnc)
+	return w.addAccount(pvtKeyHex, publicKeyStr, ethAddress, hdPath, hdSeedID, algorithm)
+}
+
+// addAccount encrypts pvtKeyHex with the user password and saves the account as primary account
+func (w *Wallet) addAccount(pvtKeyHex, publicKeyStr, ethAddress, hdPath, hdSeedID, algorithm string) (err error) {
+	pvtKeyEnc, err := w.keys.encryptPrivateKey(publicKeyStr, pvtKeyHex)
 	if err != nil {
 		return err
 	}
 	account := model.Account{
-		PrivateKey: pvtKeyEnc,
-		PublicKey:  publicKeyStr,
-		EthAddress: ethAddress,
+		PrivateKey:        pvtKeyEnc,
+		KeyEncrypted:      true,
+		PublicKey:         publicKeyStr,
+		EthAddress:        ethAddress,
+		HDPath:            hdPath,
+		HDSeedID:          hdSeedID,
+		IdentityAlgorithm: algorithm,
 	}
 	err = w.ProtoDB.AddUpdateAccount(&account)
+	if err != nil {
+		w.keys.delete(publicKeyStr)
+	}
 	return err
 }
 
-func (w *Wallet) AutoCreateAccount() (err error) {
+// OpenFromPassword opens the database and unlocks the wallet with the same password, the
+// database is closed while the wallet is locked
+func (w *Wallet) OpenFromPassword(passwd string) (err error) {
+	err = w.ProtoDB.OpenFromPassword(passwd)
+	if err != nil {
+		return err
+	}
+	return w.unlock(passwd)
+}
+
+// unlock caches the password and decrypts the private keys of all the accounts in memory, the
+// database is just opened with passwd. Plaintext private keys saved by previous versions are
+// encrypted and saved back.
+func (w *Wallet) unlock(passwd string) (err error) {
 	defer func() {
 		if err != nil {
+			w.keys.lock()
+			_ = w.ProtoDB.Close()
 			alog.Logger().Errorln(err)
 		}
 	}()
-	_, pvtKeyStr, _, publicKeyStr, _, err := common.CreatePrivateKey(libcrypto.ECDSA)
+	w.keys.unlock(passwd)
+	accounts, err := w.ProtoDB.Accounts()
 	if err != nil {
 		return err
 	}
-	if len(pvtKeyStr) == 242 {
-		pvtKeyStr = pvtKeyStr[len(pvtKeyStr)-64:]
+	for i := range accounts {
+		if accounts[i].KeyEncrypted {
+			err = w.keys.decryptPrivateKey(accounts[i])
+		} else {
+			err = w.migrateAccountKey(&accounts[i])
+		}
+		if err != nil {
+			return err
+		}
 	}
-	ethAddress, err := common.GetEthAddress(pvtKeyStr)
+	settings, err := w.ProtoDB.Settings()
 	if err != nil {
-		return
+		return err
 	}
-	pvtKeyBytes, err := hex.DecodeString(pvtKeyStr)
+	w.SetAutoLockTimeout(settings.AutoLockTimeout)
+	err = w.loadNetworks(settings)
 	if err != nil {
-		return
+		return err
 	}
-	pvtKeyEnc := hex.EncodeToString(pvtKeyBytes)
-	account := model.Account{
-		PrivateKey: pvtKeyEnc,
-		PublicKey:  publicKeyStr,
-		EthAddress: ethAddress,
+	w.clearLockedAccount()
+	w.Touch()
+	w.reconnectLockedChains()
+	w.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.WalletUnlockedEventData{},
+		Topic: pubsub.WalletUnlockedEventTopic,
+	})
+	return nil
+}
+
+// migrateAccountKey encrypts the plaintext private key of account and saves it in place
+func (w *Wallet) migrateAccountKey(account *model.Account) error {
+	pvtKeyEnc, err := w.keys.encryptPrivateKey(account.PublicKey, account.PrivateKey)
+	if err != nil {
+		return err
+	}
+	account.PrivateKey = pvtKeyEnc
+	account.KeyEncrypted = true
+	return w.ProtoDB.ReplaceAccount(account)
+}
+
+// GetPrivateKey returns the decrypted hex private key of account, it returns ErrWalletLocked
+// if the wallet is locked
+func (w *Wallet) GetPrivateKey(account model.Account) (pvtKeyHex string, err error) {
+	pvtKeyHex, err = w.keys.get(account.PublicKey)
+	if errors.Is(err, ErrKeyNotInCache) && account.KeyEncrypted {
+		err = w.keys.decryptPrivateKey(account)
+		if err != nil {
+			return "", err
+		}
+		return w.keys.get(account.PublicKey)
+	}
+	return pvtKeyHex, err
+}
+
+// DeleteAccounts deletes accounts from database and their keys from memory
+func (w *Wallet) DeleteAccounts(accounts []model.Account) (err error) {
+	err = w.ProtoDB.DeleteAccounts(accounts)
+	for _, a := range accounts {
+		w.keys.delete(a.PublicKey)
 	}
-	err = w.ProtoDB.AddUpdateAccount(&account)
 	return err
 }
 
+// Connections returns the embedded chains followed by the custom chains
 func (w *Wallet) Connections() []*evm.RPCClients {
-	return w.connections
+	w.connectionsMutex.RLock()
+	defer w.connectionsMutex.RUnlock()
+	connections := make([]*evm.RPCClients, len(w.connections))
+	copy(connections, w.connections)
+	return connections
+}
+
+// ConnectChain connects the endpoints of chain and keeps probing their health in background,
+// the current account is watched live over the websocket endpoints of chain
+func (w *Wallet) ConnectChain(conn *evm.RPCClients) {
+	w.startWatching(conn)
+	conn.StartProber(func() {
+		w.EventBroker.Fire(pubsub.Event{
+			Data:  pubsub.RPCHealthChangedEventData{ChainID: conn.Chain.ChainID.String()},
+			Topic: pubsub.RPCHealthChangedEventTopic,
+		})
+	})
+}
+
+// DisconnectChain stops probing and watching chain and disconnects its endpoints
+func (w *Wallet) DisconnectChain(conn *evm.RPCClients) {
+	w.stopWatching(conn)
+	conn.Disconnect()
+	w.EventBroker.Fire(pubsub.Event{
+		Data:  pubsub.RPCHealthChangedEventData{ChainID: conn.Chain.ChainID.String()},
+		Topic: pubsub.RPCHealthChangedEventTopic,
+	})
 }
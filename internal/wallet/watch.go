@@ -0,0 +1,150 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"math/big"
+	"time"
+)
+
+// resubscribeDelay is the wait before subscribing again after a subscription fails or when no
+// websocket endpoint of the chain is connected yet
+const resubscribeDelay = 15 * time.Second
+
+// startWatching subscribes to the current account's balance and transfers on chain over its
+// websocket endpoints until stopWatching
+func (w *Wallet) startWatching(conn *evm.RPCClients) {
+	chainID := conn.Chain.ChainID.String()
+	if _, ok := w.watchers.Get(chainID); ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.watchers.Set(chainID, cancel)
+	go w.watchChain(ctx, conn)
+}
+
+func (w *Wallet) stopWatching(conn *evm.RPCClients) {
+	chainID := conn.Chain.ChainID.String()
+	if cancel, ok := w.watchers.Get(chainID); ok {
+		cancel()
+		w.watchers.Delete(chainID)
+	}
+}
+
+// watchChain watches the current account until ctx is done, the watch restarts when the
+// current account changes
+func (w *Wallet) watchChain(ctx context.Context, conn *evm.RPCClients) {
+	sub := pubsub.AddSubscriber(w.EventBroker, pubsub.CurrentAccountChangedEventTopic)
+	defer sub.Close()
+	for {
+		watchCtx, cancel := context.WithCancel(ctx)
+		if account, err := w.Account(); err == nil && account.EthAddress != "" {
+			go w.watchAccount(watchCtx, conn, common.HexToAddress(account.EthAddress))
+		}
+		select {
+		case <-ctx.Done():
+			cancel()
+			return
+		case <-sub.Events():
+			cancel()
+		}
+	}
+}
+
+// watchAccount runs the watcher of address and subscribes again whenever it fails
+func (w *Wallet) watchAccount(ctx context.Context, conn *evm.RPCClients, address common.Address) {
+	for {
+		err := w.runAccountWatcher(ctx, conn, address)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && !errors.Is(err, evm.ErrNoWebSocket) {
+			alog.Logger().Errorln(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+func (w *Wallet) runAccountWatcher(ctx context.Context, conn *evm.RPCClients, address common.Address) error {
+	backend, err := conn.SubscriptionBackend()
+	if err != nil {
+		return err
+	}
+	chainID := &conn.Chain.ChainID
+	tokens, err := w.ChainTokens(chainID)
+	if err != nil {
+		return err
+	}
+	tokensByAddress := make(map[common.Address]evm.Token, len(tokens))
+	tokenAddresses := make([]common.Address, 0, len(tokens))
+	for _, token := range tokens {
+		tokensByAddress[token.Address] = token
+		tokenAddresses = append(tokenAddresses, token.Address)
+	}
+	watcher := evm.AccountWatcher{
+		Backend: backend,
+		ChainID: chainID,
+		Address: address,
+		Tokens:  tokenAddresses,
+		OnBalance: func(balance *big.Int) {
+			w.fireBalanceChanged(chainID, address, nil, balance)
+		},
+		OnTransfer: func(transfer evm.Transfer) {
+			if transfer.Token != nil {
+				balance, err := evm.TokenBalance(ctx, backend, *transfer.Token, address)
+				if err != nil {
+					alog.Logger().Errorln(err)
+				} else {
+					w.fireBalanceChanged(chainID, address, transfer.Token, balance)
+				}
+			}
+			if transfer.To != address {
+				return
+			}
+			if err := w.saveIncomingTransfer(conn, address.Hex(), transfer, tokensByAddress); err != nil {
+				alog.Logger().Errorln(err)
+			}
+			w.fireIncomingTransfer(conn, transfer, tokensByAddress)
+		},
+	}
+	return watcher.Watch(ctx)
+}
+
+func (w *Wallet) fireBalanceChanged(chainID *big.Int, address common.Address, token *common.Address, balance *big.Int) {
+	w.EventBroker.Fire(pubsub.Event{
+		Data: pubsub.BalanceChangedEventData{
+			ChainID: chainID,
+			Address: address,
+			Token:   token,
+			Balance: balance,
+		},
+		Topic: pubsub.BalanceChangedEventTopic,
+	})
+}
+
+func (w *Wallet) fireIncomingTransfer(conn *evm.RPCClients, transfer evm.Transfer, tokens map[common.Address]evm.Token) {
+	data := pubsub.IncomingTransferEventData{
+		ChainID:  &conn.Chain.ChainID,
+		Hash:     transfer.Hash,
+		From:     transfer.From,
+		To:       transfer.To,
+		Token:    transfer.Token,
+		Value:    transfer.Value,
+		Symbol:   conn.Chain.NativeCurrency.Symbol,
+		Decimals: conn.Chain.NativeCurrency.Decimals,
+	}
+	if transfer.Token != nil {
+		token := tokens[*transfer.Token]
+		data.Symbol = token.Symbol
+		data.Decimals = token.Decimals
+	}
+	w.EventBroker.Fire(pubsub.Event{Data: data, Topic: pubsub.IncomingTransferEventTopic})
+}
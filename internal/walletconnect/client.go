@@ -0,0 +1,383 @@
+// Package walletconnect implements the wallet side of the WalletConnect v2 sign protocol. A dApp
+// is paired by its uri, its session proposal is approved or rejected by the user and the
+// requests of the approved sessions are answered with the results of the wallet. Messages are
+// encrypted end to end and exchanged through a relay server.
+package walletconnect
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/utils"
+	"sync"
+	"time"
+)
+
+var (
+	ErrProposalNotFound = errors.New("session proposal not found")
+	ErrSessionNotFound  = errors.New("session not found")
+)
+
+// Handler receives the proposals and the requests of dApps, they are answered with the methods of
+// Client once the user decides
+type Handler interface {
+	OnProposal(proposal Proposal)
+	OnRequest(request Request)
+	// OnSessionsChanged is called when a session is settled or deleted
+	OnSessionsChanged()
+}
+
+// Options configures a Client, RelayURL is DefaultRelayURL if it's empty. The public relay
+// requires a ProjectID, a local relay for tests doesn't.
+type Options struct {
+	RelayURL  string
+	ProjectID string
+	Metadata  Metadata
+	Handler   Handler
+}
+
+// Client is connected to the relay and holds the pairings and sessions of the wallet, they
+// aren't persisted and last until the client is closed
+type Client struct {
+	Options
+	relay *relayClient
+	// pairings are the symmetric keys of the pairing topics
+	pairings  utils.Map[string, []byte]
+	proposals utils.Map[int64, Proposal]
+	sessions  utils.Map[string, Session]
+	// handled are the ids of the requests already handled, the relay may deliver a message twice
+	handled      map[int64]struct{}
+	handledMutex sync.Mutex
+}
+
+func NewClient(options Options) (*Client, error) {
+	c := &Client{
+		Options:   options,
+		pairings:  utils.NewMap[string, []byte](),
+		proposals: utils.NewMap[int64, Proposal](),
+		sessions:  utils.NewMap[string, Session](),
+		handled:   map[int64]struct{}{},
+	}
+	var err error
+	c.relay, err = newRelayClient(options.RelayURL, options.ProjectID, c.onMessage)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Pair subscribes to the pairing topic of uri, the session proposal of the dApp follows
+func (c *Client) Pair(ctx context.Context, uri string) error {
+	pairing, err := ParsePairingURI(uri)
+	if err != nil {
+		return err
+	}
+	c.pairings.Set(pairing.Topic, pairing.SymKey)
+	if err = c.relay.subscribe(ctx, pairing.Topic); err != nil {
+		c.pairings.Delete(pairing.Topic)
+		return err
+	}
+	return nil
+}
+
+// Approve settles a session for the proposal with namespaces, the accounts of namespaces are
+// the accounts shared with the dApp
+func (c *Client) Approve(ctx context.Context, proposal Proposal, namespaces map[string]Namespace) (session Session, err error) {
+	if _, ok := c.proposals.Get(proposal.ID); !ok {
+		return session, ErrProposalNotFound
+	}
+	pairingKey, ok := c.pairings.Get(proposal.PairingTopic)
+	if !ok {
+		return session, ErrProposalNotFound
+	}
+	proposerKey, err := hex.DecodeString(proposal.Proposer.PublicKey)
+	if err != nil || len(proposerKey) != keySize {
+		return session, ErrInvalidEnvelope
+	}
+	keys, err := newKeyPair()
+	if err != nil {
+		return session, err
+	}
+	symKey, err := keys.sharedKey(proposerKey)
+	if err != nil {
+		return session, err
+	}
+	session = Session{
+		Topic:        topicOf(symKey),
+		PairingTopic: proposal.PairingTopic,
+		Peer:         proposal.Proposer.Metadata,
+		Namespaces:   namespaces,
+		ExpiresAt:    time.Now().Add(sessionTTL),
+		symKey:       symKey,
+	}
+	if err = c.relay.subscribe(ctx, session.Topic); err != nil {
+		return session, err
+	}
+	result := sessionProposeResult{
+		Relay:              relay{Protocol: RelayProtocol},
+		ResponderPublicKey: hex.EncodeToString(keys.public),
+	}
+	err = c.respond(ctx, proposal.PairingTopic, pairingKey, proposal.ID, result, tagSessionProposeRes)
+	if err != nil {
+		return session, err
+	}
+	c.proposals.Delete(proposal.ID)
+	c.sessions.Set(session.Topic, session)
+	settle := sessionSettleParams{
+		Relay:      relay{Protocol: RelayProtocol},
+		Namespaces: namespaces,
+		Controller: participant{
+			PublicKey: hex.EncodeToString(keys.public),
+			Metadata:  c.Metadata,
+		},
+		Expiry:       session.ExpiresAt.Unix(),
+		PairingTopic: proposal.PairingTopic,
+	}
+	err = c.request(ctx, session.Topic, symKey, "wc_sessionSettle", settle, tagSessionSettle)
+	if err != nil {
+		c.sessions.Delete(session.Topic)
+		return session, err
+	}
+	c.Handler.OnSessionsChanged()
+	return session, nil
+}
+
+// Reject rejects the proposal with the error code and message
+func (c *Client) Reject(ctx context.Context, proposal Proposal, code int, message string) error {
+	c.proposals.Delete(proposal.ID)
+	pairingKey, ok := c.pairings.Get(proposal.PairingTopic)
+	if !ok {
+		return ErrProposalNotFound
+	}
+	return c.respondError(ctx, proposal.PairingTopic, pairingKey, proposal.ID, &Error{Code: code, Message: message}, tagSessionProposeRes)
+}
+
+// Respond answers request with result
+func (c *Client) Respond(ctx context.Context, request Request, result interface{}) error {
+	session, ok := c.sessions.Get(request.Topic)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return c.respond(ctx, session.Topic, session.symKey, request.ID, result, tagSessionRequestRes)
+}
+
+// RespondError answers request with the error code and message
+func (c *Client) RespondError(ctx context.Context, request Request, code int, message string) error {
+	session, ok := c.sessions.Get(request.Topic)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	return c.respondError(ctx, session.Topic, session.symKey, request.ID, &Error{Code: code, Message: message}, tagSessionRequestRes)
+}
+
+// Sessions returns the sessions which aren't expired
+func (c *Client) Sessions() []Session {
+	sessions := make([]Session, 0)
+	for _, session := range c.sessions.Values() {
+		if time.Now().Before(session.ExpiresAt) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// Session returns the session of topic
+func (c *Client) Session(topic string) (Session, bool) {
+	return c.sessions.Get(topic)
+}
+
+// Disconnect deletes the session of topic and lets the dApp know
+func (c *Client) Disconnect(ctx context.Context, topic string) error {
+	session, ok := c.sessions.Get(topic)
+	if !ok {
+		return ErrSessionNotFound
+	}
+	params := sessionDeleteParams{Code: codeUserDisconnected, Message: "User disconnected."}
+	err := c.request(ctx, session.Topic, session.symKey, "wc_sessionDelete", params, tagSessionDelete)
+	c.deleteSession(topic)
+	return err
+}
+
+// Close disconnects from the relay, the sessions are dropped without letting the dApps know
+func (c *Client) Close() {
+	c.relay.close()
+}
+
+func (c *Client) deleteSession(topic string) {
+	if _, ok := c.sessions.Get(topic); !ok {
+		return
+	}
+	c.sessions.Delete(topic)
+	if err := c.relay.unsubscribe(context.Background(), topic); err != nil {
+		alog.Logger().Errorln(err)
+	}
+	c.Handler.OnSessionsChanged()
+}
+
+// onMessage decrypts and handles a message published on a pairing or a session topic
+func (c *Client) onMessage(topic, message string) {
+	symKey, isPairing := c.pairings.Get(topic)
+	session, isSession := c.sessions.Get(topic)
+	if isSession {
+		symKey = session.symKey
+	}
+	if !isPairing && !isSession {
+		return
+	}
+	payload, err := decrypt(symKey, message)
+	if err != nil {
+		alog.Logger().Errorln(err)
+		return
+	}
+	var msg rpcMessage
+	if err = json.Unmarshal(payload, &msg); err != nil {
+		alog.Logger().Errorln(err)
+		return
+	}
+	if !msg.isRequest() {
+		// the acknowledgements of the settlements and deletions need no action
+		if msg.Error != nil {
+			alog.Logger().Errorln(msg.Error)
+		}
+		return
+	}
+	if !c.markHandled(msg.ID) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), relayCallTimeout)
+	defer cancel()
+	if isSession {
+		err = c.onSessionRequest(ctx, session, msg)
+	} else {
+		err = c.onPairingRequest(ctx, topic, symKey, msg)
+	}
+	if err != nil {
+		alog.Logger().Errorln(err)
+	}
+}
+
+func (c *Client) onPairingRequest(ctx context.Context, topic string, symKey []byte, msg rpcMessage) error {
+	switch msg.Method {
+	case "wc_sessionPropose":
+		var proposal Proposal
+		if err := json.Unmarshal(msg.Params, &proposal); err != nil {
+			return c.respondError(ctx, topic, symKey, msg.ID, &Error{Code: codeInvalidParams, Message: err.Error()}, tagSessionProposeRes)
+		}
+		proposal.ID = msg.ID
+		proposal.PairingTopic = topic
+		c.proposals.Set(proposal.ID, proposal)
+		c.Handler.OnProposal(proposal)
+		return nil
+	case "wc_pairingPing":
+		return c.respond(ctx, topic, symKey, msg.ID, true, tagPairingPingRes)
+	case "wc_pairingDelete":
+		c.pairings.Delete(topic)
+		err := c.respond(ctx, topic, symKey, msg.ID, true, tagPairingDeleteRes)
+		if unsubscribeErr := c.relay.unsubscribe(ctx, topic); unsubscribeErr != nil {
+			alog.Logger().Errorln(unsubscribeErr)
+		}
+		return err
+	}
+	return c.respondError(ctx, topic, symKey, msg.ID, &Error{Code: CodeUnsupportedMethods, Message: "Unsupported method " + msg.Method}, tagUnknownRes)
+}
+
+func (c *Client) onSessionRequest(ctx context.Context, session Session, msg rpcMessage) error {
+	switch msg.Method {
+	case "wc_sessionRequest":
+		var params sessionRequestParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return c.respondError(ctx, session.Topic, session.symKey, msg.ID, &Error{Code: codeInvalidParams, Message: err.Error()}, tagSessionRequestRes)
+		}
+		if !session.allows(params.ChainID, params.Request.Method) {
+			return c.respondError(ctx, session.Topic, session.symKey, msg.ID, &Error{Code: CodeUnauthorizedMethod, Message: "Unauthorized method " + params.Request.Method}, tagSessionRequestRes)
+		}
+		c.Handler.OnRequest(Request{
+			ID:      msg.ID,
+			Topic:   session.Topic,
+			ChainID: params.ChainID,
+			Method:  params.Request.Method,
+			Params:  params.Request.Params,
+			Peer:    session.Peer,
+		})
+		return nil
+	case "wc_sessionPing":
+		return c.respond(ctx, session.Topic, session.symKey, msg.ID, true, tagSessionPingRes)
+	case "wc_sessionEvent":
+		return c.respond(ctx, session.Topic, session.symKey, msg.ID, true, tagSessionEventRes)
+	case "wc_sessionUpdate":
+		return c.respond(ctx, session.Topic, session.symKey, msg.ID, true, tagSessionUpdateRes)
+	case "wc_sessionExtend":
+		return c.respond(ctx, session.Topic, session.symKey, msg.ID, true, tagSessionExtendRes)
+	case "wc_sessionDelete":
+		err := c.respond(ctx, session.Topic, session.symKey, msg.ID, true, tagSessionDeleteRes)
+		c.deleteSession(session.Topic)
+		return err
+	}
+	return c.respondError(ctx, session.Topic, session.symKey, msg.ID, &Error{Code: CodeUnsupportedMethods, Message: "Unsupported method " + msg.Method}, tagUnknownRes)
+}
+
+// allows reports whether method may be called on chainID in the session
+func (s Session) allows(chainID, method string) bool {
+	for _, namespace := range s.Namespaces {
+		chainOk := false
+		for _, account := range namespace.Accounts {
+			if len(account) > len(chainID) && account[:len(chainID)+1] == chainID+":" {
+				chainOk = true
+				break
+			}
+		}
+		if !chainOk {
+			continue
+		}
+		for _, m := range namespace.Methods {
+			if m == method {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *Client) markHandled(id int64) bool {
+	c.handledMutex.Lock()
+	defer c.handledMutex.Unlock()
+	if _, ok := c.handled[id]; ok {
+		return false
+	}
+	c.handled[id] = struct{}{}
+	return true
+}
+
+func (c *Client) request(ctx context.Context, topic string, symKey []byte, method string, params interface{}, tag int) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.publish(ctx, topic, symKey, rpcMessage{ID: newID(), JSONRPC: "2.0", Method: method, Params: data}, tag)
+}
+
+func (c *Client) respond(ctx context.Context, topic string, symKey []byte, id int64, result interface{}, tag int) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return c.publish(ctx, topic, symKey, rpcMessage{ID: id, JSONRPC: "2.0", Result: data}, tag)
+}
+
+func (c *Client) respondError(ctx context.Context, topic string, symKey []byte, id int64, rpcErr *Error, tag int) error {
+	return c.publish(ctx, topic, symKey, rpcMessage{ID: id, JSONRPC: "2.0", Error: rpcErr}, tag)
+}
+
+func (c *Client) publish(ctx context.Context, topic string, symKey []byte, msg rpcMessage, tag int) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	message, err := encrypt(symKey, payload)
+	if err != nil {
+		return err
+	}
+	return c.relay.publish(ctx, topic, message, messageTTL, tag)
+}
@@ -0,0 +1,399 @@
+package walletconnect
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+)
+
+const testTimeout = 10 * time.Second
+
+func TestParsePairingURI(t *testing.T) {
+	symKey := strings.Repeat("ab", keySize)
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Hour).Unix()
+	tests := []struct {
+		name string
+		uri  string
+		err  error
+	}{
+		{"valid", "wc:7f6e504b@2?relay-protocol=irn&symKey=" + symKey, nil},
+		{"valid with expiry", fmt.Sprintf("wc:7f6e504b@2?relay-protocol=irn&symKey=%s&expiryTimestamp=%d", symKey, future), nil},
+		{"expired", fmt.Sprintf("wc:7f6e504b@2?relay-protocol=irn&symKey=%s&expiryTimestamp=%d", symKey, past), ErrPairingExpired},
+		{"v1", "wc:8a5e5bdc-a0e4-4702-ba63-8f1a5655744f@1?bridge=https%3A%2F%2Fbridge.walletconnect.org&key=" + symKey, ErrInvalidPairingURI},
+		{"not wc", "https://example.com", ErrInvalidPairingURI},
+		{"no topic", "wc:@2?relay-protocol=irn&symKey=" + symKey, ErrInvalidPairingURI},
+		{"other relay protocol", "wc:7f6e504b@2?relay-protocol=waku&symKey=" + symKey, ErrInvalidPairingURI},
+		{"short key", "wc:7f6e504b@2?relay-protocol=irn&symKey=abcd", ErrInvalidPairingURI},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pairing, err := ParsePairingURI(test.uri)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+			if pairing.Topic != "7f6e504b" || pairing.Version != 2 || hex.EncodeToString(pairing.SymKey) != symKey {
+				t.Fatalf("got pairing %+v", pairing)
+			}
+		})
+	}
+}
+
+// testRelay is a relay which delivers the messages published on a topic to its other
+// subscribers, messages of a topic without subscribers wait until it's subscribed
+type testRelay struct {
+	upgrader    websocket.Upgrader
+	mutex       sync.Mutex
+	subscribers map[string]map[*testRelayConn]struct{}
+	mailbox     map[string][]string
+}
+
+type testRelayConn struct {
+	conn       *websocket.Conn
+	writeMutex sync.Mutex
+}
+
+func (c *testRelayConn) write(msg rpcMessage) {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	_ = c.conn.WriteJSON(msg)
+}
+
+func newTestRelay(t *testing.T) string {
+	relay := &testRelay{
+		subscribers: map[string]map[*testRelayConn]struct{}{},
+		mailbox:     map[string][]string{},
+	}
+	server := httptest.NewServer(relay)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func (r *testRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Query().Get("auth") == "" {
+		http.Error(w, "missing auth", http.StatusUnauthorized)
+		return
+	}
+	conn, err := r.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	c := &testRelayConn{conn: conn}
+	defer func() {
+		r.mutex.Lock()
+		for _, subscribers := range r.subscribers {
+			delete(subscribers, c)
+		}
+		r.mutex.Unlock()
+		_ = conn.Close()
+	}()
+	for {
+		var msg rpcMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		var params struct {
+			Topic   string `json:"topic"`
+			Message string `json:"message"`
+		}
+		if msg.isRequest() {
+			_ = json.Unmarshal(msg.Params, &params)
+		}
+		switch msg.Method {
+		case "irn_subscribe":
+			c.write(rpcMessage{ID: msg.ID, JSONRPC: "2.0", Result: json.RawMessage(`"` + params.Topic + `"`)})
+			r.mutex.Lock()
+			if r.subscribers[params.Topic] == nil {
+				r.subscribers[params.Topic] = map[*testRelayConn]struct{}{}
+			}
+			r.subscribers[params.Topic][c] = struct{}{}
+			messages := r.mailbox[params.Topic]
+			delete(r.mailbox, params.Topic)
+			r.mutex.Unlock()
+			for _, message := range messages {
+				c.write(subscriptionMessage(params.Topic, message))
+			}
+		case "irn_unsubscribe":
+			r.mutex.Lock()
+			delete(r.subscribers[params.Topic], c)
+			r.mutex.Unlock()
+			c.write(rpcMessage{ID: msg.ID, JSONRPC: "2.0", Result: json.RawMessage("true")})
+		case "irn_publish":
+			c.write(rpcMessage{ID: msg.ID, JSONRPC: "2.0", Result: json.RawMessage("true")})
+			r.mutex.Lock()
+			receivers := make([]*testRelayConn, 0)
+			for subscriber := range r.subscribers[params.Topic] {
+				if subscriber != c {
+					receivers = append(receivers, subscriber)
+				}
+			}
+			if len(receivers) == 0 {
+				r.mailbox[params.Topic] = append(r.mailbox[params.Topic], params.Message)
+			}
+			r.mutex.Unlock()
+			for _, receiver := range receivers {
+				receiver.write(subscriptionMessage(params.Topic, params.Message))
+			}
+		}
+	}
+}
+
+func subscriptionMessage(topic, message string) rpcMessage {
+	params, _ := json.Marshal(map[string]interface{}{
+		"id":   topic,
+		"data": map[string]string{"topic": topic, "message": message},
+	})
+	return rpcMessage{ID: newID(), JSONRPC: "2.0", Method: "irn_subscription", Params: params}
+}
+
+// testHandler passes the proposals and requests of the client to the test
+type testHandler struct {
+	proposals chan Proposal
+	requests  chan Request
+}
+
+func (h *testHandler) OnProposal(proposal Proposal) { h.proposals <- proposal }
+func (h *testHandler) OnRequest(request Request)    { h.requests <- request }
+func (h *testHandler) OnSessionsChanged()           {}
+
+// testDApp is the dApp side of the protocol on its own relay connection
+type testDApp struct {
+	t        *testing.T
+	relay    *relayClient
+	keys     sync.Map
+	messages chan rpcMessage
+}
+
+func newTestDApp(t *testing.T, relayURL string) *testDApp {
+	d := &testDApp{t: t, messages: make(chan rpcMessage, 16)}
+	var err error
+	d.relay, err = newRelayClient(relayURL, "", d.onMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(d.relay.close)
+	return d
+}
+
+func (d *testDApp) onMessage(topic, message string) {
+	symKey, ok := d.keys.Load(topic)
+	if !ok {
+		return
+	}
+	payload, err := decrypt(symKey.([]byte), message)
+	if err != nil {
+		d.t.Error(err)
+		return
+	}
+	var msg rpcMessage
+	if err = json.Unmarshal(payload, &msg); err != nil {
+		d.t.Error(err)
+		return
+	}
+	d.messages <- msg
+}
+
+func (d *testDApp) subscribe(ctx context.Context, symKey []byte) string {
+	topic := topicOf(symKey)
+	d.keys.Store(topic, symKey)
+	if err := d.relay.subscribe(ctx, topic); err != nil {
+		d.t.Fatal(err)
+	}
+	return topic
+}
+
+func (d *testDApp) publish(ctx context.Context, topic string, symKey []byte, msg rpcMessage) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	message, err := encrypt(symKey, payload)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	if err = d.relay.publish(ctx, topic, message, messageTTL, tagSessionRequest); err != nil {
+		d.t.Fatal(err)
+	}
+}
+
+func (d *testDApp) next() rpcMessage {
+	select {
+	case msg := <-d.messages:
+		return msg
+	case <-time.After(testTimeout):
+		d.t.Fatal("timed out waiting for a message of the wallet")
+	}
+	return rpcMessage{}
+}
+
+func TestClientSession(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	relayURL := newTestRelay(t)
+	handler := &testHandler{proposals: make(chan Proposal, 1), requests: make(chan Request, 1)}
+	client, err := NewClient(Options{RelayURL: relayURL, Metadata: Metadata{Name: "Protonet"}, Handler: handler})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	dApp := newTestDApp(t, relayURL)
+
+	// the dApp shows the pairing uri and waits on its topic
+	pairingKey, err := newKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairingTopic := dApp.subscribe(ctx, pairingKey.private)
+	uri := fmt.Sprintf("wc:%s@2?relay-protocol=irn&symKey=%s&expiryTimestamp=%d",
+		pairingTopic, hex.EncodeToString(pairingKey.private), time.Now().Add(5*time.Minute).Unix())
+	if err = client.Pair(ctx, uri); err != nil {
+		t.Fatal(err)
+	}
+
+	// the dApp proposes a session
+	dAppKeys, err := newKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposeParams, err := json.Marshal(map[string]interface{}{
+		"relays": []relay{{Protocol: RelayProtocol}},
+		"proposer": participant{
+			PublicKey: hex.EncodeToString(dAppKeys.public),
+			Metadata:  Metadata{Name: "Test dApp", URL: "https://dapp.example"},
+		},
+		"requiredNamespaces": map[string]Namespace{
+			"eip155": {Chains: []string{"eip155:1"}, Methods: []string{"personal_sign"}, Events: []string{"accountsChanged"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposeID := newID()
+	dApp.publish(ctx, pairingTopic, pairingKey.private, rpcMessage{ID: proposeID, JSONRPC: "2.0", Method: "wc_sessionPropose", Params: proposeParams})
+	var proposal Proposal
+	select {
+	case proposal = <-handler.proposals:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the session proposal")
+	}
+	if proposal.ID != proposeID || proposal.Metadata().Name != "Test dApp" || proposal.PairingTopic != pairingTopic {
+		t.Fatalf("got proposal %+v", proposal)
+	}
+
+	// the wallet approves it with its account
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	namespaces := map[string]Namespace{
+		"eip155": {
+			Accounts: []string{"eip155:1:" + address.Hex()},
+			Methods:  []string{"personal_sign"},
+			Events:   []string{"accountsChanged"},
+		},
+	}
+	session, err := client.Approve(ctx, proposal, namespaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := dApp.next()
+	if response.ID != proposeID || response.Error != nil {
+		t.Fatalf("got response %+v to the proposal", response)
+	}
+	var proposeResult sessionProposeResult
+	if err = json.Unmarshal(response.Result, &proposeResult); err != nil {
+		t.Fatal(err)
+	}
+	walletPublicKey, err := hex.DecodeString(proposeResult.ResponderPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionKey, err := dAppKeys.sharedKey(walletPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionTopic := dApp.subscribe(ctx, sessionKey)
+	if sessionTopic != session.Topic {
+		t.Fatalf("dApp derived session topic %s, wallet %s", sessionTopic, session.Topic)
+	}
+	settle := dApp.next()
+	if settle.Method != "wc_sessionSettle" {
+		t.Fatalf("got %s, want wc_sessionSettle", settle.Method)
+	}
+	var settleParams sessionSettleParams
+	if err = json.Unmarshal(settle.Params, &settleParams); err != nil {
+		t.Fatal(err)
+	}
+	if accounts := settleParams.Namespaces["eip155"].Accounts; len(accounts) != 1 || accounts[0] != "eip155:1:"+address.Hex() {
+		t.Fatalf("got settled accounts %v", accounts)
+	}
+
+	// one personal_sign round trip
+	message := []byte("Sign in to Test dApp")
+	requestParams, err := json.Marshal(map[string]interface{}{
+		"request": map[string]interface{}{
+			"method": "personal_sign",
+			"params": []string{hexutil.Encode(message), address.Hex()},
+		},
+		"chainId": "eip155:1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestID := newID()
+	dApp.publish(ctx, sessionTopic, sessionKey, rpcMessage{ID: requestID, JSONRPC: "2.0", Method: "wc_sessionRequest", Params: requestParams})
+	var request Request
+	select {
+	case request = <-handler.requests:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the personal_sign request")
+	}
+	if request.ID != requestID || request.Method != "personal_sign" || request.ChainID != "eip155:1" {
+		t.Fatalf("got request %+v", request)
+	}
+	signature, err := crypto.Sign(accounts.TextHash(message), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	if err = client.Respond(ctx, request, hexutil.Encode(signature)); err != nil {
+		t.Fatal(err)
+	}
+	response = dApp.next()
+	if response.ID != requestID || response.Error != nil {
+		t.Fatalf("got response %+v to personal_sign", response)
+	}
+	var signatureHex string
+	if err = json.Unmarshal(response.Result, &signatureHex); err != nil {
+		t.Fatal(err)
+	}
+	received, err := hexutil.Decode(signatureHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received[crypto.RecoveryIDOffset] -= 27
+	publicKey, err := crypto.SigToPub(accounts.TextHash(message), received)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer := crypto.PubkeyToAddress(*publicKey); signer != address {
+		t.Fatalf("signature recovers %s, want %s", signer, address)
+	}
+}
@@ -0,0 +1,96 @@
+package walletconnect
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"io"
+)
+
+const (
+	// keySize is the size of the symmetric keys and of the x25519 keys
+	keySize = 32
+	// envelopeType0 is a message encrypted with the symmetric key of its topic
+	envelopeType0 = byte(0)
+)
+
+var ErrInvalidEnvelope = errors.New("invalid WalletConnect envelope")
+
+// keyPair is the x25519 key pair agreeing on the symmetric key of a session
+type keyPair struct {
+	private []byte
+	public  []byte
+}
+
+func newKeyPair() (keyPair, error) {
+	private := make([]byte, keySize)
+	if _, err := rand.Read(private); err != nil {
+		return keyPair{}, err
+	}
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		return keyPair{}, err
+	}
+	return keyPair{private: private, public: public}, nil
+}
+
+// sharedKey derives the symmetric key shared with the owner of peerPublic, it's the HKDF-SHA256
+// of their x25519 shared secret
+func (k keyPair) sharedKey(peerPublic []byte) ([]byte, error) {
+	secret, err := curve25519.X25519(k.private, peerPublic)
+	if err != nil {
+		return nil, err
+	}
+	symKey := make([]byte, keySize)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, secret, nil, nil), symKey); err != nil {
+		return nil, err
+	}
+	return symKey, nil
+}
+
+// topicOf returns the topic of the messages encrypted with symKey
+func topicOf(symKey []byte) string {
+	hash := sha256.Sum256(symKey)
+	return hex.EncodeToString(hash[:])
+}
+
+// encrypt seals payload with symKey into a type 0 envelope encoded in base64
+func encrypt(symKey []byte, payload []byte) (string, error) {
+	aead, err := chacha20poly1305.New(symKey)
+	if err != nil {
+		return "", err
+	}
+	envelope := make([]byte, 1+aead.NonceSize(), 1+aead.NonceSize()+len(payload)+aead.Overhead())
+	envelope[0] = envelopeType0
+	if _, err = rand.Read(envelope[1:]); err != nil {
+		return "", err
+	}
+	envelope = aead.Seal(envelope, envelope[1:], payload, nil)
+	return base64.StdEncoding.EncodeToString(envelope), nil
+}
+
+// decrypt opens the type 0 envelope message with symKey
+func decrypt(symKey []byte, message string) ([]byte, error) {
+	envelope, err := base64.StdEncoding.DecodeString(message)
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+	aead, err := chacha20poly1305.New(symKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(envelope) < 1+aead.NonceSize()+aead.Overhead() || envelope[0] != envelopeType0 {
+		return nil, ErrInvalidEnvelope
+	}
+	nonce, sealed := envelope[1:1+aead.NonceSize()], envelope[1+aead.NonceSize():]
+	payload, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+	return payload, nil
+}
@@ -0,0 +1,303 @@
+package walletconnect
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/utils"
+	"github.com/mr-tron/base58"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRelayURL is the public relay of WalletConnect, it requires a project id
+	DefaultRelayURL  = "wss://relay.walletconnect.com"
+	relayCallTimeout = 20 * time.Second
+	reconnectDelay   = 5 * time.Second
+	authTokenTTL     = 24 * time.Hour
+)
+
+var ErrRelayClosed = errors.New("WalletConnect relay is closed")
+
+var lastID atomic.Int64
+
+// newID returns a json-rpc id, ids are the time in microseconds as used by the other clients
+func newID() int64 {
+	id := time.Now().UnixMicro()
+	for {
+		last := lastID.Load()
+		if id <= last {
+			id = last + 1
+		}
+		if lastID.CompareAndSwap(last, id) {
+			return id
+		}
+	}
+}
+
+// relayClient subscribes to topics and publishes messages on the relay over a websocket, the
+// websocket is connected again if it drops and the topics are subscribed again
+type relayClient struct {
+	url       string
+	projectID string
+	authKey   ed25519.PrivateKey
+	// onMessage is called with the messages published on the subscribed topics
+	onMessage     func(topic, message string)
+	conn          *websocket.Conn
+	connMutex     sync.RWMutex
+	writeMutex    sync.Mutex
+	connected     chan struct{}
+	pending       utils.Map[int64, chan rpcMessage]
+	subscriptions utils.Map[string, string]
+	ctx           context.Context
+	cancel        context.CancelFunc
+}
+
+func newRelayClient(relayURL, projectID string, onMessage func(topic, message string)) (*relayClient, error) {
+	if relayURL == "" {
+		relayURL = DefaultRelayURL
+	}
+	_, authKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &relayClient{
+		url:           relayURL,
+		projectID:     projectID,
+		authKey:       authKey,
+		onMessage:     onMessage,
+		connected:     make(chan struct{}),
+		pending:       utils.NewMap[int64, chan rpcMessage](),
+		subscriptions: utils.NewMap[string, string](),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+	go r.run()
+	return r, nil
+}
+
+// run keeps the websocket connected until the client is closed
+func (r *relayClient) run() {
+	for r.ctx.Err() == nil {
+		conn, err := r.dial()
+		if err != nil {
+			alog.Logger().Errorln(err)
+			select {
+			case <-r.ctx.Done():
+			case <-time.After(reconnectDelay):
+			}
+			continue
+		}
+		r.connMutex.Lock()
+		r.conn = conn
+		close(r.connected)
+		r.connMutex.Unlock()
+		go r.resubscribe()
+		err = r.read(conn)
+		r.connMutex.Lock()
+		r.conn = nil
+		r.connected = make(chan struct{})
+		r.connMutex.Unlock()
+		_ = conn.Close()
+		if r.ctx.Err() == nil {
+			alog.Logger().Errorln(err)
+		}
+	}
+}
+
+func (r *relayClient) dial() (*websocket.Conn, error) {
+	relayURL, err := url.Parse(r.url)
+	if err != nil {
+		return nil, err
+	}
+	token, err := authToken(r.authKey, r.url)
+	if err != nil {
+		return nil, err
+	}
+	query := relayURL.Query()
+	query.Set("auth", token)
+	if r.projectID != "" {
+		query.Set("projectId", r.projectID)
+	}
+	relayURL.RawQuery = query.Encode()
+	ctx, cancel := context.WithTimeout(r.ctx, relayCallTimeout)
+	defer cancel()
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, relayURL.String(), nil)
+	return conn, err
+}
+
+// read dispatches the messages of conn until it fails
+func (r *relayClient) read(conn *websocket.Conn) error {
+	for {
+		var msg rpcMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return err
+		}
+		if !msg.isRequest() {
+			if ch, ok := r.pending.Get(msg.ID); ok {
+				ch <- msg
+			}
+			continue
+		}
+		if msg.Method != "irn_subscription" {
+			continue
+		}
+		var params struct {
+			Data struct {
+				Topic   string `json:"topic"`
+				Message string `json:"message"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			alog.Logger().Errorln(err)
+			continue
+		}
+		ack := rpcMessage{ID: msg.ID, JSONRPC: "2.0", Result: json.RawMessage("true")}
+		if err := r.write(conn, ack); err != nil {
+			return err
+		}
+		go r.onMessage(params.Data.Topic, params.Data.Message)
+	}
+}
+
+func (r *relayClient) write(conn *websocket.Conn, msg rpcMessage) error {
+	r.writeMutex.Lock()
+	defer r.writeMutex.Unlock()
+	return conn.WriteJSON(msg)
+}
+
+// call sends the request of method to the relay and decodes its result into result, the call
+// waits for the websocket to connect
+func (r *relayClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, relayCallTimeout)
+	defer cancel()
+	r.connMutex.RLock()
+	connected := r.connected
+	r.connMutex.RUnlock()
+	select {
+	case <-connected:
+	case <-r.ctx.Done():
+		return ErrRelayClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	r.connMutex.RLock()
+	conn := r.conn
+	r.connMutex.RUnlock()
+	if conn == nil {
+		return ErrRelayClosed
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	msg := rpcMessage{ID: newID(), JSONRPC: "2.0", Method: method, Params: data}
+	ch := make(chan rpcMessage, 1)
+	r.pending.Set(msg.ID, ch)
+	defer r.pending.Delete(msg.ID)
+	if err = r.write(conn, msg); err != nil {
+		return err
+	}
+	select {
+	case res := <-ch:
+		if res.Error != nil {
+			return res.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(res.Result, result)
+	case <-r.ctx.Done():
+		return ErrRelayClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *relayClient) subscribe(ctx context.Context, topic string) error {
+	var id string
+	if err := r.call(ctx, "irn_subscribe", map[string]string{"topic": topic}, &id); err != nil {
+		return err
+	}
+	r.subscriptions.Set(topic, id)
+	return nil
+}
+
+func (r *relayClient) unsubscribe(ctx context.Context, topic string) error {
+	id, ok := r.subscriptions.Get(topic)
+	if !ok {
+		return nil
+	}
+	r.subscriptions.Delete(topic)
+	return r.call(ctx, "irn_unsubscribe", map[string]string{"topic": topic, "id": id}, nil)
+}
+
+func (r *relayClient) publish(ctx context.Context, topic, message string, ttl time.Duration, tag int) error {
+	params := map[string]interface{}{
+		"topic":   topic,
+		"message": message,
+		"ttl":     int64(ttl / time.Second),
+		"tag":     tag,
+		"prompt":  false,
+	}
+	return r.call(ctx, "irn_publish", params, nil)
+}
+
+// resubscribe subscribes again to the topics after the websocket is connected again
+func (r *relayClient) resubscribe() {
+	for _, topic := range r.subscriptions.Keys() {
+		if err := r.subscribe(r.ctx, topic); err != nil {
+			alog.Logger().Errorln(err)
+		}
+	}
+}
+
+func (r *relayClient) close() {
+	r.cancel()
+	r.connMutex.RLock()
+	conn := r.conn
+	r.connMutex.RUnlock()
+	if conn != nil {
+		_ = conn.Close()
+	}
+}
+
+// authToken returns the jwt authenticating the client to the relay aud, it's signed with the
+// ed25519 key of the client identified by its did:key
+func authToken(key ed25519.PrivateKey, aud string) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "EdDSA", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	sub := make([]byte, 32)
+	if _, err = rand.Read(sub); err != nil {
+		return "", err
+	}
+	// multicodec prefix of ed25519 public keys
+	didKey := append([]byte{0xed, 0x01}, key.Public().(ed25519.PublicKey)...)
+	now := time.Now()
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss": "did:key:z" + base58.Encode(didKey),
+		"sub": hex.EncodeToString(sub),
+		"aud": aud,
+		"iat": now.Unix(),
+		"exp": now.Add(authTokenTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoding := base64.RawURLEncoding
+	data := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	signature := ed25519.Sign(key, []byte(data))
+	return data + "." + encoding.EncodeToString(signature), nil
+}
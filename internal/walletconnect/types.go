@@ -0,0 +1,159 @@
+package walletconnect
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Tags of the messages published to the relay, a request and its response have their own tag
+const (
+	tagPairingDelete     = 1000
+	tagPairingDeleteRes  = 1001
+	tagPairingPing       = 1002
+	tagPairingPingRes    = 1003
+	tagSessionPropose    = 1100
+	tagSessionProposeRes = 1101
+	tagSessionSettle     = 1102
+	tagSessionSettleRes  = 1103
+	tagSessionUpdateRes  = 1105
+	tagSessionExtendRes  = 1107
+	tagSessionRequest    = 1108
+	tagSessionRequestRes = 1109
+	tagSessionEventRes   = 1111
+	tagSessionDelete     = 1112
+	tagSessionDeleteRes  = 1113
+	tagSessionPingRes    = 1115
+	tagUnknownRes        = 0
+)
+
+const (
+	// messageTTL is how long the relay keeps a message for a peer which isn't connected
+	messageTTL = 5 * time.Minute
+	// sessionTTL is the lifetime of a settled session
+	sessionTTL = 7 * 24 * time.Hour
+)
+
+// Error codes of the responses sent to dApps
+const (
+	CodeUserRejected       = 5000
+	CodeUnsupportedChains  = 5100
+	CodeUnsupportedMethods = 5101
+	CodeUnauthorizedMethod = 3001
+	CodeUnauthorizedChain  = 3005
+	// CodeRequestFailed is the json-rpc error of a request which is valid but failed
+	CodeRequestFailed    = -32000
+	codeInvalidParams    = -32602
+	codeUserDisconnected = 6000
+)
+
+// Metadata describes a dApp or the wallet to its peer
+type Metadata struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Icons       []string `json:"icons"`
+}
+
+// Namespace lists the chains, methods and events of a blockchain family such as eip155, the
+// chains are CAIP-2 ids such as eip155:1 and the accounts are CAIP-10 ids such as eip155:1:0xab..
+type Namespace struct {
+	Chains   []string `json:"chains,omitempty"`
+	Accounts []string `json:"accounts,omitempty"`
+	Methods  []string `json:"methods"`
+	Events   []string `json:"events"`
+}
+
+type relay struct {
+	Protocol string `json:"protocol"`
+}
+
+type participant struct {
+	PublicKey string   `json:"publicKey"`
+	Metadata  Metadata `json:"metadata"`
+}
+
+// Proposal is the request of a dApp to open a session with the wallet
+type Proposal struct {
+	ID                 int64                `json:"id"`
+	PairingTopic       string               `json:"pairingTopic"`
+	Proposer           participant          `json:"proposer"`
+	RequiredNamespaces map[string]Namespace `json:"requiredNamespaces"`
+	OptionalNamespaces map[string]Namespace `json:"optionalNamespaces"`
+	Relays             []relay              `json:"relays"`
+}
+
+// Metadata returns the description of the dApp which sent the proposal
+func (p Proposal) Metadata() Metadata {
+	return p.Proposer.Metadata
+}
+
+// Session is a session approved with a dApp, requests are accepted on the Namespaces only
+type Session struct {
+	Topic        string
+	PairingTopic string
+	Peer         Metadata
+	Namespaces   map[string]Namespace
+	ExpiresAt    time.Time
+	symKey       []byte
+}
+
+// Request is a call of a dApp on a session, such as personal_sign
+type Request struct {
+	ID      int64
+	Topic   string
+	ChainID string
+	Method  string
+	Params  json.RawMessage
+	Peer    Metadata
+}
+
+// Error is the error of a response sent to a dApp
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// rpcMessage is a json-rpc request or response, exchanged with the relay and with the peers
+type rpcMessage struct {
+	ID      int64           `json:"id"`
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+func (m rpcMessage) isRequest() bool {
+	return m.Method != ""
+}
+
+type sessionProposeResult struct {
+	Relay              relay  `json:"relay"`
+	ResponderPublicKey string `json:"responderPublicKey"`
+}
+
+type sessionSettleParams struct {
+	Relay        relay                `json:"relay"`
+	Namespaces   map[string]Namespace `json:"namespaces"`
+	Controller   participant          `json:"controller"`
+	Expiry       int64                `json:"expiry"`
+	PairingTopic string               `json:"pairingTopic"`
+}
+
+type sessionRequestParams struct {
+	Request struct {
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	} `json:"request"`
+	ChainID string `json:"chainId"`
+}
+
+type sessionDeleteParams struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
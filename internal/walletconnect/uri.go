@@ -0,0 +1,73 @@
+package walletconnect
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RelayProtocol is the only relay protocol of WalletConnect v2
+const RelayProtocol = "irn"
+
+var (
+	ErrInvalidPairingURI = errors.New("invalid WalletConnect pairing uri")
+	ErrPairingExpired    = errors.New("WalletConnect pairing uri is expired")
+)
+
+// PairingURI is the uri shown by a dApp to pair with a wallet, such as
+//
+//	wc:7f6e504b...@2?relay-protocol=irn&symKey=587d5484...
+//
+// Topic is where the dApp sends its session proposal, encrypted with SymKey
+type PairingURI struct {
+	Topic         string
+	Version       int
+	RelayProtocol string
+	SymKey        []byte
+	// ExpiresAt is zero if the uri doesn't expire
+	ExpiresAt time.Time
+}
+
+// ParsePairingURI parses a WalletConnect v2 pairing uri, v1 uris are rejected
+func ParsePairingURI(uri string) (pairing PairingURI, err error) {
+	uri = strings.TrimSpace(uri)
+	if !strings.HasPrefix(uri, "wc:") {
+		return pairing, ErrInvalidPairingURI
+	}
+	path, rawQuery, _ := strings.Cut(strings.TrimPrefix(uri, "wc:"), "?")
+	topic, version, ok := strings.Cut(path, "@")
+	if !ok || topic == "" {
+		return pairing, ErrInvalidPairingURI
+	}
+	pairing.Topic = topic
+	pairing.Version, err = strconv.Atoi(version)
+	if err != nil || pairing.Version != 2 {
+		return pairing, ErrInvalidPairingURI
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return pairing, ErrInvalidPairingURI
+	}
+	pairing.RelayProtocol = query.Get("relay-protocol")
+	if pairing.RelayProtocol != RelayProtocol {
+		return pairing, ErrInvalidPairingURI
+	}
+	pairing.SymKey, err = hex.DecodeString(query.Get("symKey"))
+	if err != nil || len(pairing.SymKey) != keySize {
+		return pairing, ErrInvalidPairingURI
+	}
+	if expiry := query.Get("expiryTimestamp"); expiry != "" {
+		seconds, err := strconv.ParseInt(expiry, 10, 64)
+		if err != nil {
+			return pairing, ErrInvalidPairingURI
+		}
+		pairing.ExpiresAt = time.Unix(seconds, 0)
+		if time.Now().After(pairing.ExpiresAt) {
+			return pairing, ErrPairingExpired
+		}
+	}
+	return pairing, nil
+}
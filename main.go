@@ -2,12 +2,19 @@ package main
 
 import (
 	"gioui.org/app"
+	"github.com/mearaj/protonet/internal/cli"
 	"github.com/mearaj/protonet/ui"
 	log "github.com/sirupsen/logrus"
 	"os"
 )
 
 func main() {
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		if err := cli.Run(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	go func() {
 		w := app.NewWindow(app.Title("Protonet"))
 		if err := ui.Loop(w); err != nil {
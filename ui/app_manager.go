@@ -9,6 +9,7 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"gioui.org/x/explorer"
 	"gioui.org/x/notify"
 	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/assets/fonts"
@@ -17,8 +18,13 @@ import (
 	"github.com/mearaj/protonet/ui/page/accounts"
 	"github.com/mearaj/protonet/ui/page/chat"
 	"github.com/mearaj/protonet/ui/page/contacts"
+	"github.com/mearaj/protonet/ui/page/currency"
+	"github.com/mearaj/protonet/ui/page/dapps"
+	"github.com/mearaj/protonet/ui/page/devices"
 	"github.com/mearaj/protonet/ui/page/help"
 	"github.com/mearaj/protonet/ui/page/notifications"
+	"github.com/mearaj/protonet/ui/page/profile"
+	"github.com/mearaj/protonet/ui/page/security"
 	"github.com/mearaj/protonet/ui/page/settings"
 	"github.com/mearaj/protonet/ui/page/theme"
 	"github.com/mearaj/protonet/ui/page/wallet"
@@ -36,6 +42,7 @@ type AppManager struct {
 	Constraints     layout.Constraints
 	Metric          unit.Metric
 	notifier        notify.Notifier
+	explorer        *explorer.Explorer
 	system.Insets
 	// isStageRunning, true value indicates app is running in foreground,
 	// false indicates running in background
@@ -64,6 +71,11 @@ func (m *AppManager) Notifier() notify.Notifier {
 	return m.notifier
 }
 
+// Explorer opens the native file explorer for reading and writing files
+func (m *AppManager) Explorer() *explorer.Explorer {
+	return m.explorer
+}
+
 func (m *AppManager) Snackbar() Snackbar {
 	return m.snackbar
 }
@@ -230,6 +242,8 @@ func (m *AppManager) NavigateToURL(pageURL URL, afterNavCallback func()) {
 		m.pagesStack = []Page{m.settingsSideBar}
 	case ChatPageURL:
 		page = chat.New(m)
+	case ContactRequestsPageURL:
+		page = chat.NewRequests(m)
 	case WalletPageURL:
 		page = wallet.New(m)
 	case AccountsPageURL:
@@ -240,6 +254,16 @@ func (m *AppManager) NavigateToURL(pageURL URL, afterNavCallback func()) {
 		page = theme.New(m)
 	case NotificationsPageURL:
 		page = notifications.New(m)
+	case SecurityPageURL:
+		page = security.New(m)
+	case CurrencyPageURL:
+		page = currency.New(m)
+	case ProfilePageURL:
+		page = profile.New(m)
+	case DevicesPageURL:
+		page = devices.New(m)
+	case DAppsPageURL:
+		page = dapps.New(m)
 	case HelpPageURL:
 		page = help.New(m)
 	case AboutPageURL:
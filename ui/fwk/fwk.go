@@ -8,6 +8,7 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"gioui.org/x/explorer"
 	"gioui.org/x/notify"
 	"github.com/mearaj/protonet/internal/pubsub"
 	"image/color"
@@ -26,6 +27,7 @@ type Manager interface {
 	Theme() *material.Theme
 	Window() *app.Window
 	Notifier() notify.Notifier
+	Explorer() *explorer.Explorer
 	Modal() Modal
 	PageFromURL(url URL) Page
 	SystemInsets() system.Insets
@@ -74,16 +76,22 @@ type ViewWithDBListener interface {
 type URL string
 
 const (
-	SettingsPageURL      URL = "/settings"
-	AccountsPageURL          = SettingsPageURL + "/accounts"
-	ContactsPageURL          = SettingsPageURL + "/contacts"
-	ThemePageURL             = SettingsPageURL + "/theme"
-	NotificationsPageURL     = SettingsPageURL + "/notifications"
-	HelpPageURL              = SettingsPageURL + "/help"
-	AboutPageURL             = SettingsPageURL + "/about"
-	ChatPageURL          URL = "/chat"
-	ChatRoomPageURL      URL = "/chat-room"
-	WalletPageURL        URL = "/wallet"
+	SettingsPageURL        URL = "/settings"
+	AccountsPageURL            = SettingsPageURL + "/accounts"
+	ProfilePageURL             = SettingsPageURL + "/profile"
+	DevicesPageURL             = SettingsPageURL + "/devices"
+	ContactsPageURL            = SettingsPageURL + "/contacts"
+	ThemePageURL               = SettingsPageURL + "/theme"
+	NotificationsPageURL       = SettingsPageURL + "/notifications"
+	SecurityPageURL            = SettingsPageURL + "/security"
+	CurrencyPageURL            = SettingsPageURL + "/currency"
+	DAppsPageURL               = SettingsPageURL + "/dapps"
+	HelpPageURL                = SettingsPageURL + "/help"
+	AboutPageURL               = SettingsPageURL + "/about"
+	ChatPageURL            URL = "/chat"
+	ContactRequestsPageURL     = ChatPageURL + "/requests"
+	ChatRoomPageURL        URL = "/chat-room"
+	WalletPageURL          URL = "/wallet"
 )
 
 type (
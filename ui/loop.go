@@ -9,10 +9,12 @@ import (
 	"gioui.org/op"
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
+	"gioui.org/x/explorer"
 	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/internal/pubsub"
 	"github.com/mearaj/protonet/internal/wallet"
 	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/page/dapps"
 	"image"
 	"os/exec"
 	"runtime"
@@ -43,15 +45,19 @@ func init() {
 func Loop(w *app.Window) error {
 	var ops op.Ops
 	appManager.window = w
+	appManager.explorer = explorer.NewExplorer(w)
 
 	// backClickTag is meant for tracking user's backClick action, specially on mobile
 	var backClickTag struct{}
+	// activityTag is meant for tracking user's activity, it postpones the wallet's auto lock
+	var activityTag struct{}
 
 	subscription := pubsub.AddSubscriber(wallet.GlobalWallet.EventBroker)
 
 	for {
 		select {
 		case e := <-w.Events():
+			appManager.explorer.ListenEvents(e)
 			switch e := e.(type) {
 			case system.DestroyEvent:
 				alog.Logger().Errorln("system.DestroyEvent called", e.Err)
@@ -60,6 +66,9 @@ func Loop(w *app.Window) error {
 				appManager.Insets = e.Insets
 				e.Insets = system.Insets{}
 				gtx := layout.NewContext(&ops, e)
+				if len(gtx.Events(&activityTag)) > 0 {
+					wallet.GlobalWallet.Touch()
+				}
 				for _, event := range gtx.Events(&backClickTag) {
 					switch e := event.(type) {
 					case key.Event:
@@ -82,7 +91,7 @@ func Loop(w *app.Window) error {
 				areaStack := clip.Rect(image.Rectangle{Max: gtx.Constraints.Max}).Push(gtx.Ops)
 				// In desktop layout, sidebar exists and needs to listen to entire window's pointer event
 				// hence added here. It avoids conflict with page that contains sidebar
-				for _, elem := range []interface{}{appManager.CurrentPage(), appManager.settingsSideBar} {
+				for _, elem := range []interface{}{appManager.CurrentPage(), appManager.settingsSideBar, &activityTag} {
 					pointer.InputOp{
 						Types: pointer.Enter | pointer.Leave | pointer.Drag | pointer.Press | pointer.Release | pointer.Scroll | pointer.Move,
 						Tag:   elem,
@@ -112,6 +121,22 @@ func Loop(w *app.Window) error {
 				}
 			}
 		case event := <-subscription.Events():
+			// Wallet is locked, hence return to the password form
+			if _, ok := event.Data.(pubsub.WalletLockedEventData); ok {
+				appManager.Modal().Dismiss(nil)
+				appManager.NavigateToURL(SettingsPageURL, nil)
+				appManager.NavigateToURL(ChatPageURL, nil)
+				w.Invalidate()
+			}
+			// A dApp asks for a session or a call, which always needs the approval of the user
+			switch data := event.Data.(type) {
+			case pubsub.DAppProposalEventData:
+				dapps.ShowProposal(&appManager, data.Proposal)
+				w.Invalidate()
+			case pubsub.DAppRequestEventData:
+				dapps.ShowRequest(&appManager, data.Request)
+				w.Invalidate()
+			}
 			var settingsBarFound bool
 			for _, eachPage := range appManager.pagesStack {
 				if l, ok := eachPage.(DatabaseListener); ok {
@@ -1,6 +1,7 @@
 package chat
 
 import (
+	"fmt"
 	"gioui.org/io/pointer"
 	"gioui.org/layout"
 	"gioui.org/op"
@@ -36,6 +37,8 @@ type page struct {
 	btnMenuContent          widget.Clickable
 	btnMenuIcon             widget.Clickable
 	btnAccountDetails       widget.Clickable
+	btnRequests             widget.Clickable
+	btnRequestsBanner       widget.Clickable
 	navIcon                 *widget.Icon
 	menuIcon                *widget.Icon
 	menuVisibilityAnim      component.VisibilityAnimation
@@ -50,6 +53,7 @@ type page struct {
 	isFetchingContactsCount bool
 	listPosition            layout.Position
 	contactsCount           int64
+	requestsCount           int64
 	ModalContent            *view.ModalContent
 	initialized             bool
 }
@@ -113,6 +117,10 @@ func (p *page) Layout(gtx Gtx) (d Dim) {
 		})
 		//p.menuVisibilityAnim.Disappear(gtx.Now)
 	}
+	if p.btnRequests.Clicked() || p.btnRequestsBanner.Clicked() {
+		p.menuVisibilityAnim.Disappear(gtx.Now)
+		p.NavigateToPage(NewRequests(p.Manager), nil)
+	}
 	if p.btnAccountDetails.Clicked() {
 		if p.AccountDetails == nil || p.AccountDetails.Account.PublicKey != a.PublicKey {
 			p.AccountDetails = view.NewAccountDetails(p.Manager, a)
@@ -138,6 +146,7 @@ func (p *page) Layout(gtx Gtx) (d Dim) {
 
 	d = flex.Layout(gtx,
 		layout.Rigid(p.DrawAppBar),
+		layout.Rigid(p.drawRequestsBanner),
 		layout.Rigid(p.drawChatItems),
 	)
 	p.drawMenuLayout(gtx)
@@ -224,6 +233,28 @@ func (p *page) drawChatItems(gtx Gtx) Dim {
 	})
 }
 
+// drawRequestsBanner leads to the requests inbox while there are requests
+func (p *page) drawRequestsBanner(gtx Gtx) Dim {
+	if p.requestsCount == 0 || !wallet.GlobalWallet.IsOpen() {
+		return Dim{}
+	}
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	btnStyle := material.ButtonLayoutStyle{Background: p.Theme.ContrastBg, Button: &p.btnRequestsBanner}
+	btnStyle.Background.A = 30
+	return btnStyle.Layout(gtx, func(gtx Gtx) Dim {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		return layout.UniformInset(unit.Dp(16)).Layout(gtx, func(gtx Gtx) Dim {
+			txt := fmt.Sprintf("%d Message Requests", p.requestsCount)
+			if p.requestsCount == 1 {
+				txt = "1 Message Request"
+			}
+			bd := material.Body1(p.Theme, txt)
+			bd.Font.Weight = text.Bold
+			return bd.Layout(gtx)
+		})
+	})
+}
+
 func (p *page) drawMenuLayout(gtx Gtx) Dim {
 	if p.btnBackdrop.Clicked() {
 		p.menuVisibilityAnim.Disappear(gtx.Now)
@@ -329,6 +360,29 @@ func (p *page) drawMenuItems(gtx Gtx) Dim {
 				},
 			)
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if a.PublicKey == "" {
+				return Dim{}
+			}
+			btnStyle := material.ButtonLayoutStyle{Button: &p.btnRequests}
+			btnStyle.Background = color.NRGBA(colornames.White)
+			return btnStyle.Layout(gtx,
+				func(gtx Gtx) Dim {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					inset := inset
+					return inset.Layout(gtx, func(gtx Gtx) Dim {
+						return layout.Flex{Spacing: layout.SpaceEnd}.Layout(gtx,
+							layout.Rigid(func(gtx Gtx) Dim {
+								bd := material.Body1(p.Theme, "Message Requests")
+								bd.Color = color.NRGBA(colornames.Black)
+								bd.Alignment = text.Start
+								return bd.Layout(gtx)
+							}),
+						)
+					})
+				},
+			)
+		}),
 	)
 }
 
@@ -390,6 +444,13 @@ func (p *page) OnDatabaseChange(event pubsub.Event) {
 	switch e := event.Data.(type) {
 	case pubsub.CurrentAccountChangedEventData, pubsub.AccountsChangedEventData:
 		p.fetchContacts(0, defaultListSize)
+		p.fetchContactsCount()
+	case pubsub.ContactRequestsChangedEventData:
+		if e.AccountPublicKey == acc.PublicKey {
+			p.fetchContactsCount()
+			// an accepted request is a new contact
+			p.fetchContacts(0, len(p.chatPageItems)+1)
+		}
 	case pubsub.ContactsChangeEventData:
 		if e.AccountPublicKey == acc.PublicKey {
 			if len(p.chatPageItems) == 0 {
@@ -415,9 +476,11 @@ func (p *page) fetchContactsCount() {
 		if err != nil {
 			alog.Logger().Errorln(err)
 		}
+		requestsCount, _ := wallet.GlobalWallet.ContactRequestsCount(acc.PublicKey)
 		p.isFetchingContactsCount = false
-		if p.contactsCount != count {
+		if p.contactsCount != count || p.requestsCount != requestsCount {
 			p.contactsCount = count
+			p.requestsCount = requestsCount
 			p.Window().Invalidate()
 		}
 	}
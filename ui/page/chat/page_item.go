@@ -17,7 +17,9 @@ import (
 	. "github.com/mearaj/protonet/ui/fwk"
 	"github.com/mearaj/protonet/ui/page/chatroom"
 	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
 	"image"
+	"image/color"
 )
 
 type pageItem struct {
@@ -88,18 +90,44 @@ func (pi *pageItem) layoutContent(gtx Gtx) Dim {
 								d := flex.Layout(gtx,
 									layout.Rigid(func(gtx Gtx) Dim {
 										textSize := unit.Sp(14)
-										label := material.Label(pi.Theme, textSize, pi.contact.PublicKey)
+										label := material.Label(pi.Theme, textSize, pi.contact.DisplayName())
 										label.Font.Weight = text.Bold
 										return component.TruncatingLabelStyle(label).Layout(gtx)
 									}),
 									layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
 									layout.Rigid(func(gtx Gtx) Dim {
+										presence := chat.GlobalChat.Presence(pi.contact.PublicKey)
+										if presence.IsTyping() {
+											op.InvalidateOp{At: presence.TypingUntil}.Add(gtx.Ops)
+											label := material.Label(pi.Theme, pi.Theme.TextSize*0.9, "typing…")
+											label.Color = pi.Theme.ContrastBg
+											label.Font.Style = text.Italic
+											return label.Layout(gtx)
+										}
 										if pi.lastMessage.Text == "" {
 											return Dim{}
 										}
 										label := material.Label(pi.Theme, pi.Theme.TextSize*0.9, pi.lastMessage.Text)
 										return component.TruncatingLabelStyle(label).Layout(gtx)
 									}),
+									layout.Rigid(func(gtx Gtx) Dim {
+										presence := chat.GlobalChat.Presence(pi.contact.PublicKey)
+										if presence.IsTyping() {
+											return Dim{}
+										}
+										txt, changesAt := view.PresenceText(presence)
+										if txt == "" {
+											return Dim{}
+										}
+										if !changesAt.IsZero() {
+											op.InvalidateOp{At: changesAt}.Add(gtx.Ops)
+										}
+										label := material.Caption(pi.Theme, txt)
+										if presence.Online {
+											label.Color = color.NRGBA(colornames.Green700)
+										}
+										return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, label.Layout)
+									}),
 								)
 								return d
 							})
@@ -238,6 +266,16 @@ func (p *pageItem) OnDatabaseChange(event pubsub.Event) {
 			p.fetchMessagesUnreadCount()
 			p.fetchLastMessage()
 		}
+	case pubsub.PresenceChangedEventData:
+		if e.PublicKey == p.contact.PublicKey {
+			p.Window().Invalidate()
+		}
+	case pubsub.SaveContactEventData:
+		if e.Contact.AccountPublicKey == p.contact.AccountPublicKey && e.Contact.PublicKey == p.contact.PublicKey {
+			p.contact = e.Contact
+			p.AvatarView.Image, _, _ = image.Decode(bytes.NewReader(e.Contact.Avatar))
+			p.Window().Invalidate()
+		}
 	}
 }
 
@@ -0,0 +1,269 @@
+package chat
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image/color"
+)
+
+// requestsPage is the inbox of the messages of unknown senders, a request is accepted, deleted
+// or blocked, and the block list is managed below the requests
+type requestsPage struct {
+	Manager
+	Theme            *material.Theme
+	title            string
+	buttonNavigation widget.Clickable
+	navigationIcon   *widget.Icon
+	requests         []*requestItem
+	blocked          []*blockedItem
+	fetched          bool
+	layout.List
+}
+
+type requestItem struct {
+	*pageItem
+	btnAccept widget.Clickable
+	btnDelete widget.Clickable
+	btnBlock  widget.Clickable
+}
+
+type blockedItem struct {
+	chat.BlockedContact
+	btnUnblock widget.Clickable
+}
+
+func NewRequests(manager Manager) Page {
+	navIcon, _ := widget.NewIcon(icons.NavigationArrowBack)
+	return &requestsPage{
+		Manager:        manager,
+		Theme:          manager.Theme(),
+		title:          "Message Requests",
+		navigationIcon: navIcon,
+		List:           layout.List{Axis: layout.Vertical},
+	}
+}
+
+func (p *requestsPage) Layout(gtx Gtx) Dim {
+	if p.Theme == nil {
+		p.Theme = p.Manager.Theme()
+	}
+	if !p.fetched {
+		p.fetched = true
+		p.fetch()
+	}
+	flex := layout.Flex{Axis: layout.Vertical,
+		Spacing:   layout.SpaceEnd,
+		Alignment: layout.Start,
+	}
+	d := flex.Layout(gtx,
+		layout.Rigid(p.DrawAppBar),
+		layout.Flexed(1, p.drawContent),
+	)
+	return d
+}
+
+func (p *requestsPage) drawContent(gtx Gtx) Dim {
+	th := p.Theme
+	children := []layout.Widget{
+		func(gtx Gtx) Dim {
+			txt := "Messages of senders who aren't in your contacts wait here until you accept them. " +
+				"Replying to a request accepts it."
+			return layout.UniformInset(unit.Dp(16)).Layout(gtx, material.Body2(th, txt).Layout)
+		},
+	}
+	if len(p.requests) == 0 {
+		children = append(children, func(gtx Gtx) Dim {
+			return layout.Inset{Left: unit.Dp(16), Right: unit.Dp(16)}.Layout(gtx, material.Body1(th, "No requests").Layout)
+		})
+	}
+	for _, request := range p.requests {
+		request := request
+		children = append(children, func(gtx Gtx) Dim {
+			return request.Layout(gtx, p)
+		})
+	}
+	children = append(children, func(gtx Gtx) Dim {
+		inset := layout.Inset{Top: unit.Dp(24), Bottom: unit.Dp(8), Left: unit.Dp(16), Right: unit.Dp(16)}
+		return inset.Layout(gtx, material.Subtitle1(th, "Blocked").Layout)
+	})
+	if len(p.blocked) == 0 {
+		children = append(children, func(gtx Gtx) Dim {
+			return layout.Inset{Left: unit.Dp(16), Right: unit.Dp(16)}.Layout(gtx, material.Body1(th, "Nobody is blocked").Layout)
+		})
+	}
+	for _, blocked := range p.blocked {
+		blocked := blocked
+		children = append(children, func(gtx Gtx) Dim {
+			return blocked.Layout(gtx, p)
+		})
+	}
+	return p.List.Layout(gtx, len(children), func(gtx Gtx, index int) Dim {
+		return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, children[index])
+	})
+}
+
+func (r *requestItem) Layout(gtx Gtx, p *requestsPage) Dim {
+	th := p.Theme
+	publicKey := r.contact.PublicKey
+	if r.btnAccept.Clicked() {
+		p.run(func(accountPublicKey string) error {
+			_, err := wallet.GlobalWallet.AcceptContactRequest(accountPublicKey, publicKey)
+			return err
+		})
+	}
+	if r.btnDelete.Clicked() {
+		p.run(func(accountPublicKey string) error {
+			return wallet.GlobalWallet.DeleteContactRequest(accountPublicKey, publicKey)
+		})
+	}
+	if r.btnBlock.Clicked() {
+		p.run(func(string) error {
+			return chat.GlobalChat.BlockContact(publicKey)
+		})
+	}
+	flex := layout.Flex{Axis: layout.Vertical}
+	return flex.Layout(gtx,
+		layout.Rigid(r.pageItem.Layout),
+		layout.Rigid(func(gtx Gtx) Dim {
+			inset := layout.Inset{Top: unit.Dp(8), Left: unit.Dp(16), Right: unit.Dp(16)}
+			return inset.Layout(gtx, func(gtx Gtx) Dim {
+				flex := layout.Flex{Spacing: layout.SpaceStart, Alignment: layout.Middle}
+				return flex.Layout(gtx,
+					layout.Rigid(func(gtx Gtx) Dim {
+						btn := material.Button(th, &r.btnBlock, "Block")
+						btn.Background = color.NRGBA(colornames.Red500)
+						return btn.Layout(gtx)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Button(th, &r.btnDelete, "Delete").Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+					layout.Rigid(material.Button(th, &r.btnAccept, "Accept").Layout),
+				)
+			})
+		}),
+	)
+}
+
+func (b *blockedItem) Layout(gtx Gtx, p *requestsPage) Dim {
+	th := p.Theme
+	publicKey := b.PublicKey
+	if b.btnUnblock.Clicked() {
+		p.run(func(accountPublicKey string) error {
+			return wallet.GlobalWallet.UnblockContact(accountPublicKey, publicKey)
+		})
+	}
+	inset := layout.Inset{Left: unit.Dp(16), Right: unit.Dp(16)}
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		flex := layout.Flex{Alignment: layout.Middle}
+		return flex.Layout(gtx,
+			layout.Flexed(1, func(gtx Gtx) Dim {
+				flex := layout.Flex{Axis: layout.Vertical}
+				return flex.Layout(gtx,
+					layout.Rigid(material.Body1(th, b.PublicKey).Layout),
+					layout.Rigid(material.Caption(th, "Blocked on "+b.BlockedAt.Local().Format("Jan 2 2006")).Layout),
+				)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, material.Button(th, &b.btnUnblock, "Unblock").Layout)
+			}),
+		)
+	})
+}
+
+// run runs action for the current account and reports its error
+func (p *requestsPage) run(action func(accountPublicKey string) error) {
+	go func() {
+		defer p.Window().Invalidate()
+		account, err := wallet.GlobalWallet.Account()
+		if err == nil {
+			err = action(account.PublicKey)
+		}
+		if err != nil {
+			alog.Logger().Errorln(err)
+			p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+		}
+	}()
+}
+
+func (p *requestsPage) fetch() {
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return
+	}
+	count, err := wallet.GlobalWallet.ContactRequestsCount(account.PublicKey)
+	requests := make([]*requestItem, 0)
+	if err == nil && count > 0 {
+		contacts, _ := wallet.GlobalWallet.ContactRequests(account.PublicKey, 0, int(count))
+		for _, contact := range contacts {
+			requests = append(requests, &requestItem{pageItem: newChatPageItem(p.Manager, contact)})
+		}
+	}
+	p.requests = requests
+	blockedContacts, _ := wallet.GlobalWallet.BlockedContacts(account.PublicKey)
+	blocked := make([]*blockedItem, len(blockedContacts))
+	for i, blockedContact := range blockedContacts {
+		blocked[i] = &blockedItem{BlockedContact: blockedContact}
+	}
+	p.blocked = blocked
+}
+
+func (p *requestsPage) OnDatabaseChange(event pubsub.Event) {
+	switch event.Data.(type) {
+	case pubsub.ContactRequestsChangedEventData, pubsub.BlockedContactsChangedEventData,
+		pubsub.CurrentAccountChangedEventData:
+		p.fetched = false
+		p.Window().Invalidate()
+	}
+	for _, request := range p.requests {
+		request.OnDatabaseChange(event)
+	}
+}
+
+func (p *requestsPage) DrawAppBar(gtx Gtx) Dim {
+	gtx.Constraints.Max.Y = gtx.Dp(56)
+	th := p.Theme
+	if p.buttonNavigation.Clicked() {
+		p.PopUp()
+	}
+
+	return view.DrawAppBarLayout(gtx, th, func(gtx Gtx) Dim {
+		return layout.Flex{Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx Gtx) Dim {
+						navigationIcon := p.navigationIcon
+						button := material.IconButton(th, &p.buttonNavigation, navigationIcon, "Nav Icon Button")
+						button.Size = unit.Dp(40)
+						button.Background = th.Palette.ContrastBg
+						button.Color = th.Palette.ContrastFg
+						button.Inset = layout.UniformInset(unit.Dp(8))
+						return button.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx Gtx) Dim {
+						return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+							titleText := p.title
+							title := material.Body1(th, titleText)
+							title.Color = th.Palette.ContrastFg
+							title.TextSize = unit.Sp(18)
+							return title.Layout(gtx)
+						})
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (p *requestsPage) URL() URL {
+	return ContactRequestsPageURL
+}
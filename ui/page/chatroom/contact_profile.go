@@ -0,0 +1,163 @@
+package chatroom
+
+import (
+	"bytes"
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	chat2 "github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"image"
+	"image/color"
+)
+
+// contactProfileView shows the profile shared by contact and lets the user give the contact a
+// name of their own, which is shown instead of the name of the profile
+type contactProfileView struct {
+	Manager
+	Theme      *material.Theme
+	contact    chat2.Contact
+	avatarView view.AvatarView
+	inputName  component.TextField
+	btnSave    widget.Clickable
+	saving     bool
+	err        error
+	// onChange is called with the contact after its local name is saved
+	onChange func(contact chat2.Contact)
+	*view.ModalContent
+}
+
+func newContactProfileView(manager Manager, theme *material.Theme, contact chat2.Contact, onChange func(contact chat2.Contact)) *contactProfileView {
+	v := &contactProfileView{
+		Manager:   manager,
+		Theme:     theme,
+		contact:   contact,
+		inputName: component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}, CharLimit: model.MaxProfileNameLength},
+		onChange:  onChange,
+	}
+	v.avatarView.Image, _, _ = image.Decode(bytes.NewReader(contact.Avatar))
+	v.inputName.SetText(contact.LocalName)
+	v.ModalContent = view.NewModalContent(func() { v.Modal().Dismiss(nil) })
+	return v
+}
+
+func (v *contactProfileView) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return v.ModalContent.DrawContent(gtx, v.Theme, v.drawView)
+}
+
+func (v *contactProfileView) drawView(gtx Gtx) Dim {
+	if v.btnSave.Clicked() && !v.saving {
+		v.save()
+	}
+	for _, e := range v.inputName.Events() {
+		if _, ok := e.(widget.SubmitEvent); ok && !v.saving {
+			v.save()
+		}
+	}
+	th := v.Theme
+	profileName := v.contact.ProfileName
+	if profileName == "" {
+		profileName = "No name shared"
+	}
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				if v.avatarView.Theme == nil {
+					v.avatarView.Theme = th
+				}
+				v.avatarView.Size = image.Point{X: gtx.Dp(96), Y: gtx.Dp(96)}
+				return layout.Center.Layout(gtx, v.avatarView.Layout)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, material.H6(th, profileName).Layout)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if v.contact.ProfileStatus == "" {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, material.Body1(th, v.contact.ProfileStatus).Layout)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if v.contact.ProfileUpdatedAt.IsZero() {
+					return Dim{}
+				}
+				txt := fmt.Sprintf("Profile updated on %s", v.contact.ProfileUpdatedAt.Local().Format("Jan 2 2006 15:04"))
+				return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, material.Caption(th, txt).Layout)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, material.Caption(th, "Public Key").Layout)
+			}),
+			layout.Rigid(material.Body2(th, v.contact.PublicKey).Layout),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if v.contact.EthAddress == "" {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					flex := layout.Flex{Axis: layout.Vertical}
+					return flex.Layout(gtx,
+						layout.Rigid(material.Caption(th, "Address").Layout),
+						layout.Rigid(material.Body2(th, v.contact.EthAddress).Layout),
+					)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					return v.inputName.Layout(gtx, th, "Your Name For This Contact")
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if v.err == nil {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					lbl := material.Body2(th, v.err.Error())
+					lbl.Color = color.NRGBA(colornames.Red500)
+					return lbl.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					if v.saving {
+						loader := view.Loader{Theme: th}
+						return loader.Layout(gtx)
+					}
+					flex := layout.Flex{Spacing: layout.SpaceSides, Alignment: layout.Middle}
+					return flex.Layout(gtx, layout.Rigid(material.Button(th, &v.btnSave, "Save Name").Layout))
+				})
+			}),
+		)
+	})
+}
+
+// save saves the local name of the contact and reports the saved contact to onChange
+func (v *contactProfileView) save() {
+	v.saving = true
+	v.err = nil
+	name := v.inputName.Text()
+	go func() {
+		defer v.Window().Invalidate()
+		contact := v.contact
+		err := wallet.GlobalWallet.SetContactName(&contact, name)
+		v.saving = false
+		if err != nil {
+			v.err = err
+			return
+		}
+		v.contact = contact
+		if v.onChange != nil {
+			v.onChange(contact)
+		}
+		v.Snackbar().Show("Name saved", nil, color.NRGBA{}, "")
+	}()
+}
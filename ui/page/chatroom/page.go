@@ -2,6 +2,8 @@ package chatroom
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"gioui.org/io/pointer"
 	"gioui.org/layout"
 	"gioui.org/op"
@@ -11,9 +13,12 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/mearaj/audio"
 	"github.com/mearaj/protonet/alog"
 	chat2 "github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
 	"github.com/mearaj/protonet/internal/pubsub"
 	"github.com/mearaj/protonet/internal/wallet"
 	. "github.com/mearaj/protonet/ui/fwk"
@@ -22,6 +27,7 @@ import (
 	"golang.org/x/exp/shiny/materialdesign/icons"
 	"image"
 	"image/color"
+	"math/big"
 	"runtime"
 	"strings"
 	"time"
@@ -48,6 +54,15 @@ type page struct {
 	btnVoiceMessage          widget.Clickable
 	btnAudioCall             widget.Clickable
 	btnVideoCall             widget.Clickable
+	btnPay                   widget.Clickable
+	btnRequest               widget.Clickable
+	btnSafe                  widget.Clickable
+	btnSafetyNumber          widget.Clickable
+	btnContactProfile        widget.Clickable
+	btnAcceptRequest         widget.Clickable
+	btnBlock                 widget.Clickable
+	btnUnblock               widget.Clickable
+	blocked                  bool
 	iconMenu                 *widget.Icon
 	iconNav                  *widget.Icon
 	iconExpand               *widget.Icon
@@ -55,6 +70,10 @@ type page struct {
 	iconVoiceMessage         *widget.Icon
 	iconAudioCall            *widget.Icon
 	iconVideoCall            *widget.Icon
+	iconPay                  *widget.Icon
+	iconRequest              *widget.Icon
+	iconSafe                 *widget.Icon
+	iconVerified             *widget.Icon
 	contact                  chat2.Contact
 	menuAnimation            component.VisibilityAnimation
 	iconsStackAnimation      component.VisibilityAnimation
@@ -69,6 +88,12 @@ type page struct {
 	messagesCount            int64
 	initialized              bool
 	recorder                 *audio.RawRecorder
+	// identifying is true while the ethereum address of the contact is requested before paying
+	identifying bool
+	// payingRequest is true while the currency of a payment request is looked up before paying
+	payingRequest bool
+	// approvingSafeTx is true while a safe transaction is signed or its execution is prepared
+	approvingSafeTx bool
 }
 
 func New(manager Manager, contact chat2.Contact) Page {
@@ -80,6 +105,10 @@ func New(manager Manager, contact chat2.Contact) Page {
 	iconVoiceMessage, _ := widget.NewIcon(icons.AVMic)
 	iconAudioCall, _ := widget.NewIcon(icons.CommunicationPhone)
 	iconVideoCall, _ := widget.NewIcon(icons.AVVideoCall)
+	iconPay, _ := widget.NewIcon(icons.EditorAttachMoney)
+	iconRequest, _ := widget.NewIcon(icons.ActionReceipt)
+	iconSafe, _ := widget.NewIcon(icons.ActionAccountBalance)
+	iconVerified, _ := widget.NewIcon(icons.ActionVerifiedUser)
 	submitEnabled := runtime.GOOS != "android" && runtime.GOOS != "ios"
 	pg := page{
 		Manager:            manager,
@@ -93,6 +122,10 @@ func New(manager Manager, contact chat2.Contact) Page {
 		iconVoiceMessage:   iconVoiceMessage,
 		iconAudioCall:      iconAudioCall,
 		iconVideoCall:      iconVideoCall,
+		iconPay:            iconPay,
+		iconRequest:        iconRequest,
+		iconSafe:           iconSafe,
+		iconVerified:       iconVerified,
 		fetchingMessagesCh: make(chan []chat2.Message, 10),
 		pageItems:          make([]*PageItem, 0),
 		List: layout.List{
@@ -115,6 +148,13 @@ func (p *page) Layout(gtx Gtx) Dim {
 		if p.Theme == nil {
 			p.Theme = p.Manager.Theme()
 		}
+		// the contact opened from a list may be older than its verification
+		if contact, err := wallet.GlobalWallet.Contact(p.contact.AccountPublicKey, p.contact.PublicKey); err == nil {
+			p.contact = contact
+		} else if contact, err = wallet.GlobalWallet.ContactRequest(p.contact.AccountPublicKey, p.contact.PublicKey); err == nil {
+			p.contact = contact
+		}
+		p.blocked = wallet.GlobalWallet.IsContactBlocked(p.contact.AccountPublicKey, p.contact.PublicKey)
 		p.fetchMessages(0, defaultListSize)
 		p.fetchMessagesCount()
 		p.initialized = true
@@ -134,6 +174,8 @@ func (p *page) Layout(gtx Gtx) Dim {
 	flex := layout.Flex{Axis: layout.Vertical, Spacing: layout.SpaceBetween}
 	d := flex.Layout(gtx,
 		layout.Rigid(p.DrawAppBar),
+		layout.Rigid(p.drawKeyChangedBanner),
+		layout.Rigid(p.drawRequestBanner),
 		layout.Flexed(1, p.drawChatRoomList),
 		layout.Rigid(p.drawSendMsgField),
 	)
@@ -164,16 +206,58 @@ func (p *page) DrawAppBar(gtx Gtx) Dim {
 						return button.Layout(gtx)
 					}),
 					layout.Rigid(func(gtx Gtx) Dim {
-						gtx.Constraints.Max.X = gtx.Constraints.Max.X - gtx.Dp(56)
+						if p.btnContactProfile.Clicked() {
+							p.showContactProfile()
+						}
+						gtx.Constraints.Max.X = gtx.Constraints.Max.X - gtx.Dp(96)
 						return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
-							titleText := p.contact.PublicKey
-							title := material.Label(th, unit.Sp(18), titleText)
-							title.Color = th.Palette.ContrastFg
-							return component.TruncatingLabelStyle(title).Layout(gtx)
+							return p.btnContactProfile.Layout(gtx, func(gtx Gtx) Dim {
+								flex := layout.Flex{Axis: layout.Vertical}
+								return flex.Layout(gtx,
+									layout.Rigid(func(gtx Gtx) Dim {
+										title := material.Label(th, unit.Sp(18), p.contact.DisplayName())
+										title.Color = th.Palette.ContrastFg
+										return component.TruncatingLabelStyle(title).Layout(gtx)
+									}),
+									layout.Rigid(func(gtx Gtx) Dim {
+										// the presence of the contact is shown over the status of its profile
+										txt, changesAt := view.PresenceText(chat2.GlobalChat.Presence(p.contact.PublicKey))
+										if !changesAt.IsZero() {
+											op.InvalidateOp{At: changesAt}.Add(gtx.Ops)
+										}
+										if txt == "" {
+											txt = p.contact.ProfileStatus
+										}
+										if txt == "" {
+											return Dim{}
+										}
+										status := material.Caption(th, txt)
+										status.Color = th.Palette.ContrastFg
+										return component.TruncatingLabelStyle(status).Layout(gtx)
+									}),
+								)
+							})
 						})
 					}),
 				)
 			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if p.btnSafetyNumber.Clicked() {
+					p.showSafetyNumber()
+				}
+				// the shield is green for a verified contact and red while its key changed
+				button := material.IconButton(th, &p.btnSafetyNumber, p.iconVerified, "Safety Number")
+				button.Size = unit.Dp(24)
+				button.Background = th.Palette.ContrastBg
+				button.Color = th.Palette.ContrastFg
+				if p.contact.IsVerified() {
+					button.Color = color.NRGBA(colornames.Green300)
+				} else if p.contact.KeyChanged() {
+					button.Color = color.NRGBA(colornames.Red300)
+				}
+				button.Inset = layout.UniformInset(unit.Dp(8))
+				return button.Layout(gtx)
+			}),
 			layout.Rigid(func(gtx Gtx) Dim {
 				if p.AvatarView.Size == (image.Point{}) {
 					p.AvatarView.Size = image.Point{X: gtx.Dp(36), Y: gtx.Dp(36)}
@@ -253,8 +337,13 @@ func (p *page) drawChatRoomListItem(gtx Gtx, index int) Dim {
 
 func (p *page) inputMsgFieldSubmitted() (submit bool) {
 	for _, event := range p.inputMsgField.Events() {
-		if _, submit = event.(widget.SubmitEvent); submit {
-			break
+		switch event.(type) {
+		case widget.SubmitEvent:
+			submit = true
+		case widget.ChangeEvent:
+			// the contact sees that a message is typed until it's sent or erased
+			typing := strings.TrimSpace(p.inputMsgField.Text()) != ""
+			chat2.GlobalChat.SetTyping(p.contact.PublicKey, typing)
 		}
 	}
 	return submit
@@ -273,6 +362,7 @@ func (p *page) drawSendMsgField(gtx Gtx) Dim {
 			}
 			acc, _ := wallet.GlobalWallet.Account()
 			chat2.GlobalChat.SendNewMessage(&acc, &msg)
+			chat2.GlobalChat.SetTyping(p.contact.PublicKey, false)
 		}
 	}
 	fl := layout.Flex{
@@ -385,6 +475,76 @@ func (p *page) drawIconsStackItems(gtx Gtx) Dim {
 	//inset := layout.UniformInset(unit.Dp(12))
 	flex := layout.Flex{Axis: layout.Vertical}
 	return flex.Layout(gtx,
+		layout.Rigid(func(gtx Gtx) Dim {
+			inset := layout.Inset{Left: unit.Dp(8.0)}
+			return inset.Layout(
+				gtx,
+				func(gtx Gtx) Dim {
+					p.handlePayClick()
+					if p.identifying {
+						loader := view.Loader{Size: image.Point{X: gtx.Dp(42), Y: gtx.Dp(42)}}
+						return loader.Layout(gtx)
+					}
+					return material.IconButtonStyle{
+						Background: p.Theme.ContrastBg,
+						Color:      p.Theme.ContrastFg,
+						Icon:       p.iconPay,
+						Size:       unit.Dp(24.0),
+						Button:     &p.btnPay,
+						Inset:      layout.UniformInset(unit.Dp(9)),
+					}.Layout(gtx)
+				},
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx Gtx) Dim {
+			inset := layout.Inset{Left: unit.Dp(8.0)}
+			return inset.Layout(
+				gtx,
+				func(gtx Gtx) Dim {
+					if p.btnRequest.Clicked() {
+						p.Modal().Show(newRequestCurrencyForm(p.Manager, p.Theme, p.contact).Layout, nil, Animation{
+							Duration: time.Millisecond * 250,
+							State:    component.Invisible,
+							Started:  time.Time{},
+						})
+					}
+					return material.IconButtonStyle{
+						Background: p.Theme.ContrastBg,
+						Color:      p.Theme.ContrastFg,
+						Icon:       p.iconRequest,
+						Size:       unit.Dp(24.0),
+						Button:     &p.btnRequest,
+						Inset:      layout.UniformInset(unit.Dp(9)),
+					}.Layout(gtx)
+				},
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx Gtx) Dim {
+			inset := layout.Inset{Left: unit.Dp(8.0)}
+			return inset.Layout(
+				gtx,
+				func(gtx Gtx) Dim {
+					if p.btnSafe.Clicked() {
+						p.Modal().Show(newSafeCurrencyForm(p.Manager, p.Theme, p.contact).Layout, nil, Animation{
+							Duration: time.Millisecond * 250,
+							State:    component.Invisible,
+							Started:  time.Time{},
+						})
+					}
+					return material.IconButtonStyle{
+						Background: p.Theme.ContrastBg,
+						Color:      p.Theme.ContrastFg,
+						Icon:       p.iconSafe,
+						Size:       unit.Dp(24.0),
+						Button:     &p.btnSafe,
+						Inset:      layout.UniformInset(unit.Dp(9)),
+					}.Layout(gtx)
+				},
+			)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
 		layout.Rigid(func(gtx Gtx) Dim {
 			inset := layout.Inset{Left: unit.Dp(8.0)}
 			return inset.Layout(
@@ -494,6 +654,32 @@ func (p *page) OnDatabaseChange(event pubsub.Event) {
 			e.ContactPublicKey == p.contact.PublicKey {
 			shouldFetch = true
 		}
+	case pubsub.SafeTxChangedEventData:
+		for _, i := range p.pageItems {
+			if i.safeHash == e.Hash {
+				safeTx := e.SafeTx
+				i.safeTx = &safeTx
+				i.safeCheckedAt = time.Time{}
+			}
+		}
+		p.Window().Invalidate()
+	case pubsub.SaveContactEventData:
+		if e.Contact.AccountPublicKey == acc.PublicKey && e.Contact.PublicKey == p.contact.PublicKey {
+			if !bytes.Equal(e.Contact.Avatar, p.contact.Avatar) {
+				p.AvatarView.Image = nil
+			}
+			p.contact = e.Contact
+			p.Window().Invalidate()
+		}
+	case pubsub.PresenceChangedEventData:
+		if e.PublicKey == p.contact.PublicKey {
+			p.Window().Invalidate()
+		}
+	case pubsub.BlockedContactsChangedEventData:
+		if e.AccountPublicKey == acc.PublicKey {
+			p.blocked = wallet.GlobalWallet.IsContactBlocked(acc.PublicKey, p.contact.PublicKey)
+			p.Window().Invalidate()
+		}
 	case pubsub.CurrentAccountChangedEventData:
 		shouldFetch = true
 	}
@@ -516,6 +702,10 @@ func (p *page) syncMessagesPageItems(messages []chat2.Message) {
 				Message:          messages[i],
 				Theme:            p.Theme,
 				accountPublicKey: acc.PublicKey,
+				paymentOf:        p.paymentOf,
+				onPayRequest:     p.payRequest,
+				onApproveSafe:    p.approveSafeTx,
+				onExecuteSafe:    p.executeSafeTx,
 			}
 			p.pageItems = append(p.pageItems, msgItem)
 		}
@@ -523,6 +713,10 @@ func (p *page) syncMessagesPageItems(messages []chat2.Message) {
 		p.pageItems = p.pageItems[:len(messages)]
 	}
 	for i := range messages {
+		if p.pageItems[i].Message.ID != messages[i].ID {
+			p.pageItems[i].resetSafeTx()
+			p.pageItems[i].resetCurrency()
+		}
 		p.pageItems[i].Message = messages[i]
 		if len(p.pageItems[i].Message.Audio) != 0 {
 			var err error
@@ -619,6 +813,248 @@ func (p *page) handleRecordingClick(gtx Gtx) {
 	}
 }
 
+// handlePayClick shows the payment form, the ethereum address of a contact added by public key
+// is requested from its peer first
+func (p *page) handlePayClick() {
+	if !p.btnPay.Clicked() || p.identifying {
+		return
+	}
+	p.identifying = true
+	go func() {
+		defer p.Window().Invalidate()
+		contact, err := chat2.GlobalChat.IdentifyContact(context.Background(), p.contact)
+		p.identifying = false
+		if err != nil {
+			txt := fmt.Sprintf("Couldn't get the Ethereum address of the contact: %s", err)
+			p.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+			return
+		}
+		p.contact = contact
+		p.Modal().Show(newPaymentForm(p.Manager, p.Theme, contact).Layout, nil, Animation{
+			Duration: time.Millisecond * 250,
+			State:    component.Invisible,
+			Started:  time.Time{},
+		})
+	}()
+}
+
+// paymentOf returns the payment sent in the chat for the payment request of requestID, a payment
+// which failed, doesn't match its transaction or claims a claimed transfer doesn't pay the request
+func (p *page) paymentOf(requestID string) *model.Payment {
+	for _, item := range p.pageItems {
+		payment := item.Message.Payment
+		if payment == nil || payment.RequestID != requestID {
+			continue
+		}
+		if payment.Status == model.PaymentStatusFailed || payment.Status == model.PaymentStatusInvalid ||
+			payment.Status == model.PaymentStatusDuplicate {
+			continue
+		}
+		return payment
+	}
+	return nil
+}
+
+// payRequest shows the form sending the payment requested by msg, the chain of the request must
+// be connected and a token which isn't known is read from the chain
+func (p *page) payRequest(msg chat2.Message) {
+	if p.payingRequest {
+		return
+	}
+	request := *msg.PaymentRequest
+	conn, ok := wallet.GlobalWallet.Connection(request.ChainID)
+	if !ok || !conn.IsConnected() {
+		txt := fmt.Sprintf("Connect to the chain %s in the wallet to pay the request", request.ChainID)
+		p.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+		return
+	}
+	p.payingRequest = true
+	go func() {
+		defer p.Window().Invalidate()
+		defer func() { p.payingRequest = false }()
+		var token *evm.Token
+		decimals := conn.Chain.NativeCurrency.Decimals
+		if request.TokenAddress != "" {
+			t, err := wallet.GlobalWallet.Token(conn, common.HexToAddress(request.TokenAddress))
+			if err != nil {
+				txt := fmt.Sprintf("Couldn't read the token of the request: %s", err)
+				p.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+				return
+			}
+			token, decimals = &t, t.Decimals
+		}
+		amount, ok := new(big.Int).SetString(request.Amount, 10)
+		if !ok {
+			p.Snackbar().Show(wallet.ErrInvalidPaymentRequest.Error(), nil, color.NRGBA{}, "")
+			return
+		}
+		showSendForm(p.Manager, p.Theme, p.contact, conn, token, request.To, evm.FormatUnits(amount, decimals), msg.ID)
+	}()
+}
+
+// approveSafeTx signs safeTx as an owner of its safe and sends the signature to the contact
+func (p *page) approveSafeTx(safeTx model.SafeTx) {
+	if p.approvingSafeTx {
+		return
+	}
+	p.approvingSafeTx = true
+	go func() {
+		defer p.Window().Invalidate()
+		defer func() { p.approvingSafeTx = false }()
+		approval, err := wallet.GlobalWallet.ApproveSafeTx(safeTx.Hash)
+		if err != nil {
+			p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+			return
+		}
+		msg := chat2.Message{
+			Recipient:    p.contact.PublicKey,
+			CreatedAt:    time.Now().UTC(),
+			SafeApproval: &approval,
+		}
+		acc, _ := wallet.GlobalWallet.Account()
+		chat2.GlobalChat.SendNewMessage(&acc, &msg)
+	}()
+}
+
+// executeSafeTx prepares the execution of safeTx and shows it for confirmation
+func (p *page) executeSafeTx(safeTx model.SafeTx) {
+	if p.approvingSafeTx {
+		return
+	}
+	p.approvingSafeTx = true
+	go func() {
+		defer p.Window().Invalidate()
+		defer func() { p.approvingSafeTx = false }()
+		conn, prepared, err := wallet.GlobalWallet.PrepareSafeExec(safeTx)
+		if err != nil {
+			p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+			return
+		}
+		form := newSafeExecForm(p.Manager, p.Theme, conn, safeTx, prepared)
+		p.Modal().Show(form.Layout, nil, Animation{
+			Duration: time.Millisecond * 250,
+			State:    component.Invisible,
+			Started:  time.Time{},
+		})
+	}()
+}
+
 func (p *page) URL() URL {
 	return ChatRoomPageURL + "/" + URL(p.contact.PublicKey)
 }
+
+// drawKeyChangedBanner warns that the ethereum address of the contact was known with another key
+// until the user verifies the safety number
+func (p *page) drawKeyChangedBanner(gtx Gtx) Dim {
+	if !p.contact.KeyChanged() {
+		return Dim{}
+	}
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	mac := op.Record(gtx.Ops)
+	d := layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx Gtx) Dim {
+		gtx.Constraints.Min.X = gtx.Constraints.Max.X
+		lbl := material.Body1(p.Theme, keyChangedWarning(p.contact)+" Tap the shield to verify.")
+		lbl.Color = color.NRGBA(colornames.White)
+		lbl.Font.Weight = text.Bold
+		return lbl.Layout(gtx)
+	})
+	call := mac.Stop()
+	component.Rect{Size: d.Size, Color: color.NRGBA(colornames.Red700)}.Layout(gtx)
+	call.Add(gtx.Ops)
+	return d
+}
+
+// showContactProfile shows the profile of the contact to give it a local name
+func (p *page) showContactProfile() {
+	form := newContactProfileView(p.Manager, p.Theme, p.contact, func(contact chat2.Contact) {
+		p.contact = contact
+	})
+	p.Modal().Show(form.Layout, nil, Animation{
+		Duration: time.Millisecond * 250,
+		State:    component.Invisible,
+		Started:  time.Time{},
+	})
+}
+
+// showSafetyNumber shows the safety number of the conversation to verify the contact
+func (p *page) showSafetyNumber() {
+	form := newSafetyNumberView(p.Manager, p.Theme, p.contact, func(contact chat2.Contact) {
+		p.contact = contact
+	})
+	p.Modal().Show(form.Layout, nil, Animation{
+		Duration: time.Millisecond * 250,
+		State:    component.Invisible,
+		Started:  time.Time{},
+	})
+}
+
+// drawRequestBanner lets the user accept or block the sender of a request, or unblock a blocked
+// contact
+func (p *page) drawRequestBanner(gtx Gtx) Dim {
+	if !p.contact.Request && !p.blocked {
+		return Dim{}
+	}
+	publicKey := p.contact.PublicKey
+	if p.btnAcceptRequest.Clicked() {
+		p.runContactAction(func(accountPublicKey string) error {
+			_, err := wallet.GlobalWallet.AcceptContactRequest(accountPublicKey, publicKey)
+			return err
+		})
+	}
+	if p.btnBlock.Clicked() {
+		p.runContactAction(func(string) error {
+			return chat2.GlobalChat.BlockContact(publicKey)
+		})
+	}
+	if p.btnUnblock.Clicked() {
+		p.runContactAction(func(accountPublicKey string) error {
+			return wallet.GlobalWallet.UnblockContact(accountPublicKey, publicKey)
+		})
+	}
+	txt := "This sender isn't in your contacts. Replying accepts the request."
+	if p.blocked {
+		txt = "This contact is blocked, their messages are dropped."
+	}
+	gtx.Constraints.Min.X = gtx.Constraints.Max.X
+	mac := op.Record(gtx.Ops)
+	d := layout.UniformInset(unit.Dp(12)).Layout(gtx, func(gtx Gtx) Dim {
+		flex := layout.Flex{Alignment: layout.Middle}
+		return flex.Layout(gtx,
+			layout.Flexed(1, material.Body2(p.Theme, txt).Layout),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if p.blocked {
+					return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, material.Button(p.Theme, &p.btnUnblock, "Unblock").Layout)
+				}
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx Gtx) Dim {
+						btn := material.Button(p.Theme, &p.btnBlock, "Block")
+						btn.Background = color.NRGBA(colornames.Red500)
+						return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, btn.Layout)
+					}),
+					layout.Rigid(func(gtx Gtx) Dim {
+						return layout.Inset{Left: unit.Dp(8)}.Layout(gtx, material.Button(p.Theme, &p.btnAcceptRequest, "Accept").Layout)
+					}),
+				)
+			}),
+		)
+	})
+	call := mac.Stop()
+	component.Rect{Size: d.Size, Color: color.NRGBA(colornames.Grey200)}.Layout(gtx)
+	call.Add(gtx.Ops)
+	return d
+}
+
+// runContactAction runs action for the current account and reports its error
+func (p *page) runContactAction(action func(accountPublicKey string) error) {
+	go func() {
+		defer p.Window().Invalidate()
+		account, err := wallet.GlobalWallet.Account()
+		if err == nil {
+			err = action(account.PublicKey)
+		}
+		if err != nil {
+			alog.Logger().Errorln(err)
+			p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+		}
+	}()
+}
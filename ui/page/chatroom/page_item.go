@@ -1,6 +1,9 @@
 package chatroom
 
 import (
+	"errors"
+	"fmt"
+	"gioui.org/io/clipboard"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/clip"
@@ -9,14 +12,22 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/mearaj/audio"
 	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/assets/fonts"
 	"github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/wallet"
 	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
 	"golang.org/x/exp/shiny/materialdesign/icons"
 	"image"
+	"image/color"
 	"math"
+	"time"
 )
 
 type PageItem struct {
@@ -27,10 +38,54 @@ type PageItem struct {
 	stopIcon         *widget.Icon
 	accountPublicKey string
 	player           *audio.RawPlayer
+	// verifyingPayment is true while the payment of the message is verified on chain
+	verifyingPayment bool
+	paymentCheckedAt time.Time
+	paymentErr       error
+	// currency is the currency of the payment or the payment request of the message read from the
+	// tokens of its chain or from the chain, the symbol and decimals claimed by the peer aren't
+	// trusted
+	currency          *wallet.Currency
+	currencyErr       error
+	loadingCurrency   bool
+	currencyCheckedAt time.Time
+	// requestErr is the result of the verification of the payment request of the message
+	requestErr      error
+	requestVerified bool
+	qrCode          view.QRCode
+	btnCopyURI      widget.Clickable
+	btnPayRequest   widget.Clickable
+	// paymentOf returns the payment message sent for the request of requestID, if any
+	paymentOf func(requestID string) *model.Payment
+	// onPayRequest is called when the user pays the payment request of the message
+	onPayRequest func(msg chat.Message)
+	// safeTx is the saved safe transaction of the message with the signatures collected from all
+	// the conversations, safeHash is its hash computed locally
+	safeTx         *model.SafeTx
+	safeHash       string
+	safeSummary    string
+	safeLoaded     bool
+	safeStatus     *wallet.SafeTxStatus
+	safeErr        error
+	checkingSafe   bool
+	safeCheckedAt  time.Time
+	btnApproveSafe widget.Clickable
+	btnExecuteSafe widget.Clickable
+	// onApproveSafe is called when the user signs the safe transaction of the message
+	onApproveSafe func(safeTx model.SafeTx)
+	// onExecuteSafe is called when the user executes the safe transaction of the message
+	onExecuteSafe func(safeTx model.SafeTx)
 }
 
+// paymentVerifyInterval is the interval between the verifications of a pending payment
+const paymentVerifyInterval = 15 * time.Second
+
+// safeCheckInterval is the interval between the checks of a safe transaction which isn't executed
+const safeCheckInterval = 15 * time.Second
+
 func (p *PageItem) Layout(gtx Gtx) (d Dim) {
-	if p.Message.Text == "" && len(p.Message.Audio) == 0 {
+	if p.Message.Text == "" && len(p.Message.Audio) == 0 && p.Message.Payment == nil &&
+		p.Message.PaymentRequest == nil && p.Message.SafeTx == nil && p.Message.SafeApproval == nil {
 		return d
 	}
 	if p.Theme == nil {
@@ -98,36 +153,25 @@ func (p *PageItem) Layout(gtx Gtx) (d Dim) {
 						return Dim{}
 					}),
 					layout.Rigid(func(gtx Gtx) Dim {
-						if p.Message.Text != "" {
-							macro := op.Record(gtx.Ops)
-							inset := layout.UniformInset(unit.Dp(12))
-							d := inset.Layout(gtx, func(gtx Gtx) Dim {
-								flex := layout.Flex{}
-								gtx.Constraints.Min.X = 0
-								return flex.Layout(gtx,
-									layout.Rigid(func(gtx Gtx) Dim {
-										gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) / 1.5)
-										bd := material.Body1(p.Theme, p.Message.Text)
-										return bd.Layout(gtx)
-									}))
+						if p.Message.Payment != nil {
+							return p.drawBubble(gtx, isMe, func(gtx Gtx) Dim {
+								return p.drawPayment(gtx, isMe)
+							})
+						} else if p.Message.PaymentRequest != nil {
+							return p.drawBubble(gtx, isMe, func(gtx Gtx) Dim {
+								return p.drawPaymentRequest(gtx, isMe)
+							})
+						} else if p.Message.SafeTx != nil {
+							return p.drawBubble(gtx, isMe, func(gtx Gtx) Dim {
+								return p.drawSafeTx(gtx, isMe)
+							})
+						} else if p.Message.SafeApproval != nil {
+							return p.drawBubble(gtx, isMe, p.drawSafeApproval)
+						} else if p.Message.Text != "" {
+							return p.drawBubble(gtx, isMe, func(gtx Gtx) Dim {
+								bd := material.Body1(p.Theme, p.Message.Text)
+								return bd.Layout(gtx)
 							})
-							call := macro.Stop()
-							bgColor := p.Theme.ContrastBg
-							bgColor.A = 50
-							radius := gtx.Dp(16)
-							sE, sW, nW, nE := radius, radius, radius, radius
-							if isMe {
-								nE = 0
-							} else {
-								nW = 0
-							}
-							clipOp := clip.RRect{Rect: image.Rectangle{
-								Max: image.Point{X: d.Size.X, Y: d.Size.Y},
-							}, SE: sE, SW: sW, NW: nW, NE: nE}.Push(gtx.Ops)
-							component.Rect{Color: bgColor, Size: d.Size}.Layout(gtx)
-							call.Add(gtx.Ops)
-							clipOp.Pop()
-							return d
 						} else if len(p.Message.Audio) != 0 {
 							btn := &p.btnPlayPauseIcon
 							icon := p.playIcon
@@ -178,3 +222,406 @@ func (p *PageItem) handlePlayPauseClick(gtx Gtx) {
 		}()
 	}
 }
+
+// drawBubble draws content in the bubble of a message
+func (p *PageItem) drawBubble(gtx Gtx, isMe bool, content layout.Widget) Dim {
+	macro := op.Record(gtx.Ops)
+	inset := layout.UniformInset(unit.Dp(12))
+	d := inset.Layout(gtx, func(gtx Gtx) Dim {
+		flex := layout.Flex{}
+		gtx.Constraints.Min.X = 0
+		return flex.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) / 1.5)
+				return content(gtx)
+			}))
+	})
+	call := macro.Stop()
+	bgColor := p.Theme.ContrastBg
+	bgColor.A = 50
+	radius := gtx.Dp(16)
+	sE, sW, nW, nE := radius, radius, radius, radius
+	if isMe {
+		nE = 0
+	} else {
+		nW = 0
+	}
+	clipOp := clip.RRect{Rect: image.Rectangle{
+		Max: image.Point{X: d.Size.X, Y: d.Size.Y},
+	}, SE: sE, SW: sW, NW: nW, NE: nE}.Push(gtx.Ops)
+	component.Rect{Color: bgColor, Size: d.Size}.Layout(gtx)
+	call.Add(gtx.Ops)
+	clipOp.Pop()
+	return d
+}
+
+// drawPayment draws the payment of the message with its status verified on chain, a pending
+// payment is verified again every paymentVerifyInterval
+func (p *PageItem) drawPayment(gtx Gtx, isMe bool) Dim {
+	payment := *p.Message.Payment
+	if payment.Status == model.PaymentStatusPending {
+		if !p.verifyingPayment && time.Since(p.paymentCheckedAt) >= paymentVerifyInterval {
+			p.verifyPayment()
+		}
+		op.InvalidateOp{At: gtx.Now.Add(paymentVerifyInterval)}.Add(gtx.Ops)
+	}
+	p.loadCurrency(gtx, payment.ChainID, payment.TokenAddress)
+	if p.currency != nil {
+		payment.Symbol, payment.Decimals = p.currency.Symbol, p.currency.Decimals
+	}
+	summary := fmt.Sprintf("Received %s", wallet.FormatPayment(payment))
+	if isMe {
+		summary = fmt.Sprintf("Sent %s", wallet.FormatPayment(payment))
+	}
+	chainName := payment.ChainID
+	if chain, ok := evm.ChainByID(payment.ChainID); ok {
+		chainName = chain.Name
+	}
+	status, statusColor := "Pending", color.NRGBA(colornames.Orange500)
+	switch payment.Status {
+	case model.PaymentStatusConfirmed:
+		status, statusColor = "Verified on chain", color.NRGBA(colornames.Green500)
+	case model.PaymentStatusFailed:
+		status, statusColor = "Failed", color.NRGBA(colornames.Red500)
+	case model.PaymentStatusInvalid:
+		status, statusColor = "Doesn't match its transaction", color.NRGBA(colornames.Red500)
+	case model.PaymentStatusDuplicate:
+		status, statusColor = "Transfer already claimed by another payment", color.NRGBA(colornames.Red500)
+	}
+	if payment.Status == model.PaymentStatusPending && p.paymentErr != nil {
+		status = fmt.Sprintf("%s, %s", status, p.paymentErr)
+	}
+	flex := layout.Flex{Axis: layout.Vertical}
+	return flex.Layout(gtx,
+		layout.Rigid(func(gtx Gtx) Dim {
+			lbl := material.Body1(p.Theme, summary)
+			lbl.Font.Weight = text.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(material.Caption(p.Theme, "on "+chainName).Layout),
+		layout.Rigid(p.drawTokenAddress(payment.TokenAddress)),
+		layout.Rigid(func(gtx Gtx) Dim {
+			lbl := material.Caption(p.Theme, status)
+			lbl.Color = statusColor
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(material.Caption(p.Theme, payment.TxHash).Layout),
+	)
+}
+
+// loadCurrency reads the currency of tokenAddress on the chain of chainID once, it's read again
+// every paymentVerifyInterval while it fails such as when the chain isn't connected
+func (p *PageItem) loadCurrency(gtx Gtx, chainID, tokenAddress string) {
+	if p.currency != nil {
+		return
+	}
+	op.InvalidateOp{At: gtx.Now.Add(time.Second)}.Add(gtx.Ops)
+	if p.loadingCurrency || time.Since(p.currencyCheckedAt) < paymentVerifyInterval {
+		return
+	}
+	p.loadingCurrency = true
+	go func() {
+		currency, err := wallet.GlobalWallet.Currency(chainID, tokenAddress)
+		if err == nil {
+			p.currency = &currency
+		}
+		p.currencyErr = err
+		p.currencyCheckedAt = time.Now()
+		p.loadingCurrency = false
+	}()
+}
+
+// drawTokenAddress draws the contract address of a token which isn't in the tokens of the chain,
+// its symbol is only what the contract claims
+func (p *PageItem) drawTokenAddress(tokenAddress string) layout.Widget {
+	return func(gtx Gtx) Dim {
+		if tokenAddress == "" || (p.currency != nil && p.currency.Known) {
+			return Dim{}
+		}
+		return material.Caption(p.Theme, "Token "+tokenAddress).Layout(gtx)
+	}
+}
+
+func (p *PageItem) resetCurrency() {
+	p.currency, p.currencyErr, p.currencyCheckedAt = nil, nil, time.Time{}
+}
+
+func (p *PageItem) verifyPayment() {
+	p.verifyingPayment = true
+	msg := p.Message
+	go func() {
+		// the page updates the item when the status is saved
+		p.paymentErr = wallet.GlobalWallet.VerifyPayment(&msg)
+		p.paymentCheckedAt = time.Now()
+		p.verifyingPayment = false
+	}()
+}
+
+// drawPaymentRequest draws the payment request of the message with its EIP-681 uri and QR code,
+// the recipient of a valid request pays it with the Pay button
+func (p *PageItem) drawPaymentRequest(gtx Gtx, isMe bool) Dim {
+	request := *p.Message.PaymentRequest
+	if !p.requestVerified {
+		p.requestVerified = true
+		p.requestErr = wallet.GlobalWallet.VerifyPaymentRequest(request, p.Message.Sender)
+	}
+	var payment *model.Payment
+	if p.paymentOf != nil {
+		payment = p.paymentOf(p.Message.ID)
+	}
+	uri, err := wallet.PaymentRequestURI(request)
+	if err != nil {
+		p.requestErr = err
+	}
+	uriText := uri.String()
+	if p.btnCopyURI.Clicked() {
+		clipboard.WriteOp{Text: uriText}.Add(gtx.Ops)
+	}
+	canPay := !isMe && payment == nil && !request.IsExpired() && p.requestErr == nil
+	if p.btnPayRequest.Clicked() && canPay && p.onPayRequest != nil {
+		p.onPayRequest(p.Message)
+	}
+	// the amount is shown in the currency the request is paid with
+	p.loadCurrency(gtx, request.ChainID, request.TokenAddress)
+	summary := "Requested payment"
+	switch {
+	case p.currency != nil:
+		summary = fmt.Sprintf("Requested %s", wallet.FormatPaymentRequest(request, *p.currency))
+	case p.currencyErr != nil:
+		summary = fmt.Sprintf("%s, %s", summary, p.currencyErr)
+	}
+	chainName := request.ChainID
+	chain, chainOk := evm.ChainByID(request.ChainID)
+	if chainOk {
+		chainName = chain.Name
+	}
+	status, statusColor := "Awaiting payment", color.NRGBA(colornames.Orange500)
+	switch {
+	case p.requestErr != nil && !errors.Is(p.requestErr, wallet.ErrPaymentRequestExpired):
+		status, statusColor = "Invalid signature", color.NRGBA(colornames.Red500)
+	case payment != nil:
+		status, statusColor = "Paid", color.NRGBA(colornames.Green500)
+	case request.IsExpired():
+		status, statusColor = "Expired", color.NRGBA(colornames.Red500)
+	case !request.ExpiresAt.IsZero():
+		status = fmt.Sprintf("%s until %s", status, request.ExpiresAt.Local().Format("Mon, Jan 2, 3:04 PM"))
+	}
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx Gtx) Dim {
+			lbl := material.Body1(p.Theme, summary)
+			lbl.Font.Weight = text.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(material.Caption(p.Theme, "on "+chainName).Layout),
+		layout.Rigid(p.drawTokenAddress(request.TokenAddress)),
+	}
+	if request.Memo != "" {
+		children = append(children, layout.Rigid(material.Body2(p.Theme, request.Memo).Layout))
+	}
+	children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+		lbl := material.Caption(p.Theme, status)
+		lbl.Color = statusColor
+		return lbl.Layout(gtx)
+	}))
+	if payment != nil {
+		link := payment.TxHash
+		if chainOk && chain.ExplorerTxURL(payment.TxHash) != "" {
+			link = chain.ExplorerTxURL(payment.TxHash)
+		}
+		children = append(children, layout.Rigid(material.Caption(p.Theme, link).Layout))
+	}
+	if err == nil {
+		p.qrCode.Text = uriText
+		children = append(children,
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, p.qrCode.Layout)
+			}),
+			layout.Rigid(material.Caption(p.Theme, uriText).Layout),
+		)
+	}
+	children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+		return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+			flex := layout.Flex{Alignment: layout.Middle}
+			return flex.Layout(gtx,
+				layout.Rigid(material.Button(p.Theme, &p.btnCopyURI, "Copy URI").Layout),
+				layout.Rigid(func(gtx Gtx) Dim {
+					if !canPay {
+						return Dim{}
+					}
+					inset := layout.Inset{Left: unit.Dp(8)}
+					return inset.Layout(gtx, material.Button(p.Theme, &p.btnPayRequest, "Pay").Layout)
+				}),
+			)
+		})
+	}))
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+// loadSafeTx loads the saved safe transaction of the message, the hash of a proposal is computed
+// from its fields as the hash claimed by the peer isn't trusted
+func (p *PageItem) loadSafeTx() {
+	p.safeLoaded = true
+	if p.Message.SafeApproval != nil {
+		p.safeHash = p.Message.SafeApproval.Hash
+	} else {
+		tx, err := wallet.SafeTxOf(*p.Message.SafeTx)
+		if err != nil {
+			p.safeErr = err
+			return
+		}
+		hash, err := tx.Hash()
+		if err != nil {
+			p.safeErr = err
+			return
+		}
+		p.safeHash = hash.Hex()
+	}
+	safeTx, err := wallet.GlobalWallet.SafeTx(p.safeHash)
+	if err == nil {
+		p.safeTx = &safeTx
+		p.safeSummary = wallet.GlobalWallet.FormatSafeTx(safeTx)
+	} else if p.Message.SafeTx != nil {
+		p.safeSummary = wallet.GlobalWallet.FormatSafeTx(*p.Message.SafeTx)
+	}
+}
+
+// resetSafeTx clears the safe transaction loaded for the previous message of the item
+func (p *PageItem) resetSafeTx() {
+	p.safeTx, p.safeHash, p.safeSummary, p.safeLoaded = nil, "", "", false
+	p.safeStatus, p.safeErr, p.safeCheckedAt = nil, nil, time.Time{}
+}
+
+// drawSafeTx draws the proposed safe transaction of the message with the owners which signed it,
+// an owner which didn't sign approves it and any owner executes it once the threshold is met
+func (p *PageItem) drawSafeTx(gtx Gtx, isMe bool) Dim {
+	if !p.safeLoaded {
+		p.loadSafeTx()
+	}
+	safeTx := *p.Message.SafeTx
+	if p.safeTx != nil {
+		safeTx = *p.safeTx
+	}
+	status := p.safeStatus
+	if status == nil || !status.NonceUsed {
+		if !p.checkingSafe && time.Since(p.safeCheckedAt) >= safeCheckInterval {
+			p.checkSafeTx(safeTx)
+		}
+		op.InvalidateOp{At: gtx.Now.Add(safeCheckInterval)}.Add(gtx.Ops)
+	}
+	account, _ := wallet.GlobalWallet.Account()
+	owner := common.HexToAddress(account.EthAddress)
+	_, signed := safeTx.Signatures[owner.Hex()]
+	canApprove := !signed && status != nil && !status.NonceUsed && status.Safe.IsOwner(owner)
+	canExecute := status != nil && status.CanExecute()
+	if p.btnApproveSafe.Clicked() && canApprove && p.onApproveSafe != nil {
+		p.onApproveSafe(safeTx)
+	}
+	if p.btnExecuteSafe.Clicked() && canExecute && p.onExecuteSafe != nil {
+		p.onExecuteSafe(safeTx)
+	}
+	summary := "Safe transfer proposed"
+	if isMe {
+		summary = "Safe transfer you proposed"
+	}
+	chainName := safeTx.ChainID
+	chain, chainOk := evm.ChainByID(safeTx.ChainID)
+	if chainOk {
+		chainName = chain.Name
+	}
+	statusText, statusColor := fmt.Sprintf("%d signatures, checking the safe", len(safeTx.Signatures)), color.NRGBA(colornames.Orange500)
+	switch {
+	case p.safeErr != nil:
+		statusText = fmt.Sprintf("%d signatures, %s", len(safeTx.Signatures), p.safeErr)
+	case status == nil:
+	case status.NonceUsed && safeTx.TxHash != "":
+		statusText, statusColor = "Executed", color.NRGBA(colornames.Green500)
+	case status.NonceUsed:
+		statusText, statusColor = fmt.Sprintf("Nonce %s is used, executed or replaced", safeTx.Nonce), p.Theme.ContrastBg
+	case status.CanExecute():
+		statusText = fmt.Sprintf("%d of %d owners signed, ready to execute", len(status.Approvals), status.Safe.Threshold)
+		statusColor = color.NRGBA(colornames.Green500)
+	default:
+		statusText = fmt.Sprintf("%d of %d owners signed", len(status.Approvals), status.Safe.Threshold)
+	}
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx Gtx) Dim {
+			lbl := material.Body1(p.Theme, summary)
+			lbl.Font.Weight = text.Bold
+			return lbl.Layout(gtx)
+		}),
+		layout.Rigid(material.Body2(p.Theme, p.safeSummary).Layout),
+		layout.Rigid(material.Caption(p.Theme, fmt.Sprintf("from Safe %s on %s", safeTx.Safe, chainName)).Layout),
+		layout.Rigid(material.Caption(p.Theme, "Nonce "+safeTx.Nonce).Layout),
+	}
+	if safeTx.Memo != "" {
+		children = append(children, layout.Rigid(material.Body2(p.Theme, safeTx.Memo).Layout))
+	}
+	children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+		lbl := material.Caption(p.Theme, statusText)
+		lbl.Color = statusColor
+		return lbl.Layout(gtx)
+	}))
+	if safeTx.TxHash != "" {
+		link := safeTx.TxHash
+		if chainOk && chain.ExplorerTxURL(safeTx.TxHash) != "" {
+			link = chain.ExplorerTxURL(safeTx.TxHash)
+		}
+		children = append(children, layout.Rigid(material.Caption(p.Theme, link).Layout))
+	}
+	if canApprove || canExecute {
+		children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+			return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+				flex := layout.Flex{Alignment: layout.Middle}
+				return flex.Layout(gtx,
+					layout.Rigid(func(gtx Gtx) Dim {
+						if !canApprove {
+							return Dim{}
+						}
+						return material.Button(p.Theme, &p.btnApproveSafe, "Sign").Layout(gtx)
+					}),
+					layout.Rigid(func(gtx Gtx) Dim {
+						if !canExecute {
+							return Dim{}
+						}
+						inset := layout.Inset{Left: unit.Dp(8)}
+						return inset.Layout(gtx, material.Button(p.Theme, &p.btnExecuteSafe, "Execute").Layout)
+					}),
+				)
+			})
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+func (p *PageItem) checkSafeTx(safeTx model.SafeTx) {
+	p.checkingSafe = true
+	go func() {
+		status, err := wallet.GlobalWallet.SafeTxStatus(safeTx)
+		p.safeErr = err
+		if err == nil {
+			p.safeStatus = &status
+		}
+		p.safeCheckedAt = time.Now()
+		p.checkingSafe = false
+	}()
+}
+
+// drawSafeApproval draws the signature of a safe transaction sent by an owner
+func (p *PageItem) drawSafeApproval(gtx Gtx) Dim {
+	if !p.safeLoaded {
+		p.loadSafeTx()
+	}
+	approval := *p.Message.SafeApproval
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx Gtx) Dim {
+			lbl := material.Body1(p.Theme, "Signed a Safe transfer")
+			lbl.Font.Weight = text.Bold
+			return lbl.Layout(gtx)
+		}),
+	}
+	if p.safeSummary != "" {
+		children = append(children, layout.Rigid(material.Body2(p.Theme, p.safeSummary).Layout))
+	}
+	children = append(children, layout.Rigid(material.Caption(p.Theme, "by "+approval.Owner).Layout))
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
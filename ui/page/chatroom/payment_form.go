@@ -0,0 +1,146 @@
+package chatroom
+
+import (
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/alog"
+	chat2 "github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"time"
+)
+
+// currencyForm lets the user choose a currency among the native currencies and the tokens of the
+// connected chains, onSelect is called with the chosen currency
+type currencyForm struct {
+	Manager
+	Theme    *material.Theme
+	title    string
+	options  []*currencyOption
+	onSelect func(option *currencyOption)
+	*view.ModalContent
+}
+
+// currencyOption is a currency of a connected chain, token is nil for the native currency
+type currencyOption struct {
+	conn  *evm.RPCClients
+	token *evm.Token
+	btn   widget.Clickable
+}
+
+func newCurrencyForm(manager Manager, theme *material.Theme, title string, onSelect func(option *currencyOption)) *currencyForm {
+	f := &currencyForm{
+		Manager:  manager,
+		Theme:    theme,
+		title:    title,
+		options:  make([]*currencyOption, 0),
+		onSelect: onSelect,
+	}
+	for _, conn := range wallet.GlobalWallet.Connections() {
+		if !conn.IsConnected() {
+			continue
+		}
+		f.options = append(f.options, &currencyOption{conn: conn})
+		tokens, err := wallet.GlobalWallet.ChainTokens(&conn.Chain.ChainID)
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+		for i := range tokens {
+			f.options = append(f.options, &currencyOption{conn: conn, token: &tokens[i]})
+		}
+	}
+	f.ModalContent = view.NewModalContent(func() { f.Modal().Dismiss(nil) })
+	return f
+}
+
+func (f *currencyForm) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return f.ModalContent.DrawContent(gtx, f.Theme, f.drawOptions)
+}
+
+func (f *currencyForm) drawOptions(gtx Gtx) Dim {
+	for _, option := range f.options {
+		if option.btn.Clicked() {
+			f.onSelect(option)
+		}
+	}
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		children := []layout.FlexChild{
+			layout.Rigid(material.H6(f.Theme, f.title).Layout),
+		}
+		if len(f.options) == 0 {
+			children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+				txt := "Connect to a chain in the wallet first"
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, material.Body1(f.Theme, txt).Layout)
+			}))
+		}
+		for _, option := range f.options {
+			option := option
+			children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+				txt := fmt.Sprintf("%s on %s", option.symbol(), option.conn.Chain.Name)
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return material.Button(f.Theme, &option.btn, txt).Layout(gtx)
+				})
+			}))
+		}
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+}
+
+func (o *currencyOption) symbol() string {
+	if o.token != nil {
+		return o.token.Symbol
+	}
+	return o.conn.Chain.NativeCurrency.Symbol
+}
+
+// newPaymentForm returns the form choosing the currency of a payment to contact
+func newPaymentForm(manager Manager, theme *material.Theme, contact chat2.Contact) *currencyForm {
+	title := fmt.Sprintf("Pay %s", contact.EthAddress)
+	return newCurrencyForm(manager, theme, title, func(option *currencyOption) {
+		manager.Modal().Dismiss(func() {
+			showSendForm(manager, theme, contact, option.conn, option.token, contact.EthAddress, "", "")
+		})
+	})
+}
+
+// showSendForm shows the form sending a payment to the address to of contact, the payment
+// message is sent to the contact once the transaction is sent. A payment of a request has its
+// amount fixed and is linked to the message of the request by requestID.
+func showSendForm(manager Manager, theme *material.Theme, contact chat2.Contact, conn *evm.RPCClients, token *evm.Token, to, amount, requestID string) {
+	sendForm := view.NewSendForm(manager, theme, conn, token)
+	sendForm.SetRecipient(to)
+	if amount != "" {
+		sendForm.SetAmount(amount)
+	}
+	sendForm.OnSent = func(prepared *evm.PreparedTx, txHash common.Hash) {
+		payment, err := wallet.GlobalWallet.NewPayment(conn, token, prepared, txHash)
+		if err != nil {
+			alog.Logger().Errorln(err)
+			return
+		}
+		payment.RequestID = requestID
+		msg := chat2.Message{
+			Recipient: contact.PublicKey,
+			CreatedAt: time.Now().UTC(),
+			Payment:   &payment,
+		}
+		acc, _ := wallet.GlobalWallet.Account()
+		chat2.GlobalChat.SendNewMessage(&acc, &msg)
+	}
+	manager.Modal().Show(sendForm.Layout, nil, Animation{
+		Duration: time.Millisecond * 250,
+		State:    component.Invisible,
+		Started:  time.Time{},
+	})
+}
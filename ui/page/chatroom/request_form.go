@@ -0,0 +1,170 @@
+package chatroom
+
+import (
+	"errors"
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	chat2 "github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRequestExpiry is the expiry in hours filled in a new payment request
+const defaultRequestExpiry = "24"
+
+var errInvalidExpiry = errors.New("expiry must be a number of hours, 0 never expires")
+
+// requestForm sends a signed request of a payment in the chosen currency to contact
+type requestForm struct {
+	Manager
+	Theme       *material.Theme
+	contact     chat2.Contact
+	conn        *evm.RPCClients
+	token       *evm.Token
+	symbol      string
+	inputAmount component.TextField
+	inputMemo   component.TextField
+	inputExpiry component.TextField
+	btnSend     view.IconButton
+	sending     bool
+	err         error
+	*view.ModalContent
+}
+
+// newRequestCurrencyForm returns the form choosing the currency of a payment requested from
+// contact, the request form is shown once the currency is chosen
+func newRequestCurrencyForm(manager Manager, theme *material.Theme, contact chat2.Contact) *currencyForm {
+	return newCurrencyForm(manager, theme, "Request a Payment", func(option *currencyOption) {
+		manager.Modal().Dismiss(func() {
+			form := newRequestForm(manager, theme, contact, option.conn, option.token, option.symbol())
+			manager.Modal().Show(form.Layout, nil, Animation{
+				Duration: time.Millisecond * 250,
+				State:    component.Invisible,
+				Started:  time.Time{},
+			})
+		})
+	})
+}
+
+func newRequestForm(manager Manager, theme *material.Theme, contact chat2.Contact, conn *evm.RPCClients, token *evm.Token, symbol string) *requestForm {
+	iconSend, _ := widget.NewIcon(icons.ContentSend)
+	f := &requestForm{
+		Manager:     manager,
+		Theme:       theme,
+		contact:     contact,
+		conn:        conn,
+		token:       token,
+		symbol:      symbol,
+		inputAmount: component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputMemo:   component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputExpiry: component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		btnSend: view.IconButton{
+			Theme: theme,
+			Icon:  iconSend,
+			Text:  "Send Request",
+		},
+	}
+	f.inputExpiry.SetText(defaultRequestExpiry)
+	f.ModalContent = view.NewModalContent(func() { f.Modal().Dismiss(nil) })
+	return f
+}
+
+func (f *requestForm) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return f.ModalContent.DrawContent(gtx, f.Theme, f.drawForm)
+}
+
+func (f *requestForm) drawForm(gtx Gtx) Dim {
+	if f.btnSend.Button.Clicked() && !f.sending {
+		f.send()
+	}
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				txt := fmt.Sprintf("Request %s on %s", f.symbol, f.conn.Chain.Name)
+				return material.H6(f.Theme, txt).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					return f.inputAmount.Layout(gtx, f.Theme, fmt.Sprintf("Amount (%s)", f.symbol))
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					return f.inputMemo.Layout(gtx, f.Theme, "Memo")
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					return f.inputExpiry.Layout(gtx, f.Theme, "Expires In (Hours)")
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if f.err == nil {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					lbl := material.Body2(f.Theme, f.err.Error())
+					lbl.Color = color.NRGBA(colornames.Red500)
+					return lbl.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					if f.sending {
+						loader := view.Loader{Theme: f.Theme}
+						return loader.Layout(gtx)
+					}
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return f.btnSend.Layout(gtx)
+				})
+			}),
+		)
+	})
+}
+
+// send signs the request and sends it to the contact
+func (f *requestForm) send() {
+	f.err = nil
+	hours, err := strconv.ParseFloat(strings.TrimSpace(f.inputExpiry.Text()), 64)
+	if err != nil || hours < 0 {
+		f.err = errInvalidExpiry
+		return
+	}
+	f.sending = true
+	amount := strings.TrimSpace(f.inputAmount.Text())
+	memo := f.inputMemo.Text()
+	expiry := time.Duration(hours * float64(time.Hour))
+	go func() {
+		defer f.Window().Invalidate()
+		request, err := wallet.GlobalWallet.NewPaymentRequest(f.conn, f.token, amount, memo, expiry)
+		f.sending = false
+		if err != nil {
+			f.err = err
+			return
+		}
+		msg := chat2.Message{
+			Recipient:      f.contact.PublicKey,
+			CreatedAt:      time.Now().UTC(),
+			PaymentRequest: &request,
+		}
+		acc, _ := wallet.GlobalWallet.Account()
+		chat2.GlobalChat.SendNewMessage(&acc, &msg)
+		f.Modal().Dismiss(nil)
+	}()
+}
@@ -0,0 +1,241 @@
+package chatroom
+
+import (
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	chat2 "github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image/color"
+	"strings"
+	"time"
+)
+
+// safeForm proposes a transfer from a Safe to the contact, who is another owner of the safe,
+// the proposal is signed by the current account
+type safeForm struct {
+	Manager
+	Theme       *material.Theme
+	contact     chat2.Contact
+	conn        *evm.RPCClients
+	token       *evm.Token
+	symbol      string
+	inputSafe   component.TextField
+	inputTo     component.TextField
+	inputAmount component.TextField
+	inputMemo   component.TextField
+	btnPropose  view.IconButton
+	proposing   bool
+	err         error
+	*view.ModalContent
+}
+
+// newSafeCurrencyForm returns the form choosing the currency of a transfer from a Safe proposed
+// to contact, the safe form is shown once the currency is chosen
+func newSafeCurrencyForm(manager Manager, theme *material.Theme, contact chat2.Contact) *currencyForm {
+	return newCurrencyForm(manager, theme, "Propose a Safe Transfer", func(option *currencyOption) {
+		manager.Modal().Dismiss(func() {
+			form := newSafeForm(manager, theme, contact, option.conn, option.token, option.symbol())
+			manager.Modal().Show(form.Layout, nil, Animation{
+				Duration: time.Millisecond * 250,
+				State:    component.Invisible,
+				Started:  time.Time{},
+			})
+		})
+	})
+}
+
+func newSafeForm(manager Manager, theme *material.Theme, contact chat2.Contact, conn *evm.RPCClients, token *evm.Token, symbol string) *safeForm {
+	iconPropose, _ := widget.NewIcon(icons.ContentSend)
+	f := &safeForm{
+		Manager:     manager,
+		Theme:       theme,
+		contact:     contact,
+		conn:        conn,
+		token:       token,
+		symbol:      symbol,
+		inputSafe:   component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputTo:     component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputAmount: component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputMemo:   component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		btnPropose: view.IconButton{
+			Theme: theme,
+			Icon:  iconPropose,
+			Text:  "Sign and Propose",
+		},
+	}
+	f.ModalContent = view.NewModalContent(func() { f.Modal().Dismiss(nil) })
+	return f
+}
+
+func (f *safeForm) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return f.ModalContent.DrawContent(gtx, f.Theme, f.drawForm)
+}
+
+func (f *safeForm) drawForm(gtx Gtx) Dim {
+	if f.btnPropose.Button.Clicked() && !f.proposing {
+		f.propose()
+	}
+	fields := []struct {
+		field *component.TextField
+		hint  string
+	}{
+		{&f.inputSafe, "Safe Address"},
+		{&f.inputTo, "Recipient Address"},
+		{&f.inputAmount, fmt.Sprintf("Amount (%s)", f.symbol)},
+		{&f.inputMemo, "Memo"},
+	}
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		children := []layout.FlexChild{
+			layout.Rigid(func(gtx Gtx) Dim {
+				txt := fmt.Sprintf("Propose %s on %s", f.symbol, f.conn.Chain.Name)
+				return material.H6(f.Theme, txt).Layout(gtx)
+			}),
+		}
+		for _, each := range fields {
+			each := each
+			children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					return each.field.Layout(gtx, f.Theme, each.hint)
+				})
+			}))
+		}
+		children = append(children,
+			layout.Rigid(func(gtx Gtx) Dim {
+				if f.err == nil {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					lbl := material.Body2(f.Theme, f.err.Error())
+					lbl.Color = color.NRGBA(colornames.Red500)
+					return lbl.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					if f.proposing {
+						loader := view.Loader{Theme: f.Theme}
+						return loader.Layout(gtx)
+					}
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return f.btnPropose.Layout(gtx)
+				})
+			}),
+		)
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx, children...)
+	})
+}
+
+// propose signs the transfer as an owner of the safe and sends it to the contact
+func (f *safeForm) propose() {
+	f.err = nil
+	f.proposing = true
+	safe := strings.TrimSpace(f.inputSafe.Text())
+	to := strings.TrimSpace(f.inputTo.Text())
+	amount := strings.TrimSpace(f.inputAmount.Text())
+	memo := f.inputMemo.Text()
+	go func() {
+		defer f.Window().Invalidate()
+		safeTx, err := wallet.GlobalWallet.ProposeSafeTransfer(f.conn, safe, f.token, to, amount, memo)
+		f.proposing = false
+		if err != nil {
+			f.err = err
+			return
+		}
+		msg := chat2.Message{
+			Recipient: f.contact.PublicKey,
+			CreatedAt: time.Now().UTC(),
+			SafeTx:    &safeTx,
+		}
+		acc, _ := wallet.GlobalWallet.Account()
+		chat2.GlobalChat.SendNewMessage(&acc, &msg)
+		f.Modal().Dismiss(nil)
+	}()
+}
+
+// safeExecForm confirms the execution of a safe transaction signed by enough owners, the current
+// account pays the fee of the execution
+type safeExecForm struct {
+	Manager
+	Theme    *material.Theme
+	conn     *evm.RPCClients
+	safeTx   model.SafeTx
+	prepared *evm.PreparedTx
+	btnYes   widget.Clickable
+	btnNo    widget.Clickable
+	sending  bool
+	err      error
+	*view.ModalContent
+}
+
+func newSafeExecForm(manager Manager, theme *material.Theme, conn *evm.RPCClients, safeTx model.SafeTx, prepared *evm.PreparedTx) *safeExecForm {
+	f := &safeExecForm{
+		Manager:  manager,
+		Theme:    theme,
+		conn:     conn,
+		safeTx:   safeTx,
+		prepared: prepared,
+	}
+	f.ModalContent = view.NewModalContent(func() { f.Modal().Dismiss(nil) })
+	return f
+}
+
+func (f *safeExecForm) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return f.ModalContent.DrawContent(gtx, f.Theme, f.drawConfirmation)
+}
+
+func (f *safeExecForm) drawConfirmation(gtx Gtx) Dim {
+	if f.btnYes.Clicked() && !f.sending {
+		f.send()
+	}
+	if f.btnNo.Clicked() && !f.sending {
+		f.Modal().Dismiss(nil)
+	}
+	if f.sending {
+		loader := view.Loader{Theme: f.Theme}
+		return layout.UniformInset(unit.Dp(16)).Layout(gtx, loader.Layout)
+	}
+	currency := f.conn.Chain.NativeCurrency
+	content := fmt.Sprintf("Execute %s from Safe %s?\nMax network fee: %s %s",
+		wallet.GlobalWallet.FormatSafeTx(f.safeTx), f.safeTx.Safe,
+		evm.FormatUnits(f.prepared.MaxFee(), currency.Decimals), currency.Symbol,
+	)
+	if f.err != nil {
+		content = fmt.Sprintf("%s\n%s", content, f.err)
+	}
+	promptContent := view.NewPromptContent(f.Theme, "Execute Safe Transaction", content, &f.btnYes, &f.btnNo)
+	return promptContent.Layout(gtx)
+}
+
+func (f *safeExecForm) send() {
+	f.sending = true
+	f.err = nil
+	go func() {
+		defer f.Window().Invalidate()
+		txHash, err := wallet.GlobalWallet.ExecuteSafeTx(f.conn, f.safeTx, f.prepared)
+		f.sending = false
+		if err != nil {
+			f.err = err
+			return
+		}
+		f.Modal().Dismiss(func() {
+			txt := fmt.Sprintf("Transaction %s sent", txHash.Hex())
+			f.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+		})
+	}()
+}
@@ -0,0 +1,187 @@
+package chatroom
+
+import (
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	chat2 "github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"image/color"
+	"strings"
+)
+
+// safetyNumberView shows the safety number of the conversation with contact as digits and as a
+// QR code, the contact is verified by comparing the digits or pasting the code scanned from the
+// device of the contact
+type safetyNumberView struct {
+	Manager
+	Theme        *material.Theme
+	contact      chat2.Contact
+	safetyNumber string
+	qrCode       view.QRCode
+	inputCode    component.TextField
+	btnCompare   widget.Clickable
+	btnVerify    widget.Clickable
+	btnUnverify  widget.Clickable
+	saving       bool
+	err          error
+	// onChange is called with the contact after its verification changes
+	onChange func(contact chat2.Contact)
+	*view.ModalContent
+}
+
+func newSafetyNumberView(manager Manager, theme *material.Theme, contact chat2.Contact, onChange func(contact chat2.Contact)) *safetyNumberView {
+	v := &safetyNumberView{
+		Manager:   manager,
+		Theme:     theme,
+		contact:   contact,
+		inputCode: component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		onChange:  onChange,
+	}
+	v.safetyNumber, v.err = wallet.GlobalWallet.ContactSafetyNumber(contact)
+	v.qrCode = view.QRCode{Text: common.SafetyNumberQRCode(v.safetyNumber), Size: unit.Dp(200)}
+	v.ModalContent = view.NewModalContent(func() { v.Modal().Dismiss(nil) })
+	return v
+}
+
+func (v *safetyNumberView) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return v.ModalContent.DrawContent(gtx, v.Theme, v.drawView)
+}
+
+func (v *safetyNumberView) drawView(gtx Gtx) Dim {
+	if v.btnCompare.Clicked() && !v.saving {
+		code := strings.TrimSpace(v.inputCode.Text())
+		v.save(func(contact *chat2.Contact) error {
+			return wallet.GlobalWallet.VerifyContact(contact, code)
+		})
+	}
+	if v.btnVerify.Clicked() && !v.saving {
+		v.save(wallet.GlobalWallet.MarkContactVerified)
+	}
+	if v.btnUnverify.Clicked() && !v.saving {
+		v.save(wallet.GlobalWallet.UnverifyContact)
+	}
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx,
+			layout.Rigid(material.H6(v.Theme, "Safety Number").Layout),
+			layout.Rigid(func(gtx Gtx) Dim {
+				txt := "Compare this number with the one shown on the device of your contact, in person " +
+					"or over a call you trust. If both match, nobody is in the middle of your conversation."
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Body2(v.Theme, txt).Layout)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if !v.contact.KeyChanged() {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					lbl := material.Body1(v.Theme, keyChangedWarning(v.contact))
+					lbl.Color = color.NRGBA(colornames.Red500)
+					return lbl.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					lbl := material.H6(v.Theme, common.FormatSafetyNumber(v.safetyNumber))
+					lbl.Alignment = text.Middle
+					return layout.Center.Layout(gtx, lbl.Layout)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if v.safetyNumber == "" {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					return layout.Center.Layout(gtx, v.qrCode.Layout)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				txt := "Not verified"
+				if v.contact.IsVerified() {
+					txt = fmt.Sprintf("Verified on %s", v.contact.VerifiedAt.Local().Format("Jan 2 2006 15:04"))
+				}
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					lbl := material.Subtitle1(v.Theme, txt)
+					if v.contact.IsVerified() {
+						lbl.Color = color.NRGBA(colornames.Green700)
+					}
+					return lbl.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					return v.inputCode.Layout(gtx, v.Theme, "Scanned Code Or Number Of Contact")
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if v.err == nil {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					lbl := material.Body2(v.Theme, v.err.Error())
+					lbl.Color = color.NRGBA(colornames.Red500)
+					return lbl.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					if v.saving {
+						loader := view.Loader{Theme: v.Theme}
+						return loader.Layout(gtx)
+					}
+					flex := layout.Flex{Spacing: layout.SpaceSides, Alignment: layout.Middle}
+					if v.contact.IsVerified() {
+						return flex.Layout(gtx, layout.Rigid(func(gtx Gtx) Dim {
+							btn := material.Button(v.Theme, &v.btnUnverify, "Clear Verification")
+							btn.Background = color.NRGBA(colornames.Red500)
+							return btn.Layout(gtx)
+						}))
+					}
+					return flex.Layout(gtx,
+						layout.Rigid(material.Button(v.Theme, &v.btnCompare, "Compare Code").Layout),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+						layout.Rigid(material.Button(v.Theme, &v.btnVerify, "Numbers Match").Layout),
+					)
+				})
+			}),
+		)
+	})
+}
+
+// save applies update to the contact and reports the saved contact to onChange
+func (v *safetyNumberView) save(update func(contact *chat2.Contact) error) {
+	v.saving = true
+	v.err = nil
+	go func() {
+		defer v.Window().Invalidate()
+		contact := v.contact
+		err := update(&contact)
+		v.saving = false
+		if err != nil {
+			v.err = err
+			return
+		}
+		v.contact = contact
+		v.inputCode.Clear()
+		if v.onChange != nil {
+			v.onChange(contact)
+		}
+	}()
+}
+
+// keyChangedWarning is shown while the new key of a known address isn't verified
+func keyChangedWarning(contact chat2.Contact) string {
+	return fmt.Sprintf("The key of %s changed. Anyone could be using the new key until you verify "+
+		"the safety number with your contact.", contact.EthAddress)
+}
@@ -1,6 +1,7 @@
 package contacts
 
 import (
+	"bytes"
 	"fmt"
 	"gioui.org/io/pointer"
 	"gioui.org/layout"
@@ -19,6 +20,7 @@ import (
 	"github.com/mearaj/protonet/ui/view"
 	"golang.org/x/exp/shiny/materialdesign/colornames"
 	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image"
 	"image/color"
 	"time"
 )
@@ -486,10 +488,12 @@ func (p *page) fetchContacts(offset, limit int) {
 		}
 		pageItems := make([]*pageItem, len(contacts))
 		for i, eachContact := range contacts {
+			img, _, _ := image.Decode(bytes.NewReader(eachContact.Avatar))
 			pageItems[i] = &pageItem{
-				Theme:   p.Theme,
-				Manager: p.Manager,
-				Contact: eachContact,
+				Theme:      p.Theme,
+				Manager:    p.Manager,
+				Contact:    eachContact,
+				AvatarView: view.AvatarView{Theme: p.Theme, Image: img},
 			}
 		}
 		p.contactItems = pageItems
@@ -26,6 +26,7 @@ type pageItem struct {
 	widget.Clickable
 	buttonIconMore    widget.Clickable
 	btnChat           widget.Clickable
+	btnBlock          widget.Clickable
 	btnMenuContent    widget.Clickable
 	buttonIconMoreDim Dim
 	Manager
@@ -64,6 +65,18 @@ func (i *pageItem) layoutContent(gtx Gtx) Dim {
 		})
 	}
 
+	if i.btnBlock.Clicked() {
+		i.menuVisibilityAnim.Disappear(gtx.Now)
+		publicKey := i.Contact.PublicKey
+		go func() {
+			if err := chat.GlobalChat.BlockContact(publicKey); err != nil {
+				i.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+				return
+			}
+			i.Snackbar().Show("Blocked, unblock it in Message Requests", nil, color.NRGBA{}, "")
+		}()
+	}
+
 	if i.Clickable.Clicked() {
 		if !i.menuVisibilityAnim.Visible() {
 			if i.SelectionMode {
@@ -109,7 +122,22 @@ func (i *pageItem) layoutContent(gtx Gtx) Dim {
 					d := inset.Layout(gtx, func(gtx Gtx) Dim {
 						d := flex.Layout(gtx,
 							layout.Rigid(func(gtx Gtx) Dim {
-								b := material.Body1(i.Theme, i.Contact.PublicKey)
+								b := material.Body1(i.Theme, i.Contact.DisplayName())
+								b.Font.Weight = text.Bold
+								return b.Layout(gtx)
+							}),
+							layout.Rigid(func(gtx Gtx) Dim {
+								var b material.LabelStyle
+								switch {
+								case i.Contact.KeyChanged():
+									b = material.Caption(i.Theme, "Key changed, verify the safety number")
+									b.Color = color.NRGBA(colornames.Red500)
+								case i.Contact.IsVerified():
+									b = material.Caption(i.Theme, "Verified")
+									b.Color = color.NRGBA(colornames.Green700)
+								default:
+									return Dim{}
+								}
 								b.Font.Weight = text.Bold
 								return b.Layout(gtx)
 							}),
@@ -207,5 +235,24 @@ func (i *pageItem) drawMenuItems(gtx Gtx) Dim {
 				},
 			)
 		}),
+		layout.Rigid(func(gtx Gtx) Dim {
+			btnStyle := material.ButtonLayoutStyle{Button: &i.btnBlock}
+			btnStyle.Background = color.NRGBA(colornames.White)
+			return btnStyle.Layout(gtx,
+				func(gtx Gtx) Dim {
+					inset := inset
+					return inset.Layout(gtx, func(gtx Gtx) Dim {
+						return layout.Flex{Spacing: layout.SpaceEnd}.Layout(gtx,
+							layout.Flexed(1, func(gtx Gtx) Dim {
+								bd := material.Body1(i.Theme, "Block")
+								bd.Color = color.NRGBA(colornames.Red500)
+								bd.Alignment = text.Start
+								return bd.Layout(gtx)
+							}),
+						)
+					})
+				},
+			)
+		}),
 	)
 }
@@ -0,0 +1,125 @@
+package currency
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image/color"
+)
+
+type page struct {
+	Manager
+	Theme            *material.Theme
+	title            string
+	buttonNavigation widget.Clickable
+	navigationIcon   *widget.Icon
+	currencyEnum     widget.Enum
+	layout.List
+}
+
+func New(manager Manager) Page {
+	navIcon, _ := widget.NewIcon(icons.NavigationArrowBack)
+	p := &page{
+		Manager:        manager,
+		Theme:          manager.Theme(),
+		title:          "Currency",
+		navigationIcon: navIcon,
+		List:           layout.List{Axis: layout.Vertical},
+	}
+	p.currencyEnum.Value = wallet.GlobalWallet.DisplayCurrency()
+	return p
+}
+
+func (p *page) Layout(gtx Gtx) Dim {
+	if p.Theme == nil {
+		p.Theme = p.Manager.Theme()
+	}
+	if p.currencyEnum.Changed() {
+		p.onCurrencyChange()
+	}
+	flex := layout.Flex{Axis: layout.Vertical,
+		Spacing:   layout.SpaceEnd,
+		Alignment: layout.Start,
+	}
+	d := flex.Layout(gtx,
+		layout.Rigid(p.DrawAppBar),
+		layout.Flexed(1, p.drawContent),
+	)
+	return d
+}
+
+func (p *page) drawContent(gtx Gtx) Dim {
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		return p.List.Layout(gtx, len(wallet.DisplayCurrencies)+2, func(gtx Gtx, index int) Dim {
+			switch {
+			case index == 0:
+				return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					return material.Subtitle1(p.Theme, "Show the portfolio value in").Layout(gtx)
+				})
+			case index <= len(wallet.DisplayCurrencies):
+				currency := wallet.DisplayCurrencies[index-1]
+				return material.RadioButton(p.Theme, &p.currencyEnum, currency, currency).Layout(gtx)
+			default:
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					txt := "Prices are read from the Chainlink price feeds of the connected mainnets, " +
+						"connect Ethereum Mainnet or Polygon Mainnet to value the portfolio."
+					return material.Body2(p.Theme, txt).Layout(gtx)
+				})
+			}
+		})
+	})
+}
+
+func (p *page) onCurrencyChange() {
+	err := wallet.GlobalWallet.SetDisplayCurrency(p.currencyEnum.Value)
+	if err != nil {
+		alog.Logger().Errorln(err)
+		p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+	}
+}
+
+func (p *page) DrawAppBar(gtx Gtx) Dim {
+	gtx.Constraints.Max.Y = gtx.Dp(56)
+	th := p.Theme
+	if p.buttonNavigation.Clicked() {
+		p.PopUp()
+	}
+
+	return view.DrawAppBarLayout(gtx, th, func(gtx Gtx) Dim {
+		return layout.Flex{Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx Gtx) Dim {
+						navigationIcon := p.navigationIcon
+						button := material.IconButton(th, &p.buttonNavigation, navigationIcon, "Nav Icon Button")
+						button.Size = unit.Dp(40)
+						button.Background = th.Palette.ContrastBg
+						button.Color = th.Palette.ContrastFg
+						button.Inset = layout.UniformInset(unit.Dp(8))
+						return button.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx Gtx) Dim {
+						return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+							titleText := p.title
+							title := material.Body1(th, titleText)
+							title.Color = th.Palette.ContrastFg
+							title.TextSize = unit.Sp(18)
+							return title.Layout(gtx)
+						})
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (p *page) URL() URL {
+	return CurrencyPageURL
+}
@@ -0,0 +1,334 @@
+package dapps
+
+import (
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/wallet"
+	"github.com/mearaj/protonet/internal/walletconnect"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"image/color"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ShowProposal asks the user to approve or reject the session proposed by a dApp
+func ShowProposal(manager Manager, proposal walletconnect.Proposal) {
+	a := &proposalApproval{
+		Manager:  manager,
+		Theme:    manager.Theme(),
+		proposal: proposal,
+	}
+	a.ModalContent = view.NewModalContent(a.reject)
+	showModal(manager, a.Layout)
+}
+
+// ShowRequest decodes the call of a dApp and asks the user to approve it, the call is rejected
+// when it can't be decoded
+func ShowRequest(manager Manager, request walletconnect.Request) {
+	go func() {
+		call, err := wallet.GlobalWallet.PrepareDAppCall(request)
+		if err != nil {
+			alog.Logger().Errorln(err)
+			if err := wallet.GlobalWallet.RejectDAppCall(request, err); err != nil {
+				alog.Logger().Errorln(err)
+			}
+			manager.Snackbar().Show(fmt.Sprintf("%s: %s", peerName(request.Peer), err), nil, color.NRGBA{}, "")
+			manager.Window().Invalidate()
+			return
+		}
+		if call.Sign != nil {
+			a := view.NewSignApproval(manager, manager.Theme(), *call.Sign)
+			a.Origin = peerName(request.Peer)
+			a.OnSigned = func(signature []byte) {
+				if err := wallet.GlobalWallet.RespondDAppSignature(request, signature); err != nil {
+					alog.Logger().Errorln(err)
+					manager.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+				}
+			}
+			a.OnRejected = func() {
+				if err := wallet.GlobalWallet.RejectDAppCall(request, nil); err != nil {
+					alog.Logger().Errorln(err)
+				}
+			}
+			showModal(manager, a.Layout)
+		} else {
+			a := &txApproval{
+				Manager: manager,
+				Theme:   manager.Theme(),
+				call:    call,
+			}
+			a.ModalContent = view.NewModalContent(a.reject)
+			showModal(manager, a.Layout)
+		}
+		manager.Window().Invalidate()
+	}()
+}
+
+// showModal shows w on top of the modals already shown, a dApp may ask while the user is busy
+func showModal(manager Manager, w layout.Widget) {
+	manager.Modal().Show(w, nil, Animation{
+		Duration: time.Millisecond * 250,
+		State:    component.Invisible,
+		Started:  time.Time{},
+	})
+}
+
+// proposalApproval shows the dApp, chains and methods of a session proposal
+type proposalApproval struct {
+	Manager
+	Theme      *material.Theme
+	proposal   walletconnect.Proposal
+	btnApprove widget.Clickable
+	btnReject  widget.Clickable
+	approving  bool
+	err        error
+	*view.ModalContent
+}
+
+func (a *proposalApproval) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return a.ModalContent.DrawContent(gtx, a.Theme, a.drawApproval)
+}
+
+func (a *proposalApproval) drawApproval(gtx Gtx) Dim {
+	if a.btnApprove.Clicked() && !a.approving {
+		a.approve()
+	}
+	if a.btnReject.Clicked() && !a.approving {
+		a.reject()
+	}
+	th := a.Theme
+	metadata := a.proposal.Metadata()
+	account, _ := wallet.GlobalWallet.Account()
+	required, methods := namespacesSummary(a.proposal.RequiredNamespaces)
+	optional, _ := namespacesSummary(a.proposal.OptionalNamespaces)
+	lines := []string{
+		"Required chains: " + required,
+		"Optional chains: " + optional,
+		"Methods: " + methods,
+		"Account: " + account.EthAddress,
+	}
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		children := []layout.FlexChild{
+			layout.Rigid(material.H6(th, "Connect "+peerName(metadata)).Layout),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Caption(th, metadata.URL).Layout)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Body2(th, metadata.Description).Layout)
+			}),
+		}
+		for _, line := range lines {
+			line := line
+			children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Body2(th, line).Layout)
+			}))
+		}
+		children = append(children,
+			layout.Rigid(func(gtx Gtx) Dim {
+				return drawError(gtx, th, a.err)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return drawButtons(gtx, th, a.approving, &a.btnReject, &a.btnApprove, "Approve")
+			}),
+		)
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx, children...)
+	})
+}
+
+func (a *proposalApproval) approve() {
+	a.approving = true
+	a.err = nil
+	go func() {
+		defer a.Window().Invalidate()
+		err := wallet.GlobalWallet.ApproveDApp(a.proposal)
+		a.approving = false
+		if err != nil {
+			a.err = err
+			return
+		}
+		a.Modal().Dismiss(nil)
+	}()
+}
+
+func (a *proposalApproval) reject() {
+	a.Modal().Dismiss(func() {
+		go func() {
+			if err := wallet.GlobalWallet.RejectDApp(a.proposal); err != nil {
+				alog.Logger().Errorln(err)
+			}
+		}()
+	})
+}
+
+// txApproval shows the transaction a dApp asks to send and sends it once approved
+type txApproval struct {
+	Manager
+	Theme      *material.Theme
+	call       wallet.DAppCall
+	btnApprove widget.Clickable
+	btnReject  widget.Clickable
+	sending    bool
+	err        error
+	*view.ModalContent
+}
+
+func (a *txApproval) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return a.ModalContent.DrawContent(gtx, a.Theme, a.drawApproval)
+}
+
+func (a *txApproval) drawApproval(gtx Gtx) Dim {
+	if a.btnApprove.Clicked() && !a.sending {
+		a.send()
+	}
+	if a.btnReject.Clicked() && !a.sending {
+		a.reject()
+	}
+	th := a.Theme
+	tx := a.call.Tx
+	currency := a.call.Conn.Chain.NativeCurrency
+	lines := []string{
+		"Chain: " + a.call.Conn.Chain.Name,
+		"From: " + tx.From.Hex(),
+		"To: " + tx.To.Hex(),
+		fmt.Sprintf("Value: %s %s", evm.FormatUnits(tx.Value, currency.Decimals), currency.Symbol),
+		fmt.Sprintf("Data: %d bytes", len(tx.Data)),
+		fmt.Sprintf("Max Fee: %s %s", evm.FormatUnits(tx.MaxFee(), currency.Decimals), currency.Symbol),
+	}
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		children := []layout.FlexChild{
+			layout.Rigid(material.H6(th, "Transaction Request").Layout),
+			layout.Rigid(func(gtx Gtx) Dim {
+				txt := "From " + peerName(a.call.Peer)
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Body1(th, txt).Layout)
+			}),
+		}
+		for _, line := range lines {
+			line := line
+			children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Body2(th, line).Layout)
+			}))
+		}
+		children = append(children,
+			layout.Rigid(func(gtx Gtx) Dim {
+				return drawError(gtx, th, a.err)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return drawButtons(gtx, th, a.sending, &a.btnReject, &a.btnApprove, "Send")
+			}),
+		)
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx, children...)
+	})
+}
+
+func (a *txApproval) send() {
+	a.sending = true
+	a.err = nil
+	go func() {
+		defer a.Window().Invalidate()
+		err := wallet.GlobalWallet.SendDAppTransaction(a.call)
+		a.sending = false
+		if err != nil {
+			a.err = err
+			return
+		}
+		a.Modal().Dismiss(nil)
+	}()
+}
+
+func (a *txApproval) reject() {
+	a.Modal().Dismiss(func() {
+		go func() {
+			if err := wallet.GlobalWallet.RejectDAppCall(a.call.Request, nil); err != nil {
+				alog.Logger().Errorln(err)
+			}
+		}()
+	})
+}
+
+func drawError(gtx Gtx, th *material.Theme, err error) Dim {
+	if err == nil {
+		return Dim{}
+	}
+	return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+		lbl := material.Body2(th, err.Error())
+		lbl.Color = color.NRGBA(colornames.Red500)
+		return lbl.Layout(gtx)
+	})
+}
+
+func drawButtons(gtx Gtx, th *material.Theme, busy bool, btnReject, btnApprove *widget.Clickable, approveTxt string) Dim {
+	return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+		if busy {
+			loader := view.Loader{Theme: th}
+			return loader.Layout(gtx)
+		}
+		flex := layout.Flex{Spacing: layout.SpaceSides, Alignment: layout.Middle}
+		return flex.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				btn := material.Button(th, btnReject, "Reject")
+				btn.Background = color.NRGBA(colornames.Red500)
+				return btn.Layout(gtx)
+			}),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+			layout.Rigid(material.Button(th, btnApprove, approveTxt).Layout),
+		)
+	})
+}
+
+// namespacesSummary returns the names of the chains and the methods of namespaces
+func namespacesSummary(namespaces map[string]walletconnect.Namespace) (chains string, methods string) {
+	chainNames := make([]string, 0)
+	methodNames := make([]string, 0)
+	for _, namespace := range namespaces {
+		for _, chain := range namespace.Chains {
+			chainNames = append(chainNames, chainName(chain))
+		}
+		methodNames = append(methodNames, namespace.Methods...)
+	}
+	sort.Strings(chainNames)
+	sort.Strings(methodNames)
+	if len(chainNames) == 0 {
+		chainNames = append(chainNames, "None")
+	}
+	if len(methodNames) == 0 {
+		methodNames = append(methodNames, "None")
+	}
+	return strings.Join(chainNames, ", "), strings.Join(methodNames, ", ")
+}
+
+// chainName returns the name of a CAIP-2 chain such as eip155:1, or the chain itself if unknown
+func chainName(chain string) string {
+	chainID, found := strings.CutPrefix(chain, "eip155:")
+	if !found {
+		return chain
+	}
+	if c, ok := evm.ChainByID(chainID); ok {
+		return c.Name
+	}
+	return chain
+}
+
+// peerName returns the name of a dApp, or its url when it has no name
+func peerName(metadata walletconnect.Metadata) string {
+	if metadata.Name != "" {
+		return metadata.Name
+	}
+	return metadata.URL
+}
@@ -0,0 +1,268 @@
+package dapps
+
+import (
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"github.com/mearaj/protonet/internal/wallet"
+	"github.com/mearaj/protonet/internal/walletconnect"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image/color"
+	"strings"
+)
+
+// page pairs dApps by their WalletConnect uri, lists the open sessions and configures the relay
+type page struct {
+	Manager
+	Theme            *material.Theme
+	title            string
+	buttonNavigation widget.Clickable
+	navigationIcon   *widget.Icon
+	inputURI         component.TextField
+	inputRelayURL    component.TextField
+	inputProjectID   component.TextField
+	btnConnect       widget.Clickable
+	btnSaveRelay     widget.Clickable
+	pairing          bool
+	err              error
+	sessions         []*sessionItem
+	sessionsFetched  bool
+	layout.List
+}
+
+type sessionItem struct {
+	walletconnect.Session
+	btnDisconnect widget.Clickable
+}
+
+func New(manager Manager) Page {
+	navIcon, _ := widget.NewIcon(icons.NavigationArrowBack)
+	p := &page{
+		Manager:        manager,
+		Theme:          manager.Theme(),
+		title:          "dApps",
+		navigationIcon: navIcon,
+		inputURI:       component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputRelayURL:  component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputProjectID: component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		List:           layout.List{Axis: layout.Vertical},
+	}
+	if settings, err := wallet.GlobalWallet.Settings(); err == nil {
+		p.inputRelayURL.SetText(settings.WalletConnectRelayURL)
+		p.inputProjectID.SetText(settings.WalletConnectProjectID)
+	}
+	return p
+}
+
+func (p *page) Layout(gtx Gtx) Dim {
+	if p.Theme == nil {
+		p.Theme = p.Manager.Theme()
+	}
+	if !p.sessionsFetched {
+		p.sessionsFetched = true
+		p.fetchSessions()
+	}
+	if p.btnConnect.Clicked() && !p.pairing {
+		p.pair()
+	}
+	if p.btnSaveRelay.Clicked() {
+		p.saveRelay()
+	}
+	flex := layout.Flex{Axis: layout.Vertical,
+		Spacing:   layout.SpaceEnd,
+		Alignment: layout.Start,
+	}
+	d := flex.Layout(gtx,
+		layout.Rigid(p.DrawAppBar),
+		layout.Flexed(1, p.drawContent),
+	)
+	return d
+}
+
+func (p *page) drawContent(gtx Gtx) Dim {
+	th := p.Theme
+	sessions := p.sessions
+	children := []layout.Widget{
+		material.Subtitle1(th, "Connect a dApp").Layout,
+		func(gtx Gtx) Dim {
+			flex := layout.Flex{Alignment: layout.Middle}
+			return flex.Layout(gtx,
+				layout.Flexed(1, func(gtx Gtx) Dim {
+					return p.inputURI.Layout(gtx, th, "WalletConnect URI")
+				}),
+				layout.Rigid(func(gtx Gtx) Dim {
+					inset := layout.Inset{Left: unit.Dp(16)}
+					if p.pairing {
+						loader := view.Loader{Theme: th}
+						return inset.Layout(gtx, loader.Layout)
+					}
+					return inset.Layout(gtx, material.Button(th, &p.btnConnect, "Connect").Layout)
+				}),
+			)
+		},
+		func(gtx Gtx) Dim {
+			if p.err == nil {
+				return Dim{}
+			}
+			lbl := material.Body2(th, p.err.Error())
+			lbl.Color = color.NRGBA(colornames.Red500)
+			return lbl.Layout(gtx)
+		},
+		func(gtx Gtx) Dim {
+			return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, material.Subtitle1(th, "Sessions").Layout)
+		},
+	}
+	if len(sessions) == 0 {
+		children = append(children, material.Body2(th, "No dApp is connected").Layout)
+	}
+	for _, session := range sessions {
+		session := session
+		children = append(children, func(gtx Gtx) Dim {
+			return session.Layout(gtx, p)
+		})
+	}
+	children = append(children,
+		func(gtx Gtx) Dim {
+			return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, material.Subtitle1(th, "Relay").Layout)
+		},
+		func(gtx Gtx) Dim {
+			hint := fmt.Sprintf("Relay URL, empty for %s", walletconnect.DefaultRelayURL)
+			return p.inputRelayURL.Layout(gtx, th, hint)
+		},
+		func(gtx Gtx) Dim {
+			return p.inputProjectID.Layout(gtx, th, "Project ID")
+		},
+		func(gtx Gtx) Dim {
+			return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Button(th, &p.btnSaveRelay, "Save Relay").Layout)
+		},
+	)
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		return p.List.Layout(gtx, len(children), func(gtx Gtx, index int) Dim {
+			return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, children[index])
+		})
+	})
+}
+
+func (s *sessionItem) Layout(gtx Gtx, p *page) Dim {
+	th := p.Theme
+	if s.btnDisconnect.Clicked() {
+		p.disconnect(s.Topic)
+	}
+	chains := make([]string, 0)
+	for _, namespace := range s.Namespaces {
+		chains = append(chains, namespace.Chains...)
+	}
+	flex := layout.Flex{Alignment: layout.Middle}
+	return flex.Layout(gtx,
+		layout.Flexed(1, func(gtx Gtx) Dim {
+			flex := layout.Flex{Axis: layout.Vertical}
+			return flex.Layout(gtx,
+				layout.Rigid(material.Body1(th, s.Peer.Name).Layout),
+				layout.Rigid(material.Caption(th, s.Peer.URL).Layout),
+				layout.Rigid(material.Caption(th, strings.Join(chains, ", ")).Layout),
+			)
+		}),
+		layout.Rigid(func(gtx Gtx) Dim {
+			btn := material.Button(th, &s.btnDisconnect, "Disconnect")
+			btn.Background = color.NRGBA(colornames.Red500)
+			return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, btn.Layout)
+		}),
+	)
+}
+
+func (p *page) fetchSessions() {
+	sessions := wallet.GlobalWallet.DAppSessions()
+	items := make([]*sessionItem, len(sessions))
+	for i, session := range sessions {
+		items[i] = &sessionItem{Session: session}
+	}
+	p.sessions = items
+}
+
+func (p *page) pair() {
+	p.pairing = true
+	p.err = nil
+	uri := p.inputURI.Text()
+	go func() {
+		defer p.Window().Invalidate()
+		p.err = wallet.GlobalWallet.PairDApp(uri)
+		p.pairing = false
+		if p.err == nil {
+			p.inputURI.SetText("")
+			p.Snackbar().Show("Paired, waiting for the dApp to propose a session", nil, color.NRGBA{}, "")
+		}
+	}()
+}
+
+func (p *page) disconnect(topic string) {
+	go func() {
+		if err := wallet.GlobalWallet.DisconnectDApp(topic); err != nil {
+			p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+		}
+		p.Window().Invalidate()
+	}()
+}
+
+func (p *page) saveRelay() {
+	err := wallet.GlobalWallet.SetWalletConnectRelay(p.inputRelayURL.Text(), p.inputProjectID.Text())
+	txt := "Saved the relay"
+	if err != nil {
+		txt = err.Error()
+	}
+	p.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+}
+
+func (p *page) OnDatabaseChange(event pubsub.Event) {
+	switch event.Data.(type) {
+	case pubsub.DAppSessionsChangedEventData:
+		p.sessionsFetched = false
+		p.Window().Invalidate()
+	}
+}
+
+func (p *page) DrawAppBar(gtx Gtx) Dim {
+	gtx.Constraints.Max.Y = gtx.Dp(56)
+	th := p.Theme
+	if p.buttonNavigation.Clicked() {
+		p.PopUp()
+	}
+
+	return view.DrawAppBarLayout(gtx, th, func(gtx Gtx) Dim {
+		return layout.Flex{Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx Gtx) Dim {
+						navigationIcon := p.navigationIcon
+						button := material.IconButton(th, &p.buttonNavigation, navigationIcon, "Nav Icon Button")
+						button.Size = unit.Dp(40)
+						button.Background = th.Palette.ContrastBg
+						button.Color = th.Palette.ContrastFg
+						button.Inset = layout.UniformInset(unit.Dp(8))
+						return button.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx Gtx) Dim {
+						return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+							titleText := p.title
+							title := material.Body1(th, titleText)
+							title.Color = th.Palette.ContrastFg
+							title.TextSize = unit.Sp(18)
+							return title.Layout(gtx)
+						})
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (p *page) URL() URL {
+	return DAppsPageURL
+}
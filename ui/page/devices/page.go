@@ -0,0 +1,263 @@
+package devices
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image/color"
+)
+
+// page lists the devices of the current account, this device is linked to the account here and
+// the other devices are removed
+type page struct {
+	Manager
+	Theme            *material.Theme
+	title            string
+	buttonNavigation widget.Clickable
+	navigationIcon   *widget.Icon
+	account          model.Account
+	items            []*deviceItem
+	inputName        component.TextField
+	btnLink          widget.Clickable
+	fetched          bool
+	saving           bool
+	layout.List
+}
+
+type deviceItem struct {
+	model.Device
+	btnRemove widget.Clickable
+}
+
+func New(manager Manager) Page {
+	navIcon, _ := widget.NewIcon(icons.NavigationArrowBack)
+	return &page{
+		Manager:        manager,
+		Theme:          manager.Theme(),
+		title:          "Devices",
+		navigationIcon: navIcon,
+		inputName:      component.TextField{Editor: widget.Editor{SingleLine: true}, CharLimit: model.MaxProfileNameLength},
+		List:           layout.List{Axis: layout.Vertical},
+	}
+}
+
+func (p *page) Layout(gtx Gtx) Dim {
+	if p.Theme == nil {
+		p.Theme = p.Manager.Theme()
+	}
+	if !p.fetched {
+		p.fetched = true
+		p.fetch()
+	}
+	if p.btnLink.Clicked() && !p.saving {
+		p.linkDevice()
+	}
+	flex := layout.Flex{Axis: layout.Vertical,
+		Spacing:   layout.SpaceEnd,
+		Alignment: layout.Start,
+	}
+	d := flex.Layout(gtx,
+		layout.Rigid(p.DrawAppBar),
+		layout.Flexed(1, p.drawContent),
+	)
+	return d
+}
+
+func (p *page) drawContent(gtx Gtx) Dim {
+	th := p.Theme
+	children := []layout.Widget{
+		func(gtx Gtx) Dim {
+			txt := "The devices of an account share its key and chat as separate peers. Your contacts " +
+				"deliver your messages to all of them, and the messages you send or read on one device " +
+				"are synced to the others."
+			return material.Body2(th, txt).Layout(gtx)
+		},
+		func(gtx Gtx) Dim {
+			return material.Subtitle1(th, "This Device").Layout(gtx)
+		},
+	}
+	if p.account.IsLinkedDevice() {
+		children = append(children, func(gtx Gtx) Dim {
+			return material.Body1(th, "Linked device of this account").Layout(gtx)
+		})
+	} else {
+		children = append(children,
+			func(gtx Gtx) Dim {
+				txt := "Primary device of this account. To use this account on another device, import " +
+					"the key of the account there and link that device from its own Devices page. " +
+					"A linked device stays linked until it's removed."
+				return material.Body2(th, txt).Layout(gtx)
+			},
+			func(gtx Gtx) Dim {
+				return p.inputName.Layout(gtx, th, "Name Of This Device")
+			},
+			func(gtx Gtx) Dim {
+				if p.saving {
+					loader := view.Loader{Theme: th}
+					return loader.Layout(gtx)
+				}
+				return material.Button(th, &p.btnLink, "Link This Device").Layout(gtx)
+			},
+		)
+	}
+	children = append(children, func(gtx Gtx) Dim {
+		return material.Subtitle1(th, "Linked Devices").Layout(gtx)
+	})
+	if len(p.items) == 0 {
+		children = append(children, material.Body1(th, "No linked devices").Layout)
+	}
+	for _, item := range p.items {
+		item := item
+		children = append(children, func(gtx Gtx) Dim {
+			return item.Layout(gtx, p)
+		})
+	}
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		return p.List.Layout(gtx, len(children), func(gtx Gtx, index int) Dim {
+			return layout.Inset{Bottom: unit.Dp(16)}.Layout(gtx, children[index])
+		})
+	})
+}
+
+func (d *deviceItem) Layout(gtx Gtx, p *page) Dim {
+	th := p.Theme
+	devicePublicKey := d.DevicePublicKey
+	if d.btnRemove.Clicked() && !p.saving {
+		p.run(func() error {
+			return wallet.GlobalWallet.RevokeDevice(devicePublicKey)
+		})
+	}
+	isThisDevice := d.DevicePublicKey == p.account.DevicePublicKey
+	caption := "Linked on " + d.CreatedAt.Local().Format("Jan 2 2006")
+	if d.IsRevoked() {
+		caption = "Removed on " + d.RevokedAt.Local().Format("Jan 2 2006")
+	} else if isThisDevice {
+		caption += ", this device"
+	}
+	flex := layout.Flex{Alignment: layout.Middle}
+	return flex.Layout(gtx,
+		layout.Flexed(1, func(gtx Gtx) Dim {
+			flex := layout.Flex{Axis: layout.Vertical}
+			return flex.Layout(gtx,
+				layout.Rigid(material.Body1(th, d.Name).Layout),
+				layout.Rigid(func(gtx Gtx) Dim {
+					lbl := material.Caption(th, caption)
+					if d.IsRevoked() {
+						lbl.Color = color.NRGBA(colornames.Red500)
+					}
+					return lbl.Layout(gtx)
+				}),
+				layout.Rigid(material.Caption(th, d.DevicePublicKey).Layout),
+			)
+		}),
+		layout.Rigid(func(gtx Gtx) Dim {
+			if d.IsRevoked() || isThisDevice {
+				return Dim{}
+			}
+			return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+				btn := material.Button(th, &d.btnRemove, "Remove")
+				btn.Background = color.NRGBA(colornames.Red500)
+				return btn.Layout(gtx)
+			})
+		}),
+	)
+}
+
+func (p *page) linkDevice() {
+	name := p.inputName.Text()
+	p.run(func() error {
+		_, err := wallet.GlobalWallet.LinkDevice(name)
+		if err == nil {
+			p.inputName.Clear()
+		}
+		return err
+	})
+}
+
+// run runs action and reports its error
+func (p *page) run(action func() error) {
+	p.saving = true
+	go func() {
+		defer func() {
+			p.saving = false
+			p.Window().Invalidate()
+		}()
+		if err := action(); err != nil {
+			alog.Logger().Errorln(err)
+			p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+		}
+	}()
+}
+
+func (p *page) fetch() {
+	account, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return
+	}
+	p.account = account
+	devices, _ := wallet.GlobalWallet.Devices(account.PublicKey, account.PublicKey)
+	items := make([]*deviceItem, len(devices))
+	for i, device := range devices {
+		items[i] = &deviceItem{Device: device}
+	}
+	p.items = items
+}
+
+func (p *page) OnDatabaseChange(event pubsub.Event) {
+	switch event.Data.(type) {
+	case pubsub.DevicesChangedEventData, pubsub.AccountsChangedEventData,
+		pubsub.CurrentAccountChangedEventData:
+		p.fetched = false
+		p.Window().Invalidate()
+	}
+}
+
+func (p *page) DrawAppBar(gtx Gtx) Dim {
+	gtx.Constraints.Max.Y = gtx.Dp(56)
+	th := p.Theme
+	if p.buttonNavigation.Clicked() {
+		p.PopUp()
+	}
+
+	return view.DrawAppBarLayout(gtx, th, func(gtx Gtx) Dim {
+		return layout.Flex{Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx Gtx) Dim {
+						navigationIcon := p.navigationIcon
+						button := material.IconButton(th, &p.buttonNavigation, navigationIcon, "Nav Icon Button")
+						button.Size = unit.Dp(40)
+						button.Background = th.Palette.ContrastBg
+						button.Color = th.Palette.ContrastFg
+						button.Inset = layout.UniformInset(unit.Dp(8))
+						return button.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx Gtx) Dim {
+						return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+							titleText := p.title
+							title := material.Body1(th, titleText)
+							title.Color = th.Palette.ContrastFg
+							title.TextSize = unit.Sp(18)
+							return title.Layout(gtx)
+						})
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (p *page) URL() URL {
+	return DevicesPageURL
+}
@@ -0,0 +1,261 @@
+package profile
+
+import (
+	"bytes"
+	"errors"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"gioui.org/x/explorer"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/pubsub"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"io"
+)
+
+// maxAvatarFileSize bounds the image file read for an avatar, it's scaled down before it's saved
+const maxAvatarFileSize = 16 << 20
+
+// page edits the profile of the current account, the display name, the status and the avatar
+// shared with the contacts
+type page struct {
+	Manager
+	Theme            *material.Theme
+	title            string
+	buttonNavigation widget.Clickable
+	navigationIcon   *widget.Icon
+	inputName        component.TextField
+	inputStatus      component.TextField
+	avatarView       view.AvatarView
+	avatar           []byte
+	btnChooseAvatar  widget.Clickable
+	btnRemoveAvatar  widget.Clickable
+	btnSave          widget.Clickable
+	fetched          bool
+	saving           bool
+	layout.List
+}
+
+func New(manager Manager) Page {
+	navIcon, _ := widget.NewIcon(icons.NavigationArrowBack)
+	return &page{
+		Manager:        manager,
+		Theme:          manager.Theme(),
+		title:          "Profile",
+		navigationIcon: navIcon,
+		inputName:      component.TextField{Editor: widget.Editor{SingleLine: true}, CharLimit: model.MaxProfileNameLength},
+		inputStatus:    component.TextField{Editor: widget.Editor{SingleLine: true}, CharLimit: model.MaxProfileStatusLength},
+		List:           layout.List{Axis: layout.Vertical},
+	}
+}
+
+func (p *page) Layout(gtx Gtx) Dim {
+	if p.Theme == nil {
+		p.Theme = p.Manager.Theme()
+	}
+	if !p.fetched {
+		p.fetched = true
+		p.fetch()
+	}
+	if p.btnChooseAvatar.Clicked() && !p.saving {
+		p.chooseAvatar()
+	}
+	if p.btnRemoveAvatar.Clicked() && !p.saving {
+		p.setAvatar(nil)
+	}
+	if p.btnSave.Clicked() && !p.saving {
+		p.save()
+	}
+	flex := layout.Flex{Axis: layout.Vertical,
+		Spacing:   layout.SpaceEnd,
+		Alignment: layout.Start,
+	}
+	d := flex.Layout(gtx,
+		layout.Rigid(p.DrawAppBar),
+		layout.Flexed(1, p.drawContent),
+	)
+	return d
+}
+
+func (p *page) drawContent(gtx Gtx) Dim {
+	th := p.Theme
+	children := []layout.Widget{
+		func(gtx Gtx) Dim {
+			txt := "Your contacts see this profile, it's signed with your chat key and sent to them " +
+				"when they connect."
+			return material.Body2(th, txt).Layout(gtx)
+		},
+		func(gtx Gtx) Dim {
+			flex := layout.Flex{Alignment: layout.Middle}
+			return flex.Layout(gtx,
+				layout.Rigid(func(gtx Gtx) Dim {
+					if p.avatarView.Theme == nil {
+						p.avatarView.Theme = th
+					}
+					p.avatarView.Size = image.Point{X: gtx.Dp(72), Y: gtx.Dp(72)}
+					return p.avatarView.Layout(gtx)
+				}),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+				layout.Rigid(material.Button(th, &p.btnChooseAvatar, "Choose Avatar").Layout),
+				layout.Rigid(layout.Spacer{Width: unit.Dp(8)}.Layout),
+				layout.Rigid(func(gtx Gtx) Dim {
+					if len(p.avatar) == 0 {
+						return Dim{}
+					}
+					return material.Button(th, &p.btnRemoveAvatar, "Remove").Layout(gtx)
+				}),
+			)
+		},
+		func(gtx Gtx) Dim {
+			return p.inputName.Layout(gtx, th, "Display Name")
+		},
+		func(gtx Gtx) Dim {
+			return p.inputStatus.Layout(gtx, th, "Status")
+		},
+		func(gtx Gtx) Dim {
+			if p.saving {
+				loader := view.Loader{Theme: th}
+				return loader.Layout(gtx)
+			}
+			return material.Button(th, &p.btnSave, "Save").Layout(gtx)
+		},
+	}
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		return p.List.Layout(gtx, len(children), func(gtx Gtx, index int) Dim {
+			return layout.Inset{Bottom: unit.Dp(16)}.Layout(gtx, children[index])
+		})
+	})
+}
+
+func (p *page) fetch() {
+	profile, err := wallet.GlobalWallet.Profile()
+	if err != nil {
+		return
+	}
+	p.inputName.SetText(profile.Name)
+	p.inputStatus.SetText(profile.Status)
+	p.setAvatar(profile.Avatar)
+}
+
+func (p *page) setAvatar(avatar []byte) {
+	p.avatar = avatar
+	p.avatarView.Image = nil
+	if len(avatar) != 0 {
+		p.avatarView.Image, _, _ = image.Decode(bytes.NewReader(avatar))
+	}
+}
+
+// chooseAvatar scales down the image chosen by the user, it's saved with the profile
+func (p *page) chooseAvatar() {
+	p.saving = true
+	go func() {
+		var err error
+		defer func() {
+			p.saving = false
+			if err != nil {
+				alog.Logger().Errorln(err)
+				p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+			}
+			p.Window().Invalidate()
+		}()
+		file, err := p.Explorer().ChooseFile(".png", ".jpg", ".jpeg", ".gif")
+		if errors.Is(err, explorer.ErrUserDecline) {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+		defer func() { _ = file.Close() }()
+		data, err := io.ReadAll(io.LimitReader(file, maxAvatarFileSize+1))
+		if err != nil {
+			return
+		}
+		if len(data) > maxAvatarFileSize {
+			err = model.ErrProfileAvatarTooLong
+			return
+		}
+		avatar, err := common.EncodeAvatar(data)
+		if err != nil {
+			return
+		}
+		p.setAvatar(avatar)
+	}()
+}
+
+func (p *page) save() {
+	p.saving = true
+	name, status, avatar := p.inputName.Text(), p.inputStatus.Text(), p.avatar
+	go func() {
+		defer func() {
+			p.saving = false
+			p.Window().Invalidate()
+		}()
+		_, err := chat.GlobalChat.UpdateProfile(name, status, avatar)
+		if err != nil {
+			alog.Logger().Errorln(err)
+			p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+			return
+		}
+		p.Snackbar().Show("Profile saved", nil, color.NRGBA{}, "")
+	}()
+}
+
+func (p *page) OnDatabaseChange(event pubsub.Event) {
+	switch event.Data.(type) {
+	case pubsub.CurrentAccountChangedEventData:
+		p.fetched = false
+		p.Window().Invalidate()
+	}
+}
+
+func (p *page) DrawAppBar(gtx Gtx) Dim {
+	gtx.Constraints.Max.Y = gtx.Dp(56)
+	th := p.Theme
+	if p.buttonNavigation.Clicked() {
+		p.PopUp()
+	}
+
+	return view.DrawAppBarLayout(gtx, th, func(gtx Gtx) Dim {
+		return layout.Flex{Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx Gtx) Dim {
+						navigationIcon := p.navigationIcon
+						button := material.IconButton(th, &p.buttonNavigation, navigationIcon, "Nav Icon Button")
+						button.Size = unit.Dp(40)
+						button.Background = th.Palette.ContrastBg
+						button.Color = th.Palette.ContrastFg
+						button.Inset = layout.UniformInset(unit.Dp(8))
+						return button.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx Gtx) Dim {
+						return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+							titleText := p.title
+							title := material.Body1(th, titleText)
+							title.Color = th.Palette.ContrastFg
+							title.TextSize = unit.Sp(18)
+							return title.Layout(gtx)
+						})
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (p *page) URL() URL {
+	return ProfilePageURL
+}
@@ -0,0 +1,164 @@
+package security
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image/color"
+	"time"
+)
+
+// autoLockOption is an option for auto lock timeout, timeout of zero means never
+type autoLockOption struct {
+	label   string
+	timeout time.Duration
+}
+
+var autoLockOptions = []autoLockOption{
+	{label: "Never", timeout: 0},
+	{label: "1 minute", timeout: time.Minute},
+	{label: "5 minutes", timeout: time.Minute * 5},
+	{label: "15 minutes", timeout: time.Minute * 15},
+	{label: "30 minutes", timeout: time.Minute * 30},
+	{label: "1 hour", timeout: time.Hour},
+}
+
+type page struct {
+	Manager
+	Theme            *material.Theme
+	title            string
+	buttonNavigation widget.Clickable
+	navigationIcon   *widget.Icon
+	autoLockEnum     widget.Enum
+	buttonLockNow    view.IconButton
+	layout.List
+}
+
+func New(manager Manager) Page {
+	navIcon, _ := widget.NewIcon(icons.NavigationArrowBack)
+	lockIcon, _ := widget.NewIcon(icons.ActionLock)
+	p := &page{
+		Manager:        manager,
+		Theme:          manager.Theme(),
+		title:          "Security",
+		navigationIcon: navIcon,
+		buttonLockNow: view.IconButton{
+			Theme: manager.Theme(),
+			Icon:  lockIcon,
+			Text:  "Lock Now",
+		},
+		List: layout.List{Axis: layout.Vertical},
+	}
+	p.autoLockEnum.Value = wallet.GlobalWallet.AutoLockTimeout().String()
+	return p
+}
+
+func (p *page) Layout(gtx Gtx) Dim {
+	if p.Theme == nil {
+		p.Theme = p.Manager.Theme()
+	}
+	if p.autoLockEnum.Changed() {
+		p.onAutoLockChange()
+	}
+	if p.buttonLockNow.Button.Clicked() {
+		wallet.GlobalWallet.Lock()
+	}
+	flex := layout.Flex{Axis: layout.Vertical,
+		Spacing:   layout.SpaceEnd,
+		Alignment: layout.Start,
+	}
+	d := flex.Layout(gtx,
+		layout.Rigid(p.DrawAppBar),
+		layout.Flexed(1, p.drawContent),
+	)
+	return d
+}
+
+func (p *page) drawContent(gtx Gtx) Dim {
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		return p.List.Layout(gtx, len(autoLockOptions)+2, func(gtx Gtx, index int) Dim {
+			switch {
+			case index == 0:
+				return layout.Inset{Bottom: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					return material.Subtitle1(p.Theme, "Lock wallet after inactivity").Layout(gtx)
+				})
+			case index <= len(autoLockOptions):
+				option := autoLockOptions[index-1]
+				return material.RadioButton(p.Theme, &p.autoLockEnum, option.timeout.String(), option.label).Layout(gtx)
+			default:
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, p.buttonLockNow.Layout)
+			}
+		})
+	})
+}
+
+func (p *page) onAutoLockChange() {
+	var err error
+	defer func() {
+		if err != nil {
+			alog.Logger().Errorln(err)
+			p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+		}
+	}()
+	var timeout time.Duration
+	timeout, err = time.ParseDuration(p.autoLockEnum.Value)
+	if err != nil {
+		return
+	}
+	settings, err := wallet.GlobalWallet.Settings()
+	if err != nil {
+		return
+	}
+	settings.AutoLockTimeout = timeout
+	err = wallet.GlobalWallet.SaveSettings(&settings)
+	if err != nil {
+		return
+	}
+	wallet.GlobalWallet.SetAutoLockTimeout(timeout)
+}
+
+func (p *page) DrawAppBar(gtx Gtx) Dim {
+	gtx.Constraints.Max.Y = gtx.Dp(56)
+	th := p.Theme
+	if p.buttonNavigation.Clicked() {
+		p.PopUp()
+	}
+
+	return view.DrawAppBarLayout(gtx, th, func(gtx Gtx) Dim {
+		return layout.Flex{Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+					layout.Rigid(func(gtx Gtx) Dim {
+						navigationIcon := p.navigationIcon
+						button := material.IconButton(th, &p.buttonNavigation, navigationIcon, "Nav Icon Button")
+						button.Size = unit.Dp(40)
+						button.Background = th.Palette.ContrastBg
+						button.Color = th.Palette.ContrastFg
+						button.Inset = layout.UniformInset(unit.Dp(8))
+						return button.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx Gtx) Dim {
+						return layout.Inset{Left: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+							titleText := p.title
+							title := material.Body1(th, titleText)
+							title.Color = th.Palette.ContrastFg
+							title.TextSize = unit.Sp(18)
+							return title.Layout(gtx)
+						})
+					}),
+				)
+			}),
+		)
+	})
+}
+
+func (p *page) URL() URL {
+	return SecurityPageURL
+}
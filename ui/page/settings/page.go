@@ -41,11 +41,16 @@ type page struct {
 func New(manager Manager) Page {
 	menuIcon, _ := widget.NewIcon(icons.ContentAddCircle)
 	accountsIcon, _ := widget.NewIcon(icons.SocialGroup)
+	profileIcon, _ := widget.NewIcon(icons.ActionAccountCircle)
+	devicesIcon, _ := widget.NewIcon(icons.HardwareDevicesOther)
 	walletIcon, _ := widget.NewIcon(icons.ActionAccountBalanceWallet)
 	contactsIcon, _ := widget.NewIcon(icons.CommunicationContacts)
 	chatIcon, _ := widget.NewIcon(icons.CommunicationChat)
 	themeIcon, _ := widget.NewIcon(icons.ImagePalette)
 	notificationsIcon, _ := widget.NewIcon(icons.SocialNotifications)
+	securityIcon, _ := widget.NewIcon(icons.ActionLock)
+	currencyIcon, _ := widget.NewIcon(icons.EditorAttachMoney)
+	dAppsIcon, _ := widget.NewIcon(icons.ActionSettingsInputAntenna)
 	helpIcon, _ := widget.NewIcon(icons.ActionHelp)
 	aboutIcon, _ := widget.NewIcon(icons.ActionInfo)
 	p := page{
@@ -79,6 +84,20 @@ func New(manager Manager) Page {
 				Icon:    accountsIcon,
 				url:     AccountsPageURL,
 			},
+			{
+				Manager: manager,
+				Theme:   manager.Theme(),
+				Title:   "Profile",
+				Icon:    profileIcon,
+				url:     ProfilePageURL,
+			},
+			{
+				Manager: manager,
+				Theme:   manager.Theme(),
+				Title:   "Devices",
+				Icon:    devicesIcon,
+				url:     DevicesPageURL,
+			},
 			{
 				Manager: manager,
 				Theme:   manager.Theme(),
@@ -100,6 +119,27 @@ func New(manager Manager) Page {
 				Icon:    notificationsIcon,
 				url:     NotificationsPageURL,
 			},
+			{
+				Manager: manager,
+				Theme:   manager.Theme(),
+				Title:   "Security",
+				Icon:    securityIcon,
+				url:     SecurityPageURL,
+			},
+			{
+				Manager: manager,
+				Theme:   manager.Theme(),
+				Title:   "Currency",
+				Icon:    currencyIcon,
+				url:     CurrencyPageURL,
+			},
+			{
+				Manager: manager,
+				Theme:   manager.Theme(),
+				Title:   "dApps",
+				Icon:    dAppsIcon,
+				url:     DAppsPageURL,
+			},
 			{
 				Manager: manager,
 				Theme:   manager.Theme(),
@@ -0,0 +1,142 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/mearaj/protonet/internal/wallet"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// networkForm adds a custom chain with its first rpc url
+type networkForm struct {
+	Manager
+	Theme         *material.Theme
+	inputChainID  component.TextField
+	inputName     component.TextField
+	inputSymbol   component.TextField
+	inputDecimals component.TextField
+	inputRPC      component.TextField
+	inputExplorer component.TextField
+	btnAdd        view.IconButton
+	err           error
+	adding        bool
+	*view.ModalContent
+}
+
+func newNetworkForm(manager Manager, theme *material.Theme) *networkForm {
+	iconAdd, _ := widget.NewIcon(icons.ContentAdd)
+	f := &networkForm{
+		Manager:       manager,
+		Theme:         theme,
+		inputChainID:  component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputName:     component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputSymbol:   component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputDecimals: component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputRPC:      component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputExplorer: component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		btnAdd: view.IconButton{
+			Theme: theme,
+			Icon:  iconAdd,
+			Text:  "Add Network",
+		},
+	}
+	f.inputDecimals.SetText("18")
+	f.ModalContent = view.NewModalContent(func() { f.Modal().Dismiss(nil) })
+	return f
+}
+
+func (f *networkForm) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return f.ModalContent.DrawContent(gtx, f.Theme, f.drawForm)
+}
+
+func (f *networkForm) drawForm(gtx Gtx) Dim {
+	if f.btnAdd.Button.Clicked() && !f.adding {
+		f.add()
+	}
+	fields := []struct {
+		*component.TextField
+		hint string
+	}{
+		{&f.inputChainID, "Chain ID"},
+		{&f.inputName, "Network Name"},
+		{&f.inputSymbol, "Currency Symbol"},
+		{&f.inputDecimals, "Currency Decimals"},
+		{&f.inputRPC, "RPC URL"},
+		{&f.inputExplorer, "Block Explorer URL (Optional)"},
+	}
+	children := []layout.FlexChild{
+		layout.Rigid(material.H6(f.Theme, "Add Network").Layout),
+	}
+	for _, field := range fields {
+		field := field
+		children = append(children, layout.Rigid(func(gtx Gtx) Dim {
+			return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+				return field.Layout(gtx, f.Theme, field.hint)
+			})
+		}))
+	}
+	children = append(children,
+		layout.Rigid(func(gtx Gtx) Dim {
+			if f.err == nil {
+				return Dim{}
+			}
+			return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+				lbl := material.Body2(f.Theme, f.err.Error())
+				lbl.Color = color.NRGBA(colornames.Red500)
+				return lbl.Layout(gtx)
+			})
+		}),
+		layout.Rigid(func(gtx Gtx) Dim {
+			return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+				if f.adding {
+					loader := view.Loader{Theme: f.Theme}
+					return loader.Layout(gtx)
+				}
+				gtx.Constraints.Min.X = gtx.Constraints.Max.X
+				return f.btnAdd.Layout(gtx)
+			})
+		}),
+	)
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+	})
+}
+
+func (f *networkForm) add() {
+	decimals, err := strconv.Atoi(strings.TrimSpace(f.inputDecimals.Text()))
+	if err != nil {
+		f.err = errors.New("invalid decimals")
+		return
+	}
+	f.adding = true
+	f.err = nil
+	chainID, name, symbol := f.inputChainID.Text(), f.inputName.Text(), f.inputSymbol.Text()
+	rpcURL, explorerURL := f.inputRPC.Text(), f.inputExplorer.Text()
+	go func() {
+		conn, err := wallet.GlobalWallet.AddNetwork(chainID, name, symbol, decimals, rpcURL, explorerURL)
+		f.adding = false
+		if err != nil {
+			f.err = err
+			f.Window().Invalidate()
+			return
+		}
+		f.Modal().Dismiss(func() {
+			txt := fmt.Sprintf("Successfully added network %s", conn.Chain.Name)
+			f.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+		})
+		f.Window().Invalidate()
+	}()
+}
@@ -1,11 +1,13 @@
 package wallet
 
 import (
+	"fmt"
 	"gioui.org/layout"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"github.com/mearaj/protonet/internal/evm"
 	"github.com/mearaj/protonet/internal/pubsub"
 	"github.com/mearaj/protonet/ui/fwk"
 	"github.com/mearaj/protonet/ui/view"
@@ -32,6 +34,9 @@ const (
 
 type page struct {
 	allChainsTab tabAllChains
+	tokensTab    tabTokens
+	historyTab   tabHistory
+	signTab      tabSign
 	Manager
 	Theme            *material.Theme
 	title            string
@@ -54,8 +59,14 @@ func New(manager Manager) Page {
 		title:          "Wallet",
 		navigationIcon: navIcon,
 		allChainsTab:   tabAllChains{title: "All"},
+		tokensTab:      tabTokens{title: "Tokens"},
+		historyTab:     tabHistory{title: "History"},
+		signTab:        tabSign{title: "Sign"},
 	}
 	p.allChainsTab.page = &p
+	p.tokensTab.page = &p
+	p.historyTab.page = &p
+	p.signTab.page = &p
 	return &p
 }
 
@@ -65,6 +76,9 @@ func (p *page) Layout(gtx Gtx) Dim {
 			p.Theme = p.Manager.Theme()
 		}
 		p.allChainsTab.Axis = layout.Vertical
+		p.tokensTab.Axis = layout.Vertical
+		p.historyTab.Axis = layout.Vertical
+		p.signTab.Axis = layout.Vertical
 		p.initTabs()
 		p.initialized = true
 	}
@@ -119,7 +133,7 @@ func (p *page) DrawAppBar(gtx Gtx) Dim {
 }
 
 func (p *page) initTabs() {
-	tabs := [2]view.Tab{}
+	tabs := [4]view.Tab{}
 	p.Tabs.Header = p.drawTabHead
 	p.Tabs.Body = p.drawTabBody
 	p.Tabs.Tabs = tabs[:]
@@ -130,7 +144,11 @@ func (p *page) drawTabHead(gtx Gtx, index int) Dim {
 	case 0:
 		return p.allChainsTab.drawTabHead(gtx)
 	case 1:
-		return p.allChainsTab.drawTabHead(gtx)
+		return p.tokensTab.drawTabHead(gtx)
+	case 2:
+		return p.historyTab.drawTabHead(gtx)
+	case 3:
+		return p.signTab.drawTabHead(gtx)
 	}
 	return Dim{}
 }
@@ -140,17 +158,83 @@ func (p *page) drawTabBody(gtx Gtx, index int) Dim {
 	case 0:
 		return p.allChainsTab.drawTabBody(gtx)
 	case 1:
-		return p.allChainsTab.drawTabBody(gtx)
-
+		return p.tokensTab.drawTabBody(gtx)
+	case 2:
+		return p.historyTab.drawTabBody(gtx)
+	case 3:
+		return p.signTab.drawTabBody(gtx)
 	}
 	return Dim{}
 }
 
 func (p *page) OnDatabaseChange(event pubsub.Event) {
-	switch event.Data.(type) {
-
+	switch e := event.Data.(type) {
+	case pubsub.TransactionSentEventData:
+		p.allChainsTab.refreshBalances(e.ChainID)
+		p.tokensTab.refreshBalances(e.ChainID)
+		p.Window().Invalidate()
+	case pubsub.TransactionMinedEventData:
+		var txt string
+		switch {
+		case event.Err != nil:
+			txt = fmt.Sprintf("Transaction %s failed: %s", e.Hash.Hex(), event.Err)
+		default:
+			txt = fmt.Sprintf("Transaction %s mined in block %s", e.Hash.Hex(), e.Receipt.BlockNumber)
+		}
+		p.allChainsTab.refreshBalances(e.ChainID)
+		p.tokensTab.refreshBalances(e.ChainID)
+		p.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+		p.Window().Invalidate()
+	case pubsub.TokensChangedEventData:
+		p.tokensTab.refreshTokens(e.ChainID)
+		p.allChainsTab.portfolioStale = true
+		p.Window().Invalidate()
+	case pubsub.RPCHealthChangedEventData:
+		p.allChainsTab.refreshConnection(e.ChainID)
+		p.Window().Invalidate()
+	case pubsub.TransactionsChangedEventData:
+		p.historyTab.refreshHistory(e.ChainID)
+		p.Window().Invalidate()
+	case pubsub.BalanceChangedEventData:
+		if e.Token == nil {
+			p.allChainsTab.refreshBalances(e.ChainID)
+		} else {
+			p.tokensTab.refreshBalances(e.ChainID)
+			p.allChainsTab.portfolioStale = true
+		}
+		p.Window().Invalidate()
+	case pubsub.IncomingTransferEventData:
+		txt := fmt.Sprintf("Received %s %s from %s", evm.FormatUnits(e.Value, e.Decimals), e.Symbol, e.From.Hex())
+		p.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+		p.Window().Invalidate()
+	case pubsub.NetworksChangedEventData:
+		p.refreshNetworks(e.ChainID)
+		p.Window().Invalidate()
+	case pubsub.WalletUnlockedEventData:
+		// the custom chains and endpoints are loaded on unlock
+		p.refreshNetworks("")
+		p.Window().Invalidate()
+	case pubsub.CurrentAccountChangedEventData:
+		p.tokensTab.refreshTokens("")
+		p.historyTab.refreshHistory("")
+		p.allChainsTab.portfolioStale = true
+		p.Window().Invalidate()
+	case pubsub.SettingsChangedEventData:
+		if e.DisplayCurrency != p.allChainsTab.portfolio.Currency {
+			p.allChainsTab.portfolioStale = true
+			p.Window().Invalidate()
+		}
 	}
 }
+
+// refreshNetworks rebuilds the tabs listing the connections after the chains or their endpoints
+// change, chainID is in decimal and empty chainID rebuilds only the lists of chains
+func (p *page) refreshNetworks(chainID string) {
+	p.allChainsTab.refreshNetworks(chainID)
+	p.tokensTab.initialized = false
+	p.historyTab.initialized = false
+}
+
 func (p *page) URL() URL {
 	return fwk.WalletPageURL
 }
@@ -1,22 +1,30 @@
 package wallet
 
 import (
+	"errors"
 	"fmt"
 	"gioui.org/layout"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"gioui.org/x/explorer"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/assets/fonts"
 	"github.com/mearaj/protonet/internal/evm"
 	"github.com/mearaj/protonet/internal/wallet"
 	"github.com/mearaj/protonet/ui/fwk"
 	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/icons"
 	"golang.org/x/image/colornames"
 	"image"
 	"image/color"
+	"io"
+	"math/big"
+	"sort"
 	"strings"
+	"time"
 )
 
 type tabAllChains struct {
@@ -27,22 +35,149 @@ type tabAllChains struct {
 	chainItems         []*tabAllChainsConnItem
 	chainItemsFiltered []*tabAllChainsConnItem
 	filterText         string
+	// filterStale filters the chains again when the chains or favorites change
+	filterStale      bool
+	btnAddNetwork    widget.Clickable
+	btnLoadChainList widget.Clickable
+	loadingChainList bool
+	// portfolio is the value of the balances on the connected chains, it's valued again when
+	// portfolioStale is set
+	portfolio        wallet.PortfolioValue
+	portfolioStale   bool
+	valuingPortfolio bool
+	// valuedChains are the chain ids in decimal of the chains connected when the portfolio was valued
+	valuedChains map[string]struct{}
 }
 
+// init creates the items of the new connections and keeps the items of the existing ones
 func (p *tabAllChains) init() {
-	p.chainItems = make([]*tabAllChainsConnItem, len(evm.ChainsSlice()))
-	for i, ch := range wallet.GlobalWallet.Connections() {
+	items := make(map[*evm.RPCClients]*tabAllChainsConnItem, len(p.chainItems))
+	for _, item := range p.chainItems {
+		items[item.ConnChain] = item
+	}
+	connections := wallet.GlobalWallet.Connections()
+	p.chainItems = make([]*tabAllChainsConnItem, len(connections))
+	for i, ch := range connections {
+		if item, ok := items[ch]; ok {
+			p.chainItems[i] = item
+			continue
+		}
 		p.chainItems[i] = &tabAllChainsConnItem{
 			ConnChain: ch,
 			Theme:     p.Theme,
+			page:      p.page,
+			inputRPC: component.TextField{
+				Editor: widget.Editor{SingleLine: true, Submit: true},
+			},
 		}
 	}
-	filteredItems := make([]*tabAllChainsConnItem, len(p.chainItems))
-	copy(filteredItems, p.chainItems)
-	p.chainItemsFiltered = append(p.chainItemsFiltered[:0], filteredItems...)
+	p.filterStale = true
+	p.portfolioStale = true
 	p.initialized = true
 }
 
+// refreshNetworks rebuilds the items of chainID after its endpoints or the chains change,
+// chainID is in decimal and empty chainID rebuilds all the items
+func (p *tabAllChains) refreshNetworks(chainID string) {
+	for _, item := range p.chainItems {
+		if chainID == "" || item.ConnChain.Chain.ChainID.String() == chainID {
+			item.initialized = false
+		}
+	}
+	p.initialized = false
+}
+
+// refreshBalances fetches the balances of chainID again when they are drawn next
+func (p *tabAllChains) refreshBalances(chainID *big.Int) {
+	for _, item := range p.chainItems {
+		if item.ConnChain.Chain.ChainID.Cmp(chainID) != 0 {
+			continue
+		}
+		for _, stateItem := range item.connStateItems {
+			stateItem.balFetched = false
+		}
+	}
+	p.portfolioStale = true
+}
+
+// refreshConnection values the portfolio again when chainID in decimal got connected or
+// disconnected since it was valued
+func (p *tabAllChains) refreshConnection(chainID string) {
+	for _, item := range p.chainItems {
+		if item.ConnChain.Chain.ChainID.String() != chainID {
+			continue
+		}
+		_, valued := p.valuedChains[chainID]
+		if item.ConnChain.IsConnected() != valued {
+			p.portfolioStale = true
+		}
+	}
+}
+
+// valuePortfolio values the balances on the connected chains in the display currency, reading
+// the balances and the price feeds is slow hence it's async
+func (p *tabAllChains) valuePortfolio() {
+	p.valuingPortfolio = true
+	p.portfolioStale = false
+	go func() {
+		valuedChains := make(map[string]struct{})
+		for _, conn := range wallet.GlobalWallet.Connections() {
+			if conn.IsConnected() {
+				valuedChains[conn.Chain.ChainID.String()] = struct{}{}
+			}
+		}
+		portfolio, err := wallet.GlobalWallet.PortfolioValue()
+		if err != nil {
+			alog.Logger().Errorln(err)
+		}
+		p.portfolio, p.valuedChains = portfolio, valuedChains
+		p.valuingPortfolio = false
+		p.Window().Invalidate()
+	}()
+}
+
+// drawPortfolio shows the value of the balances on the connected chains and the assets held
+// which couldn't be valued
+func (p *tabAllChains) drawPortfolio(gtx fwk.Gtx) fwk.Dim {
+	if !wallet.GlobalWallet.IsOpen() {
+		return fwk.Dim{}
+	}
+	if p.portfolioStale && !p.valuingPortfolio {
+		p.valuePortfolio()
+	}
+	txt := "Portfolio: connect a mainnet to value it"
+	if p.portfolio.Total != nil && len(p.portfolio.Chains) > 0 {
+		txt = fmt.Sprintf("Portfolio: %s", wallet.FormatFiat(p.portfolio.Total, p.portfolio.Currency))
+	}
+	inset := layout.Inset{Bottom: 8}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				flex := layout.Flex{Alignment: layout.Middle}
+				return flex.Layout(gtx,
+					layout.Rigid(material.H6(p.Theme, txt).Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !p.valuingPortfolio {
+							return fwk.Dim{}
+						}
+						inset := layout.Inset{Left: 8}
+						loader := view.Loader{Theme: p.Theme, Size: image.Pt(gtx.Dp(20), gtx.Dp(20))}
+						return inset.Layout(gtx, loader.Layout)
+					}),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if len(p.portfolio.Unpriced) == 0 {
+					return fwk.Dim{}
+				}
+				txt := fmt.Sprintf("Not valued: %s", strings.Join(p.portfolio.Unpriced, ", "))
+				return material.Body2(p.Theme, txt).Layout(gtx)
+			}),
+		)
+	})
+}
+
 func (p *tabAllChains) drawTabHead(gtx fwk.Gtx) fwk.Dim {
 	if !p.initialized {
 		p.init()
@@ -63,13 +198,24 @@ func (p *tabAllChains) drawTabBody(gtx fwk.Gtx) fwk.Dim {
 	if !p.initialized {
 		p.init()
 	}
+	if p.btnAddNetwork.Clicked() {
+		p.Modal().Show(newNetworkForm(p.Manager, p.Theme).Layout, nil, fwk.Animation{
+			Duration: time.Millisecond * 250,
+			State:    component.Invisible,
+			Started:  time.Time{},
+		})
+	}
+	if p.btnLoadChainList.Clicked() && !p.loadingChainList {
+		p.loadChainList()
+	}
 	filterText := strings.TrimSpace(strings.ToLower(p.filterText))
 	searchText := strings.TrimSpace(strings.ToLower(p.search.Text()))
-	if filterText != searchText {
+	if filterText != searchText || p.filterStale {
 		p.filterText = searchText
+		p.filterStale = false
 		filteredItems := make([]*tabAllChainsConnItem, 0)
 		for _, ch := range p.chainItems {
-			if len(ch.ConnChain.RPCClients) == 0 {
+			if len(ch.ConnChain.Clients()) == 0 {
 				continue
 			}
 			text1 := strings.ToLower(ch.ConnChain.Chain.Name)
@@ -81,35 +227,105 @@ func (p *tabAllChains) drawTabBody(gtx fwk.Gtx) fwk.Dim {
 				filteredItems = append(filteredItems, ch)
 			}
 		}
+		// favorite chains come first
+		sort.SliceStable(filteredItems, func(i, j int) bool {
+			favI := wallet.GlobalWallet.IsFavoriteChain(filteredItems[i].ConnChain.Chain.ChainID.String())
+			favJ := wallet.GlobalWallet.IsFavoriteChain(filteredItems[j].ConnChain.Chain.ChainID.String())
+			return favI && !favJ
+		})
 		p.chainItemsFiltered = append(p.chainItemsFiltered[:0], filteredItems...)
 	}
 	inset := layout.UniformInset(16)
 	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-		return p.allChainsTab.List.Layout(gtx, len(p.chainItemsFiltered), func(gtx layout.Context, index int) layout.Dimensions {
-			if len(p.chainItemsFiltered[index].ConnChain.RPCClients) == 0 {
-				return layout.Dimensions{}
-			}
-			flex := layout.Flex{Axis: layout.Vertical}
-			return flex.Layout(gtx,
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					inset := layout.Inset{Top: 12, Bottom: 12}
-					return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-						return p.chainItemsFiltered[index].Layout(gtx)
-					})
-				}),
-				layout.Rigid(component.Divider(p.Theme).Layout),
-			)
-		})
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx,
+			layout.Rigid(p.drawPortfolio),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				inset := layout.Inset{Bottom: 8}
+				return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					flex := layout.Flex{Alignment: layout.Middle}
+					return flex.Layout(gtx,
+						layout.Rigid(material.Button(p.Theme, &p.btnAddNetwork, "Add Network").Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							inset := layout.Inset{Left: 8}
+							if p.loadingChainList {
+								loader := view.Loader{Theme: p.Theme, Size: image.Pt(gtx.Dp(28), gtx.Dp(28))}
+								return inset.Layout(gtx, loader.Layout)
+							}
+							return inset.Layout(gtx, material.Button(p.Theme, &p.btnLoadChainList, "Load Chain List").Layout)
+						}),
+					)
+				})
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return p.allChainsTab.List.Layout(gtx, len(p.chainItemsFiltered), func(gtx layout.Context, index int) layout.Dimensions {
+					if len(p.chainItemsFiltered[index].ConnChain.Clients()) == 0 {
+						return layout.Dimensions{}
+					}
+					flex := layout.Flex{Axis: layout.Vertical}
+					return flex.Layout(gtx,
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							inset := layout.Inset{Top: 12, Bottom: 12}
+							return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								return p.chainItemsFiltered[index].Layout(gtx)
+							})
+						}),
+						layout.Rigid(component.Divider(p.Theme).Layout),
+					)
+				})
+			}),
+		)
 	})
 }
 
+// loadChainList merges a chain list bundle such as chainlist's chains.json chosen by the user
+func (p *tabAllChains) loadChainList() {
+	p.loadingChainList = true
+	go func() {
+		var err error
+		defer func() {
+			p.loadingChainList = false
+			if err != nil {
+				p.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+			}
+			p.Window().Invalidate()
+		}()
+		file, err := p.Explorer().ChooseFile(".json")
+		if errors.Is(err, explorer.ErrUserDecline) {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+		defer func() { _ = file.Close() }()
+		data, err := io.ReadAll(io.LimitReader(file, wallet.MaxChainListSize+1))
+		if err != nil {
+			return
+		}
+		added, updated, err := wallet.GlobalWallet.LoadChainList(data)
+		if err != nil {
+			return
+		}
+		txt := fmt.Sprintf("Loaded chain list with %d new and %d updated chains", added, updated)
+		p.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+	}()
+}
+
 type tabAllChainsConnItem struct {
 	ConnChain      *evm.RPCClients
 	connStateItems []*tabAllChainsConnStateItem
+	btnAutoConnect widget.Clickable
+	btnFavorite    widget.Clickable
+	btnRemove      widget.Clickable
+	btnAddRPC      widget.Clickable
+	inputRPC       component.TextField
+	err            error
 	layout.List
 	widget.Clickable
 	InsetHeader layout.Inset
 	*material.Theme
+	page        *page
 	initialized bool
 }
 
@@ -118,10 +334,17 @@ func (c *tabAllChainsConnItem) Layout(gtx fwk.Gtx) fwk.Dim {
 		if c.Theme == nil {
 			c.Theme = fonts.NewTheme()
 		}
-		if len(c.ConnChain.RPCClients) > 0 {
-			c.connStateItems = make([]*tabAllChainsConnStateItem, len(c.ConnChain.RPCClients))
-			for i, connState := range c.ConnChain.RPCClients {
-				c.connStateItems[i] = &tabAllChainsConnStateItem{RPCClient: connState, Theme: c.Theme}
+		clients := c.ConnChain.Clients()
+		c.connStateItems = make([]*tabAllChainsConnStateItem, len(clients))
+		for i, connState := range clients {
+			c.connStateItems[i] = &tabAllChainsConnStateItem{
+				RPCClient: connState,
+				Theme:     c.Theme,
+				page:      c.page,
+				conn:      c.ConnChain,
+				inputAPIKey: component.TextField{
+					Editor: widget.Editor{SingleLine: true, Submit: true, Mask: '*'},
+				},
 			}
 		}
 		if c.InsetHeader == (layout.Inset{}) {
@@ -129,43 +352,157 @@ func (c *tabAllChainsConnItem) Layout(gtx fwk.Gtx) fwk.Dim {
 		}
 		c.initialized = true
 	}
+	isProbing := c.ConnChain.IsProbing()
+	chainID := c.ConnChain.Chain.ChainID.String()
+	isFavorite := wallet.GlobalWallet.IsFavoriteChain(chainID)
+	isCustom := wallet.GlobalWallet.IsCustomNetwork(chainID)
+	if c.btnFavorite.Clicked() {
+		c.err = wallet.GlobalWallet.SetFavoriteChain(chainID, !isFavorite)
+		isFavorite = !isFavorite
+		c.page.allChainsTab.filterStale = true
+	}
+	if c.btnRemove.Clicked() {
+		conn := c.ConnChain
+		go func() {
+			err := wallet.GlobalWallet.RemoveNetwork(conn)
+			if err != nil {
+				c.err = err
+				c.page.Window().Invalidate()
+			}
+		}()
+	}
+	if c.btnAddRPC.Clicked() {
+		c.err = wallet.GlobalWallet.AddRPC(c.ConnChain, c.inputRPC.Text())
+		if c.err == nil {
+			c.inputRPC.SetText("")
+		}
+	}
+	if c.btnAutoConnect.Clicked() {
+		for _, item := range c.connStateItems {
+			item.balFetched = false
+		}
+		if isProbing {
+			go wallet.GlobalWallet.DisconnectChain(c.ConnChain)
+		} else {
+			wallet.GlobalWallet.ConnectChain(c.ConnChain)
+		}
+	}
 	flex := layout.Flex{Axis: layout.Vertical}
 	return flex.Layout(gtx,
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			btnStyle := material.ButtonLayout(c.Theme, &c.Clickable)
 			return btnStyle.Button.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				return c.InsetHeader.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-					return material.H5(c.Theme, c.ConnChain.Chain.Name).Layout(gtx)
+					flex := layout.Flex{Alignment: layout.Middle}
+					return flex.Layout(gtx,
+						layout.Flexed(1, material.H5(c.Theme, c.ConnChain.Chain.Name).Layout),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return drawFavoriteButton(gtx, c.Theme, &c.btnFavorite, isFavorite)
+						}),
+					)
 				})
 			})
 		}),
+		layout.Rigid(c.drawFlags),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			inset := layout.Inset{Bottom: 8}
 			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-				flex := layout.Flex{}
+				flex := layout.Flex{Alignment: layout.Middle}
 				return flex.Layout(gtx,
-					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 						txt := fmt.Sprintf("Currency: %s", c.ConnChain.Chain.NativeCurrency.Name)
+						portfolio := c.page.allChainsTab.portfolio
+						if value, ok := portfolio.ChainValue(&c.ConnChain.Chain.ChainID); ok {
+							txt = fmt.Sprintf("%s · ≈ %s", txt, wallet.FormatFiat(value, portfolio.Currency))
+						}
 						w := material.Body1(c.Theme, txt)
 						w.TextSize = unit.Sp(16)
 						return w.Layout(gtx)
 					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						// auto connect connects all the endpoints and keeps ranking them by health
+						btnStyle := material.Button(c.Theme, &c.btnAutoConnect, "Auto Connect")
+						btnStyle.Background = color.NRGBA(colornames.Green)
+						if isProbing {
+							btnStyle.Text = "Disconnect All"
+							btnStyle.Background = color.NRGBA(colornames.Red)
+						}
+						return btnStyle.Layout(gtx)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if !isCustom {
+							return fwk.Dim{}
+						}
+						inset := layout.Inset{Left: 8}
+						btnStyle := material.Button(c.Theme, &c.btnRemove, "Remove")
+						btnStyle.Background = color.NRGBA(colornames.Red)
+						return inset.Layout(gtx, btnStyle.Layout)
+					}),
 				)
 			})
 		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			c.List.Axis = layout.Vertical
-			return c.List.Layout(gtx, len(c.ConnChain.RPCClients), func(gtx layout.Context, index int) layout.Dimensions {
+			return c.List.Layout(gtx, len(c.connStateItems), func(gtx layout.Context, index int) layout.Dimensions {
 				return c.connStateItems[index].Layout(gtx)
 			})
 		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			flex := layout.Flex{Alignment: layout.Middle}
+			return flex.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return c.inputRPC.Layout(gtx, c.Theme, "Custom RPC URL")
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					inset := layout.Inset{Left: 16}
+					return inset.Layout(gtx, material.Button(c.Theme, &c.btnAddRPC, "Add RPC").Layout)
+				}),
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if c.err == nil {
+				return fwk.Dim{}
+			}
+			inset := layout.Inset{Top: 4}
+			lbl := material.Body2(c.Theme, c.err.Error())
+			lbl.Color = color.NRGBA(colornames.Red)
+			return inset.Layout(gtx, lbl.Layout)
+		}),
 	)
 }
 
+// drawFlags warns about a deprecated chain or the red flags of the chain such as a reused chain id
+func (c *tabAllChainsConnItem) drawFlags(gtx fwk.Gtx) fwk.Dim {
+	chain := c.ConnChain.Chain
+	if !chain.IsFlagged() {
+		return fwk.Dim{}
+	}
+	warnings := make([]string, 0, len(chain.RedFlags)+1)
+	if chain.IsDeprecated() {
+		warnings = append(warnings, "Deprecated")
+	}
+	if len(chain.RedFlags) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Red flags: %s", strings.Join(chain.RedFlags, ", ")))
+	}
+	inset := layout.Inset{Bottom: 4}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		lbl := material.Body2(c.Theme, strings.Join(warnings, " · "))
+		lbl.Color = color.NRGBA(colornames.Red)
+		return lbl.Layout(gtx)
+	})
+}
+
 type tabAllChainsConnStateItem struct {
 	*evm.RPCClient
-	btnConnect widget.Clickable
+	btnConnect  widget.Clickable
+	btnSend     widget.Clickable
+	btnFavorite widget.Clickable
+	btnRemove   widget.Clickable
+	btnSaveKey  widget.Clickable
+	inputAPIKey component.TextField
 	*material.Theme
+	page *page
+	conn *evm.RPCClients
 	State
 	layout.Inset
 	initialized bool
@@ -183,17 +520,57 @@ func (c *tabAllChainsConnStateItem) Layout(gtx fwk.Gtx) fwk.Dim {
 	}
 	inset := layout.Inset{Bottom: 12}
 	isConnected := c.IsConnected()
+	isFavorite := wallet.GlobalWallet.IsFavoriteRPC(c.RPC)
+	if c.btnFavorite.Clicked() {
+		c.err = wallet.GlobalWallet.SetFavoriteRPC(c.RPC, !isFavorite)
+		isFavorite = !isFavorite
+	}
+	if c.btnRemove.Clicked() {
+		c.err = wallet.GlobalWallet.RemoveRPC(c.conn, c.RPC)
+	}
+	if c.btnSaveKey.Clicked() {
+		if c.err = wallet.GlobalWallet.SetAPIKey(c.conn, c.RPC, c.inputAPIKey.Text()); c.err == nil {
+			c.inputAPIKey.SetText("")
+			c.page.Snackbar().Show("API key saved", nil, color.NRGBA{}, "")
+		}
+	}
+	if c.btnSend.Clicked() {
+		c.page.Modal().Show(view.NewSendForm(c.page.Manager, c.Theme, c.conn, nil).Layout, nil, fwk.Animation{
+			Duration: time.Millisecond * 250,
+			State:    component.Invisible,
+			Started:  time.Time{},
+		})
+	}
 	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		flex := layout.Flex{Axis: layout.Vertical}
 		return flex.Layout(gtx,
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				inset := layout.Inset{Bottom: 4}
 				return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
-					w := material.Body1(c.Theme, string(c.RPCClient.RPC))
-					w.TextSize = unit.Sp(16)
-					return w.Layout(gtx)
+					flex := layout.Flex{Alignment: layout.Middle}
+					return flex.Layout(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							w := material.Body1(c.Theme, string(c.RPCClient.RPC))
+							w.TextSize = unit.Sp(16)
+							return w.Layout(gtx)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							return drawFavoriteButton(gtx, c.Theme, &c.btnFavorite, isFavorite)
+						}),
+						layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+							if !wallet.GlobalWallet.IsCustomRPC(c.RPC) {
+								return fwk.Dim{}
+							}
+							inset := layout.Inset{Left: 8}
+							btnStyle := material.Button(c.Theme, &c.btnRemove, "Remove")
+							btnStyle.Background = color.NRGBA(colornames.Red)
+							return inset.Layout(gtx, btnStyle.Layout)
+						}),
+					)
 				})
 			}),
+			layout.Rigid(c.drawHealth),
+			layout.Rigid(c.drawAPIKey),
 			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 				flex := layout.Flex{}
 				return flex.Layout(gtx, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
@@ -218,14 +595,21 @@ func (c *tabAllChainsConnStateItem) Layout(gtx fwk.Gtx) fwk.Dim {
 									if err != nil {
 										alog.Logger().Errorln(err)
 									}
+									c.page.allChainsTab.refreshConnection(c.conn.Chain.ChainID.String())
 									c.State = StateIdle
 								case false:
+									// connect verifies the chain id over network, hence it's async
 									c.State = StateConnecting
-									err := c.RPCClient.Connect()
-									if err != nil {
-										alog.Logger().Errorln(err)
-									}
-									c.State = StateIdle
+									go func() {
+										err := c.RPCClient.Connect()
+										if err != nil {
+											alog.Logger().Errorln(err)
+											c.page.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+										}
+										c.page.allChainsTab.refreshConnection(c.conn.Chain.ChainID.String())
+										c.State = StateIdle
+										c.page.Window().Invalidate()
+									}()
 								}
 							}
 							btnStyle.Background = color.NRGBA(colornames.Green)
@@ -257,12 +641,100 @@ func (c *tabAllChainsConnStateItem) Layout(gtx fwk.Gtx) fwk.Dim {
 											return fwk.Dim{}
 										}
 										return material.Body1(c.Theme, c.bal).Layout(gtx)
+									}),
+									layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+										inset := layout.Inset{Left: 16}
+										return inset.Layout(gtx, material.Button(c.Theme, &c.btnSend, "Send").Layout)
 									}))
 							})
 						}),
+						layout.Rigid(c.drawPendingTransactions),
 					)
 				}))
 			}),
 		)
 	})
 }
+
+// drawPendingTransactions lists the transactions sent from the current account which aren't mined yet
+func (c *tabAllChainsConnStateItem) drawPendingTransactions(gtx fwk.Gtx) fwk.Dim {
+	if !c.IsConnected() || !wallet.GlobalWallet.IsOpen() {
+		return fwk.Dim{}
+	}
+	acc, err := wallet.GlobalWallet.Account()
+	if err != nil {
+		return fwk.Dim{}
+	}
+	pendingTxs := wallet.GlobalWallet.PendingTransactions(&c.conn.Chain.ChainID, common.HexToAddress(acc.EthAddress))
+	children := make([]layout.FlexChild, 0, len(pendingTxs))
+	for _, tx := range pendingTxs {
+		currency := c.conn.Chain.NativeCurrency
+		txt := fmt.Sprintf("Pending: %s %s to %s (%s)",
+			evm.FormatUnits(tx.Value, currency.Decimals), currency.Symbol, tx.To.Hex(), tx.Hash.Hex())
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Bottom: 4}
+			return inset.Layout(gtx, material.Body2(c.Theme, txt).Layout)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+// drawAPIKey shows the api key input of the templated urls, the saved key itself isn't shown
+func (c *tabAllChainsConnStateItem) drawAPIKey(gtx fwk.Gtx) fwk.Dim {
+	if !c.KeyRequired() {
+		return fwk.Dim{}
+	}
+	hint := "API Key"
+	if c.APIKey() != "" {
+		hint = "API Key (saved, enter a new key to replace it)"
+	}
+	inset := layout.Inset{Bottom: 8}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		flex := layout.Flex{Alignment: layout.Middle}
+		return flex.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return c.inputAPIKey.Layout(gtx, c.Theme, hint)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				inset := layout.Inset{Left: 16}
+				return inset.Layout(gtx, material.Button(c.Theme, &c.btnSaveKey, "Save Key").Layout)
+			}),
+		)
+	})
+}
+
+// drawFavoriteButton draws the star toggling the favorite of a chain or an endpoint
+func drawFavoriteButton(gtx fwk.Gtx, th *material.Theme, btn *widget.Clickable, isFavorite bool) fwk.Dim {
+	icon, _ := widget.NewIcon(icons.ToggleStarBorder)
+	if isFavorite {
+		icon, _ = widget.NewIcon(icons.ToggleStar)
+	}
+	button := material.IconButton(th, btn, icon, "Favorite")
+	button.Size = unit.Dp(24)
+	button.Inset = layout.UniformInset(unit.Dp(4))
+	button.Background = color.NRGBA{}
+	button.Color = th.Palette.ContrastBg
+	return button.Layout(gtx)
+}
+
+// drawHealth shows the result of the last probe of the endpoint and whether it's the best ranked
+func (c *tabAllChainsConnStateItem) drawHealth(gtx fwk.Gtx) fwk.Dim {
+	health := c.Health()
+	if health.CheckedAt.IsZero() {
+		return fwk.Dim{}
+	}
+	txt := fmt.Sprintf("Healthy · %d ms · block %d", health.Latency.Milliseconds(), health.BlockNumber)
+	txtColor := color.NRGBA(colornames.Green)
+	if !health.IsHealthy() {
+		txt = fmt.Sprintf("Unhealthy · %s", health.Err)
+		txtColor = color.NRGBA(colornames.Red)
+	} else if ranked := c.conn.Ranked(); len(ranked) > 0 && ranked[0] == c.RPCClient {
+		txt = fmt.Sprintf("%s · Best", txt)
+	}
+	inset := layout.Inset{Bottom: 4}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		lbl := material.Caption(c.Theme, txt)
+		lbl.Color = txtColor
+		return lbl.Layout(gtx)
+	})
+}
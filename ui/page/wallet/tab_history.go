@@ -0,0 +1,289 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"gioui.org/io/clipboard"
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/model"
+	"github.com/mearaj/protonet/internal/wallet"
+	"github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/page/chatroom"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"image/color"
+	"strings"
+)
+
+// tabHistory shows the transactions of the current account on the connected chains
+type tabHistory struct {
+	initialized bool
+	layout.List
+	*page
+	title      string
+	chainItems []*tabHistoryChainItem
+}
+
+func (p *tabHistory) init() {
+	p.chainItems = make([]*tabHistoryChainItem, 0)
+	for _, ch := range wallet.GlobalWallet.Connections() {
+		if len(ch.Clients()) == 0 {
+			continue
+		}
+		p.chainItems = append(p.chainItems, &tabHistoryChainItem{page: p.page, conn: ch})
+	}
+	p.initialized = true
+}
+
+// refreshHistory reads the history of chainID again when it's drawn next, chainID is in decimal.
+// Empty chainID refreshes and scans again all the chains, as needed when the account changes.
+func (p *tabHistory) refreshHistory(chainID string) {
+	for _, item := range p.chainItems {
+		if chainID == "" {
+			item.scanned = false
+		}
+		if chainID == "" || item.conn.Chain.ChainID.String() == chainID {
+			item.txsFetched = false
+		}
+	}
+}
+
+func (p *tabHistory) drawTabHead(gtx fwk.Gtx) fwk.Dim {
+	if !p.initialized {
+		p.init()
+	}
+	inset := layout.UniformInset(12)
+	maxWidth := p.width / len(p.Tabs.Tabs)
+	gtx.Constraints.Max.X, gtx.Constraints.Min.X = maxWidth, maxWidth
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return material.H6(p.Theme, p.title).Layout(gtx)
+		})
+	})
+}
+
+func (p *tabHistory) drawTabBody(gtx fwk.Gtx) fwk.Dim {
+	if !p.initialized {
+		p.init()
+	}
+	searchText := strings.TrimSpace(strings.ToLower(p.search.Text()))
+	items := make([]*tabHistoryChainItem, 0)
+	if wallet.GlobalWallet.IsOpen() {
+		for _, item := range p.chainItems {
+			if !item.conn.IsConnected() {
+				continue
+			}
+			name := strings.ToLower(item.conn.Chain.Name)
+			shortName := strings.ToLower(item.conn.Chain.ShortName)
+			if strings.Contains(name, searchText) || strings.Contains(shortName, searchText) {
+				items = append(items, item)
+			}
+		}
+	}
+	inset := layout.UniformInset(16)
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		if len(items) == 0 {
+			txt := "Connect to a chain in the All tab to see its transactions"
+			return material.Body1(p.Theme, txt).Layout(gtx)
+		}
+		return p.List.Layout(gtx, len(items), func(gtx layout.Context, index int) layout.Dimensions {
+			flex := layout.Flex{Axis: layout.Vertical}
+			return flex.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					inset := layout.Inset{Top: 12, Bottom: 12}
+					return inset.Layout(gtx, items[index].Layout)
+				}),
+				layout.Rigid(component.Divider(p.Theme).Layout),
+			)
+		})
+	})
+}
+
+// tabHistoryChainItem lists the transactions of a chain, the chain is scanned for incoming
+// transfers when it's drawn first and on refresh
+type tabHistoryChainItem struct {
+	page       *page
+	conn       *evm.RPCClients
+	txs        []*tabHistoryTxItem
+	txsFetched bool
+	scanned    bool
+	scanning   bool
+	btnRefresh widget.Clickable
+	err        error
+}
+
+func (c *tabHistoryChainItem) Layout(gtx fwk.Gtx) fwk.Dim {
+	if !c.txsFetched {
+		c.txsFetched = true
+		c.fetchTransactions()
+	}
+	if (!c.scanned || c.btnRefresh.Clicked()) && !c.scanning {
+		c.scanned = true
+		c.scan()
+	}
+	th := c.page.Theme
+	txs := c.txs
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Bottom: 8}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				flex := layout.Flex{Alignment: layout.Middle}
+				return flex.Layout(gtx,
+					layout.Flexed(1, material.H5(th, c.conn.Chain.Name).Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						if c.scanning {
+							loader := view.Loader{Theme: th}
+							return loader.Layout(gtx)
+						}
+						return material.Button(th, &c.btnRefresh, "Refresh").Layout(gtx)
+					}),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if c.err == nil {
+				return fwk.Dim{}
+			}
+			inset := layout.Inset{Bottom: 8}
+			lbl := material.Body2(th, c.err.Error())
+			lbl.Color = color.NRGBA(colornames.Red500)
+			return inset.Layout(gtx, lbl.Layout)
+		}),
+	}
+	if len(txs) == 0 {
+		children = append(children, layout.Rigid(material.Body2(th, "No transactions yet").Layout))
+	}
+	for _, tx := range txs {
+		tx := tx
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return tx.Layout(gtx, c)
+		}))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+func (c *tabHistoryChainItem) fetchTransactions() {
+	go func() {
+		txs, err := wallet.GlobalWallet.TransactionHistory(&c.conn.Chain.ChainID)
+		if err != nil {
+			c.err = err
+		}
+		items := make([]*tabHistoryTxItem, len(txs))
+		for i, tx := range txs {
+			items[i] = &tabHistoryTxItem{Transaction: tx}
+		}
+		c.txs = items
+		c.page.Window().Invalidate()
+	}()
+}
+
+func (c *tabHistoryChainItem) scan() {
+	c.scanning = true
+	go func() {
+		c.err = wallet.GlobalWallet.ScanTransactionHistory(c.conn)
+		c.scanning = false
+		c.page.Window().Invalidate()
+	}()
+}
+
+type tabHistoryTxItem struct {
+	model.Transaction
+	btnCopyLink widget.Clickable
+	btnChat     widget.Clickable
+	// resolving is true while the protonet user of the counterparty is looked up
+	resolving bool
+}
+
+func (t *tabHistoryTxItem) Layout(gtx fwk.Gtx, c *tabHistoryChainItem) fwk.Dim {
+	th := c.page.Theme
+	link := c.conn.Chain.ExplorerTxURL(t.Hash)
+	if link == "" {
+		link = t.Hash
+	}
+	if t.btnCopyLink.Clicked() {
+		clipboard.WriteOp{Text: link}.Add(gtx.Ops)
+		c.page.Snackbar().Show("Copied "+link, nil, color.NRGBA{}, "")
+	}
+	if t.btnChat.Clicked() && !t.resolving {
+		t.chat(c)
+	}
+	var summary string
+	switch t.Direction {
+	case model.TransactionIncoming:
+		summary = fmt.Sprintf("Received %s from %s", wallet.FormatTransactionValue(t.Transaction), t.From)
+	default:
+		summary = fmt.Sprintf("Sent %s to %s", wallet.FormatTransactionValue(t.Transaction), t.To)
+	}
+	status, statusColor := "Pending", color.NRGBA(colornames.Orange500)
+	switch t.Status {
+	case model.TransactionStatusConfirmed:
+		status, statusColor = "Confirmed", color.NRGBA(colornames.Green500)
+	case model.TransactionStatusFailed:
+		status, statusColor = "Failed", color.NRGBA(colornames.Red500)
+	}
+	inset := layout.Inset{Bottom: 12}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		flex := layout.Flex{Alignment: layout.Middle}
+		return flex.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				flex := layout.Flex{Axis: layout.Vertical}
+				return flex.Layout(gtx,
+					layout.Rigid(material.Body1(th, summary).Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						txt := fmt.Sprintf("%s · %s", status, t.CreatedAt.Format("2006-01-02 15:04"))
+						lbl := material.Caption(th, txt)
+						lbl.Color = statusColor
+						return lbl.Layout(gtx)
+					}),
+					layout.Rigid(material.Caption(th, link).Layout),
+				)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				inset := layout.Inset{Left: 16}
+				if t.resolving {
+					loader := view.Loader{Theme: th}
+					return inset.Layout(gtx, loader.Layout)
+				}
+				return inset.Layout(gtx, material.Button(th, &t.btnChat, "Chat").Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				inset := layout.Inset{Left: 8}
+				return inset.Layout(gtx, material.Button(th, &t.btnCopyLink, "Copy Link").Layout)
+			}),
+		)
+	})
+}
+
+// counterparty returns the address the transaction was exchanged with
+func (t *tabHistoryTxItem) counterparty() string {
+	if t.Direction == model.TransactionIncoming {
+		return t.From
+	}
+	return t.To
+}
+
+// chat looks up the protonet user owning the counterparty address, adds it to the contacts and
+// opens the chat with it
+func (t *tabHistoryTxItem) chat(c *tabHistoryChainItem) {
+	t.resolving = true
+	address := t.counterparty()
+	go func() {
+		defer c.page.Window().Invalidate()
+		contact, err := chat.GlobalChat.AddContact(context.Background(), address)
+		t.resolving = false
+		if err != nil {
+			txt := fmt.Sprintf("Couldn't find %s: %s", address, err)
+			c.page.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+			return
+		}
+		chatRoomPage := chatroom.New(c.page.Manager, contact)
+		c.page.NavigateToURL(fwk.ChatPageURL, func() {
+			c.page.NavigateToPage(chatRoomPage, nil)
+		})
+	}()
+}
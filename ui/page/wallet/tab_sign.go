@@ -0,0 +1,216 @@
+package wallet
+
+import (
+	"fmt"
+	"gioui.org/io/clipboard"
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/mearaj/protonet/internal/wallet"
+	"github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"image/color"
+	"strconv"
+	"time"
+)
+
+// tabSign signs personal messages and EIP-712 typed data with the current account and verifies
+// the signatures of any address
+type tabSign struct {
+	initialized bool
+	layout.List
+	*page
+	title          string
+	kind           widget.Enum
+	inputPayload   component.TextField
+	inputAddress   component.TextField
+	inputSignature component.TextField
+	btnSign        widget.Clickable
+	btnVerify      widget.Clickable
+	btnCopy        widget.Clickable
+	signature      string
+	err            error
+	verified       string
+	verifyErr      error
+}
+
+func (p *tabSign) init() {
+	p.kind.Value = strconv.Itoa(int(wallet.SignKindMessage))
+	p.inputAddress = component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}}
+	p.inputSignature = component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}}
+	p.initialized = true
+}
+
+func (p *tabSign) drawTabHead(gtx fwk.Gtx) fwk.Dim {
+	if !p.initialized {
+		p.init()
+	}
+	inset := layout.UniformInset(12)
+	maxWidth := p.width / len(p.Tabs.Tabs)
+	gtx.Constraints.Max.X, gtx.Constraints.Min.X = maxWidth, maxWidth
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return material.H6(p.Theme, p.title).Layout(gtx)
+		})
+	})
+}
+
+func (p *tabSign) drawTabBody(gtx fwk.Gtx) fwk.Dim {
+	if !p.initialized {
+		p.init()
+	}
+	if p.btnSign.Clicked() {
+		p.sign()
+	}
+	if p.btnVerify.Clicked() {
+		p.verify()
+	}
+	if p.btnCopy.Clicked() {
+		clipboard.WriteOp{Text: p.signature}.Add(gtx.Ops)
+		p.Snackbar().Show("Copied signature", nil, color.NRGBA{}, "")
+	}
+	th := p.Theme
+	inset := layout.UniformInset(16)
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		if !wallet.GlobalWallet.IsOpen() {
+			return material.Body1(th, "Open the wallet to sign messages").Layout(gtx)
+		}
+		return p.List.Layout(gtx, 1, func(gtx layout.Context, index int) layout.Dimensions {
+			flex := layout.Flex{Axis: layout.Vertical}
+			return flex.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					flex := layout.Flex{Alignment: layout.Middle}
+					return flex.Layout(gtx,
+						layout.Rigid(p.drawKind(wallet.SignKindMessage)),
+						layout.Rigid(p.drawKind(wallet.SignKindTypedData)),
+					)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					hint := "Message, text or 0x prefixed hex"
+					if p.signKind() == wallet.SignKindTypedData {
+						hint = "Typed Data JSON"
+					}
+					inset := layout.Inset{Top: 8}
+					return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return p.inputPayload.Layout(gtx, th, hint)
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					inset := layout.Inset{Top: 16}
+					return inset.Layout(gtx, material.Button(th, &p.btnSign, "Sign").Layout)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return p.drawError(gtx, p.err)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if p.signature == "" {
+						return fwk.Dim{}
+					}
+					inset := layout.Inset{Top: 16}
+					return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						flex := layout.Flex{Alignment: layout.Middle}
+						return flex.Layout(gtx,
+							layout.Flexed(1, material.Body2(th, p.signature).Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								inset := layout.Inset{Left: 16}
+								return inset.Layout(gtx, material.Button(th, &p.btnCopy, "Copy").Layout)
+							}),
+						)
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					inset := layout.Inset{Top: 24, Bottom: 8}
+					return inset.Layout(gtx, component.Divider(th).Layout)
+				}),
+				layout.Rigid(material.H6(th, "Verify").Layout),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					inset := layout.Inset{Top: 8}
+					return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return p.inputAddress.Layout(gtx, th, "Signer Address")
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					inset := layout.Inset{Top: 8}
+					return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						return p.inputSignature.Layout(gtx, th, "Signature")
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					inset := layout.Inset{Top: 16}
+					return inset.Layout(gtx, material.Button(th, &p.btnVerify, "Verify").Layout)
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if p.verified == "" {
+						return p.drawError(gtx, p.verifyErr)
+					}
+					inset := layout.Inset{Top: 8}
+					lbl := material.Body2(th, p.verified)
+					lbl.Color = color.NRGBA(colornames.Green500)
+					return inset.Layout(gtx, lbl.Layout)
+				}),
+			)
+		})
+	})
+}
+
+func (p *tabSign) drawKind(kind wallet.SignKind) layout.Widget {
+	return func(gtx layout.Context) layout.Dimensions {
+		inset := layout.Inset{Right: 16}
+		radio := material.RadioButton(p.Theme, &p.kind, strconv.Itoa(int(kind)), kind.String())
+		return inset.Layout(gtx, radio.Layout)
+	}
+}
+
+func (p *tabSign) drawError(gtx fwk.Gtx, err error) fwk.Dim {
+	if err == nil {
+		return fwk.Dim{}
+	}
+	inset := layout.Inset{Top: 8}
+	lbl := material.Body2(p.Theme, err.Error())
+	lbl.Color = color.NRGBA(colornames.Red500)
+	return inset.Layout(gtx, lbl.Layout)
+}
+
+func (p *tabSign) signKind() wallet.SignKind {
+	kind, _ := strconv.Atoi(p.kind.Value)
+	return wallet.SignKind(kind)
+}
+
+// sign shows the decoded data for approval and signs it with the current account once approved,
+// the verification fields are filled with the signature
+func (p *tabSign) sign() {
+	p.signature = ""
+	request, err := wallet.NewSignRequest(p.signKind(), p.inputPayload.Text())
+	p.err = err
+	if err != nil {
+		return
+	}
+	approval := view.NewSignApproval(p.Manager, p.Theme, request)
+	approval.OnSigned = func(signature []byte) {
+		account, _ := wallet.GlobalWallet.Account()
+		p.signature = hexutil.Encode(signature)
+		p.inputAddress.SetText(account.EthAddress)
+		p.inputSignature.SetText(p.signature)
+		p.Window().Invalidate()
+	}
+	p.Modal().Show(approval.Layout, nil, fwk.Animation{
+		Duration: time.Millisecond * 250,
+		State:    component.Invisible,
+		Started:  time.Time{},
+	})
+}
+
+func (p *tabSign) verify() {
+	p.verified = ""
+	request, err := wallet.NewSignRequest(p.signKind(), p.inputPayload.Text())
+	if err == nil {
+		err = request.Verify(p.inputAddress.Text(), p.inputSignature.Text())
+	}
+	p.verifyErr = err
+	if err == nil {
+		p.verified = fmt.Sprintf("Valid signature of %s", p.inputAddress.Text())
+	}
+}
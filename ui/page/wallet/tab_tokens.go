@@ -0,0 +1,283 @@
+package wallet
+
+import (
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/wallet"
+	"github.com/mearaj/protonet/ui/fwk"
+	"github.com/mearaj/protonet/ui/view"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"image/color"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// tabTokens shows the token holdings of the current account on the connected chains
+type tabTokens struct {
+	initialized bool
+	layout.List
+	*page
+	title      string
+	chainItems []*tabTokensChainItem
+}
+
+func (p *tabTokens) init() {
+	p.chainItems = make([]*tabTokensChainItem, 0)
+	for _, ch := range wallet.GlobalWallet.Connections() {
+		if len(ch.Clients()) == 0 {
+			continue
+		}
+		p.chainItems = append(p.chainItems, &tabTokensChainItem{
+			page: p.page,
+			conn: ch,
+			inputAddress: component.TextField{
+				Editor: widget.Editor{SingleLine: true, Submit: true},
+			},
+		})
+	}
+	p.initialized = true
+}
+
+// refreshTokens fetches the tokens of chainID again when they are drawn next, chainID is in
+// decimal and empty chainID refreshes all the chains
+func (p *tabTokens) refreshTokens(chainID string) {
+	for _, item := range p.chainItems {
+		if chainID == "" || item.conn.Chain.ChainID.String() == chainID {
+			item.tokensFetched = false
+		}
+	}
+}
+
+// refreshBalances fetches the token balances of chainID again when they are drawn next
+func (p *tabTokens) refreshBalances(chainID *big.Int) {
+	for _, item := range p.chainItems {
+		if item.conn.Chain.ChainID.Cmp(chainID) == 0 {
+			item.refreshBalances()
+		}
+	}
+}
+
+func (p *tabTokens) drawTabHead(gtx fwk.Gtx) fwk.Dim {
+	if !p.initialized {
+		p.init()
+	}
+	inset := layout.UniformInset(12)
+	maxWidth := p.width / len(p.Tabs.Tabs)
+	gtx.Constraints.Max.X, gtx.Constraints.Min.X = maxWidth, maxWidth
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Center.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return material.H6(p.Theme, p.title).Layout(gtx)
+		})
+	})
+}
+
+func (p *tabTokens) drawTabBody(gtx fwk.Gtx) fwk.Dim {
+	if !p.initialized {
+		p.init()
+	}
+	searchText := strings.TrimSpace(strings.ToLower(p.search.Text()))
+	items := make([]*tabTokensChainItem, 0)
+	if wallet.GlobalWallet.IsOpen() {
+		for _, item := range p.chainItems {
+			if !item.conn.IsConnected() {
+				continue
+			}
+			name := strings.ToLower(item.conn.Chain.Name)
+			shortName := strings.ToLower(item.conn.Chain.ShortName)
+			if strings.Contains(name, searchText) || strings.Contains(shortName, searchText) {
+				items = append(items, item)
+			}
+		}
+	}
+	inset := layout.UniformInset(16)
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		if len(items) == 0 {
+			txt := "Connect to a chain in the All tab to see its tokens"
+			return material.Body1(p.Theme, txt).Layout(gtx)
+		}
+		return p.List.Layout(gtx, len(items), func(gtx layout.Context, index int) layout.Dimensions {
+			flex := layout.Flex{Axis: layout.Vertical}
+			return flex.Layout(gtx,
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					inset := layout.Inset{Top: 12, Bottom: 12}
+					return inset.Layout(gtx, items[index].Layout)
+				}),
+				layout.Rigid(component.Divider(p.Theme).Layout),
+			)
+		})
+	})
+}
+
+// tabTokensChainItem lists the tokens of a chain and adds tokens by contract address
+type tabTokensChainItem struct {
+	page          *page
+	conn          *evm.RPCClients
+	tokens        []*tabTokensTokenItem
+	tokensFetched bool
+	inputAddress  component.TextField
+	btnAdd        widget.Clickable
+	adding        bool
+	err           error
+}
+
+func (c *tabTokensChainItem) Layout(gtx fwk.Gtx) fwk.Dim {
+	if !c.tokensFetched {
+		c.tokensFetched = true
+		c.fetchTokens()
+	}
+	if c.btnAdd.Clicked() && !c.adding {
+		c.addToken()
+	}
+	th := c.page.Theme
+	tokens := c.tokens
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Bottom: 8}
+			return inset.Layout(gtx, material.H5(th, c.conn.Chain.Name).Layout)
+		}),
+	}
+	for _, token := range tokens {
+		token := token
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return token.Layout(gtx, c)
+		}))
+	}
+	children = append(children,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			inset := layout.Inset{Top: 8}
+			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				flex := layout.Flex{Alignment: layout.Middle}
+				return flex.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return c.inputAddress.Layout(gtx, th, "Token Contract Address")
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						inset := layout.Inset{Left: 16}
+						if c.adding {
+							loader := view.Loader{Theme: th}
+							return inset.Layout(gtx, loader.Layout)
+						}
+						return inset.Layout(gtx, material.Button(th, &c.btnAdd, "Add Token").Layout)
+					}),
+				)
+			})
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			if c.err == nil {
+				return fwk.Dim{}
+			}
+			inset := layout.Inset{Top: 4}
+			lbl := material.Body2(th, c.err.Error())
+			lbl.Color = color.NRGBA(colornames.Red500)
+			return inset.Layout(gtx, lbl.Layout)
+		}),
+	)
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+func (c *tabTokensChainItem) fetchTokens() {
+	go func() {
+		tokens, err := wallet.GlobalWallet.ChainTokens(&c.conn.Chain.ChainID)
+		if err != nil {
+			c.err = err
+		}
+		items := make([]*tabTokensTokenItem, len(tokens))
+		for i, token := range tokens {
+			items[i] = &tabTokensTokenItem{Token: token}
+		}
+		c.tokens = items
+		c.page.Window().Invalidate()
+	}()
+}
+
+// refreshBalances fetches the token balances again when they are drawn next
+func (c *tabTokensChainItem) refreshBalances() {
+	for _, token := range c.tokens {
+		token.balFetched = false
+	}
+}
+
+func (c *tabTokensChainItem) addToken() {
+	c.adding = true
+	c.err = nil
+	address := strings.TrimSpace(c.inputAddress.Text())
+	go func() {
+		token, err := wallet.GlobalWallet.AddToken(c.conn, address)
+		c.adding = false
+		c.err = err
+		if err == nil {
+			c.inputAddress.SetText("")
+			txt := fmt.Sprintf("Successfully added token %s", token.Symbol)
+			c.page.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+		}
+		c.page.Window().Invalidate()
+	}()
+}
+
+type tabTokensTokenItem struct {
+	evm.Token
+	btnSend    widget.Clickable
+	btnRemove  widget.Clickable
+	bal        string
+	balFetched bool
+	err        error
+}
+
+func (t *tabTokensTokenItem) Layout(gtx fwk.Gtx, c *tabTokensChainItem) fwk.Dim {
+	th := c.page.Theme
+	if !t.balFetched {
+		t.balFetched = true
+		go func() {
+			bal, err := wallet.GlobalWallet.TokenBalance(c.conn, t.Token)
+			t.err = err
+			if err == nil {
+				t.bal = evm.FormatUnits(bal, t.Decimals)
+			}
+			c.page.Window().Invalidate()
+		}()
+	}
+	if t.btnSend.Clicked() {
+		token := t.Token
+		c.page.Modal().Show(view.NewSendForm(c.page.Manager, th, c.conn, &token).Layout, nil, fwk.Animation{
+			Duration: time.Millisecond * 250,
+			State:    component.Invisible,
+			Started:  time.Time{},
+		})
+	}
+	if t.btnRemove.Clicked() {
+		if err := wallet.GlobalWallet.RemoveToken(t.Token); err != nil {
+			c.err = err
+		}
+	}
+	inset := layout.Inset{Bottom: 8}
+	return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		flex := layout.Flex{Alignment: layout.Middle}
+		return flex.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				txt := fmt.Sprintf("%s %s", t.bal, t.Symbol)
+				if t.err != nil {
+					txt = fmt.Sprintf("%s: %s", t.Symbol, t.err)
+				}
+				return material.Body1(th, txt).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				inset := layout.Inset{Left: 16}
+				return inset.Layout(gtx, material.Button(th, &t.btnSend, "Send").Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if !t.Custom {
+					return fwk.Dim{}
+				}
+				inset := layout.Inset{Left: 8}
+				btn := material.Button(th, &t.btnRemove, "Remove")
+				btn.Background = color.NRGBA(colornames.Red500)
+				return inset.Layout(gtx, btn.Layout)
+			}),
+		)
+	})
+}
@@ -10,8 +10,12 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"gioui.org/x/component"
+	"gioui.org/x/explorer"
+	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/assets/fonts"
 	"github.com/mearaj/protonet/internal/chat"
+	"github.com/mearaj/protonet/internal/common"
+	"github.com/mearaj/protonet/internal/keystore"
 	"github.com/mearaj/protonet/internal/wallet"
 	. "github.com/mearaj/protonet/ui/fwk"
 	"golang.org/x/exp/shiny/materialdesign/colornames"
@@ -19,20 +23,25 @@ import (
 	"image"
 	"image/color"
 	"strings"
+	"time"
 )
 
 type AccountDetails struct {
 	*material.Theme
 	buttonCopyPvtKey        IconButton
 	buttonCopyPubKey        IconButton
+	buttonCopyMnemonic      IconButton
+	buttonExportKeystore    IconButton
 	buttonPrivateKeyVisible IconButton
 	buttonPrivateKeyHidden  IconButton
 	inputPassword           *component.TextField
 	Account                 chat.Account
 	inputPasswordStr        string
 	pvtKeyStr               string
+	mnemonicStr             string
 	pvtKeyListLayout        layout.List
 	pubKeyListLayout        layout.List
+	mnemonicListLayout      layout.List
 	Manager
 }
 
@@ -40,6 +49,7 @@ func NewAccountDetails(manager Manager, account chat.Account) *AccountDetails {
 	iconCopy, _ := widget.NewIcon(icons.ContentContentCopy)
 	iconVisible, _ := widget.NewIcon(icons.ActionVisibility)
 	iconHidden, _ := widget.NewIcon(icons.ActionVisibilityOff)
+	iconExport, _ := widget.NewIcon(icons.FileFileDownload)
 	accountDetails := AccountDetails{
 		Theme:         manager.Theme(),
 		Account:       account,
@@ -55,6 +65,16 @@ func NewAccountDetails(manager Manager, account chat.Account) *AccountDetails {
 			Icon:  iconCopy,
 			Text:  "Copy Public Key",
 		},
+		buttonCopyMnemonic: IconButton{
+			Theme: manager.Theme(),
+			Icon:  iconCopy,
+			Text:  "Copy Recovery Phrase",
+		},
+		buttonExportKeystore: IconButton{
+			Theme: manager.Theme(),
+			Icon:  iconExport,
+			Text:  "Export Keystore File",
+		},
 		buttonPrivateKeyVisible: IconButton{
 			Theme: manager.Theme(),
 			Icon:  iconVisible,
@@ -90,6 +110,14 @@ func (ad *AccountDetails) Layout(gtx Gtx) Dim {
 			inset := inset
 			return inset.Layout(gtx, ad.drawPvtKeyField)
 		}),
+		layout.Rigid(func(gtx Gtx) Dim {
+			// Recovery phrase exists only for HD accounts
+			if ad.Account.HDPath == "" {
+				return Dim{}
+			}
+			inset := inset
+			return inset.Layout(gtx, ad.drawMnemonicField)
+		}),
 		layout.Rigid(func(gtx Gtx) Dim {
 			inset := inset
 			return inset.Layout(gtx, ad.drawPubKeyField)
@@ -107,16 +135,22 @@ func (ad *AccountDetails) drawPasswordField(gtx Gtx) Dim {
 			ad.pvtKeyStr = ""
 		} else {
 			err = wallet.GlobalWallet.VerifyPassword(ad.inputPasswordStr)
+			if err == nil {
+				ad.pvtKeyStr, err = common.GetPrivateKeyFromPasswd(ad.Account, ad.inputPasswordStr)
+			}
+			if err == nil && ad.Account.HDPath != "" {
+				ad.mnemonicStr, err = wallet.GlobalWallet.Mnemonic(ad.Account)
+			}
 			if err != nil {
 				ad.pvtKeyStr = ""
+				ad.mnemonicStr = ""
 				ad.inputPassword.SetError(err.Error())
-			} else {
-				ad.pvtKeyStr = ad.Account.PrivateKey
 			}
 		}
 	}
 	if ad.buttonPrivateKeyVisible.Button.Clicked() {
 		ad.pvtKeyStr = ""
+		ad.mnemonicStr = ""
 	}
 	labelPasswordText := "Enter Password"
 	flex := layout.Flex{Axis: layout.Vertical}
@@ -147,6 +181,9 @@ func (ad *AccountDetails) drawPvtKeyField(gtx Gtx) Dim {
 	if ad.buttonCopyPvtKey.Button.Clicked() {
 		ad.Manager.Window().WriteClipboard(ad.pvtKeyStr)
 	}
+	if ad.buttonExportKeystore.Button.Clicked() && ad.pvtKeyStr != "" {
+		ad.exportKeystore()
+	}
 	flex := layout.Flex{Axis: layout.Vertical}
 	return flex.Layout(gtx,
 		layout.Rigid(func(gtx Gtx) Dim {
@@ -183,6 +220,93 @@ func (ad *AccountDetails) drawPvtKeyField(gtx Gtx) Dim {
 			gtx.Constraints.Min.X = gtx.Constraints.Max.X
 			return ad.buttonCopyPvtKey.Layout(gtx)
 		}),
+		layout.Rigid(func(gtx Gtx) Dim {
+			// password is verified only when the private key is visible
+			if ad.pvtKeyStr == "" {
+				return Dim{}
+			}
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, ad.buttonExportKeystore.Layout)
+		}),
+	)
+}
+
+// exportKeystore saves the private key as keystore file encrypted with the verified password
+func (ad *AccountDetails) exportKeystore() {
+	passwd := ad.inputPasswordStr
+	account := ad.Account
+	go func() {
+		var err error
+		defer func() {
+			if err != nil {
+				alog.Logger().Errorln(err)
+				ad.Snackbar().Show(err.Error(), nil, color.NRGBA{}, "")
+			}
+			ad.Window().Invalidate()
+		}()
+		keyJSON, err := wallet.GlobalWallet.ExportKeystore(account, passwd)
+		if err != nil {
+			return
+		}
+		file, err := ad.Explorer().CreateFile(keystore.FileName(account.EthAddress, time.Now()) + ".json")
+		if errors.Is(err, explorer.ErrUserDecline) {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+		_, err = file.Write(keyJSON)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return
+		}
+		ad.Snackbar().Show("Keystore file is exported, it's encrypted with your password", nil, color.NRGBA{}, "")
+	}()
+}
+
+func (ad *AccountDetails) drawMnemonicField(gtx Gtx) Dim {
+	if ad.buttonCopyMnemonic.Button.Clicked() {
+		ad.Manager.Window().WriteClipboard(ad.mnemonicStr)
+	}
+	flex := layout.Flex{Axis: layout.Vertical}
+	return flex.Layout(gtx,
+		layout.Rigid(func(gtx Gtx) Dim {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			var txt string
+			txt = strings.TrimSpace(ad.mnemonicStr)
+			txtColor := ad.Theme.Fg
+			if txt == "" {
+				txt = "Your Recovery Phrase (" + ad.Account.HDPath + ")"
+				txtColor = color.NRGBA(colornames.Grey500)
+			}
+			inset := layout.UniformInset(unit.Dp(16))
+			mac := op.Record(gtx.Ops)
+			d := inset.Layout(gtx,
+				func(gtx Gtx) Dim {
+					lbl := material.Label(ad.Theme, ad.Theme.TextSize, txt)
+					lbl.Color = txtColor
+					return ad.mnemonicListLayout.Layout(gtx, 1, func(gtx layout.Context, index int) layout.Dimensions {
+						return lbl.Layout(gtx)
+					})
+				})
+			stop := mac.Stop()
+			bounds := image.Rect(0, 0, d.Size.X, d.Size.Y)
+			rect := clip.UniformRRect(bounds, gtx.Dp(4))
+			paint.FillShape(gtx.Ops,
+				ad.Theme.Fg,
+				clip.Stroke{Path: rect.Path(gtx.Ops), Width: float32(gtx.Dp(1))}.Op(),
+			)
+			stop.Add(gtx.Ops)
+			return d
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
+		layout.Rigid(func(gtx Gtx) Dim {
+			gtx.Constraints.Min.X = gtx.Constraints.Max.X
+			return ad.buttonCopyMnemonic.Layout(gtx)
+		}),
 	)
 }
 
@@ -222,6 +346,14 @@ func (ad *AccountDetails) drawPubKeyField(gtx Gtx) Dim {
 			stop.Add(gtx.Ops)
 			return d
 		}),
+		layout.Rigid(func(gtx Gtx) Dim {
+			if publicKey == "" {
+				return Dim{}
+			}
+			inset := layout.Inset{Top: unit.Dp(8)}
+			txt := "Chat Identity: " + identityAlgorithmLabels[ad.Account.Algorithm()]
+			return inset.Layout(gtx, material.Body2(ad.Theme, txt).Layout)
+		}),
 		layout.Rigid(layout.Spacer{Height: unit.Dp(8)}.Layout),
 		layout.Rigid(func(gtx Gtx) Dim {
 			gtx.Constraints.Min.X = gtx.Constraints.Max.X
@@ -1,6 +1,7 @@
 package view
 
 import (
+	"errors"
 	"gioui.org/io/clipboard"
 	"gioui.org/layout"
 	"gioui.org/op"
@@ -10,16 +11,32 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"gioui.org/x/explorer"
+	"github.com/mearaj/protonet/alog"
 	"github.com/mearaj/protonet/assets/fonts"
+	"github.com/mearaj/protonet/internal/keystore"
+	"github.com/mearaj/protonet/internal/model"
 	"github.com/mearaj/protonet/internal/wallet"
 	. "github.com/mearaj/protonet/ui/fwk"
 	"golang.org/x/exp/shiny/materialdesign/colornames"
 	"golang.org/x/exp/shiny/materialdesign/icons"
 	"image"
 	"image/color"
+	"io"
 	"strings"
 )
 
+// maxKeystoreFileSize limits the size of the chosen keystore file, keystore files are around 500 bytes
+const maxKeystoreFileSize = 1 << 16
+
+// identityAlgorithmLabels describe the algorithms of the chat identity of a new account
+var identityAlgorithmLabels = map[string]string{
+	model.IdentityECDSA:     "ECDSA P-256, compatible with the older versions",
+	model.IdentitySecp256k1: "secp256k1, the public key of the Ethereum account",
+	model.IdentityEd25519:   "Ed25519",
+}
+
 type accountForm struct {
 	Manager
 	Theme                 *material.Theme
@@ -33,6 +50,9 @@ type accountForm struct {
 	btnNewID              IconButton
 	btnSubmitImportKey    IconButton
 	btnPasteKey           IconButton
+	btnChooseKeystore     IconButton
+	inputPassphrase       *component.TextField
+	algorithmEnum         widget.Enum
 	navigationIcon        *widget.Icon
 	iDDetailsView         AccountDetails
 	errorCreateNewID      error
@@ -49,6 +69,7 @@ func NewAccountFormView(manager Manager, onSuccess func()) View {
 	iconCreateNewID, _ := widget.NewIcon(icons.ActionDone)
 	iconImportFile, _ := widget.NewIcon(icons.FileFileUpload)
 	pasteIcon, _ := widget.NewIcon(icons.ContentContentPaste)
+	fileIcon, _ := widget.NewIcon(icons.FileFolderOpen)
 	th := manager.Theme()
 	errorTh := *fonts.NewTheme()
 	errorTh.ContrastBg = color.NRGBA(colornames.Red500)
@@ -74,6 +95,12 @@ func NewAccountFormView(manager Manager, onSuccess func()) View {
 			Icon:  pasteIcon,
 			Text:  "Paste",
 		},
+		btnChooseKeystore: IconButton{
+			Theme: th,
+			Icon:  fileIcon,
+			Text:  "Keystore File",
+		},
+		inputPassphrase: &component.TextField{Editor: widget.Editor{SingleLine: true, Mask: '*'}},
 		btnNewID: IconButton{
 			Theme: th,
 			Icon:  iconCreateNewID,
@@ -89,6 +116,7 @@ func NewAccountFormView(manager Manager, onSuccess func()) View {
 			Manager: manager,
 		},
 	}
+	s.algorithmEnum.Value = model.IdentityECDSA
 	s.ModalContent = NewModalContent(func() {
 		s.Modal().Dismiss(nil)
 		s.creatingNewID = false
@@ -108,6 +136,10 @@ func (p *accountForm) Layout(gtx Gtx) Dim {
 	inset := layout.UniformInset(unit.Dp(16))
 	flex := layout.Flex{Axis: layout.Vertical, Alignment: layout.Start}
 	d := flex.Layout(gtx,
+		layout.Rigid(func(gtx Gtx) Dim {
+			inset := inset
+			return inset.Layout(gtx, p.drawAlgorithmField)
+		}),
 		layout.Rigid(func(gtx Gtx) Dim {
 			inset := inset
 			return inset.Layout(gtx, p.drawImportKeyTextField)
@@ -141,6 +173,18 @@ func (p *accountForm) Layout(gtx Gtx) Dim {
 	return d
 }
 
+// drawAlgorithmField chooses the algorithm of the chat identity of the imported or created account
+func (p *accountForm) drawAlgorithmField(gtx Gtx) Dim {
+	children := []layout.FlexChild{
+		layout.Rigid(material.Subtitle1(p.Theme, "Chat Identity").Layout),
+	}
+	for _, algorithm := range model.IdentityAlgorithms {
+		label := identityAlgorithmLabels[algorithm]
+		children = append(children, layout.Rigid(material.RadioButton(p.Theme, &p.algorithmEnum, algorithm, label).Layout))
+	}
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
 func (p *accountForm) drawImportKeyTextField(gtx Gtx) Dim {
 	if p.btnPasteKey.Button.Clicked() {
 		clipboard.ReadOp{Tag: &p.btnPasteKey}.Add(gtx.Ops)
@@ -158,9 +202,15 @@ func (p *accountForm) drawImportKeyTextField(gtx Gtx) Dim {
 
 	if p.btnClear.Button.Clicked() {
 		p.pvtKeyStr = ""
+		p.inputPassphrase.SetText("")
 		p.errorImportKey = nil
 	}
 
+	if p.btnChooseKeystore.Button.Clicked() {
+		p.chooseKeystoreFile()
+	}
+	isKeystore := keystore.IsKeystore([]byte(p.pvtKeyStr))
+
 	if p.btnSubmitImportKey.Button.Clicked() && !p.submittingImportedKey {
 		p.submittingImportedKey = true
 		p.createAccountFromPvtKeyHexStr()
@@ -173,7 +223,7 @@ func (p *accountForm) drawImportKeyTextField(gtx Gtx) Dim {
 			txt = strings.TrimSpace(p.pvtKeyStr)
 			txtColor := p.Theme.Fg
 			if txt == "" {
-				txt = "Paste key file contents here"
+				txt = "Paste key file contents or recovery phrase here"
 				txtColor = color.NRGBA(colornames.Grey500)
 			}
 			if p.errorImportKey != nil {
@@ -199,6 +249,15 @@ func (p *accountForm) drawImportKeyTextField(gtx Gtx) Dim {
 			stop.Add(gtx.Ops)
 			return d
 		}),
+		layout.Rigid(func(gtx Gtx) Dim {
+			// keystore file is encrypted, hence ask for its passphrase
+			if !isKeystore {
+				return Dim{}
+			}
+			return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+				return p.inputPassphrase.Layout(gtx, p.Theme, "Keystore Passphrase")
+			})
+		}),
 		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 			gtx.Constraints.Min.X = gtx.Constraints.Max.X
 			mobileWidth := gtx.Dp(350)
@@ -213,6 +272,9 @@ func (p *accountForm) drawImportKeyTextField(gtx Gtx) Dim {
 			clearLayout := layout.Flexed(1, func(gtx Gtx) Dim {
 				return p.btnClear.Layout(gtx)
 			})
+			keystoreLayout := layout.Flexed(1, func(gtx Gtx) Dim {
+				return p.btnChooseKeystore.Layout(gtx)
+			})
 			if gtx.Constraints.Max.X <= mobileWidth {
 				flex.Axis = layout.Vertical
 				spacerLayout.Width = 0
@@ -226,6 +288,9 @@ func (p *accountForm) drawImportKeyTextField(gtx Gtx) Dim {
 				clearLayout = layout.Rigid(func(gtx Gtx) Dim {
 					return p.btnClear.Layout(gtx)
 				})
+				keystoreLayout = layout.Rigid(func(gtx Gtx) Dim {
+					return p.btnChooseKeystore.Layout(gtx)
+				})
 			}
 			inset := layout.Inset{Top: unit.Dp(16)}
 			return inset.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -234,6 +299,8 @@ func (p *accountForm) drawImportKeyTextField(gtx Gtx) Dim {
 					layout.Rigid(spacerLayout.Layout),
 					pasteLayout,
 					layout.Rigid(spacerLayout.Layout),
+					keystoreLayout,
+					layout.Rigid(spacerLayout.Layout),
 					clearLayout,
 				)
 			})
@@ -278,8 +345,16 @@ func (p *accountForm) drawAutoCreateField(gtx Gtx) Dim {
 
 func (p *accountForm) createAccountFromPvtKeyHexStr() {
 	p.submittingImportedKey = true
+	algorithm := p.algorithmEnum.Value
 	go func() {
-		p.errorImportKey = wallet.GlobalWallet.CreateAccount(p.pvtKeyStr)
+		// a private key is a single word whereas a recovery phrase has at least 12 words
+		if keystore.IsKeystore([]byte(p.pvtKeyStr)) {
+			p.errorImportKey = wallet.GlobalWallet.ImportKeystore([]byte(p.pvtKeyStr), p.inputPassphrase.Text(), algorithm)
+		} else if len(strings.Fields(p.pvtKeyStr)) > 1 {
+			p.errorImportKey = wallet.GlobalWallet.ImportMnemonic(p.pvtKeyStr, algorithm)
+		} else {
+			p.errorImportKey = wallet.GlobalWallet.CreateAccount(p.pvtKeyStr, algorithm)
+		}
 		p.submittingImportedKey = false
 		if p.errorImportKey == nil {
 			//p.account.PrivateKey = p.Service().Account().PrivateKey
@@ -293,8 +368,9 @@ func (p *accountForm) createAccountFromPvtKeyHexStr() {
 
 func (p *accountForm) autoCreateAccount() {
 	p.creatingNewID = true
+	algorithm := p.algorithmEnum.Value
 	go func() {
-		p.errorCreateNewID = wallet.GlobalWallet.AutoCreateAccount()
+		p.errorCreateNewID = wallet.GlobalWallet.AutoCreateAccount(algorithm)
 		p.creatingNewID = false
 		if p.errorCreateNewID == nil {
 			if p.OnSuccess != nil {
@@ -307,3 +383,36 @@ func (p *accountForm) autoCreateAccount() {
 		p.Window().Invalidate()
 	}()
 }
+
+// chooseKeystoreFile reads the keystore file chosen by the user into pvtKeyStr
+func (p *accountForm) chooseKeystoreFile() {
+	go func() {
+		var err error
+		defer func() {
+			if err != nil {
+				alog.Logger().Errorln(err)
+				p.errorImportKey = err
+			}
+			p.Window().Invalidate()
+		}()
+		file, err := p.Explorer().ChooseFile(".json")
+		if errors.Is(err, explorer.ErrUserDecline) {
+			err = nil
+			return
+		}
+		if err != nil {
+			return
+		}
+		defer func() { _ = file.Close() }()
+		data, err := io.ReadAll(io.LimitReader(file, maxKeystoreFileSize))
+		if err != nil {
+			return
+		}
+		if !keystore.IsKeystore(data) {
+			err = keystore.ErrInvalidKeystore
+			return
+		}
+		p.pvtKeyStr = string(data)
+		p.errorImportKey = nil
+	}()
+}
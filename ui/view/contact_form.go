@@ -1,6 +1,7 @@
 package view
 
 import (
+	"context"
 	"gioui.org/io/clipboard"
 	"gioui.org/io/key"
 	"gioui.org/layout"
@@ -10,12 +11,10 @@ import (
 	"gioui.org/x/component"
 	"github.com/mearaj/protonet/assets/fonts"
 	"github.com/mearaj/protonet/internal/chat"
-	"github.com/mearaj/protonet/internal/wallet"
 	. "github.com/mearaj/protonet/ui/fwk"
 	"golang.org/x/exp/shiny/materialdesign/colornames"
 	"golang.org/x/exp/shiny/materialdesign/icons"
 	"image/color"
-	"time"
 )
 
 // contactForm Always call NewContactForm function to create contactForm
@@ -107,7 +106,7 @@ func (p *contactForm) Layout(gtx Gtx) Dim {
 }
 
 func (p *contactForm) drawNewChatTextField(gtx Gtx) Dim {
-	labelHintText := "Enter/Paste contact's public address"
+	labelHintText := "Enter/Paste contact's public key, Ethereum address or ENS name"
 
 	if p.buttonPasteKey.Button.Clicked() {
 		clipboard.ReadOp{Tag: &p.buttonPasteKey}.Add(gtx.Ops)
@@ -129,17 +128,17 @@ func (p *contactForm) drawNewChatTextField(gtx Gtx) Dim {
 	}
 	if p.buttonSubmit.Button.Clicked() && !p.addingNewClient {
 		p.addingNewClient = true
-		acc, _ := wallet.GlobalWallet.Account()
-		contact := chat.Contact{PublicKey: p.inputNewChat.Text(), Identified: true, AccountPublicKey: acc.PublicKey}
-		contact.CreatedAt = time.Now()
-		contact.UpdatedAt = time.Now()
-		go func(contact *chat.Contact) {
-			p.errorNewChat = wallet.GlobalWallet.AddUpdateContact(contact)
+		input := p.inputNewChat.Text()
+		go func() {
+			defer p.Window().Invalidate()
+			// input is a public key, an ethereum address or an ens name
+			contact, err := chat.GlobalChat.AddContact(context.Background(), input)
+			p.errorNewChat = err
 			if p.errorNewChat == nil && p.OnSuccess != nil {
 				p.OnSuccess(contact.PublicKey)
 			}
 			p.addingNewClient = false
-		}(&contact)
+		}()
 	}
 	gtx.Constraints.Min.X = gtx.Constraints.Max.X
 	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
@@ -0,0 +1,24 @@
+package view
+
+import (
+	"github.com/mearaj/protonet/internal/chat"
+	"time"
+)
+
+// presenceRefreshInterval updates the last seen time shown for a contact
+const presenceRefreshInterval = time.Minute
+
+// PresenceText returns the text shown for the presence of a contact, typing first then online
+// and last seen, it's empty if the contact wasn't seen since the chat started. The returned time
+// is when the text changes by itself.
+func PresenceText(presence chat.Presence) (string, time.Time) {
+	switch {
+	case presence.IsTyping():
+		return "typing…", presence.TypingUntil
+	case presence.Online:
+		return "online", time.Time{}
+	case !presence.LastSeen.IsZero():
+		return "last seen " + CustomTime(presence.LastSeen), time.Now().Add(presenceRefreshInterval)
+	}
+	return "", time.Time{}
+}
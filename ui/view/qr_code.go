@@ -0,0 +1,49 @@
+package view
+
+import (
+	"gioui.org/layout"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"github.com/mearaj/protonet/alog"
+	"github.com/mearaj/protonet/internal/qrcode"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"image"
+)
+
+// qrCodeScale is the pixels of a module of the encoded image, the image is large enough that
+// scaling it down keeps the modules sharp
+const qrCodeScale = 8
+
+// QRCode draws Text as a QR code of Size, the code is encoded again only when Text changes
+type QRCode struct {
+	Text    string
+	Size    unit.Dp
+	encoded string
+	imgOp   paint.ImageOp
+	err     error
+}
+
+func (q *QRCode) Layout(gtx Gtx) Dim {
+	if q.encoded != q.Text {
+		q.encoded = q.Text
+		var code *qrcode.Code
+		code, q.err = qrcode.Encode(q.Text)
+		if q.err != nil {
+			alog.Logger().Errorln(q.err)
+		} else {
+			q.imgOp = paint.NewImageOp(code.Image(qrCodeScale, 4))
+		}
+	}
+	if q.err != nil {
+		return Dim{}
+	}
+	size := q.Size
+	if size == 0 {
+		size = 160
+	}
+	px := gtx.Dp(size)
+	gtx.Constraints.Min, gtx.Constraints.Max = image.Pt(px, px), image.Pt(px, px)
+	img := widget.Image{Src: q.imgOp, Fit: widget.Contain, Position: layout.Center}
+	return img.Layout(gtx)
+}
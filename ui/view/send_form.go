@@ -0,0 +1,203 @@
+package view
+
+import (
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"gioui.org/x/component"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mearaj/protonet/internal/evm"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"golang.org/x/exp/shiny/materialdesign/icons"
+	"image/color"
+	"strings"
+)
+
+// SendForm sends native currency of the chain or the token from the current account, the
+// transaction is prepared first and sent only after the user confirms its fee
+type SendForm struct {
+	Manager
+	Theme       *material.Theme
+	conn        *evm.RPCClients
+	token       *evm.Token
+	to          string
+	amount      string
+	inputTo     component.TextField
+	inputAmount component.TextField
+	btnReview   IconButton
+	btnYes      widget.Clickable
+	btnNo       widget.Clickable
+	prepared    *evm.PreparedTx
+	err         error
+	preparing   bool
+	sending     bool
+	// OnSent is called after the transaction is sent, instead of showing its hash
+	OnSent func(prepared *evm.PreparedTx, txHash common.Hash)
+	*ModalContent
+}
+
+// NewSendForm returns the form sending native currency if token is nil
+func NewSendForm(manager Manager, theme *material.Theme, conn *evm.RPCClients, token *evm.Token) *SendForm {
+	iconReview, _ := widget.NewIcon(icons.ActionDone)
+	f := &SendForm{
+		Manager:     manager,
+		Theme:       theme,
+		conn:        conn,
+		token:       token,
+		inputTo:     component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		inputAmount: component.TextField{Editor: widget.Editor{SingleLine: true, Submit: true}},
+		btnReview: IconButton{
+			Theme: theme,
+			Icon:  iconReview,
+			Text:  "Review",
+		},
+	}
+	f.ModalContent = NewModalContent(func() { f.Modal().Dismiss(nil) })
+	return f
+}
+
+// SetRecipient fixes the recipient of the transfer to address
+func (f *SendForm) SetRecipient(address string) {
+	f.inputTo.SetText(address)
+	f.inputTo.ReadOnly = true
+}
+
+// SetAmount fixes the amount of the transfer, amount is in decimal such as 1.5
+func (f *SendForm) SetAmount(amount string) {
+	f.inputAmount.SetText(amount)
+	f.inputAmount.ReadOnly = true
+}
+
+func (f *SendForm) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	if f.prepared != nil {
+		return f.ModalContent.DrawContent(gtx, f.Theme, f.drawConfirmation)
+	}
+	return f.ModalContent.DrawContent(gtx, f.Theme, f.drawForm)
+}
+
+func (f *SendForm) drawForm(gtx Gtx) Dim {
+	if f.btnReview.Button.Clicked() && !f.preparing {
+		f.prepare()
+	}
+	symbol := f.symbol()
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx,
+			layout.Rigid(func(gtx Gtx) Dim {
+				txt := fmt.Sprintf("Send %s on %s", symbol, f.conn.Chain.Name)
+				return material.H6(f.Theme, txt).Layout(gtx)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					return f.inputTo.Layout(gtx, f.Theme, "Recipient Address")
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					return f.inputAmount.Layout(gtx, f.Theme, fmt.Sprintf("Amount (%s)", symbol))
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if f.err == nil {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					lbl := material.Body2(f.Theme, f.err.Error())
+					lbl.Color = color.NRGBA(colornames.Red500)
+					return lbl.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					if f.preparing {
+						loader := Loader{Theme: f.Theme}
+						return loader.Layout(gtx)
+					}
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					return f.btnReview.Layout(gtx)
+				})
+			}),
+		)
+	})
+}
+
+func (f *SendForm) drawConfirmation(gtx Gtx) Dim {
+	if f.btnYes.Clicked() && !f.sending {
+		f.send()
+	}
+	if f.btnNo.Clicked() && !f.sending {
+		f.prepared = nil
+	}
+	if f.sending {
+		loader := Loader{Theme: f.Theme}
+		return layout.UniformInset(unit.Dp(16)).Layout(gtx, loader.Layout)
+	}
+	currency := f.conn.Chain.NativeCurrency
+	symbol := f.symbol()
+	content := fmt.Sprintf("Send %s %s to %s?\nMax network fee: %s %s",
+		f.amount, symbol, f.to,
+		evm.FormatUnits(f.prepared.MaxFee(), currency.Decimals), currency.Symbol,
+	)
+	if f.token == nil {
+		content = fmt.Sprintf("%s\nMax total: %s %s", content,
+			evm.FormatUnits(f.prepared.MaxTotal(), currency.Decimals), currency.Symbol)
+	}
+	promptContent := NewPromptContent(f.Theme, "Confirm Transaction", content, &f.btnYes, &f.btnNo)
+	return promptContent.Layout(gtx)
+}
+
+func (f *SendForm) prepare() {
+	f.preparing = true
+	f.err = nil
+	f.to = strings.TrimSpace(f.inputTo.Text())
+	f.amount = strings.TrimSpace(f.inputAmount.Text())
+	to, amount := f.to, f.amount
+	go func() {
+		if f.token != nil {
+			f.prepared, f.err = wallet.GlobalWallet.PrepareTokenTransfer(f.conn, *f.token, to, amount)
+		} else {
+			f.prepared, f.err = wallet.GlobalWallet.PrepareNativeTransfer(f.conn, to, amount)
+		}
+		f.preparing = false
+		f.Window().Invalidate()
+	}()
+}
+
+func (f *SendForm) send() {
+	f.sending = true
+	prepared := f.prepared
+	go func() {
+		txHash, err := wallet.GlobalWallet.SendTransaction(f.conn, prepared)
+		f.sending = false
+		if err != nil {
+			f.prepared = nil
+			f.err = err
+			f.Window().Invalidate()
+			return
+		}
+		f.Modal().Dismiss(func() {
+			if f.OnSent != nil {
+				f.OnSent(prepared, txHash)
+				return
+			}
+			txt := fmt.Sprintf("Transaction %s sent", txHash.Hex())
+			f.Snackbar().Show(txt, nil, color.NRGBA{}, "")
+		})
+		f.Window().Invalidate()
+	}()
+}
+
+// symbol returns the symbol of the currency being sent
+func (f *SendForm) symbol() string {
+	if f.token != nil {
+		return f.token.Symbol
+	}
+	return f.conn.Chain.NativeCurrency.Symbol
+}
@@ -0,0 +1,135 @@
+package view
+
+import (
+	"fmt"
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/mearaj/protonet/internal/wallet"
+	. "github.com/mearaj/protonet/ui/fwk"
+	"golang.org/x/exp/shiny/materialdesign/colornames"
+	"image/color"
+)
+
+// SignApproval shows the decoded data of a sign request and signs it with the current account
+// only after the user approves it
+type SignApproval struct {
+	Manager
+	Theme *material.Theme
+	// Origin is who asks for the signature, such as the url of a dApp, empty for the user
+	Origin     string
+	request    wallet.SignRequest
+	payload    string
+	err        error
+	btnApprove widget.Clickable
+	btnReject  widget.Clickable
+	signing    bool
+	// OnSigned is called with the signature after the request is approved and signed
+	OnSigned func(signature []byte)
+	// OnRejected is called when the user rejects the request or closes the approval
+	OnRejected func()
+	*ModalContent
+}
+
+func NewSignApproval(manager Manager, theme *material.Theme, request wallet.SignRequest) *SignApproval {
+	a := &SignApproval{
+		Manager: manager,
+		Theme:   theme,
+		request: request,
+	}
+	a.payload, a.err = request.Payload()
+	a.ModalContent = NewModalContent(a.reject)
+	return a
+}
+
+func (a *SignApproval) Layout(gtx Gtx) Dim {
+	gtx.Constraints.Max.X = int(float32(gtx.Constraints.Max.X) * 0.85)
+	gtx.Constraints.Max.Y = int(float32(gtx.Constraints.Max.Y) * 0.85)
+	return a.ModalContent.DrawContent(gtx, a.Theme, a.drawApproval)
+}
+
+func (a *SignApproval) drawApproval(gtx Gtx) Dim {
+	if a.btnApprove.Clicked() && !a.signing && a.payload != "" {
+		a.sign()
+	}
+	if a.btnReject.Clicked() && !a.signing {
+		a.reject()
+	}
+	account, _ := wallet.GlobalWallet.Account()
+	inset := layout.UniformInset(unit.Dp(16))
+	return inset.Layout(gtx, func(gtx Gtx) Dim {
+		flex := layout.Flex{Axis: layout.Vertical}
+		return flex.Layout(gtx,
+			layout.Rigid(material.H6(a.Theme, "Signature Request").Layout),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if a.Origin == "" {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Body1(a.Theme, "From "+a.Origin).Layout)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				txt := fmt.Sprintf("%s signed by %s", a.request.Kind, account.EthAddress)
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, material.Caption(a.Theme, txt).Layout)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, material.Body2(a.Theme, a.payload).Layout)
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				if a.err == nil {
+					return Dim{}
+				}
+				return layout.Inset{Top: unit.Dp(8)}.Layout(gtx, func(gtx Gtx) Dim {
+					lbl := material.Body2(a.Theme, a.err.Error())
+					lbl.Color = color.NRGBA(colornames.Red500)
+					return lbl.Layout(gtx)
+				})
+			}),
+			layout.Rigid(func(gtx Gtx) Dim {
+				return layout.Inset{Top: unit.Dp(16)}.Layout(gtx, func(gtx Gtx) Dim {
+					if a.signing {
+						loader := Loader{Theme: a.Theme}
+						return loader.Layout(gtx)
+					}
+					flex := layout.Flex{Spacing: layout.SpaceSides, Alignment: layout.Middle}
+					return flex.Layout(gtx,
+						layout.Rigid(func(gtx Gtx) Dim {
+							btn := material.Button(a.Theme, &a.btnReject, "Reject")
+							btn.Background = color.NRGBA(colornames.Red500)
+							return btn.Layout(gtx)
+						}),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(16)}.Layout),
+						layout.Rigid(material.Button(a.Theme, &a.btnApprove, "Sign").Layout),
+					)
+				})
+			}),
+		)
+	})
+}
+
+func (a *SignApproval) sign() {
+	a.signing = true
+	a.err = nil
+	go func() {
+		defer a.Window().Invalidate()
+		signature, err := wallet.GlobalWallet.Sign(a.request)
+		a.signing = false
+		if err != nil {
+			a.err = err
+			return
+		}
+		a.Modal().Dismiss(func() {
+			if a.OnSigned != nil {
+				a.OnSigned(signature)
+			}
+		})
+	}()
+}
+
+func (a *SignApproval) reject() {
+	a.Modal().Dismiss(func() {
+		if a.OnRejected != nil {
+			a.OnRejected()
+		}
+	})
+}